@@ -56,6 +56,9 @@ func parseArgs() (*interactive.Context, string, time.Duration, error) {
 	}
 
 	timeoutDuration := time.Duration(*timeout) * time.Second
+	if err := interactive.ConfigureSSHBackend(); err != nil {
+		return nil, "", timeoutDuration, err
+	}
 	goExpectSpawner := interactive.NewGoExpectSpawner()
 	var spawner interactive.Spawner = goExpectSpawner
 	context, err := interactive.SpawnSSH(&spawner, args[0], args[1], timeoutDuration, interactive.Verbose(true), interactive.SendTimeout(timeoutDuration))
@@ -109,5 +109,7 @@ func NewCommand() *cobra.Command {
 		return nil
 	}
 	addclaim.AddCommand(claimAddFile)
+	addclaim.AddCommand(newDiffCommand())
+	addclaim.AddCommand(newMergeCommand())
 	return addclaim
 }
@@ -0,0 +1,72 @@
+package claim
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/test-network-function/test-network-function/pkg/claimdiff"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+)
+
+const (
+	diffFormatText = "text"
+	diffFormatJSON = "json"
+	// regressionExitCode is the exit code of `claim diff` when a test fails in the new claim but did not in the old
+	// one.
+	regressionExitCode = 2
+)
+
+var (
+	diffFormat string
+
+	claimDiff = &cobra.Command{
+		Use:   "diff OLD_CLAIM NEW_CLAIM",
+		Short: "Compare two claim files",
+		Long: "Compare two claim files: the tests which changed state, the new and removed tests, and the changes " +
+			"in the versions, configurations and nodes. The exit code is 2 when a test regressed, i.e. fails in " +
+			"the new claim but did not in the old one.",
+		Args: cobra.ExactArgs(2), //nolint:gomnd // the two claim files
+		RunE: claimDiffRun,
+	}
+)
+
+func claimDiffRun(cmd *cobra.Command, args []string) error {
+	if diffFormat != diffFormatText && diffFormat != diffFormatJSON {
+		return fmt.Errorf("unsupported format %q, use %s or %s", diffFormat, diffFormatText, diffFormatJSON)
+	}
+	oldClaim, err := claimutil.ReadClaimFile(args[0])
+	if err != nil {
+		return fmt.Errorf("error reading the old claim file: %w", err)
+	}
+	newClaim, err := claimutil.ReadClaimFile(args[1])
+	if err != nil {
+		return fmt.Errorf("error reading the new claim file: %w", err)
+	}
+
+	diff, err := claimdiff.Compare(oldClaim.Claim, newClaim.Claim)
+	if err != nil {
+		return fmt.Errorf("error comparing the claim files: %w", err)
+	}
+	if diffFormat == diffFormatJSON {
+		err = diff.WriteJSON(os.Stdout)
+	} else {
+		err = diff.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing the differences: %w", err)
+	}
+
+	if len(diff.Regressions()) > 0 {
+		os.Exit(regressionExitCode)
+	}
+	return nil
+}
+
+func newDiffCommand() *cobra.Command {
+	claimDiff.Flags().StringVarP(
+		&diffFormat, "format", "f", diffFormatText,
+		"output format: text or json",
+	)
+	return claimDiff
+}
@@ -0,0 +1,76 @@
+package claim
+
+import (
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/test-network-function/test-network-function/pkg/claimmerge"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+)
+
+var (
+	mergeOutput         string
+	mergeAllowConflicts bool
+
+	claimMerge = &cobra.Command{
+		Use:   "merge CLAIM CLAIM... -o OUTPUT",
+		Short: "Merge the claim files of several runs of the test suites into one claim file",
+		Long: "Merge the claim files of several runs of the test suites into one claim file. The results are " +
+			"the union of the results of the claims, and the versions, configurations and nodes are those of the " +
+			"first claim. The start and end times and the versions of each claim, and the claim each result comes " +
+			"from, are recorded under the \"" + claimmerge.MergeInfoKey + "\" raw results. The merge fails when " +
+			"the claims were run with different versions or configurations, unless --allow-conflicts is set.",
+		Args: cobra.MinimumNArgs(2), //nolint:gomnd // at least two claims to merge
+		RunE: claimMergeRun,
+	}
+)
+
+func claimMergeRun(cmd *cobra.Command, args []string) error {
+	sources := []claimmerge.Source{}
+	for _, claimPath := range args {
+		claimRoot, err := claimutil.ReadClaimFile(claimPath)
+		if err != nil {
+			return fmt.Errorf("error reading claim file: %w", err)
+		}
+		sources = append(sources, claimmerge.Source{Name: claimPath, Root: claimRoot})
+	}
+
+	merged, info, err := claimmerge.Merge(sources)
+	if err != nil {
+		return fmt.Errorf("error merging the claim files: %w", err)
+	}
+	for i := range info.Conflicts {
+		conflict := &info.Conflicts[i]
+		log.Warnf("%s conflicts with %s: %s %s is %s", conflict.Source, args[0], conflict.Section, conflict.Path,
+			conflict.Kind)
+	}
+	if !mergeAllowConflicts && info.HasConflicts(claimmerge.SectionVersions, claimmerge.SectionConfigurations) {
+		return errors.New("the claims were run with different versions or configurations, use --allow-conflicts to merge " +
+			"them anyway")
+	}
+
+	err = claimutil.WriteClaimFile(mergeOutput, merged)
+	if err != nil {
+		return fmt.Errorf("error writing the merged claim file: %w", err)
+	}
+	log.Printf("Claim files merged into `%s`\n", mergeOutput)
+	return nil
+}
+
+func newMergeCommand() *cobra.Command {
+	claimMerge.Flags().StringVarP(
+		&mergeOutput, "output", "o", "",
+		"merged claim file. (Required)",
+	)
+	err := claimMerge.MarkFlagRequired("output")
+	if err != nil {
+		return nil
+	}
+	claimMerge.Flags().BoolVar(
+		&mergeAllowConflicts, "allow-conflicts", false,
+		"merge claims run with different versions or configurations",
+	)
+	return claimMerge
+}
@@ -217,6 +217,9 @@ func runSSHCmd(_ *cobra.Command, args []string) {
 	tester, handlers := setupTest(file)
 
 	// SSH shell creation.
+	if err := interactive.ConfigureSSHBackend(); err != nil {
+		fatalError("could not configure the ssh backend", err, testExpecterCreationFailedExitCode)
+	}
 	goExpectSpawner := interactive.NewGoExpectSpawner()
 	var spawnContext interactive.Spawner = goExpectSpawner
 	context, err := interactive.SpawnSSH(&spawnContext, user, host, (*tester).Timeout(), interactive.Verbose(true), interactive.SendTimeout((*tester).Timeout()))
@@ -9,6 +9,8 @@ import (
 	"github.com/test-network-function/test-network-function/cmd/tnf/generate/handler"
 	"github.com/test-network-function/test-network-function/cmd/tnf/grade"
 	"github.com/test-network-function/test-network-function/cmd/tnf/jsontest"
+	"github.com/test-network-function/test-network-function/cmd/tnf/report"
+	"github.com/test-network-function/test-network-function/cmd/tnf/snapshot"
 )
 
 var (
@@ -30,6 +32,8 @@ func main() {
 	generate.AddCommand(handler.NewCommand())
 	rootCmd.AddCommand(jsontest.NewCommand())
 	rootCmd.AddCommand(grade.NewCommand())
+	rootCmd.AddCommand(snapshot.NewCommand())
+	rootCmd.AddCommand(report.NewCommand())
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,106 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/report"
+)
+
+const (
+	reportFilePermissions = 0644
+)
+
+var (
+	claimPath  string
+	outputPath string
+
+	reportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Renders a claim file for sharing",
+	}
+
+	htmlCmd = &cobra.Command{
+		Use:   "html",
+		Short: "Renders a claim file as a self-contained HTML page",
+		RunE:  runHTMLCmd,
+	}
+
+	sarifCmd = &cobra.Command{
+		Use:   "sarif",
+		Short: "Converts the findings of a claim file to SARIF " + report.SARIFVersion,
+		RunE:  runSARIFCmd,
+	}
+)
+
+// writeReport reads the claim file and writes its rendering to the output file, or to stdout.
+func writeReport(render func(io.Writer, *claim.Root) error) error {
+	claimRoot, err := claimutil.ReadClaimFile(claimPath)
+	if err != nil {
+		return fmt.Errorf("error reading claim file: %w", err)
+	}
+	if outputPath == "" {
+		err = render(os.Stdout, claimRoot)
+	} else {
+		var output *os.File
+		output, err = os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, reportFilePermissions)
+		if err != nil {
+			return fmt.Errorf("error creating the report file: %w", err)
+		}
+		err = render(output, claimRoot)
+		if closeErr := output.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error writing the report: %w", err)
+	}
+	return nil
+}
+
+func runHTMLCmd(_ *cobra.Command, _ []string) error {
+	return writeReport(report.WriteHTML)
+}
+
+func runSARIFCmd(_ *cobra.Command, _ []string) error {
+	return writeReport(report.WriteSARIF)
+}
+
+// NewCommand returns the "report" command.
+func NewCommand() *cobra.Command {
+	reportCmd.PersistentFlags().StringVarP(
+		&claimPath, "claim", "c", "",
+		"claim file. (Required)",
+	)
+	err := reportCmd.MarkPersistentFlagRequired("claim")
+	if err != nil {
+		return nil
+	}
+	reportCmd.PersistentFlags().StringVarP(
+		&outputPath, "output", "o", "",
+		"report file, stdout by default",
+	)
+	reportCmd.AddCommand(htmlCmd)
+	reportCmd.AddCommand(sarifCmd)
+	return reportCmd
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
+)
+
+const (
+	junitFileName = "cnf-certification-tests_junit.xml"
+	// replayOutputDir is the default directory of the replay results, relative to the snapshot directory.
+	replayOutputDir = "replay"
+	// nonIntrusiveEnvironmentVariableKey restricts the suites to the checks that do not alter the cluster.
+	nonIntrusiveEnvironmentVariableKey = "TNF_NON_INTRUSIVE_ONLY"
+)
+
+var (
+	snapshotDir string
+	outputDir   string
+	testBinary  string
+	suites      []string
+
+	// defaultSuites are the suites that can be replayed from a snapshot.
+	defaultSuites = []string{
+		environment.AccessControlTestKey,
+		environment.LifecycleTestKey,
+		environment.ObservabilityTestKey,
+		environment.NetworkingTestKey,
+	}
+
+	snapshotCommand = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Capture the cluster interactions of a test run and replay them without a cluster.",
+	}
+
+	captureCommand = &cobra.Command{
+		Use:   "capture",
+		Short: "Run the test suites against the cluster and record every command output into a snapshot directory.",
+		RunE:  runCapture,
+	}
+
+	replayCommand = &cobra.Command{
+		Use:   "replay",
+		Short: "Run the test suites against a snapshot directory instead of a cluster.",
+		RunE:  runReplay,
+	}
+)
+
+// runSuites runs the test suite binary in its own directory, as run-cnf-suites.sh does, in the given snapshot mode.
+func runSuites(mode, dir, resultsDir string) error {
+	binaryPath, err := filepath.Abs(testBinary)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(resultsDir, 0755) //nolint:gomnd // standard directory permissions
+	if err != nil {
+		return err
+	}
+	args := []string{
+		"-ginkgo.focus=" + strings.Join(suites, "|"),
+		"-claimloc", resultsDir,
+		"-junit", resultsDir,
+		"-ginkgo.junit-report", filepath.Join(resultsDir, junitFileName),
+		"-ginkgo.v",
+		"-test.v",
+	}
+	cmd := exec.Command(binaryPath, args...)
+	cmd.Dir = filepath.Dir(binaryPath)
+	cmd.Env = append(os.Environ(),
+		snapshot.ModeEnvironmentVariableKey+"="+mode,
+		snapshot.DirEnvironmentVariableKey+"="+dir,
+		nonIntrusiveEnvironmentVariableKey+"=true",
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	log.Infof("running %s with focus %q in %s mode", binaryPath, strings.Join(suites, "|"), mode)
+	return cmd.Run()
+}
+
+func runCapture(cmd *cobra.Command, args []string) error {
+	dir, err := filepath.Abs(snapshotDir)
+	if err != nil {
+		return err
+	}
+	// Start from an empty snapshot, the suite binary adds to it.
+	s, err := snapshot.Create(dir)
+	if err != nil {
+		return err
+	}
+	err = runSuites(snapshot.ModeCapture, dir, dir)
+	if err != nil {
+		// Failing checks are expected, the snapshot is still usable.
+		log.Warnf("the test suites ended with an error: %s", err)
+	}
+	s, err = snapshot.Open(s.Dir())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Captured %d commands into %s\n", len(s.Entries()), dir)
+	return nil
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	dir, err := filepath.Abs(snapshotDir)
+	if err != nil {
+		return err
+	}
+	if _, err = snapshot.Open(dir); err != nil {
+		return fmt.Errorf("unable to open the snapshot: %s", err)
+	}
+	resultsDir := outputDir
+	if resultsDir == "" {
+		resultsDir = filepath.Join(dir, replayOutputDir)
+	}
+	resultsDir, err = filepath.Abs(resultsDir)
+	if err != nil {
+		return err
+	}
+	err = runSuites(snapshot.ModeReplay, dir, resultsDir)
+	if err != nil {
+		log.Warnf("the test suites ended with an error: %s", err)
+	}
+	fmt.Printf("Replay results written to %s\n", resultsDir)
+	return nil
+}
+
+// NewCommand returns the snapshot command with its capture and replay subcommands.
+func NewCommand() *cobra.Command {
+	for _, command := range []*cobra.Command{captureCommand, replayCommand} {
+		command.Flags().StringVarP(&snapshotDir, "dir", "d", "", "Path to the snapshot directory")
+		command.Flags().StringSliceVarP(&suites, "focus", "f", defaultSuites, "Test suites to run")
+		command.Flags().StringVarP(&testBinary, "test-binary", "t",
+			filepath.Join("test-network-function", "test-network-function.test"), "Path to the test suite binary")
+		err := command.MarkFlagRequired("dir")
+		if err != nil {
+			return nil
+		}
+		snapshotCommand.AddCommand(command)
+	}
+	replayCommand.Flags().StringVarP(&outputDir, "output", "o", "",
+		"Path to the directory of the replay claim and junit files, defaults to the replay directory of the snapshot")
+	return snapshotCommand
+}
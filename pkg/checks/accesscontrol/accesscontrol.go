@@ -0,0 +1,488 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package accesscontrol
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/automountservice"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/clusterrolebinding"
+	containerpkg "github.com/test-network-function/test-network-function/pkg/tnf/handlers/container"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/rolebinding"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+	"github.com/test-network-function/test-network-function/pkg/tnf/testcases"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+const (
+	// ocGetCrPluralNameFormat is the CR name to use with "oc get <resource_name>".
+	ocGetCrPluralNameFormat = "oc get crd %s -o jsonpath='{.spec.names.plural}'"
+
+	// ocGetCrNamespaceFormat is the "oc get" format string to get the namespaced-only resources created for a given CRD.
+	ocGetCrNamespaceFormat = "oc get %s -A -o go-template='{{range .items}}{{if .metadata.namespace}}{{.metadata.name}},{{.metadata.namespace}}{{\"\n\"}}{{end}}{{end}}'"
+)
+
+var (
+	invalidNamespacePrefixes = []string{
+		"default",
+		"openshift-",
+		"istio-",
+		"aspenmesh-",
+	}
+)
+
+func init() {
+	checks.Register(checks.Check{ID: identifiers.TestNamespaceBestPracticesIdentifier, Run: testNamespace})
+	checks.Register(checks.Check{ID: identifiers.TestPodServiceAccountBestPracticesIdentifier, Run: testServiceAccount})
+	checks.Register(checks.Check{ID: identifiers.TestPodRoleBindingsBestPracticesIdentifier, Run: testRoleBindings})
+	checks.Register(checks.Check{ID: identifiers.TestPodClusterRoleBindingsBestPracticesIdentifier, Run: testClusterRoleBindings})
+	checks.Register(checks.Check{ID: identifiers.TestPodAutomountServiceAccountIdentifier, Run: testAutomountService})
+	checks.Register(checks.Check{ID: identifiers.TestHostResourceIdentifier, Run: testHostResource})
+}
+
+// PodTestCase is a test case configured in testconfigure.yml for the pods under test.
+type PodTestCase struct {
+	*testcases.BaseTestCase
+	// Type is the configured test of the test case, e.g. testcases.PrivilegedRoles.
+	Type string
+}
+
+// ConfiguredTestCases returns the pod test cases configured in testconfigure.yml, which are not skipped.
+func ConfiguredTestCases() ([]PodTestCase, error) {
+	testCases := []PodTestCase{}
+	for _, testType := range testcases.GetConfiguredPodTests() {
+		testFile, err := testcases.LoadConfiguredTestFile(environment.ConfiguredTestFile)
+		if err != nil {
+			return nil, err
+		}
+		testConfigure := testcases.ContainsConfiguredTest(testFile.CnfTest, testType)
+		renderedTestCase, err := testConfigure.RenderTestCaseSpec(testcases.Cnf, testType)
+		if err != nil {
+			return nil, err
+		}
+		for i := range renderedTestCase.TestCase {
+			if !renderedTestCase.TestCase[i].SkipTest {
+				testCases = append(testCases, PodTestCase{BaseTestCase: &renderedTestCase.TestCase[i], Type: testType})
+			}
+		}
+	}
+	return testCases, nil
+}
+
+// HostResourceCheck returns the check running testCase, one of the ConfiguredTestCases, on the pods under test.  The
+// Ginkgo suite runs it in a spec of its own.
+func HostResourceCheck(testCase PodTestCase) *checks.Check {
+	return &checks.Check{ID: identifiers.TestHostResourceIdentifier, Run: func(t *checks.T) {
+		runTestOnPods(t, testCase.BaseTestCase, testCase.Type)
+	}}
+}
+
+// testHostResource runs the ConfiguredTestCases on the pods under test.
+func testHostResource(t *checks.T) {
+	testCases, err := ConfiguredTestCases()
+	if err != nil {
+		t.Failf("The pod test cases could not be loaded. Error: %v", err)
+		return
+	}
+	for _, testCase := range testCases {
+		runTestOnPods(t, testCase.BaseTestCase, testCase.Type)
+	}
+}
+
+type failedTcInfo struct {
+	tc           string
+	containerIdx int
+	ns           string
+}
+
+func addFailedTcInfo(failedTcs map[string][]failedTcInfo, tc, pod, ns string, containerIdx int) {
+	if tcs, exists := failedTcs[pod]; exists {
+		tcs = append(tcs, failedTcInfo{tc: tc, containerIdx: containerIdx, ns: ns})
+		failedTcs[pod] = tcs
+	} else {
+		failedTcs[pod] = []failedTcInfo{{tc: tc, containerIdx: containerIdx, ns: ns}}
+	}
+}
+
+// nonCompliantPod returns the non-compliant object of a pod which failed the TC tc, or could not be tested when err is
+// not nil.  containerIdx is the index of the failed container, whose object is then a container of the pod, or a
+// negative value when the TC is not run per container.
+func nonCompliantPod(tc string, pod *configsections.Pod, containerIdx int, err error) tnf.NonCompliantObject {
+	object := tnf.NonCompliantObject{Kind: tnf.KindPod, Namespace: pod.Namespace, Name: pod.Name, Reason: tc}
+	details := []string{}
+	switch {
+	case containerIdx >= 0 && containerIdx < len(pod.ContainerNames):
+		object.Kind = tnf.KindContainer
+		object.Container = pod.ContainerNames[containerIdx]
+	case containerIdx >= 0:
+		// The container names are unknown for the pods which were not discovered.
+		details = append(details, fmt.Sprintf("container index %d", containerIdx))
+	}
+	if err != nil {
+		object.Reason = tnf.ReasonCheckError
+		details = append(details, fmt.Sprintf("%s: %v", tc, err))
+	}
+	object.Details = strings.Join(details, ", ")
+	return object
+}
+
+//nolint:funlen // ignore hugeParam error. Pointers to loop iterator vars are bad and `testCmd` is likely to be such.
+func runTestOnPods(t *checks.T, testCmd *testcases.BaseTestCase, testType string) {
+	const noContainerIdx = -1
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pods could not be tested. Error: %v", err)
+		return
+	}
+	failedTcs := map[string][]failedTcInfo{} // maps a pod name to a slice of failed TCs
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		if testCmd.ExpectedType == testcases.Function {
+			for _, val := range testCmd.ExpectedStatus {
+				testCmd.ExpectedStatusFn(podUnderTest.Name, testcases.StatusFunctionType(val))
+			}
+		}
+		var args []interface{}
+		if testType == testcases.PrivilegedRoles {
+			args = []interface{}{podUnderTest.Namespace, podUnderTest.Namespace, podUnderTest.ServiceAccount}
+		} else {
+			args = []interface{}{podUnderTest.Name, podUnderTest.Namespace}
+		}
+		var count int
+		if testCmd.Loop > 0 {
+			count = podUnderTest.ContainerCount
+		} else {
+			count = testCmd.Loop
+		}
+
+		if count > 0 {
+			count := 0
+			for count < podUnderTest.ContainerCount {
+				log.Debugf("Executing TC %s on pod %s (ns %s), container index %d", testCmd.Name, podUnderTest.Namespace, podUnderTest.Name, count)
+				argsCount := append(args, count) //nolint:gocritic
+				cmd := fmt.Sprintf(testCmd.Command, argsCount...)
+				cmdArgs := strings.Split(cmd, " ")
+				cnfInTest := containerpkg.NewPod(cmdArgs, podUnderTest.Name, podUnderTest.Namespace, testCmd.ExpectedStatus, testCmd.ResultType, testCmd.Action, environment.DefaultTimeout)
+				test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), cnfInTest, []reel.Handler{cnfInTest}, context.GetErrorChannel())
+				if err != nil {
+					t.Fatalf("Pod %s (ns %s) could not be tested. Error: %v", podUnderTest.Name, podUnderTest.Namespace, err)
+				}
+				test.RunWithCallbacks(nil, func() {
+					t.Printf("FAILURE: Command sent: %s, Expectations: %v", cmd, testCmd.ExpectedStatus)
+					addFailedTcInfo(failedTcs, testCmd.Name, podUnderTest.Name, podUnderTest.Namespace, count)
+					t.ReportNonCompliant(nonCompliantPod(testCmd.Name, podUnderTest, count, nil))
+				}, func(e error) {
+					t.Printf("ERROR: Command sent: %s, Expectations: %v, Error: %v", cmd, testCmd.ExpectedStatus, e)
+					addFailedTcInfo(failedTcs, testCmd.Name, podUnderTest.Name, podUnderTest.Namespace, count)
+					t.ReportNonCompliant(nonCompliantPod(testCmd.Name, podUnderTest, count, e))
+				})
+				count++
+			}
+		} else {
+			log.Debugf("Executing TC %s on pod %s (ns %s)", testCmd.Name, podUnderTest.Namespace, podUnderTest.Name)
+			cmd := fmt.Sprintf(testCmd.Command, args...)
+			cmdArgs := strings.Split(cmd, " ")
+			podTest := containerpkg.NewPod(cmdArgs, podUnderTest.Name, podUnderTest.Namespace, testCmd.ExpectedStatus, testCmd.ResultType, testCmd.Action, environment.DefaultTimeout)
+			test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), podTest, []reel.Handler{podTest}, context.GetErrorChannel())
+			if err != nil {
+				t.Fatalf("Pod %s (ns %s) could not be tested. Error: %v", podUnderTest.Name, podUnderTest.Namespace, err)
+			}
+			test.RunWithCallbacks(nil, func() {
+				t.Printf("FAILURE: Command sent: %s, Expectations: %v", cmd, testCmd.ExpectedStatus)
+				addFailedTcInfo(failedTcs, testCmd.Name, podUnderTest.Name, podUnderTest.Namespace, noContainerIdx)
+				t.ReportNonCompliant(nonCompliantPod(testCmd.Name, podUnderTest, noContainerIdx, nil))
+			}, func(e error) {
+				t.Printf("ERROR: Command sent: %s, Expectations: %v, Error: %v", cmd, testCmd.ExpectedStatus, e)
+				addFailedTcInfo(failedTcs, testCmd.Name, podUnderTest.Name, podUnderTest.Namespace, noContainerIdx)
+				t.ReportNonCompliant(nonCompliantPod(testCmd.Name, podUnderTest, noContainerIdx, e))
+			})
+		}
+	}
+
+	if n := len(failedTcs); n > 0 {
+		log.Debugf("Failed TCs: %+v", failedTcs)
+		t.Failf("%d pods failed the test.", n)
+	}
+}
+
+func getCrsNamespaces(crdName, crdKind string, context *interactive.Context) (map[string]string, error) {
+	if crdKind == "" {
+		return nil, fmt.Errorf("no plural name for the CRD %s", crdName)
+	}
+	getCrNamespaceCommand := fmt.Sprintf(ocGetCrNamespaceFormat, crdKind)
+	cmdOut := utils.ExecuteCommandAndValidate(getCrNamespaceCommand, environment.DefaultTimeout, context, func() {
+		tnf.ClaimFilePrintf("CRD %s: Failed to get CRs (kind=%s)", crdName, crdKind)
+	})
+
+	return parseCrOutput(cmdOut)
+}
+
+func parseCrOutput(rawOutput string) (map[string]string, error) {
+	const crNameFieldIdx = 0
+	const namespaceFieldIdx = 1
+	const expectedNumFields = 2
+	crNamespaces := map[string]string{}
+	if rawOutput == "" {
+		// Filter out empty (0 CRs) output.
+		return crNamespaces, nil
+	}
+
+	lines := strings.Split(rawOutput, "\n")
+	for _, line := range lines {
+		lineFields := strings.Split(line, ",")
+		if len(lineFields) != expectedNumFields {
+			return crNamespaces, fmt.Errorf("failed to parse output line %s", line)
+		}
+		crNamespaces[lineFields[crNameFieldIdx]] = lineFields[namespaceFieldIdx]
+	}
+
+	return crNamespaces, nil
+}
+
+func testCrsNamespaces(t *checks.T, crNames, configNamespaces []string, context *interactive.Context) map[string][]string {
+	invalidCrs := map[string][]string{}
+	for _, crdName := range crNames {
+		getCrPluralNameCommand := fmt.Sprintf(ocGetCrPluralNameFormat, crdName)
+		crdPluralName := utils.ExecuteCommandAndValidate(getCrPluralNameCommand, environment.DefaultTimeout, context, func() {
+			t.Printf("CRD %s: Failed to get CR plural name.", crdName)
+		})
+
+		crNamespaces, err := getCrsNamespaces(crdName, crdPluralName, context)
+		if err != nil {
+			t.Fatalf("Failed to get CRs for CRD %s - Error: %v", crdName, err)
+		}
+
+		log.Debugf("CRD %s has %d CRs (plural name: %s).", crdName, len(crNamespaces), crdPluralName)
+		for crName, namespace := range crNamespaces {
+			log.Debugf("Checking CR %s - Namespace %s", crName, namespace)
+			if !utils.StringInSlice(configNamespaces, namespace, false) {
+				t.Printf("CRD: %s (kind:%s) - CR %s has an invalid namespace (%s)", crdName, crdPluralName, crName, namespace)
+				if crNames, exists := invalidCrs[crdName]; exists {
+					invalidCrs[crdName] = append(crNames, crName)
+				} else {
+					invalidCrs[crdName] = []string{crName}
+				}
+			}
+		}
+	}
+	return invalidCrs
+}
+
+func testNamespace(t *checks.T) {
+	env := t.Env()
+	log.Debugf("CNF resources' namespaces should not have any of the following prefixes: %v", invalidNamespacePrefixes)
+	var failedNamespaces []string
+	for _, namespace := range env.NameSpacesUnderTest {
+		log.Debugf("Checking namespace %s", namespace)
+		for _, invalidPrefix := range invalidNamespacePrefixes {
+			if strings.HasPrefix(namespace, invalidPrefix) {
+				t.Printf("Namespace %s has invalid prefix %s", namespace, invalidPrefix)
+				failedNamespaces = append(failedNamespaces, namespace)
+			}
+		}
+	}
+
+	if failedNamespacesNum := len(failedNamespaces); failedNamespacesNum > 0 {
+		t.Failf("Found %d namespaces with an invalid prefix.", failedNamespacesNum)
+		return
+	}
+
+	log.Debugf("CNF pods' should belong to any of the configured namespaces: %v", env.NameSpacesUnderTest)
+
+	if nonValidPodsNum := len(env.Config.NonValidPods); nonValidPodsNum > 0 {
+		for _, invalidPod := range env.Config.NonValidPods {
+			t.Printf("Pod %s has invalid namespace %s", invalidPod.Name, invalidPod.Namespace)
+		}
+
+		t.Failf("Found %d pods under test belonging to invalid namespaces.", nonValidPodsNum)
+		return
+	}
+
+	log.Debugf("CRs from autodiscovered CRDs should belong to the configured namespaces: %v", env.NameSpacesUnderTest)
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("CRs could not be tested. Error: %v", err)
+		return
+	}
+	invalidCrs := testCrsNamespaces(t, env.CrdNames, env.NameSpacesUnderTest, context)
+
+	if invalidCrsNum := len(invalidCrs); invalidCrsNum > 0 {
+		for crdName, crs := range invalidCrs {
+			for _, crName := range crs {
+				t.Printf("CRD %s - CR %s has an invalid namespace.", crdName, crName)
+			}
+		}
+		t.Failf("Found %d CRs belonging to invalid namespaces.", invalidCrsNum)
+	}
+}
+
+func testServiceAccount(t *checks.T) {
+	log.Debugf("Should have a valid ServiceAccount name")
+	failedPods := []*configsections.Pod{}
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("Testing service account for pod %s (ns: %s)", podUnderTest.Name, podUnderTest.Namespace)
+		if podUnderTest.ServiceAccount == "" {
+			t.Printf("Pod %s (ns: %s) doesn't have a service account name.", podUnderTest.Name, podUnderTest.Namespace)
+			failedPods = append(failedPods, podUnderTest)
+		}
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods without service account: %+v", failedPods)
+		t.Failf("%d pods don't have a service account name.", n)
+	}
+}
+
+//nolint:funlen
+func testAutomountService(t *checks.T) {
+	log.Debugf("Should have automountServiceAccountToken set to false")
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pods could not be tested. Error: %v", err)
+		return
+	}
+	msg := []string{}
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("check the existence of pod service account %s (ns= %s )", podUnderTest.Namespace, podUnderTest.Name)
+		if podUnderTest.ServiceAccount == "" {
+			t.Failf("Pod %s (ns: %s) doesn't have a service account name.", podUnderTest.Name, podUnderTest.Namespace)
+			return
+		}
+		tester := automountservice.NewAutomountService(automountservice.WithNamespace(podUnderTest.Namespace), automountservice.WithServiceAccount(podUnderTest.ServiceAccount))
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Service account %s (ns: %s) could not be tested. Error: %v", podUnderTest.ServiceAccount, podUnderTest.Namespace, err)
+			return
+		}
+		test.RunAndValidate()
+		serviceAccountToken := tester.Token()
+		tester = automountservice.NewAutomountService(automountservice.WithNamespace(podUnderTest.Namespace), automountservice.WithPodname(podUnderTest.Name))
+		test, err = tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Pod %s (ns: %s) could not be tested. Error: %v", podUnderTest.Name, podUnderTest.Namespace, err)
+			return
+		}
+		test.RunAndValidate()
+		podToken := tester.Token()
+		// The token can be specified in the pod directly
+		// or it can be specified in the service account of the pod
+		// if no service account is configured, then the pod will use the configuration
+		// of the default service account in that namespace
+		// the token defined in the pod has takes precedence
+		// the test would pass iif token is explicitly set to false
+		// if the token is set to true in the pod, the test would fail right away
+		if podToken == automountservice.TokenIsTrue {
+			msg = append(msg, fmt.Sprintf("Pod %s:%s is configured with automountServiceAccountToken set to true ", podUnderTest.Namespace, podUnderTest.Name))
+			continue
+		}
+		// The pod token is false means the pod is configured properly
+		// The pod is not configured and the service account is configured with false means
+		// the pod will inherit the behavior `false` and the test would pass
+		if podToken == automountservice.TokenIsFalse || serviceAccountToken == automountservice.TokenIsFalse {
+			continue
+		}
+		// the service account is configured with true means all the pods
+		// using this service account are not configured properly, register the error
+		// message and fail
+		if serviceAccountToken == automountservice.TokenIsTrue {
+			msg = append(msg, fmt.Sprintf("serviceaccount %s:%s is configured with automountServiceAccountToken set to true, impacting pod %s ", podUnderTest.Namespace, podUnderTest.ServiceAccount, podUnderTest.Name))
+		}
+		// the token should be set explicitly to false, otherwise, it's a failure
+		// register the error message and check the next pod
+		if serviceAccountToken == automountservice.TokenNotSet {
+			msg = append(msg, fmt.Sprintf("serviceaccount %s:%s is not configured with automountServiceAccountToken set to false, impacting pod %s ", podUnderTest.Namespace, podUnderTest.ServiceAccount, podUnderTest.Name))
+		}
+	}
+	if len(msg) > 0 {
+		t.Printf("%s", strings.Join(msg, ""))
+		t.Failf("%d pods are not configured with automountServiceAccountToken set to false.", len(msg))
+	}
+}
+
+func testRoleBindings(t *checks.T) {
+	failedPods := []*configsections.Pod{}
+	log.Debugf("Should not have RoleBinding in other namespaces")
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		context, err := t.LocalShell()
+		if err != nil {
+			t.Failf("Pods could not be tested. Error: %v", err)
+			return
+		}
+		log.Debugf("Testing role binding  %s %s", podUnderTest.Namespace, podUnderTest.Name)
+		if podUnderTest.ServiceAccount == "" {
+			t.Skipf("Can not test when serviceAccountName is empty. Please check previous tests for failures")
+			return
+		}
+		rbTester := rolebinding.NewRoleBinding(environment.DefaultTimeout, podUnderTest.ServiceAccount, podUnderTest.Namespace)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), rbTester, []reel.Handler{rbTester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Pod %s (ns: %s) could not be tested. Error: %v", podUnderTest.Name, podUnderTest.Namespace, err)
+			return
+		}
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("FAILURE: Pod %s (ns: %s) roleBindings: %v", podUnderTest.Name, podUnderTest.Namespace, rbTester.GetRoleBindings())
+			failedPods = append(failedPods, podUnderTest)
+		}, func(err error) {
+			t.Printf("ERROR: Pod %s (ns: %s) roleBindings: %v, error: %v", podUnderTest.Name, podUnderTest.Namespace, rbTester.GetRoleBindings(), err)
+			failedPods = append(failedPods, podUnderTest)
+		})
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods with role bindings: %+v", failedPods)
+		t.Failf("%d pods have role bindings in other namespaces.", n)
+	}
+}
+
+func testClusterRoleBindings(t *checks.T) {
+	log.Debugf("Should not have ClusterRoleBindings")
+	failedPods := []*configsections.Pod{}
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		context, err := t.LocalShell()
+		if err != nil {
+			t.Failf("Pods could not be tested. Error: %v", err)
+			return
+		}
+		log.Debugf("Testing cluster role binding  %s %s", podUnderTest.Namespace, podUnderTest.Name)
+		if podUnderTest.ServiceAccount == "" {
+			t.Skipf("Can not test when serviceAccountName is empty. Please check previous tests for failures")
+			return
+		}
+		crbTester := clusterrolebinding.NewClusterRoleBinding(environment.DefaultTimeout, podUnderTest.ServiceAccount, podUnderTest.Namespace)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), crbTester, []reel.Handler{crbTester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Pod %s (ns: %s) could not be tested. Error: %v", podUnderTest.Name, podUnderTest.Namespace, err)
+			return
+		}
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("FAILURE: Pod: %s (ns: %s) SA: %s clusterRoleBindings: %v", podUnderTest.Name, podUnderTest.Namespace, podUnderTest.ServiceAccount, crbTester.GetClusterRoleBindings())
+			failedPods = append(failedPods, podUnderTest)
+		}, func(err error) {
+			t.Printf("ERROR: Pod: %s (ns: %s) SA: %s clusterRoleBindings: %v, error: %v", podUnderTest.Name, podUnderTest.Namespace, podUnderTest.ServiceAccount, crbTester.GetClusterRoleBindings(), err)
+			failedPods = append(failedPods, podUnderTest)
+		})
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods with cluster role bindings: %+v", failedPods)
+		t.Failf("%d pods have cluster role bindings.", n)
+	}
+}
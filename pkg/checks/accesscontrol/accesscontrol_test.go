@@ -21,9 +21,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/onsi/ginkgo/v2"
-	"github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/utils"
 )
@@ -70,7 +70,6 @@ func TestParseCrOutput(t *testing.T) {
 }
 
 func TestGetCrsNamespaces(t *testing.T) {
-	gomega.RegisterFailHandler(ginkgo.Fail)
 	origFunc := utils.ExecuteCommandAndValidate
 	defer func() {
 		utils.ExecuteCommandAndValidate = origFunc
@@ -85,6 +84,9 @@ func TestGetCrsNamespaces(t *testing.T) {
 	assert.Equal(t, map[string]string{
 		"aws-ebs-csi-driver-operator": "openshift-cloud-credential-operator",
 	}, crsNamespaces)
+
+	_, err = getCrsNamespaces("test123", "", nil)
+	assert.NotNil(t, err)
 }
 
 //nolint:funlen
@@ -149,3 +151,41 @@ func TestAddFailedTcInfo(t *testing.T) {
 		assert.Equal(t, tc.expectedMap, tc.existingMap)
 	}
 }
+
+func TestNonCompliantPod(t *testing.T) {
+	pod := &configsections.Pod{Name: "pod1", Namespace: "ns1", ContainerCount: 2, ContainerNames: []string{"c0", "c1"}}
+	testCases := []struct {
+		pod          *configsections.Pod
+		containerIdx int
+		err          error
+		expected     tnf.NonCompliantObject
+	}{
+		{
+			pod:          pod,
+			containerIdx: -1,
+			expected:     tnf.NonCompliantObject{Kind: tnf.KindPod, Namespace: "ns1", Name: "pod1", Reason: "tc1"},
+		},
+		{
+			pod:          pod,
+			containerIdx: 1,
+			expected:     tnf.NonCompliantObject{Kind: tnf.KindContainer, Namespace: "ns1", Name: "pod1", Container: "c1", Reason: "tc1"},
+		},
+		{
+			pod:          pod,
+			containerIdx: 0,
+			err:          errors.New("timeout"),
+			expected: tnf.NonCompliantObject{Kind: tnf.KindContainer, Namespace: "ns1", Name: "pod1", Container: "c0",
+				Reason: tnf.ReasonCheckError, Details: "tc1: timeout"},
+		},
+		{
+			pod:          &configsections.Pod{Name: "pod1", Namespace: "ns1", ContainerCount: 2},
+			containerIdx: 1,
+			expected: tnf.NonCompliantObject{Kind: tnf.KindPod, Namespace: "ns1", Name: "pod1", Reason: "tc1",
+				Details: "container index 1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, nonCompliantPod("tc1", tc.pod, tc.containerIdx, tc.err))
+	}
+}
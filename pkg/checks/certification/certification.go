@@ -0,0 +1,265 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package certification
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	version "github.com/hashicorp/go-version"
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/internal/api"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+const (
+	// timeout for eventually call
+	apiRequestTimeout  = 40 * time.Second
+	CertifiedOperator  = "certified-operators"
+	outMinikubeVersion = "null"
+)
+
+var (
+	ocpVersionCommand        = "oc version -o json | jq '.openshiftVersion'"
+	kubernetesVersionCommand = "oc version -o json | jq '.serverVersion.gitVersion'"
+	execCommandOutput        = func(command string) string {
+		return utils.ExecuteLocalCommandAndValidate(command, apiRequestTimeout, func() {
+			log.Error("can't run command: ", command)
+		})
+	}
+
+	certAPIClient api.CertAPIClient
+)
+
+func init() {
+	checks.Register(checks.Check{ID: identifiers.TestContainerIsCertifiedIdentifier, Run: testContainerCertificationStatus})
+	checks.Register(checks.Check{ID: identifiers.TestOperatorIsCertifiedIdentifier, Run: testAllOperatorCertified})
+	checks.Register(checks.Check{ID: identifiers.TestHelmIsCertifiedIdentifier, Run: testHelmCertified})
+}
+
+func testHelmCertified(t *checks.T) {
+	certAPIClient = api.NewHTTPClient()
+	helmcharts := t.Env().HelmchartsUnderTest
+	if len(helmcharts) == 0 {
+		t.Skipf("No helm charts to check")
+		return
+	}
+	out, err := certAPIClient.GetYamlFile()
+	if err != nil {
+		t.Failf("error while reading the helm yaml file from the api %s", err)
+		return
+	}
+	if out.Entries == nil {
+		t.Skipf("No helm charts from the api")
+		return
+	}
+	ourKubeVersion := GetKubeVersion()[1:]
+	failedHelmCharts := []configsections.HelmChart{}
+	for _, helm := range helmcharts {
+		certified := false
+		for _, entryList := range out.Entries {
+			for _, entry := range entryList {
+				if entry.Name == helm.Name && entry.Version == helm.Version {
+					if entry.KubeVersion != "" {
+						if CompareVersion(ourKubeVersion, entry.KubeVersion) {
+							certified = true
+							break
+						}
+					} else {
+						certified = true
+						break
+					}
+				}
+			}
+			if certified {
+				log.Info(fmt.Sprintf("Helm %s with version %s is certified", helm.Name, helm.Version))
+				break
+			}
+		}
+		if !certified {
+			failedHelmCharts = append(failedHelmCharts, helm)
+			t.ReportNonCompliant(tnf.NonCompliantObject{Kind: tnf.KindHelmChart, Name: helm.Name,
+				Reason: "HelmChartNotCertified", Details: "version " + helm.Version})
+		}
+	}
+	if len(failedHelmCharts) > 0 {
+		log.Errorf("Helms that are not certified: %+v", failedHelmCharts)
+		t.Printf("Helms that are not certified: %+v", failedHelmCharts)
+		t.Failf("%d helms chart are not certified.", len(failedHelmCharts))
+	}
+}
+
+// getContainerCertificationRequestFunction returns function that will try to get the certification status (CCP) for a container.
+func getContainerCertificationRequestFunction(id configsections.ContainerImageIdentifier) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return certAPIClient.GetContainerCatalogEntry(id)
+	}
+}
+
+// getOperatorCertificationRequestFunction returns function that will try to get the certification status (OCP) for an operator.
+func getOperatorCertificationRequestFunction(organization, operatorName, ocpversion string) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		return certAPIClient.IsOperatorCertified(organization, operatorName, ocpversion)
+	}
+}
+
+// waitForCertificationRequestToSuccess calls to certificationRequestFunc, the request of the certification status of
+// name, until it succeeds, retrying every second for up to timeout.  It returns the result of the last request.
+func waitForCertificationRequestToSuccess(t *checks.T, name string, certificationRequestFunc func() (interface{}, error), timeout time.Duration) interface{} {
+	const pollingPeriod = 1 * time.Second
+	policy := tnf.RetryPolicy{MaxAttempts: int(timeout / pollingPeriod), InitialBackoff: pollingPeriod}
+	var result interface{}
+	err := tnf.Retry(t.Context(), "certification status request of "+name, policy, func() error {
+		var err error
+		result, err = certificationRequestFunc()
+		return err
+	})
+	if err != nil {
+		log.Errorf("Failed to get the certification status of %s: %v", name, err)
+	}
+	return result
+}
+
+// testContainerCertificationStatus queries the API for the certification status of the listed containers.
+func testContainerCertificationStatus(t *checks.T) {
+	env := t.Env()
+	containersToQuery := make(map[configsections.ContainerImageIdentifier]bool)
+	for _, c := range env.Config.CertifiedContainerInfo {
+		containersToQuery[c] = true
+	}
+	if env.Config.CheckDiscoveredContainerCertificationStatus {
+		for _, cut := range env.ContainersUnderTest {
+			containersToQuery[cut.ImageSource.ContainerImageIdentifier] = true
+		}
+	}
+	if len(containersToQuery) == 0 {
+		t.Skipf("No containers to check configured in tnf_config.yml")
+		return
+	}
+	log.Debugf("Getting certification status. Number of containers to check: %d", len(containersToQuery))
+	certAPIClient = api.NewHTTPClient()
+	failedContainers := []configsections.ContainerImageIdentifier{}
+	allContainersToQueryEmpty := true
+	for c := range containersToQuery {
+		if c.Name == "" || c.Repository == "" {
+			t.Printf("Container name = \"%s\" or repository = \"%s\" is missing, skipping this container to query", c.Name, c.Repository)
+			continue
+		}
+		allContainersToQueryEmpty = false
+		log.Debugf("Container %s/%s should eventually be verified as certified", c.Repository, c.Name)
+		entry := waitForCertificationRequestToSuccess(t, c.Repository+"/"+c.Name, getContainerCertificationRequestFunction(c), apiRequestTimeout).(*api.ContainerCatalogEntry)
+		if entry == nil {
+			t.Printf("Container %s (repository %s) is not found in the certified container catalog.", c.Name, c.Repository)
+			failedContainers = append(failedContainers, c)
+		} else {
+			if entry.GetBestFreshnessGrade() > "C" {
+				t.Printf("Container %s (repository %s) is found in the certified container catalog but with low health index '%s'.", c.Name, c.Repository, entry.GetBestFreshnessGrade())
+				failedContainers = append(failedContainers, c)
+			}
+			log.Info(fmt.Sprintf("Container %s (repository %s) is certified.", c.Name, c.Repository))
+		}
+	}
+	if allContainersToQueryEmpty {
+		t.Skipf("No containers to check because either container name or repository is empty for all containers in tnf_config.yml")
+		return
+	}
+
+	if n := len(failedContainers); n > 0 {
+		log.Warnf("Containers that are not certified: %+v", failedContainers)
+		t.Failf("%d container images are not certified.", n)
+	}
+}
+
+func testAllOperatorCertified(t *checks.T) {
+	operatorsToQuery := t.Env().OperatorsUnderTest
+
+	if len(operatorsToQuery) == 0 {
+		t.Skipf("No operators to check configured ")
+		return
+	}
+	certAPIClient = api.NewHTTPClient()
+	log.Debugf("Verify operator as certified. Number of operators to check: %d", len(operatorsToQuery))
+	testFailed := false
+	for _, op := range operatorsToQuery {
+		ocpversion := GetOcpVersion()
+		majorDotMinorVersion := ""
+		if ocpversion != "" {
+			// Converts	major.minor.patch version format to major.minor
+			const majorMinorPatchCount = 3
+			splitVersion := strings.SplitN(ocpversion, ".", majorMinorPatchCount)
+			majorDotMinorVersion = splitVersion[0] + "." + splitVersion[1]
+		}
+		pack := op.Name
+		isCertified := waitForCertificationRequestToSuccess(t, pack, getOperatorCertificationRequestFunction(CertifiedOperator, pack, majorDotMinorVersion), apiRequestTimeout).(bool)
+		if !isCertified {
+			testFailed = true
+			log.Info(fmt.Sprintf("Operator %s not certified for OpenShift %s .", pack, majorDotMinorVersion))
+			t.Printf("Operator %s failed to be certified for OpenShift %s", pack, majorDotMinorVersion)
+		} else {
+			log.Info(fmt.Sprintf("Operator %s certified OK.", pack))
+		}
+	}
+	if testFailed {
+		t.Failf("At least one operator was not certified to run on this version of OpenShift. Check Claim.json file for details.")
+	}
+}
+func GetOcpVersion() string {
+	ocCmd := ocpVersionCommand
+	ocVersion := execCommandOutput(ocCmd)
+	if ocVersion != outMinikubeVersion {
+		nums := strings.Split(strings.ReplaceAll(ocVersion, "\"", ""), ".")
+		ocVersion = nums[0] + "." + nums[1]
+	} else {
+		ocVersion = ""
+	}
+	return ocVersion
+}
+func GetKubeVersion() string {
+	ocCmd := kubernetesVersionCommand
+	kubeVersion := execCommandOutput(ocCmd)
+	if kubeVersion != outMinikubeVersion {
+		kubeVersion = strings.Split(kubeVersion, "+")[0]
+		kubeVersion = kubeVersion[1:]
+	} else {
+		kubeVersion = ""
+	}
+	return kubeVersion
+}
+func CompareVersion(ver1, ver2 string) bool {
+	ourKubeVersion, _ := version.NewVersion(ver1)
+	kubeVersion := strings.ReplaceAll(ver2, " ", "")[2:]
+	if strings.Contains(kubeVersion, "<") {
+		kubever := strings.Split(kubeVersion, "<")
+		minVersion, _ := version.NewVersion(kubever[0])
+		maxVersion, _ := version.NewVersion(kubever[1])
+		if ourKubeVersion.GreaterThanOrEqual(minVersion) && ourKubeVersion.LessThan(maxVersion) {
+			return true
+		}
+	} else {
+		kubever := strings.Split(kubeVersion, "-")
+		minVersion, _ := version.NewVersion(kubever[0])
+		if ourKubeVersion.GreaterThanOrEqual(minVersion) {
+			return true
+		}
+	}
+	return false
+}
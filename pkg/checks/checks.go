@@ -0,0 +1,390 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/config"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/parallel"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+// Check is a test case which can run outside a Ginkgo spec.
+type Check struct {
+	// ID is the claim identifier of the test case.
+	ID claim.Identifier
+	// Run performs the test case against the environment of t.  The check passes unless it calls t.Failf or t.Skipf.
+	Run func(t *T)
+}
+
+var (
+	// registry holds the registered checks by claim identifier URL.
+	registry      = map[string]*Check{}
+	registryMutex sync.RWMutex
+
+	// getTestEnvironment is config.GetTestEnvironment, replaced in the tests.
+	getTestEnvironment = config.GetTestEnvironment
+)
+
+// Register adds a check to the ones run by Run and ginkgoadapter.RunSpec.  It panics if a check with the same identifier
+// is already registered, as it is meant to be called by the init function of the packages defining the checks.
+func Register(check Check) { //nolint:gocritic // Copied into the registry
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := registry[check.ID.Url]; ok {
+		panic(fmt.Sprintf("check %s is already registered", check.ID.Url))
+	}
+	registry[check.ID.Url] = &check
+}
+
+// TestID returns the short form of a claim identifier, e.g. "observability-container-logging", which is the name of
+// the Ginkgo spec of the check.
+func TestID(id claim.Identifier) string {
+	return strings.Join(identifiers.GetSuiteAndTestFromIdentifier(id), "-")
+}
+
+// Lookup returns the registered check with the identifier id, which is either the URL of its claim identifier or its
+// TestID.
+func Lookup(id string) (*Check, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	if check, ok := registry[id]; ok {
+		return check, nil
+	}
+	for _, check := range registry {
+		if TestID(check.ID) == id {
+			return check, nil
+		}
+	}
+	return nil, fmt.Errorf("no check registered with the identifier %q", id)
+}
+
+// List returns the identifiers of the registered checks, ordered by URL.
+func List() []claim.Identifier {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	ids := make([]claim.Identifier, 0, len(registry))
+	for _, check := range registry {
+		ids = append(ids, check.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Url < ids[j].Url
+	})
+	return ids
+}
+
+// Discover loads the test configuration and discovers the test environment, see config.TestEnvironment.Load.  The
+// failures of the discovery are returned as an error.
+func Discover() (*config.TestEnvironment, error) {
+	env := getTestEnvironment()
+	if err := env.Load(); err != nil {
+		return nil, fmt.Errorf("unable to discover the test environment: %w", err)
+	}
+	return env, nil
+}
+
+// T is the state of a running check.  Its methods are safe for concurrent use by the goroutines of the check.
+type T struct {
+	ctx context.Context
+	env *config.TestEnvironment
+	// reporter, when set, gets the output and the entries of the check as they are reported.
+	reporter tnf.Reporter
+
+	mutex        sync.Mutex
+	output       []string
+	failures     []string
+	skip         string
+	skipped      bool
+	aborted      bool
+	nonCompliant []tnf.NonCompliantObject
+	retried      []tnf.RetriedTest
+}
+
+// Context returns the context of the check, to create its tnf.Test with tnf.NewTestWithContext.
+func (t *T) Context() context.Context {
+	return t.ctx
+}
+
+// Env returns the test environment of the check.
+func (t *T) Env() *config.TestEnvironment {
+	return t.env
+}
+
+// LocalShell returns the shared session to the local shell.
+func (t *T) LocalShell() (*interactive.Context, error) {
+	return t.env.LocalShellContext()
+}
+
+// Runner returns a parallel.Runner of the test environment whose tasks write their output and report their
+// non-compliant objects to t.
+func (t *T) Runner() *parallel.Runner {
+	return t.env.GetParallelRunner().WithOutput(t.Printf, t.ReportNonCompliant)
+}
+
+// Printf adds a line to the output of the check, which is the claim file output of its test case.
+func (t *T) Printf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	t.mutex.Lock()
+	t.output = append(t.output, message)
+	if t.reporter != nil {
+		t.reporter.Printf("%s", message)
+	}
+	t.mutex.Unlock()
+}
+
+// Failf marks the check as failed with a reason.  The check goes on until its Run function returns.
+func (t *T) Failf(format string, args ...interface{}) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+// Fatalf marks the check as failed with a reason, and ends it, like the helpers failing the running test case, e.g.
+// Test.RunAndValidate.  It must be called from the goroutine running the check, or from a parallel.Task.
+func (t *T) Fatalf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	t.Failf("%s", message)
+	panic(checkEnded{message: message})
+}
+
+// Abortf marks the check as failed with a reason, ends it like Fatalf, and stops the run of the next checks, e.g. when
+// the cluster under test could not be restored after an intrusive check.
+func (t *T) Abortf(format string, args ...interface{}) {
+	t.mutex.Lock()
+	t.aborted = true
+	t.mutex.Unlock()
+	t.Fatalf(format, args...)
+}
+
+// Skipf marks the check as skipped with a reason, e.g. when the test environment has nothing to check.  A failed check
+// is not skipped.
+func (t *T) Skipf(format string, args ...interface{}) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.skipped = true
+	t.skip = fmt.Sprintf(format, args...)
+}
+
+// ReportNonCompliant attaches a non-compliant object to the result of the check, see tnf.ReportNonCompliantObject.
+func (t *T) ReportNonCompliant(object tnf.NonCompliantObject) { //nolint:gocritic // Same as tnf.ReportNonCompliantObject
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.nonCompliant = append(t.nonCompliant, object)
+	if t.reporter != nil {
+		t.reporter.AddEntry(tnf.NonCompliantObjectEntryName, object)
+	}
+}
+
+// addRetriedTest attaches a retried test to the result of the check, see tnf.Retrier.
+func (t *T) addRetriedTest(test tnf.RetriedTest) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.retried = append(t.retried, test)
+	if t.reporter != nil {
+		t.reporter.AddEntry(tnf.RetriedTestEntryName, test)
+	}
+}
+
+// Failed returns true when Failf was called.
+func (t *T) Failed() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.failures) > 0
+}
+
+// state returns the claim result state of the check, and the reason of a failed or skipped check.
+func (t *T) state() (state, reason string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	switch {
+	case len(t.failures) > 0:
+		return claimutil.StateFailed, strings.Join(t.failures, "\n")
+	case t.skipped:
+		return claimutil.StateSkipped, t.skip
+	default:
+		return claimutil.StatePassed, ""
+	}
+}
+
+// checkEnded is the panic value ending a check, see T.Fatalf.
+type checkEnded struct {
+	message string
+}
+
+func (e checkEnded) String() string {
+	return e.message
+}
+
+// checkReporter is the tnf.Reporter of a running check, so that the helpers reporting to the running test case, e.g.
+// tnf.ClaimFilePrintf, tnf.ReportNonCompliantObject or Test.RunAndValidate, report to the check.
+type checkReporter struct {
+	t *T
+}
+
+func (r checkReporter) Printf(format string, args ...interface{}) {
+	r.t.Printf(format, args...)
+}
+
+func (r checkReporter) AddEntry(name string, value interface{}) bool {
+	switch entry := value.(type) {
+	case tnf.NonCompliantObject:
+		r.t.ReportNonCompliant(entry)
+		return true
+	case tnf.RetriedTest:
+		r.t.addRetriedTest(entry)
+		return true
+	}
+	if r.t.reporter == nil {
+		return false
+	}
+	return r.t.reporter.AddEntry(name, value)
+}
+
+func (r checkReporter) Fail(message string) {
+	r.t.Fatalf("%s", message)
+}
+
+func (r checkReporter) Skip(message string) {
+	r.t.Skipf("%s", message)
+	panic(checkEnded{message: message})
+}
+
+// RunCheck runs a check against env and returns its claim result.  A panicking check fails.
+func RunCheck(ctx context.Context, env *config.TestEnvironment, check *Check) claimutil.Result {
+	return RunCheckWithReporter(ctx, env, check, nil)
+}
+
+// RunCheckWithReporter runs a check like RunCheck, and forwards its output and its entries to reporter as they are
+// reported, e.g. to the running Ginkgo spec.  The check is not failed nor skipped through reporter.
+func RunCheckWithReporter(ctx context.Context, env *config.TestEnvironment, check *Check, reporter tnf.Reporter) claimutil.Result {
+	t := &T{ctx: ctx, env: env, reporter: reporter}
+	previous := tnf.CurrentReporter()
+	tnf.SetReporter(checkReporter{t: t})
+	defer tnf.SetReporter(previous)
+	start := time.Now()
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if _, ok := recovered.(checkEnded); ok {
+					return
+				}
+				log.Errorf("check %s panicked: %v", TestID(check.ID), recovered)
+				t.Failf("%s panicked: %v", TestID(check.ID), recovered)
+			}
+		}()
+		check.Run(t)
+	}()
+	end := time.Now()
+
+	state, reason := t.state()
+	id := check.ID
+	result := claimutil.Result{Result: claim.Result{
+		Duration:      int(end.Sub(start).Nanoseconds()),
+		TestText:      identifiers.Catalog[id].Description,
+		FailureReason: reason,
+		State:         state,
+		StartTime:     start.String(),
+		EndTime:       end.String(),
+		TestID:        &id,
+	}}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, line := range t.output {
+		result.CapturedTestOutput += line + "\n"
+	}
+	if len(t.nonCompliant) > 0 {
+		result.NonCompliantObjects = t.nonCompliant
+	}
+	if len(t.retried) > 0 {
+		result.RetriedTests = t.retried
+	}
+	result.Aborted = t.aborted
+	return result
+}
+
+// Results are the claim results of the checks, keyed like the results of the Ginkgo suites, e.g.
+// "observability-observability-container-logging".
+type Results map[string][]claimutil.Result
+
+// add appends the result of check.
+func (r Results) add(check *Check, result claimutil.Result) { //nolint:gocritic // Stored by value
+	key := TestID(check.ID)
+	if suiteAndTest := identifiers.GetSuiteAndTestFromIdentifier(check.ID); len(suiteAndTest) > 0 {
+		key = suiteAndTest[0] + "-" + key
+	}
+	r[key] = append(r[key], result)
+}
+
+// Failed returns the keys of the results which did not pass nor were skipped, sorted.
+func (r Results) Failed() []string {
+	var keys []string
+	for _, key := range claimutil.SortedKeys(r) {
+		if claimutil.IsFailure(claimutil.AggregateState(r[key])) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SetClaimResults stores the results into c, see claimutil.SetResults.
+func (r Results) SetClaimResults(c *claim.Claim) {
+	claimutil.SetResults(c, r)
+}
+
+// Run runs the checks with the identifiers ids against env, in order, or all the registered checks when ids is empty.
+// An error is returned before running any check when an identifier is not registered, and with the results of the
+// checks already run when ctx is done or when a check aborts the run, see T.Abortf.
+func Run(ctx context.Context, env *config.TestEnvironment, ids ...string) (Results, error) {
+	checks := []*Check{}
+	if len(ids) == 0 {
+		for _, id := range List() {
+			ids = append(ids, id.Url)
+		}
+	}
+	for _, id := range ids {
+		check, err := Lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	results := Results{}
+	for _, check := range checks {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		log.Infof("Running check %s", TestID(check.ID))
+		result := RunCheck(ctx, env, check)
+		results.add(check, result)
+		if result.Aborted {
+			return results, fmt.Errorf("check %s aborted the run: %s", TestID(check.ID), result.FailureReason)
+		}
+	}
+	return results, nil
+}
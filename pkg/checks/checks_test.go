@@ -0,0 +1,289 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/config"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/parallel"
+)
+
+func testIdentifier(name string) claim.Identifier {
+	return claim.Identifier{Url: "http://test-network-function.com/testcases/sdk/" + name, Version: "v1.0.0"}
+}
+
+// stubRegistry replaces the registered checks with checks.
+func stubRegistry(t *testing.T, checks ...Check) {
+	orig := registry
+	t.Cleanup(func() { registry = orig })
+	registry = map[string]*Check{}
+	for _, check := range checks {
+		Register(check)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	stubRegistry(t,
+		Check{ID: testIdentifier("second"), Run: func(t *T) {}},
+		Check{ID: testIdentifier("first"), Run: func(t *T) {}},
+	)
+
+	assert.Equal(t, "sdk-first", TestID(testIdentifier("first")))
+	assert.Equal(t, []claim.Identifier{testIdentifier("first"), testIdentifier("second")}, List())
+
+	check, err := Lookup(testIdentifier("second").Url)
+	assert.Nil(t, err)
+	assert.Equal(t, testIdentifier("second"), check.ID)
+	check, err = Lookup("sdk-first")
+	assert.Nil(t, err)
+	assert.Equal(t, testIdentifier("first"), check.ID)
+	_, err = Lookup("sdk-unknown")
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() { Register(Check{ID: testIdentifier("first"), Run: func(t *T) {}}) })
+}
+
+func TestRunCheck(t *testing.T) {
+	object := tnf.NonCompliantObject{Kind: tnf.KindPod, Namespace: "tnf", Name: "test-0", Reason: "Test"}
+	testCases := []struct {
+		run             func(t *T)
+		expectedState   string
+		expectedReason  string
+		expectedOutput  string
+		expectedObject  bool
+		expectedAborted bool
+	}{
+		{
+			run:            func(t *T) { t.Printf("checking %s", "pods") },
+			expectedState:  claimutil.StatePassed,
+			expectedOutput: "checking pods\n",
+		},
+		{
+			run: func(t *T) {
+				t.Printf("checking")
+				t.ReportNonCompliant(object)
+				t.Failf("%d pods failed", 1)
+				t.Failf("again")
+			},
+			expectedState:  claimutil.StateFailed,
+			expectedReason: "1 pods failed\nagain",
+			expectedOutput: "checking\n",
+			expectedObject: true,
+		},
+		{
+			run:            func(t *T) { t.Skipf("nothing to check") },
+			expectedState:  claimutil.StateSkipped,
+			expectedReason: "nothing to check",
+		},
+		{
+			run: func(t *T) {
+				t.Skipf("nothing to check")
+				t.Failf("failed")
+			},
+			expectedState:  claimutil.StateFailed,
+			expectedReason: "failed",
+		},
+		{
+			run: func(t *T) {
+				t.Fatalf("no session")
+				t.Printf("unreachable")
+			},
+			expectedState:  claimutil.StateFailed,
+			expectedReason: "no session",
+		},
+		{
+			run: func(t *T) {
+				t.Abortf("node not restored")
+				t.Printf("unreachable")
+			},
+			expectedState:   claimutil.StateFailed,
+			expectedReason:  "node not restored",
+			expectedAborted: true,
+		},
+		{
+			// The helpers reporting to the running test case report to the check.
+			run: func(t *T) {
+				tnf.ClaimFilePrintf("checking")
+				tnf.ReportNonCompliantObject(object)
+				tnf.CurrentReporter().Fail("test failed")
+				t.Printf("unreachable")
+			},
+			expectedState:  claimutil.StateFailed,
+			expectedReason: "test failed",
+			expectedOutput: "checking\n",
+			expectedObject: true,
+		},
+		{
+			run: func(t *T) {
+				tnf.CurrentReporter().Skip("runtime not supported")
+				t.Failf("unreachable")
+			},
+			expectedState:  claimutil.StateSkipped,
+			expectedReason: "runtime not supported",
+		},
+		{
+			run:            func(t *T) { panic("broken session") },
+			expectedState:  claimutil.StateFailed,
+			expectedReason: "sdk-check panicked: broken session",
+		},
+	}
+
+	for _, tc := range testCases {
+		check := &Check{ID: testIdentifier("check"), Run: tc.run}
+		result := RunCheck(context.Background(), &config.TestEnvironment{}, check)
+		assert.Equal(t, tc.expectedState, result.State)
+		assert.Equal(t, tc.expectedReason, result.FailureReason)
+		assert.Equal(t, tc.expectedOutput, result.CapturedTestOutput)
+		assert.Equal(t, testIdentifier("check"), *result.TestID)
+		assert.NotEmpty(t, result.StartTime)
+		assert.Equal(t, tc.expectedAborted, result.Aborted)
+		if tc.expectedObject {
+			assert.Equal(t, []tnf.NonCompliantObject{object}, result.NonCompliantObjects)
+		} else {
+			assert.Nil(t, result.NonCompliantObjects)
+		}
+	}
+	_, isCheckReporter := tnf.CurrentReporter().(checkReporter)
+	assert.False(t, isCheckReporter)
+}
+
+// recordingReporter records the output and the entries forwarded by the checks.
+type recordingReporter struct {
+	tnf.Reporter
+	lines   []string
+	entries []string
+}
+
+func (r *recordingReporter) Printf(format string, args ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingReporter) AddEntry(name string, value interface{}) bool {
+	r.entries = append(r.entries, name)
+	return true
+}
+
+func TestRunCheckWithReporter(t *testing.T) {
+	retried := tnf.RetriedTest{Name: "test", Attempts: []tnf.Attempt{{Number: 1, Result: tnf.ERROR}, {Number: 2, Result: tnf.SUCCESS}}}
+	check := &Check{ID: testIdentifier("reporter"), Run: func(t *T) {
+		t.Printf("checking")
+		t.ReportNonCompliant(tnf.NonCompliantObject{Kind: tnf.KindNode, Name: "worker-0", Reason: "Test"})
+		tnf.CurrentReporter().AddEntry(tnf.RetriedTestEntryName, retried)
+	}}
+	reporter := &recordingReporter{}
+
+	result := RunCheckWithReporter(context.Background(), &config.TestEnvironment{}, check, reporter)
+	assert.Equal(t, claimutil.StatePassed, result.State)
+	assert.Equal(t, []tnf.RetriedTest{retried}, result.RetriedTests)
+	assert.Equal(t, []string{"checking"}, reporter.lines)
+	assert.Equal(t, []string{tnf.NonCompliantObjectEntryName, tnf.RetriedTestEntryName}, reporter.entries)
+}
+
+func TestRunner(t *testing.T) {
+	check := &Check{ID: testIdentifier("runner"), Run: func(t *T) {
+		report := t.Runner().Run([]string{"c0", "c1"}, func(w *parallel.Worker, task *parallel.Task) {
+			task.Printf("checking %s", task.Name)
+			if task.Index == 1 {
+				task.Failf("%s failed", task.Name)
+				task.ReportNonCompliant(tnf.NonCompliantObject{Kind: tnf.KindContainer, Name: task.Name, Reason: "Test"})
+			}
+		})
+		if n := len(report.Failed()); n > 0 {
+			t.Failf("%d containers failed", n)
+		}
+	}}
+
+	result := RunCheck(context.Background(), &config.TestEnvironment{}, check)
+	assert.Equal(t, claimutil.StateFailed, result.State)
+	assert.Equal(t, "1 containers failed", result.FailureReason)
+	assert.Equal(t, "checking c0\nchecking c1\nc1 failed\n", result.CapturedTestOutput)
+	assert.Equal(t, []tnf.NonCompliantObject{{Kind: tnf.KindContainer, Name: "c1", Reason: "Test"}}, result.NonCompliantObjects)
+}
+
+func TestRun(t *testing.T) {
+	var ran []string
+	newCheck := func(name string, fail bool) Check {
+		return Check{ID: testIdentifier(name), Run: func(t *T) {
+			ran = append(ran, name)
+			if fail {
+				t.Failf("%s failed", name)
+			}
+		}}
+	}
+	stubRegistry(t, newCheck("b", true), newCheck("a", false), newCheck("c", false))
+	env := &config.TestEnvironment{}
+
+	results, err := Run(context.Background(), env)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ran)
+	assert.Len(t, results, 3)
+	assert.Equal(t, []string{"sdk-sdk-b"}, results.Failed())
+	assert.Equal(t, "b failed", results["sdk-sdk-b"][0].FailureReason)
+	c := &claim.Claim{}
+	results.SetClaimResults(c)
+	assert.Equal(t, results["sdk-sdk-a"], c.Results["sdk-sdk-a"])
+
+	ran = nil
+	results, err = Run(context.Background(), env, "sdk-c", testIdentifier("a").Url)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"c", "a"}, ran)
+	assert.Len(t, results, 2)
+	assert.Empty(t, results.Failed())
+
+	ran = nil
+	_, err = Run(context.Background(), env, "sdk-a", "sdk-unknown")
+	assert.NotNil(t, err)
+	assert.Empty(t, ran)
+
+	stubRegistry(t, newCheck("a", false), Check{ID: testIdentifier("abort"), Run: func(t *T) {
+		ran = append(ran, "abort")
+		t.Abortf("node not restored")
+	}})
+	ran = nil
+	results, err = Run(context.Background(), env, "sdk-abort", "sdk-a")
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"abort"}, ran)
+	assert.Equal(t, []string{"sdk-sdk-abort"}, results.Failed())
+
+	ran = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = Run(ctx, env, "sdk-a")
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, results)
+	assert.Empty(t, ran)
+}
+
+func TestDiscover(t *testing.T) {
+	orig := getTestEnvironment
+	t.Cleanup(func() { getTestEnvironment = orig })
+	getTestEnvironment = func() *config.TestEnvironment { return &config.TestEnvironment{} }
+	t.Setenv("TNF_CONFIGURATION_PATH", "testdata/missing.yml")
+
+	env, err := Discover()
+	assert.Nil(t, env)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "missing.yml")
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+/*
+Package checks runs the test cases of the suite without Ginkgo, e.g. from a Go service embedding the checks.  A Check is
+a test case registered under its claim identifier.  It reports its outcome to a T, in the manner of testing.T, instead
+of calling gomega or ginkgo, so that the checks can be run both by Run, which returns their results and errors, and by
+the Ginkgo suites through ginkgoadapter.RunSpec.  The package does not depend on Ginkgo.
+
+A typical use outside Ginkgo:
+
+	import (
+		"github.com/test-network-function/test-network-function/pkg/checks"
+		_ "github.com/test-network-function/test-network-function/pkg/checks/observability"
+	)
+
+	env, err := checks.Discover()
+	if err != nil {
+		return err
+	}
+	results, err := checks.Run(ctx, env, "observability-container-logging")
+*/
+package checks
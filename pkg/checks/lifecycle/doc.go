@@ -0,0 +1,23 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+/*
+Package lifecycle registers the checks of the lifecycle test suite, such as the pod scheduling, scaling, termination,
+probes and owners of the pods under test.  They are run by the Ginkgo suite of the same name, or by checks.Run once this
+package is imported.  The intrusive checks, which scale the pod sets or drain the nodes, abort the run when the cluster
+under test cannot be restored, see checks.T.Abortf.
+*/
+package lifecycle
@@ -0,0 +1,791 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package lifecycle
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	dd "github.com/test-network-function/test-network-function/pkg/tnf/handlers/deploymentsdrain"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/nodeselector"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/owners"
+	ps "github.com/test-network-function/test-network-function/pkg/tnf/handlers/podsets"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/scaling"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+const (
+	baseNodeDrainTimeout         = 5 * time.Minute
+	maxNodeDrainTimeout          = 30 * time.Minute
+	scalingTimeout               = 1 * time.Minute
+	scalingPollingPeriod         = 1 * time.Second
+	postNodeDrainRecoveryTimeOut = 2 * time.Minute
+)
+
+var (
+	// nodeUncordonTestPath is the file location of the uncordon.json test case relative to the project root.
+	nodeUncordonTestPath = path.Join("pkg", "tnf", "handlers", "nodeuncordon", "uncordon.json")
+
+	// shutdownTestPath is the file location of shutdown.json test case relative to the project root.
+	shutdownTestPath = path.Join("pkg", "tnf", "handlers", "shutdown", "shutdown.json")
+
+	// livenessTestPath is the file location of liveness.json test case relative to the project root.
+	livenessTestPath = path.Join("pkg", "tnf", "handlers", "liveness", "liveness.json")
+
+	// readinessTestPath is the file location of readiness.json test case relative to the project root.
+	readinessTestPath = path.Join("pkg", "tnf", "handlers", "readiness", "readiness.json")
+
+	// shutdownTestDirectoryPath is the directory of the shutdown test
+	shutdownTestDirectoryPath = path.Join("pkg", "tnf", "handlers", "shutdown")
+
+	// livenessTestDirectoryPath is the directory of the liveness test
+	livenessTestDirectoryPath = path.Join("pkg", "tnf", "handlers", "liveness")
+
+	// readinessTestDirectoryPath is the directory of the readiness test
+	readinessTestDirectoryPath = path.Join("pkg", "tnf", "handlers", "readiness")
+
+	// relativeNodesTestPath is the relative path to the nodes.json test case.
+	relativeNodesTestPath = path.Join(environment.PathRelativeToRoot, nodeUncordonTestPath)
+
+	// relativeShutdownTestPath is the relative path to the shutdown.json test case.
+	relativeShutdownTestPath = path.Join(environment.PathRelativeToRoot, shutdownTestPath)
+
+	// relativeLivenessTestPath is the relative path to the liveness.json test case.
+	relativeLivenessTestPath = path.Join(environment.PathRelativeToRoot, livenessTestPath)
+
+	// relativeReadinessTestPath is the relative path to the readiness.json test case.
+	relativeReadinessTestPath = path.Join(environment.PathRelativeToRoot, readinessTestPath)
+
+	// relativeShutdownTestDirectoryPath is the directory of the shutdown directory
+	relativeShutdownTestDirectoryPath = path.Join(environment.PathRelativeToRoot, shutdownTestDirectoryPath)
+
+	// relativelivenessTestDirectoryPath is the directory of the liveness directory
+	relativeLivenessTestDirectoryPath = path.Join(environment.PathRelativeToRoot, livenessTestDirectoryPath)
+
+	// relativereadinessTestDirectoryPath is the directory of the readiness directory
+	relativeReadinessTestDirectoryPath = path.Join(environment.PathRelativeToRoot, readinessTestDirectoryPath)
+
+	// podAntiAffinityTestPath is the file location of the podantiaffinity.json test case relative to the project root.
+	podAntiAffinityTestPath = path.Join("pkg", "tnf", "handlers", "podantiaffinity", "podantiaffinity.json")
+
+	// relativePodTestPath is the relative path to the podantiaffinity.json test case.
+	relativePodTestPath = path.Join(environment.PathRelativeToRoot, podAntiAffinityTestPath)
+
+	// relativeimagepullpolicyTestPath is the relative path to the imagepullpolicy.json test case.
+	imagepullpolicyTestPath         = path.Join("pkg", "tnf", "handlers", "imagepullpolicy", "imagepullpolicy.json")
+	relativeimagepullpolicyTestPath = path.Join(environment.PathRelativeToRoot, imagepullpolicyTestPath)
+)
+
+func init() {
+	checks.Register(checks.Check{ID: identifiers.TestImagePullPolicyIdentifier, Run: testImagePolicy})
+	checks.Register(checks.Check{ID: identifiers.TestPodNodeSelectorAndAffinityBestPractices, Run: testNodeSelector})
+	checks.Register(checks.Check{ID: identifiers.TestShudtownIdentifier, Run: testShutdown})
+	checks.Register(checks.Check{ID: identifiers.TestLivenessIdentifier, Run: testLiveness})
+	checks.Register(checks.Check{ID: identifiers.TestReadinessIdentifier, Run: testReadiness})
+	checks.Register(checks.Check{ID: identifiers.TestPodHighAvailabilityBestPractices, Run: testPodAntiAffinity})
+	checks.Register(checks.Check{ID: identifiers.TestPodRecreationIdentifier, Run: testPodsRecreation})
+	checks.Register(checks.Check{ID: identifiers.TestDeploymentScalingIdentifier, Run: testScaling})
+	checks.Register(checks.Check{ID: identifiers.TestStateFulSetScalingIdentifier, Run: testStateFulSetScaling})
+	checks.Register(checks.Check{ID: identifiers.TestPodDeploymentBestPracticesIdentifier, Run: testOwner})
+}
+
+// isIntrusive skips the check when the intrusive test cases are disabled, see environment.Intrusive.
+func isIntrusive(t *checks.T) bool {
+	if !environment.Intrusive() {
+		t.Skipf("Intrusive test cases are disabled.")
+		return false
+	}
+	return true
+}
+
+// localShell returns the session to the local shell, and ends t if there is none.  It is looked up on every call, as
+// draining a node resets the sessions.
+func localShell(t *checks.T) *interactive.Context {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Fatalf("No session to the local shell. Error: %v", err)
+	}
+	return context
+}
+
+func waitForAllPodSetsReady(t *checks.T, namespace string, timeout, pollingPeriod time.Duration, resourceType configsections.PodSetType, context *interactive.Context) int { //nolint:unparam // it is fine to use always the same value for timeout
+	var elapsed time.Duration
+	var notReadyPodSets []string
+
+	for elapsed < timeout {
+		_, notReadyPodSets = GetPodSets(t, namespace, resourceType, context)
+		log.Debugf("Waiting for %s to get ready, remaining: %d PodSets", string(resourceType), len(notReadyPodSets))
+		if len(notReadyPodSets) == 0 {
+			break
+		}
+		time.Sleep(pollingPeriod)
+		elapsed += pollingPeriod
+	}
+	return len(notReadyPodSets)
+}
+
+// restoreDeployments is the last attempt to restore the original test deployments' replicaCount
+func restoreDeployments(t *checks.T) {
+	env := t.Env()
+	for i := range env.DeploymentsUnderTest {
+		// For each test deployment in the namespace, refresh the current replicas and compare.
+		refreshReplicas(t, &env.DeploymentsUnderTest[i])
+	}
+}
+
+// restoreStateFulSet is the last attempt to restore the original test PodSets' replicaCount
+func restoreStateFulSet(t *checks.T) {
+	env := t.Env()
+	for i := range env.StateFulSetUnderTest {
+		// For each test StateFulSet in the namespace, refresh the current replicas and compare.
+		refreshReplicas(t, &env.StateFulSetUnderTest[i])
+	}
+}
+
+func refreshReplicas(t *checks.T, podset *configsections.PodSet) {
+	podsets, notReadyPodsets := GetPodSets(t, podset.Namespace, podset.Type, localShell(t))
+
+	if len(notReadyPodsets) > 0 {
+		// Wait until the deployment/replicaset is ready
+		notReady := waitForAllPodSetsReady(t, podset.Namespace, scalingTimeout, scalingPollingPeriod, podset.Type, localShell(t))
+		if notReady != 0 {
+			collectNodeAndPendingPodInfo(t, podset.Namespace, localShell(t))
+			t.Abortf("Could not restore %s replicaCount for namespace %s.", string(podset.Type), podset.Namespace)
+		}
+	}
+	if podset.Hpa.HpaName != "" { // it have hpa and need to update the max min
+		runHpaScalingTest(t, podset, localShell(t))
+	}
+	key := podset.Namespace + ":" + podset.Name
+	dep, ok := podsets[key]
+	if ok {
+		if dep.Replicas != podset.Replicas {
+			log.Warn(string(podset.Type), podset.Name, " replicaCount (", podset.Replicas, ") needs to be restored.")
+
+			// Try to scale to the original deployments/statefulsets replicaCount.
+			runScalingTest(t, podset, localShell(t))
+
+			t.Env().SetNeedsRefresh()
+		}
+	}
+}
+
+func closeOcSessionsByPodset(containers map[configsections.ContainerIdentifier]*configsections.Container, podset *configsections.PodSet) {
+	log.Debug("close session for", string(podset.Type), "=", podset.Name, " start")
+	defer log.Debug("close session for", string(podset.Type), "=", podset.Name, " done")
+	for cid, c := range containers {
+		if cid.Namespace == podset.Namespace && strings.HasPrefix(cid.PodName, podset.Name+"-") {
+			log.Infof("Closing session to %s %s", cid.PodName, cid.ContainerName)
+			c.CloseOc()
+			delete(containers, cid)
+		}
+	}
+}
+
+// runScalingTest Runs a Scaling handler TC and waits for all the deployments/statefulset to be ready.
+func runScalingTest(t *checks.T, podset *configsections.PodSet, context *interactive.Context) {
+	handler := scaling.NewScaling(environment.DefaultTimeout, podset.Namespace, podset.Name, string(podset.Type), podset.Replicas)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), handler, []reel.Handler{handler}, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Failed to scale %s %s (ns %s). Error: %v", string(podset.Type), podset.Name, podset.Namespace, err)
+	}
+	test.RunAndValidate()
+
+	// Wait until the deployment/statefulset is ready
+	notReady := waitForAllPodSetsReady(t, podset.Namespace, scalingTimeout, scalingPollingPeriod, podset.Type, context)
+	if notReady != 0 {
+		collectNodeAndPendingPodInfo(t, podset.Namespace, context)
+		t.Fatalf("Failed to scale deployment for namespace %s.", podset.Namespace)
+	}
+}
+
+func runHpaScalingTest(t *checks.T, podset *configsections.PodSet, context *interactive.Context) {
+	handler := scaling.NewHpaScaling(environment.DefaultTimeout, podset.Namespace, podset.Hpa.HpaName, podset.Hpa.MinReplicas, podset.Hpa.MaxReplicas)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), handler, []reel.Handler{handler}, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Failed to auto-scale hpa %s (ns %s). Error: %v", podset.Hpa.HpaName, podset.Namespace, err)
+	}
+	test.RunAndValidate()
+
+	// Wait until the deployment/statefulset is ready
+	notReady := waitForAllPodSetsReady(t, podset.Namespace, scalingTimeout, scalingPollingPeriod, podset.Type, context)
+	if notReady != 0 {
+		collectNodeAndPendingPodInfo(t, podset.Namespace, context)
+		t.Fatalf("Failed to auto-scale %s for namespace %s.", string(podset.Type), podset.Namespace)
+	}
+}
+
+func testScaling(t *checks.T) {
+	if !isIntrusive(t) {
+		return
+	}
+	log.Debugf("Testing deployment scaling")
+	env := t.Env()
+	defer restoreDeployments(t)
+	defer env.SetNeedsRefresh()
+
+	if len(env.DeploymentsUnderTest) == 0 {
+		t.Skipf("No test deployments found.")
+		return
+	}
+	for i := range env.DeploymentsUnderTest {
+		runScalingfunc(t, &env.DeploymentsUnderTest[i])
+	}
+}
+
+func testStateFulSetScaling(t *checks.T) {
+	if !isIntrusive(t) {
+		return
+	}
+	log.Debugf("Testing StatefulSet scaling")
+	env := t.Env()
+	defer restoreStateFulSet(t)
+	defer env.SetNeedsRefresh()
+
+	if len(env.StateFulSetUnderTest) == 0 {
+		t.Skipf("No test StatefulSet found.")
+		return
+	}
+	for i := range env.StateFulSetUnderTest {
+		runScalingfunc(t, &env.StateFulSetUnderTest[i])
+	}
+}
+
+func runScalingfunc(t *checks.T, podset *configsections.PodSet) {
+	log.Debugf("Scaling %s=%s, Replicas=%d (ns=%s)", string(podset.Type), podset.Name, podset.Replicas, podset.Namespace)
+
+	closeOcSessionsByPodset(t.Env().ContainersUnderTest, podset)
+	replicaCount := podset.Replicas
+	podsetscale := *podset
+	if podsetscale.Hpa.HpaName != "" {
+		podsetscale.Hpa.MinReplicas = replicaCount - 1
+		podsetscale.Hpa.MaxReplicas = replicaCount - 1
+		runHpaScalingTest(t, &podsetscale, localShell(t)) // scale in
+		podsetscale.Hpa.MinReplicas = replicaCount
+		podsetscale.Hpa.MaxReplicas = replicaCount
+		runHpaScalingTest(t, &podsetscale, localShell(t)) // scale out
+	} else {
+		// ScaleIn, removing one pod from the replicaCount
+		podsetscale.Replicas = replicaCount - 1
+		runScalingTest(t, &podsetscale, localShell(t))
+
+		// Scaleout, restoring the original replicaCount number
+		podsetscale.Replicas = replicaCount
+		runScalingTest(t, &podsetscale, localShell(t))
+	}
+}
+
+func testNodeSelector(t *checks.T) {
+	log.Debugf("Testing pod nodeSelector")
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pod nodeSelector could not be tested. Error: %v", err)
+		return
+	}
+	badPods := []configsections.Pod{}
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("Testing pod nodeSelector %s/%s", podUnderTest.Namespace, podUnderTest.Name)
+		tester := nodeselector.NewNodeSelector(environment.DefaultTimeout, podUnderTest.Name, podUnderTest.Namespace)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Pod %s/%s could not be tested. Error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
+			return
+		}
+
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("FAILURE: Pod %s/%s has nodeSelector/nodeAffinity rule", podUnderTest.Namespace, podUnderTest.Name)
+			badPods = append(badPods, *podUnderTest)
+		}, func(err error) {
+			t.Printf("ERROR: Pod %s/%s, error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
+			badPods = append(badPods, *podUnderTest)
+		})
+	}
+
+	if n := len(badPods); n > 0 {
+		log.Debugf("Pods with nodeSelector/nodeAffinity: %+v", badPods)
+		t.Failf("%d pods found with nodeSelector/nodeAffinity rules", n)
+	}
+}
+
+//nolint:dupl
+func testShutdown(t *checks.T) {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pod pre-stop could not be tested. Error: %v", err)
+		return
+	}
+	failedPods := []*configsections.Pod{}
+	log.Debugf("Testing PUTs are configured with pre-stop lifecycle")
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("should have pre-stop configured %s/%s", podUnderTest.Namespace, podUnderTest.Name)
+		passed := shutdownTest(t, podUnderTest.Namespace, podUnderTest.Name, context)
+		if !passed {
+			failedPods = append(failedPods, podUnderTest)
+		}
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods without pre-stop configured: %+v", failedPods)
+		t.Failf("%d pods do not have pre-stop configured.", n)
+	}
+}
+
+func shutdownTest(t *checks.T, podNamespace, podName string, context *interactive.Context) bool {
+	passed := true
+	values := make(map[string]interface{})
+	values["POD_NAMESPACE"] = podNamespace
+	values["POD_NAME"] = podName
+	values["GO_TEMPLATE_PATH"] = relativeShutdownTestDirectoryPath
+	tester, handlers := utils.NewGenericTesterAndValidate(relativeShutdownTestPath, environment.RelativeSchemaPath, values)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Pod %s/%s could not be tested. Error: %v", podNamespace, podName, err)
+	}
+
+	test.RunWithCallbacks(nil, func() {
+		t.Printf("FAILURE: Pod %s/%s does not have pre-stop configured", podNamespace, podName)
+		passed = false
+	}, func(err error) {
+		t.Printf("ERROR: Pod %s/%s, error: %v", podNamespace, podName, err)
+		passed = false
+	})
+	return passed
+}
+
+//nolint:dupl
+func testLiveness(t *checks.T) {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pod liveness could not be tested. Error: %v", err)
+		return
+	}
+	failedPods := []*configsections.Pod{}
+	log.Debugf("Testing PUTs are configured with liveness lifecycle")
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("should have liveness configured %s/%s", podUnderTest.Namespace, podUnderTest.Name)
+		passed := livenessTest(t, podUnderTest.Namespace, podUnderTest.Name, context)
+		if !passed {
+			failedPods = append(failedPods, podUnderTest)
+		}
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods without liveness: %+v", failedPods)
+		t.Failf("%d pods do not have liveness configured.", n)
+	}
+}
+
+func livenessTest(t *checks.T, podNamespace, podName string, context *interactive.Context) bool {
+	passed := true
+	values := make(map[string]interface{})
+	values["POD_NAMESPACE"] = podNamespace
+	values["POD_NAME"] = podName
+	values["GO_TEMPLATE_PATH"] = relativeLivenessTestDirectoryPath
+	tester, handlers := utils.NewGenericTesterAndValidate(relativeLivenessTestPath, environment.RelativeSchemaPath, values)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Pod %s/%s could not be tested. Error: %v", podNamespace, podName, err)
+	}
+
+	test.RunWithCallbacks(nil, func() {
+		t.Printf("FAILURE: Pod %s/%s does not have liveness defined", podNamespace, podName)
+		passed = false
+	}, func(err error) {
+		t.Printf("ERROR: Pod %s/%s, error: %v", podNamespace, podName, err)
+		passed = false
+	})
+	return passed
+}
+
+//nolint:dupl
+func testReadiness(t *checks.T) {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pod readiness could not be tested. Error: %v", err)
+		return
+	}
+	failedPods := []*configsections.Pod{}
+	log.Debugf("Testing PUTs are configured with readiness lifecycle")
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("should have readiness configured %s/%s", podUnderTest.Namespace, podUnderTest.Name)
+		passed := readinessTest(t, podUnderTest.Namespace, podUnderTest.Name, context)
+		if !passed {
+			failedPods = append(failedPods, podUnderTest)
+		}
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods without readiness: %+v", failedPods)
+		t.Failf("%d pods do not have readiness configured.", n)
+	}
+}
+
+func readinessTest(t *checks.T, podNamespace, podName string, context *interactive.Context) bool {
+	passed := true
+	values := make(map[string]interface{})
+	values["POD_NAMESPACE"] = podNamespace
+	values["POD_NAME"] = podName
+	values["GO_TEMPLATE_PATH"] = relativeReadinessTestDirectoryPath
+	tester, handlers := utils.NewGenericTesterAndValidate(relativeReadinessTestPath, environment.RelativeSchemaPath, values)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Pod %s/%s could not be tested. Error: %v", podNamespace, podName, err)
+	}
+
+	test.RunWithCallbacks(nil, func() {
+		t.Printf("FAILURE: Pod %s/%s does not have readiness defined", podNamespace, podName)
+		passed = false
+	}, func(err error) {
+		t.Printf("ERROR: Pod %s/%s, error: %v", podNamespace, podName, err)
+		passed = false
+	})
+	return passed
+}
+
+func cleanupNodeDrain(t *checks.T, nodeName string) {
+	uncordonNode(t, nodeName, localShell(t))
+	for _, ns := range t.Env().NameSpacesUnderTest {
+		notReady := waitForAllPodSetsReady(t, ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.Deployment, localShell(t))
+		if notReady != 0 {
+			collectNodeAndPendingPodInfo(t, ns, localShell(t))
+			t.Abortf("Cleanup after node drain for %s failed, stopping tests to ensure cluster integrity", nodeName)
+		}
+		notReadyStateFulSets := waitForAllPodSetsReady(t, ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.StateFulSet, localShell(t))
+		if notReadyStateFulSets != 0 {
+			collectNodeAndPendingPodInfo(t, ns, localShell(t))
+			t.Abortf("Cleanup after node drain for %s failed, stopping tests to ensure cluster integrity", nodeName)
+		}
+	}
+}
+
+func testNodeDrain(t *checks.T, nodeName string) {
+	log.Debugf("Testing node drain for %s\n", nodeName)
+	// Ensure the node is uncordoned before exiting the function,
+	// and all podsets(deployments/statefulset) are ready
+	defer cleanupNodeDrain(t, nodeName)
+
+	// drain node
+	if err := drainNode(t, nodeName, localShell(t)); err != nil {
+		t.Fatalf("Draining node %s failed: %s", nodeName, err)
+	}
+
+	for _, ns := range t.Env().NameSpacesUnderTest {
+		notReadyDeployments := waitForAllPodSetsReady(t, ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.Deployment, localShell(t))
+		if notReadyDeployments != 0 {
+			collectNodeAndPendingPodInfo(t, ns, localShell(t))
+			t.Fatalf("Failed to recover deployments on namespace %s after draining node %s.", ns, nodeName)
+		}
+		notReadyStateFulSets := waitForAllPodSetsReady(t, ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.StateFulSet, localShell(t))
+		if notReadyStateFulSets != 0 {
+			collectNodeAndPendingPodInfo(t, ns, localShell(t))
+			t.Fatalf("Failed to recover statefulsets on namespace %s after draining node %s.", ns, nodeName)
+		}
+	}
+	// If we got this far, all deployments/statefulsets are ready after draining the node
+	t.Printf("Node drain for %s succeeded", nodeName)
+}
+
+func testPodsRecreation(t *checks.T) {
+	if !isIntrusive(t) {
+		return
+	}
+	env := t.Env()
+	deployments := make(ps.PodSetMap)
+	var notReadyDeployments []string
+	statefulsets := make(ps.PodSetMap)
+	var notReadyStatefulsets []string
+
+	log.Debugf("Testing node draining effect of deployment")
+	log.Debugf("test deployment in namespace %s", env.NameSpacesUnderTest)
+	for _, ns := range env.NameSpacesUnderTest {
+		var dps ps.PodSetMap
+		var sfs ps.PodSetMap
+		dps, notReadyDeployments = GetPodSets(t, ns, configsections.Deployment, localShell(t))
+		for dpKey, dp := range dps {
+			deployments[dpKey] = dp
+		}
+		sfs, notReadyStatefulsets = GetPodSets(t, ns, configsections.StateFulSet, localShell(t))
+		for sfKey, sf := range sfs {
+			statefulsets[sfKey] = sf
+		}
+		// We require that all deployments/statefulset have the desired number of replicas and are all up to date
+		if len(notReadyDeployments) != 0 && len(notReadyStatefulsets) != 0 {
+			t.Skipf("Can not test when podsets are not ready")
+			return
+		}
+	}
+	if len(deployments) == 0 && len(statefulsets) == 0 {
+		t.Skipf("no valid deployment or statefulset")
+		return
+	}
+	defer env.SetNeedsRefresh()
+	log.Debugf("should create new replicas when node is drained")
+	// We need to delete all Oc sessions because the drain operation is often deleting oauth-openshift pod
+	// This results in lost connectivity for oc sessions
+	env.ResetOc()
+	for _, n := range env.NodesUnderTest {
+		if !n.HasPodset() {
+			log.Debug("node ", n.Name, " has no podset, skip draining")
+			continue
+		}
+		testNodeDrain(t, n.Name)
+	}
+}
+
+// GetPodSets returns map of podsets(deployments/statefulset) and names of not-ready podsets
+func GetPodSets(t *checks.T, namespace string, resourceType configsections.PodSetType, context *interactive.Context) (podsets ps.PodSetMap, notReadypodsets []string) {
+	tester := ps.NewPodSets(environment.DefaultTimeout, namespace, string(resourceType))
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Failed to get the %s of namespace %s. Error: %v", string(resourceType), namespace, err)
+	}
+	test.RunAndValidate()
+
+	podsets = tester.GetPodSets()
+	for name, d := range podsets {
+		if d.Unavailable != 0 || d.Ready != d.Replicas || (d.Available != d.Replicas && d.Current != d.Replicas) || d.UpToDate != d.Replicas {
+			notReadypodsets = append(notReadypodsets, name)
+			log.Tracef("%s %s: not ready", string(resourceType), name)
+		} else {
+			log.Tracef("%s %s: ready", string(resourceType), name)
+		}
+	}
+
+	return podsets, notReadypodsets
+}
+
+func collectNodeAndPendingPodInfo(t *checks.T, ns string, context *interactive.Context) {
+	nodeStatus, _ := utils.ExecuteCommand("oc get nodes -o json | jq '.items[]|{name:.metadata.name, taints:.spec.taints}'", environment.DefaultTimeout, context)
+	t.Printf("Namespace: %s\nNode status:\n%s", ns, nodeStatus)
+
+	cmd := fmt.Sprintf("oc get pods -n %s --field-selector=status.phase!=Running,status.phase!=Succeeded -o json | jq '.items[]|{name:.metadata.name, status:.status}'", ns)
+	podStatus, _ := utils.ExecuteCommand(cmd, environment.DefaultTimeout, context)
+	t.Printf("Pending Pods:\n%s", podStatus)
+
+	cmd = fmt.Sprintf("oc get events -n %s --field-selector type!=Normal -o json --sort-by='.lastTimestamp' | jq '.items[]|{object:.involvedObject, reason:.reason, type:.type, message:.message, lastSeen:.lastTimestamp}'", ns)
+	events, _ := utils.ExecuteCommand(cmd, environment.DefaultTimeout, context)
+	t.Printf("Events:\n%s", events)
+}
+
+// getNumPodsDeployedOnNode is a helper function that returns the number of all pods
+// deployed in a given node.
+func getNumPodsDeployedOnNode(nodeName string, context *interactive.Context) (int, error) {
+	const cmdFmt = "oc get pods --all-namespaces -o wide --field-selector spec.nodeName=%s -l pod-template-hash"
+	cmd := fmt.Sprintf(cmdFmt, nodeName)
+	out, err := utils.ExecuteCommand(cmd, environment.DefaultTimeout, context)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get a pod list of pods deployed on the node: %s", err)
+	}
+
+	// The ouptut should be a table, should we expect at list one line for the columns description.
+	numPodsDeployed := len(strings.Split(out, "\n"))
+	if numPodsDeployed == 0 {
+		return 0, fmt.Errorf("empty output from cmd: %q", cmd)
+	}
+
+	return numPodsDeployed - 1, nil
+}
+
+func drainNode(t *checks.T, node string, context *interactive.Context) error {
+	// Before draining, we'll get the number of pods currently deployed on it.
+	numPodsDeployed, err := getNumPodsDeployedOnNode(node, context)
+	if err != nil {
+		return fmt.Errorf("failed to get number of pods deployed in the node: %s", err)
+	}
+
+	// We'll add one minute per pod to the base timeout for the node drain operation.
+	nodeDrainTimeout := baseNodeDrainTimeout + (time.Duration(numPodsDeployed) * time.Minute)
+
+	// Make sure the calculated timeout won't exceed the allowed maximum.
+	if nodeDrainTimeout > maxNodeDrainTimeout {
+		nodeDrainTimeout = maxNodeDrainTimeout
+	}
+
+	log.Infof("Dynamic timeout for draining node %s: %s (pods deployed: %d)", node, nodeDrainTimeout, numPodsDeployed)
+
+	tester := dd.NewDeploymentsDrain(nodeDrainTimeout, node)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+	if err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	result, err := test.Run()
+	if err != nil {
+		return err
+	}
+
+	elapsedTime := time.Since(startTime)
+	log.Infof("Draining node %s took %s.", node, elapsedTime)
+
+	if result != tnf.SUCCESS {
+		return fmt.Errorf("tester returned result code %d", result)
+	}
+
+	return nil
+}
+
+func uncordonNode(t *checks.T, node string, context *interactive.Context) {
+	values := make(map[string]interface{})
+	values["NODE"] = node
+	tester, handlers := utils.NewGenericTesterAndValidate(relativeNodesTestPath, environment.RelativeSchemaPath, values)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+	if err != nil {
+		t.Printf("ERROR: unable to uncordon node %s: %s", node, err)
+		t.Abortf("Failed to uncordon node %s, stopping tests to ensure cluster integrity", node)
+	}
+
+	test.RunWithCallbacks(nil, func() {
+		t.Printf("FAILURE: unable to uncordon node %s", node)
+		t.Abortf("Failed to uncordon node %s, stopping tests to ensure cluster integrity", node)
+	}, func(err error) {
+		t.Printf("ERROR: unable to uncordon node %s: %s", node, err)
+		t.Abortf("Failed to uncordon node %s, stopping tests to ensure cluster integrity", node)
+	})
+}
+
+// Pod antiaffinity test for all deployments
+func testPodAntiAffinity(t *checks.T) {
+	log.Debugf("Should set pod replica number greater than 1 and corresponding pod anti-affinity rules in deployment")
+	env := t.Env()
+	if len(env.DeploymentsUnderTest) == 0 {
+		t.Skipf("No test deployments found.")
+		return
+	}
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pod anti-affinity could not be tested. Error: %v", err)
+		return
+	}
+
+	badDeployments := []configsections.PodSet{}
+	for _, deployment := range env.DeploymentsUnderTest {
+		log.Debugf("Testing Pod AntiAffinity on Deployment=%s, Replicas=%d (ns=%s)",
+			deployment.Name, deployment.Replicas, deployment.Namespace)
+		if !podAntiAffinity(t, deployment.Name, deployment.Namespace, deployment.Replicas, context) {
+			badDeployments = append(badDeployments, deployment)
+		}
+	}
+
+	if n := len(badDeployments); n > 0 {
+		log.Debugf("Deployments without a valid podAntiAffinity rule: %+v", badDeployments)
+		t.Failf("%d deployments failed the test for replicaCount > 1 and podAntiAffinity rule.", n)
+	}
+}
+
+// check pod antiaffinity definition for a deployment
+func podAntiAffinity(t *checks.T, deployment, podNamespace string, replica int, context *interactive.Context) bool {
+	values := make(map[string]interface{})
+	values["DEPLOYMENT_NAME"] = deployment
+	values["DEPLOYMENT_NAMESPACE"] = podNamespace
+	tester, handlers := utils.NewGenericTesterAndValidate(relativePodTestPath, environment.RelativeSchemaPath, values)
+	test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+	if err != nil {
+		t.Fatalf("Deployment %s (ns %s) could not be tested. Error: %v", deployment, podNamespace, err)
+	}
+
+	result := true
+	test.RunWithCallbacks(nil, func() {
+		result = false
+		if replica > 1 {
+			t.Printf("FAILURE: The deployment replica count is %d, but a podAntiAffinity rule is not defined, "+
+				"you might want to change it in deployment %s in namespace %s", replica, deployment, podNamespace)
+		} else {
+			t.Printf("FAILURE: The deployment replica count is %d. Pod replica should be > 1 with an "+
+				"podAntiAffinity rule defined . You might want to change it in deployment %s in namespace %s",
+				replica, deployment, podNamespace)
+		}
+	}, func(err error) {
+		result = false
+		t.Printf("ERROR: Failed to get replica count and podAntiAffinity for deployment %s (ns %s). Error: %v", deployment, podNamespace, err)
+	})
+
+	return result
+}
+
+func testOwner(t *checks.T) {
+	log.Debugf("Testing owners of CNF pod, should be replicas Set")
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pod owners could not be tested. Error: %v", err)
+		return
+	}
+	failedPods := []*configsections.Pod{}
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		log.Debugf("Should be ReplicaSet %s %s", podUnderTest.Namespace, podUnderTest.Name)
+		tester := owners.NewOwners(environment.DefaultTimeout, podUnderTest.Namespace, podUnderTest.Name)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Pod %s/%s could not be tested. Error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
+			return
+		}
+
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("FAILURE: Pod %s/%s is not owned by a replica set", podUnderTest.Namespace, podUnderTest.Name)
+			failedPods = append(failedPods, podUnderTest)
+		}, func(err error) {
+			t.Printf("ERROR: Pod %s/%s, error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
+			failedPods = append(failedPods, podUnderTest)
+		})
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods not owned by a replica set: %+v", failedPods)
+		t.Failf("%d pods are not owned by a replica set.", n)
+	}
+}
+
+func testImagePolicy(t *checks.T) {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Image pull policy could not be tested. Error: %v", err)
+		return
+	}
+	failedPods := []*configsections.Pod{}
+	for _, podUnderTest := range t.Env().PodsUnderTest {
+		values := make(map[string]interface{})
+		values["POD_NAMESPACE"] = podUnderTest.Namespace
+		values["POD_NAME"] = podUnderTest.Name
+		for i := 0; i < podUnderTest.ContainerCount; i++ {
+			values["CONTAINER_NUM"] = i
+			tester, handlers := utils.NewGenericTesterAndValidate(relativeimagepullpolicyTestPath, environment.RelativeSchemaPath, values)
+			test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+			if err != nil {
+				t.Failf("Pod %s/%s could not be tested. Error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
+				return
+			}
+
+			test.RunWithCallbacks(nil, func() {
+				t.Printf("FAILURE: Pod %s/%s does not set imagePullPolicy to IfNotPresent", podUnderTest.Namespace, podUnderTest.Name)
+				failedPods = append(failedPods, podUnderTest)
+			}, func(err error) {
+				t.Printf("ERROR: Pod %s/%s, error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
+				failedPods = append(failedPods, podUnderTest)
+			})
+		}
+	}
+	if n := len(failedPods); n > 0 {
+		log.Debugf("Pods with incorrect image pull policy: %+v", failedPods)
+		t.Failf("%d pods have incorrect image pull policy.", n)
+	}
+}
@@ -0,0 +1,535 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package networking
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/nodeport"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/ping"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/podnodename"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/parallel"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+const (
+	commandportdeclared = "oc get pod %s -n %s -o json  | jq -r '.spec.containers[%d].ports'"
+	commandportlisten   = "ss -tulwnH"
+	ocCommandTimeOut    = time.Second * 10
+	indexprotocolname   = 0
+	indexport           = 4
+	defaultNumPings     = 5
+)
+
+type ipVersion string
+
+const (
+	IPv4 ipVersion = "IPv4"
+	IPv6 ipVersion = "IPv6"
+)
+
+type key struct {
+	port     int
+	protocol string
+}
+
+type Port []struct {
+	ContainerPort int    `json:"containerPort"`
+	Name          string `json:"name"`
+	Protocol      string `json:"protocol"`
+}
+
+// netTestContext this is a data structure describing a network test context for a given subnet (e.g. network attachment)
+// The test context defines a tester or test initiator, that is initiating the pings. It is selected randomly (first container in the list)
+// It also defines a list of destination ping targets corresponding to the other containers IPs on this subnet
+type netTestContext struct {
+	// testerContainerNodeOc session context to access the node running the container selected to initiate tests
+	testerContainerNodeOc *interactive.Oc
+	// testerSource is the container select to initiate the ping tests on this given network
+	testerSource containerIP
+	// ipDestTargets List of containers to be pinged by the testerSource on this given network
+	destTargets []containerIP
+}
+
+// containerIP holds a container identification and its IP for networking tests.
+type containerIP struct {
+	// ip address of the target container
+	ip string
+	// targetContainerIdentifier container identifier including namespace, pod name, container name, node name, and container UID
+	containerIdentifier *configsections.ContainerIdentifier
+}
+
+func (testContext netTestContext) String() string {
+	output := fmt.Sprintf("From initiating container: %s\n", testContext.testerSource.String())
+	if len(testContext.destTargets) == 0 {
+		output = "--> No target containers to test for this network" //nolint:goconst // this is only one time
+	}
+	for _, target := range testContext.destTargets {
+		output += fmt.Sprintf("--> To target container: %s\n", target.String())
+	}
+	return output
+}
+
+func (cip *containerIP) String() string {
+	return fmt.Sprintf("%s ( %s )",
+		cip.ip,
+		cip.containerIdentifier.String(),
+	)
+}
+
+func printNetTestContextMap(netsUnderTest map[string]netTestContext) string {
+	var output string
+	if len(netsUnderTest) == 0 {
+		output = "No networks to test.\n" //nolint:goconst // this is only one time
+	}
+	for netName, netUnderTest := range netsUnderTest {
+		output += fmt.Sprintf("***Test for Network attachment: %s\n", netName)
+		output += fmt.Sprintf("%s\n", netUnderTest.String())
+	}
+	return output
+}
+
+func init() {
+	checks.Register(checks.Check{ID: identifiers.TestICMPv4ConnectivityIdentifier, Run: func(t *checks.T) {
+		testDefaultNetworkConnectivity(t, defaultNumPings, IPv4)
+	}})
+	checks.Register(checks.Check{ID: identifiers.TestICMPv6ConnectivityIdentifier, Run: func(t *checks.T) {
+		testDefaultNetworkConnectivity(t, defaultNumPings, IPv6)
+	}})
+	checks.Register(checks.Check{ID: identifiers.TestICMPv4ConnectivityMultusIdentifier, Run: func(t *checks.T) {
+		testMultusNetworkConnectivity(t, defaultNumPings, IPv4)
+	}})
+	checks.Register(checks.Check{ID: identifiers.TestICMPv6ConnectivityMultusIdentifier, Run: func(t *checks.T) {
+		testMultusNetworkConnectivity(t, defaultNumPings, IPv6)
+	}})
+	checks.Register(checks.Check{ID: identifiers.TestServicesDoNotUseNodeportsIdentifier, Run: testNodePort})
+	checks.Register(checks.Check{ID: identifiers.TestUndeclaredContainerPortsUsage, Run: testListenAndDeclared})
+}
+
+// processContainerIpsPerNet takes a container ip addresses for a given network attachment's and uses it as a test target.
+// The first container in the loop is selected as the test initiator. the Oc context of the container is used to initiate the pings
+func processContainerIpsPerNet(t *checks.T, containerID *configsections.ContainerIdentifier,
+	netKey string,
+	ipAddresses []string,
+	netsUnderTest map[string]netTestContext,
+	containerNodeOc *interactive.Oc,
+	aIPVersion ipVersion) {
+	ipAddressesFiltered := FilterIPListPerVersion(ipAddresses, aIPVersion)
+	if len(ipAddressesFiltered) == 0 {
+		// if no multus addresses found, skip this container
+		t.Printf("Skipping container %s, Network %s because no multus IPs are present", containerID.PodName, netKey)
+		return
+	}
+	// Create an entry at "key" if it is not present
+	if _, ok := netsUnderTest[netKey]; !ok {
+		netsUnderTest[netKey] = netTestContext{}
+	}
+	// get a copy of the content
+	entry := netsUnderTest[netKey]
+	// Then modify the copy
+	firstIPIndex := 0
+	if entry.testerContainerNodeOc == nil {
+		t.Printf("Pod %s, container %s selected to initiate ping tests", containerID.PodName, containerID.ContainerName)
+		entry.testerSource.containerIdentifier = containerID
+		entry.testerContainerNodeOc = containerNodeOc
+		// if multiple interfaces are present for this network on this container/pod, pick the first one as the tester source ip
+		entry.testerSource.ip = ipAddressesFiltered[firstIPIndex]
+		// do no include tester's IP in the list of destination IPs to ping
+		firstIPIndex++
+	}
+
+	for _, aIP := range ipAddressesFiltered[firstIPIndex:] {
+		ipDestEntry := containerIP{}
+		ipDestEntry.containerIdentifier = containerID
+		ipDestEntry.ip = aIP
+		entry.destTargets = append(entry.destTargets, ipDestEntry)
+	}
+
+	// Then reassign map entry
+	netsUnderTest[netKey] = entry
+}
+
+func FilterIPListPerVersion(ipList []string, aIPVersion ipVersion) []string {
+	var filteredIPList []string
+	for _, aIP := range ipList {
+		if ver, _ := getIPVersion(aIP); aIPVersion == ver {
+			filteredIPList = append(filteredIPList, aIP)
+		}
+	}
+	return filteredIPList
+}
+
+func getIPVersion(aIP string) (ipVersion, error) {
+	ip := net.ParseIP(aIP)
+	if ip == nil {
+		return "", fmt.Errorf("%s is Not an IPv4 or an IPv6", aIP)
+	}
+	if ip.To4() != nil {
+		return IPv4, nil
+	}
+	return IPv6, nil
+}
+
+// runNetworkingTests takes a map netTestContext, e.g. one context per network attachment
+// and runs pings test with it. Returns a network name to a slice of bad target IPs map, or false when the test is
+// skipped as there is no network to test.
+func runNetworkingTests(t *checks.T, netsUnderTest map[string]netTestContext, count int, aIPVersion ipVersion) (map[string][]string, bool) {
+	t.Printf("%s", printNetTestContextMap(netsUnderTest))
+	log.Debugf("%s", printNetTestContextMap(netsUnderTest))
+	if len(netsUnderTest) == 0 {
+		t.Skipf("There are no %s networks to test, skipping test", aIPVersion)
+		return nil, false
+	}
+	// pings lists the ping tests of every network, run in parallel below
+	type pingTest struct {
+		netName    string
+		netContext netTestContext
+		destIP     containerIP
+	}
+	var pings []pingTest
+	var names []string
+
+	// if no network can be tested, then we need to skip the test entirely.
+	// If at least one network can be tested (e.g. > 2 IPs/ interfaces present), then we do not skip the test
+	atLeastOneNetworkTested := false
+	netNames := make([]string, 0, len(netsUnderTest))
+	for netName := range netsUnderTest {
+		netNames = append(netNames, netName)
+	}
+	sort.Strings(netNames)
+	for _, netName := range netNames {
+		netUnderTest := netsUnderTest[netName]
+		if len(netUnderTest.destTargets) == 0 {
+			log.Warnf("There are no containers to ping for %s network %s. A minimum of 2 containers is needed to run a ping test (a source and a destination) Skipping test", aIPVersion, netName)
+			t.Printf("There are no containers to ping for %s network %s. Skip testing this network", aIPVersion, netName)
+			continue
+		}
+		atLeastOneNetworkTested = true
+		log.Debugf("%s Ping tests on network %s. Number of target IPs: %d", aIPVersion, netName, len(netUnderTest.destTargets))
+		for _, aDestIP := range netUnderTest.destTargets {
+			pings = append(pings, pingTest{netName: netName, netContext: netUnderTest, destIP: aDestIP})
+			names = append(names, fmt.Sprintf("%s/%s", netName, aDestIP.ip))
+		}
+	}
+	if !atLeastOneNetworkTested {
+		t.Skipf("There are no network to test for any %s networks, skipping test", aIPVersion)
+		return nil, false
+	}
+
+	report := t.Runner().Run(names, func(w *parallel.Worker, task *parallel.Task) {
+		aPing := pings[task.Index]
+		log.Debugf("a %s Ping is issued from %s(%s) %s to %s(%s) %s",
+			aIPVersion,
+			aPing.netContext.testerSource.containerIdentifier.PodName,
+			aPing.netContext.testerSource.containerIdentifier.ContainerName,
+			aPing.netContext.testerSource.ip, aPing.destIP.containerIdentifier.PodName,
+			aPing.destIP.containerIdentifier.ContainerName,
+			aPing.destIP.ip)
+		testPing(w, task, aPing.netContext.testerContainerNodeOc, aPing.netContext.testerSource.containerIdentifier, aPing.destIP, count)
+	})
+
+	// maps a net name to a list of failed destination IPs
+	badNets := map[string][]string{}
+	for _, task := range report.Tasks {
+		if task.Failed() || task.Errored() {
+			aPing := pings[task.Index]
+			badNets[aPing.netName] = append(badNets[aPing.netName], aPing.destIP.ip)
+		}
+	}
+	return badNets, true
+}
+func testDefaultNetworkConnectivity(t *checks.T, count int, aIPVersion ipVersion) {
+	env := t.Env()
+	netsUnderTest := make(map[string]netTestContext)
+	for _, pod := range env.PodsUnderTest {
+		// The first container is used to get the network namespace
+		aContainerInPod := pod.ContainerList[0]
+		if _, ok := env.ContainersToExcludeFromConnectivityTests[aContainerInPod.ContainerIdentifier]; ok {
+			t.Printf("Skipping pod %s because it is excluded from all connectivity tests", pod.Name)
+			continue
+		}
+		netKey := "default" //nolint:goconst // only used once
+		defaultIPAddress := pod.DefaultNetworkIPAddresses
+		nodeOc := getNodeOc(t, aContainerInPod.NodeName)
+		processContainerIpsPerNet(t, &aContainerInPod.ContainerIdentifier, netKey, defaultIPAddress, netsUnderTest, nodeOc, aIPVersion)
+	}
+	badNets, tested := runNetworkingTests(t, netsUnderTest, count, aIPVersion)
+	if !tested {
+		return
+	}
+
+	if n := len(badNets); n > 0 {
+		log.Warnf("Failed nets: %+v", badNets)
+		t.Failf("%d nets failed the default network %s ping test.", n, aIPVersion)
+	}
+}
+
+func testMultusNetworkConnectivity(t *checks.T, count int, aIPVersion ipVersion) {
+	env := t.Env()
+	netsUnderTest := make(map[string]netTestContext)
+	for _, pod := range env.PodsUnderTest {
+		// The first container is used to get the network namespace
+		aContainerInPod := pod.ContainerList[0]
+		if _, ok := env.ContainersToExcludeFromConnectivityTests[aContainerInPod.ContainerIdentifier]; ok {
+			t.Printf("Skipping pod %s because it is excluded from all connectivity tests", pod.Name)
+			continue
+		}
+		if _, ok := env.ContainersToExcludeFromMultusConnectivityTests[aContainerInPod.ContainerIdentifier]; ok {
+			t.Printf("Skipping pod %s because it is excluded from multus connectivity tests only", pod.Name)
+			continue
+		}
+		for netKey, multusIPAddress := range pod.MultusIPAddressesPerNet {
+			nodeOc := getNodeOc(t, aContainerInPod.NodeName)
+			processContainerIpsPerNet(t, &aContainerInPod.ContainerIdentifier, netKey, multusIPAddress, netsUnderTest, nodeOc, aIPVersion)
+		}
+	}
+	badNets, tested := runNetworkingTests(t, netsUnderTest, count, aIPVersion)
+	if !tested {
+		return
+	}
+
+	if n := len(badNets); n > 0 {
+		log.Warnf("Failed nets: %+v", badNets)
+		t.Failf("%d nets failed the multus %s ping test.", n, aIPVersion)
+	}
+}
+
+// getNodeOc returns the session to the debug pod of the node nodeName, and ends the check when there is none.
+func getNodeOc(t *checks.T, nodeName string) *interactive.Oc {
+	node := t.Env().NodesUnderTest[nodeName]
+	if node == nil {
+		t.Fatalf("Node %s is not under test.", nodeName)
+	}
+	nodeOc := node.DebugContainer.GetOc()
+	if nodeOc == nil {
+		t.Fatalf("No session to the debug pod of node %s.", nodeName)
+	}
+	return nodeOc
+}
+
+// Test that a container can ping a target IP address.  The ping is sent from the worker session to the node of the
+// source container, the result is recorded in task.
+func testPing(w *parallel.Worker, task *parallel.Task, initiatingPodNodeOc *interactive.Oc, sourceContainerID *configsections.ContainerIdentifier, targetContainerIP containerIP, count int) {
+	log.Infof("Sending ICMP traffic(%s to %s)", initiatingPodNodeOc.GetPodName(), targetContainerIP.ip)
+	sourcePodName := initiatingPodNodeOc.GetPodName()
+	targetPodName := targetContainerIP.containerIdentifier.PodName
+
+	nodeOc, err := w.Oc(initiatingPodNodeOc)
+	if err != nil {
+		task.Errorf("ERROR: Ping test from pod %s to pod %s (ip: %s) failed. Error: %v",
+			sourcePodName, targetPodName, targetContainerIP.ip, err)
+		return
+	}
+	containerPID := utils.GetContainerPID(sourceContainerID.NodeName, nodeOc, sourceContainerID.ContainerUID, sourceContainerID.ContainerRuntime)
+	pingTester := ping.NewPingNsenter(environment.DefaultTimeout, containerPID, targetContainerIP.ip, count)
+	test, err := tnf.NewTest(nodeOc.GetExpecter(), pingTester, []reel.Handler{pingTester}, nodeOc.GetErrorChannel())
+	if err != nil {
+		task.Errorf("ERROR: Ping test from pod %s to pod %s (ip: %s) failed. Error: %v",
+			sourcePodName, targetPodName, targetContainerIP.ip, err)
+		return
+	}
+
+	test.RunWithCallbacks(func() {
+		transmitted, received, errors := pingTester.GetStats()
+		if received == transmitted && errors == 0 {
+			log.Infof("Ping test from pod %s to pod %s (ip %s) succeeded. Tx/Rx/Err: %d/%d/%d",
+				sourcePodName, targetPodName, targetContainerIP.ip, transmitted, received, errors)
+		} else {
+			task.Failf("Ping test from pod %s to pod %s (ip: %s) failed. Tx/Rx/Err: %d/%d/%d",
+				sourcePodName, targetPodName, targetContainerIP.ip, transmitted, received, errors)
+		}
+	}, func() {
+		task.Failf("FAILURE: Ping test from pod %s to pod %s (ip: %s) failed.",
+			sourcePodName, targetPodName, targetContainerIP.ip)
+	}, func(err error) {
+		task.Errorf("ERROR: Ping test from pod %s to pod %s (ip: %s) failed. Error: %v",
+			sourcePodName, targetPodName, targetContainerIP.ip, err)
+		if reel.IsTimeout(err) {
+			w.CloseOc(nodeOc)
+		}
+	})
+}
+
+func testNodePort(t *checks.T) {
+	badNamespaces := []string{}
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Services could not be tested. Error: %v", err)
+		return
+	}
+	for _, ns := range t.Env().NameSpacesUnderTest {
+		log.Debugf("Testing services in namespace %s", ns)
+		tester := nodeport.NewNodePort(environment.DefaultTimeout, ns)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Services of namespace %s could not be tested. Error: %v", ns, err)
+			return
+		}
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("Namespace %s has one or more nodePort/s", ns)
+			badNamespaces = append(badNamespaces, ns)
+		}, func(err error) {
+			t.Printf("nodePort test on namespace %s failed. Error: %v", ns, err)
+			badNamespaces = append(badNamespaces, ns)
+		})
+	}
+
+	if n := len(badNamespaces); n > 0 {
+		log.Warnf("Failed namespaces: %+v", badNamespaces)
+		t.Failf("%d namespaces have nodePort/s.", n)
+	}
+}
+
+func parseVariables(res string, declaredPorts map[key]bool) error {
+	var p Port
+	err := json.Unmarshal([]byte(res), &p)
+	if err != nil {
+		return err
+	}
+
+	for element := range p {
+		var k key
+		k.port = p[element].ContainerPort
+		k.protocol = p[element].Protocol
+		declaredPorts[k] = true
+	}
+	return nil
+}
+func declaredPortList(container int, podName, podNamespace string, declaredPorts map[key]bool) error {
+	ocCommandToExecute := fmt.Sprintf(commandportdeclared, podName, podNamespace, container)
+	res, err := utils.ExecuteLocalCommand(ocCommandToExecute, ocCommandTimeOut)
+	if err != nil {
+		return err
+	}
+	err = parseVariables(res, declaredPorts)
+	return err
+}
+
+func listeningPortList(commandlisten []string, nodeOc *interactive.Context, listeningPorts map[key]bool) error {
+	var k key
+	listeningPortCommand := strings.Join(commandlisten, " ")
+	res, err := utils.ExecuteCommand(listeningPortCommand, ocCommandTimeOut, nodeOc)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(res, "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if !strings.Contains(line, "LISTEN") {
+			continue
+		}
+		if indexprotocolname > len(fields) || indexport > len(fields) {
+			return err
+		}
+		s := strings.Split(fields[indexport], ":")
+		if len(s) == 0 {
+			log.Errorf("error decoding port number for line: %s", line)
+			continue
+		}
+		p, _ := strconv.Atoi(strings.ReplaceAll(s[len(s)-1], "\"", ""))
+		k.port = p
+		k.protocol = strings.ToUpper(fields[indexprotocolname])
+		k.protocol = strings.ReplaceAll(k.protocol, "\"", "")
+		k.protocol = strings.ReplaceAll(k.protocol, ":", "")
+		listeningPorts[k] = true
+	}
+	return nil
+}
+
+func checkIfListenIsDeclared(listeningPorts, declaredPorts map[key]bool) map[key]bool {
+	res := make(map[key]bool)
+	if len(listeningPorts) == 0 {
+		return res
+	}
+	for k := range listeningPorts {
+		_, ok := declaredPorts[k]
+		if !ok {
+			res[k] = listeningPorts[k]
+		}
+	}
+	return res
+}
+
+func testListenAndDeclared(t *checks.T) {
+	env := t.Env()
+	var skippedPods []configsections.Pod
+	var failedPods []configsections.Pod
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Pods could not be tested. Error: %v", err)
+		return
+	}
+OUTER:
+	for _, podUnderTest := range env.PodsUnderTest {
+		declaredPorts := make(map[key]bool)
+		listeningPorts := make(map[key]bool)
+		for i := 0; i < podUnderTest.ContainerCount; i++ {
+			err := declaredPortList(i, podUnderTest.Name, podUnderTest.Namespace, declaredPorts)
+			if err != nil {
+				t.Printf("Failed to get declared port for container %d due to %v, skipping pod %s", i, err, podUnderTest.Name)
+				skippedPods = append(skippedPods, *podUnderTest)
+				continue OUTER
+			}
+		}
+
+		nodeName := podnodename.NewPodNodeName(environment.DefaultTimeout, podUnderTest.Name, podUnderTest.Namespace)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), nodeName, []reel.Handler{nodeName}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Pod %s (ns %s) could not be tested. Error: %v", podUnderTest.Name, podUnderTest.Namespace, err)
+			return
+		}
+		test.RunAndValidate()
+		nodeOc := getNodeOc(t, nodeName.GetNodeName())
+		x := podUnderTest.ContainerList[0]
+		containerPID := utils.GetContainerPID(nodeName.GetNodeName(), nodeOc, x.ContainerUID, x.ContainerRuntime)
+
+		commandlisten := []string{utils.AddNsenterPrefix(containerPID), commandportlisten}
+
+		err = listeningPortList(commandlisten, nodeOc.Context, listeningPorts)
+		if err != nil {
+			t.Printf("Failed to get listening port for pod name %s in pod namespace %s due to %v, skipping this pod", podUnderTest.Name, podUnderTest.Namespace, err)
+			skippedPods = append(skippedPods, *podUnderTest)
+			continue
+		}
+		// compare between declaredPort,listeningPort
+		undeclaredPorts := checkIfListenIsDeclared(listeningPorts, declaredPorts)
+		for k := range undeclaredPorts {
+			t.Printf("pod %s ns %s is listening on port %d protocol %s, but that port was not declared in any container spec.", podUnderTest.Name, podUnderTest.Namespace, k.port, k.protocol)
+		}
+		if len(undeclaredPorts) != 0 {
+			failedPods = append(failedPods, *podUnderTest)
+		}
+	}
+
+	if nf, ns := len(failedPods), len(skippedPods); nf > 0 || ns > 0 {
+		t.Failf("Found %d pods with listening ports not declared and Skipped %d pods due to unexpected error", nf, ns)
+	}
+}
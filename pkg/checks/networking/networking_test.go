@@ -95,12 +95,12 @@ func TestDeclaredPortList(t *testing.T) {
 		},
 	}
 
-	origFunc := utils.ExecuteCommand
+	origFunc := utils.ExecuteLocalCommand
 	defer func() {
-		utils.ExecuteCommand = origFunc
+		utils.ExecuteLocalCommand = origFunc
 	}()
 	for _, tc := range testCases {
-		utils.ExecuteCommand = func(command string, timeout time.Duration, context *interactive.Context) (string, error) {
+		utils.ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
 			output, err := os.ReadFile(tc.jsonFileName)
 			return string(output), err
 		}
@@ -0,0 +1,150 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package observability
+
+import (
+	"path"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/parallel"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+var (
+	// loggingTestPath is the file location of the logging.json test case relative to the project root.
+	loggingTestPath = path.Join("pkg", "tnf", "handlers", "logging", "logging.json")
+	// relativeLoggingTestPath is the relative path to the logging.json test case.
+	relativeLoggingTestPath = path.Join(environment.PathRelativeToRoot, loggingTestPath)
+
+	// crdTestPath is the file location of the CRD status existence test case relative to the project root.
+	crdTestPath = path.Join("pkg", "tnf", "handlers", "crdstatusexistence", "crdstatusexistence.json")
+	// relativeCrdTestPath is the relative path to the crdstatusexistence.json test case.
+	relativeCrdTestPath = path.Join(environment.PathRelativeToRoot, crdTestPath)
+	// testCrdsTimeout is the timeout in seconds for the CRDs TC.
+	testCrdsTimeout = 10 * time.Second
+)
+
+func init() {
+	checks.Register(checks.Check{ID: identifiers.TestLoggingIdentifier, Run: testLogging})
+	checks.Register(checks.Check{ID: identifiers.TestCrdsStatusSubresourceIdentifier, Run: testCrds})
+}
+
+// testLogging checks that every container under test emits at least one line of log to stderr/stdout.
+func testLogging(t *checks.T) {
+	cuts := t.Env().GetSortedContainersUnderTest()
+	names := make([]string, len(cuts))
+	for i, cut := range cuts {
+		names[i] = cut.ContainerIdentifier.String()
+	}
+	report := t.Runner().Run(names, func(w *parallel.Worker, task *parallel.Task) {
+		cutIdentifier := &cuts[task.Index].ContainerIdentifier
+		nonCompliant := func(reason, details string) {
+			task.ReportNonCompliant(tnf.NonCompliantObject{Kind: tnf.KindContainer, Namespace: cutIdentifier.Namespace,
+				Name: cutIdentifier.PodName, Container: cutIdentifier.ContainerName, Reason: reason, Details: details})
+		}
+		log.Debugf("Test container: %+v. should emit at least one line of log to stderr/stdout", cutIdentifier)
+
+		context, err := w.LocalShell()
+		if err != nil {
+			task.Errorf("ERROR: Container: %s (Pod %s ns %s) could not be tested. Error: %v",
+				cutIdentifier.ContainerName, cutIdentifier.PodName, cutIdentifier.Namespace, err)
+			nonCompliant(tnf.ReasonCheckError, err.Error())
+			return
+		}
+
+		values := make(map[string]interface{})
+		values["POD_NAMESPACE"] = cutIdentifier.Namespace
+		values["POD_NAME"] = cutIdentifier.PodName
+		values["CONTAINER_NAME"] = cutIdentifier.ContainerName
+		tester, handlers, err := utils.NewGenericTester(relativeLoggingTestPath, environment.RelativeSchemaPath, values)
+		if err != nil {
+			task.Errorf("ERROR: Container: %s (Pod %s ns %s) could not be tested. Error: %v",
+				cutIdentifier.ContainerName, cutIdentifier.PodName, cutIdentifier.Namespace, err)
+			nonCompliant(tnf.ReasonCheckError, err.Error())
+			return
+		}
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+		if err != nil {
+			task.Errorf("ERROR: Container: %s (Pod %s ns %s) could not be tested. Error: %v",
+				cutIdentifier.ContainerName, cutIdentifier.PodName, cutIdentifier.Namespace, err)
+			nonCompliant(tnf.ReasonCheckError, err.Error())
+			return
+		}
+
+		test.RunWithCallbacks(nil, func() {
+			task.Failf("FAILURE: Container: %s (Pod %s ns %s) does not have any line of log to stderr/stdout",
+				cutIdentifier.ContainerName, cutIdentifier.PodName, cutIdentifier.Namespace)
+			nonCompliant("NoLogOutput", "")
+		}, func(err error) {
+			task.Errorf("ERROR: Container: %s (Pod %s ns %s) does not have any line of log to stderr/stdout. Error: %v",
+				cutIdentifier.ContainerName, cutIdentifier.PodName, cutIdentifier.Namespace, err)
+			nonCompliant(tnf.ReasonCheckError, err.Error())
+		})
+	})
+
+	failedCuts := append(report.Failed(), report.Errored()...)
+	if n := len(failedCuts); n > 0 {
+		log.Debugf("Containers without logging: %+v", failedCuts)
+		t.Failf("%d containers don't have any log to stdout/stderr.", n)
+	}
+}
+
+// testCrds checks that every CRD under test has a status subresource.
+func testCrds(t *checks.T) {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("CRDs could not be tested. Error: %v", err)
+		return
+	}
+	failedCrds := []string{}
+	for _, crdName := range t.Env().CrdNames {
+		log.Debugf("Testing CRD %s", crdName)
+
+		values := make(map[string]interface{})
+		values["CRD_NAME"] = crdName
+		values["TIMEOUT"] = testCrdsTimeout.Nanoseconds()
+
+		tester, handlers, err := utils.NewGenericTester(relativeCrdTestPath, environment.RelativeSchemaPath, values)
+		if err != nil {
+			t.Failf("CRD %s could not be tested. Error: %v", crdName, err)
+			return
+		}
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("CRD %s could not be tested. Error: %v", crdName, err)
+			return
+		}
+
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("FAILURE: CRD %s does not have a status subresource.", crdName)
+			failedCrds = append(failedCrds, crdName)
+		}, func(err error) {
+			t.Printf("FAILURE: CRD %s does not have a status subresource.", crdName)
+			failedCrds = append(failedCrds, crdName)
+		})
+	}
+
+	if n := len(failedCrds); n > 0 {
+		log.Debugf("CRDs without status subresource: %+v", failedCrds)
+		t.Failf("%d CRDs don't have status subresource", n)
+	}
+}
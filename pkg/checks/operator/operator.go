@@ -0,0 +1,183 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package operator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/operator"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+	"github.com/test-network-function/test-network-function/pkg/tnf/testcases"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+var (
+	// checkSubscriptionTestPath is the file location of the check-subscription.json test case relative to the project root.
+	checkSubscriptionTestPath = path.Join("pkg", "tnf", "handlers", "checksubscription", "check-subscription.json")
+	// relativecheckSubscriptionTestPath is the relative path to the check-subscription.json test case.
+	relativecheckSubscriptionTestPath = path.Join(environment.PathRelativeToRoot, checkSubscriptionTestPath)
+)
+
+func init() {
+	checks.Register(checks.Check{ID: identifiers.TestOperatorIsInstalledViaOLMIdentifier, Run: testOperatorsAreInstalledViaOLM})
+	checks.Register(checks.Check{ID: identifiers.TestOperatorInstallStatusIdentifier, Run: testOperatorsInstallStatus})
+}
+
+// ConfiguredTestCases returns the operator test cases configured in testconfigure.yml, which are not skipped.
+func ConfiguredTestCases() ([]testcases.BaseTestCase, error) {
+	testCases := []testcases.BaseTestCase{}
+	for _, testType := range testcases.GetConfiguredOperatorTests() {
+		testFile, err := testcases.LoadConfiguredTestFile(environment.ConfiguredTestFile)
+		if err != nil {
+			return nil, err
+		}
+		testConfigure := testcases.ContainsConfiguredTest(testFile.OperatorTest, testType)
+		renderedTestCase, err := testConfigure.RenderTestCaseSpec(testcases.Operator, testType)
+		if err != nil {
+			return nil, err
+		}
+		for _, testCase := range renderedTestCase.TestCase {
+			if !testCase.SkipTest {
+				testCases = append(testCases, testCase)
+			}
+		}
+	}
+	return testCases, nil
+}
+
+// InstallStatusCheck returns the check running testCase, one of the ConfiguredTestCases, on the operators under test.
+// The Ginkgo suite runs it in a spec of its own.
+func InstallStatusCheck(testCase testcases.BaseTestCase) *checks.Check { //nolint:gocritic // Copied like in a loop
+	return &checks.Check{ID: identifiers.TestOperatorInstallStatusIdentifier, Run: func(t *checks.T) {
+		if hasOperators(t) {
+			testOperatorsInstallStatusCase(t, testCase)
+		}
+	}}
+}
+
+// hasOperators skips the check when there is no operator under test.
+func hasOperators(t *checks.T) bool {
+	if len(t.Env().OperatorsUnderTest) == 0 {
+		t.Skipf("No Operator found.")
+		return false
+	}
+	return true
+}
+
+// testOperatorsAreInstalledViaOLM ensures all configured operators have a proper OLM subscription.
+func testOperatorsAreInstalledViaOLM(t *checks.T) {
+	if !hasOperators(t) {
+		return
+	}
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Operators could not be tested. Error: %v", err)
+		return
+	}
+	badOperators := []configsections.Operator{}
+	for _, operatorInTest := range t.Env().OperatorsUnderTest {
+		log.Debugf("%s in namespace %s Should have a valid subscription", operatorInTest.SubscriptionName, operatorInTest.Namespace)
+		values := make(map[string]interface{})
+		values["SUBSCRIPTION_NAME"] = operatorInTest.SubscriptionName
+		values["SUBSCRIPTION_NAMESPACE"] = operatorInTest.Namespace
+		tester, handlers, err := utils.NewGenericTester(relativecheckSubscriptionTestPath, environment.RelativeSchemaPath, values)
+		if err != nil {
+			t.Failf("Operator %s could not be tested. Error: %v", operatorInTest.Name, err)
+			return
+		}
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Operator %s could not be tested. Error: %v", operatorInTest.Name, err)
+			return
+		}
+
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("Operator %s doesn't have a proper OLM subscription.", operatorInTest.Name)
+			badOperators = append(badOperators, *operatorInTest)
+		}, func(err error) {
+			t.Printf("Operator %s doesn't have a proper OLM subscription. Error: %v", operatorInTest.Name, err)
+			badOperators = append(badOperators, *operatorInTest)
+		})
+	}
+
+	if n := len(badOperators); n > 0 {
+		log.Warnf("Operators without proper OLM subscription: %+v", badOperators)
+		t.Failf("%d operators found without proper OLM subscription.", n)
+	}
+}
+
+// testOperatorsInstallStatus runs the ConfiguredTestCases on the operators under test.
+func testOperatorsInstallStatus(t *checks.T) {
+	if !hasOperators(t) {
+		return
+	}
+	testCases, err := ConfiguredTestCases()
+	if err != nil {
+		t.Failf("The operator test cases could not be loaded. Error: %v", err)
+		return
+	}
+	for _, testCase := range testCases {
+		testOperatorsInstallStatusCase(t, testCase)
+	}
+}
+
+//nolint:gocritic // ignore hugeParam error. Pointers to loop iterator vars are bad and `testCase` is likely to be such.
+func testOperatorsInstallStatusCase(t *checks.T, testCase testcases.BaseTestCase) {
+	context, err := t.LocalShell()
+	if err != nil {
+		t.Failf("Operators could not be tested. Error: %v", err)
+		return
+	}
+	badOperators := []configsections.Operator{}
+	for _, op := range t.Env().OperatorsUnderTest {
+		if testCase.ExpectedType == testcases.Function {
+			for _, val := range testCase.ExpectedStatus {
+				testCase.ExpectedStatusFn(op.Name, testcases.StatusFunctionType(val))
+			}
+		}
+		name := op.Name
+		args := []interface{}{name, op.Namespace}
+		cmdArgs := strings.Split(fmt.Sprintf(testCase.Command, args...), " ")
+		opInTest := operator.NewOperator(cmdArgs, name, op.Namespace, testCase.ExpectedStatus, testCase.ResultType, testCase.Action, environment.DefaultTimeout)
+		test, err := tnf.NewTestWithContext(t.Context(), context.GetExpecter(), opInTest, []reel.Handler{opInTest}, context.GetErrorChannel())
+		if err != nil {
+			t.Failf("Operator %s could not be tested. Error: %v", name, err)
+			return
+		}
+
+		test.RunWithCallbacks(nil, func() {
+			t.Printf("Operator %s failed TC: %s", name, testCase.Name)
+			badOperators = append(badOperators, *op)
+		}, func(err error) {
+			t.Printf("Operator %s failed TC: %s. Error: %v", name, testCase.Name, err)
+			badOperators = append(badOperators, *op)
+		})
+	}
+
+	if n := len(badOperators); n > 0 {
+		log.Warnf("Operators that failed TC %s: %+v", testCase.Name, badOperators)
+		t.Failf("%d operators failed TC %s", n, testCase.Name)
+	}
+}
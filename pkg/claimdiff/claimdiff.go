@@ -0,0 +1,391 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package claimdiff compares two claim files: the state of their tests, the versions, the configurations and the nodes.
+package claimdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+)
+
+const (
+	// ChangeAdded is the kind of a Change to a value which is only in the new claim.
+	ChangeAdded = "added"
+	// ChangeRemoved is the kind of a Change to a value which is only in the old claim.
+	ChangeRemoved = "removed"
+	// ChangeModified is the kind of a Change to a value which differs between the claims.
+	ChangeModified = "modified"
+)
+
+// StateChange is a test whose state differs between the claims.
+type StateChange struct {
+	Test     string `json:"test"`
+	OldState string `json:"oldState"`
+	NewState string `json:"newState"`
+	// Regression is true when the test fails in the new claim but did not in the old one.
+	Regression bool `json:"regression"`
+}
+
+// Change is a value which differs between the claims.
+type Change struct {
+	// Path locates the value, e.g. testTarget.podsUnderTest[tnf/test-0].containercount.
+	Path     string      `json:"path"`
+	Kind     string      `json:"kind"`
+	OldValue interface{} `json:"oldValue,omitempty"`
+	NewValue interface{} `json:"newValue,omitempty"`
+}
+
+// Diff is the difference between two claims.
+type Diff struct {
+	StateChanges   []StateChange `json:"stateChanges"`
+	NewTests       []string      `json:"newTests"`
+	RemovedTests   []string      `json:"removedTests"`
+	Versions       []Change      `json:"versions"`
+	Configurations []Change      `json:"configurations"`
+	Nodes          []Change      `json:"nodes"`
+}
+
+// Compare returns the difference between oldClaim and newClaim.  The tests are matched by the URL of their identifier.
+func Compare(oldClaim, newClaim *claim.Claim) (*Diff, error) {
+	oldStates, err := testStates(oldClaim)
+	if err != nil {
+		return nil, err
+	}
+	newStates, err := testStates(newClaim)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{
+		StateChanges:   []StateChange{},
+		NewTests:       []string{},
+		RemovedTests:   []string{},
+		Configurations: CompareValues("", oldClaim.Configurations, newClaim.Configurations),
+		Nodes:          CompareValues("", oldClaim.Nodes, newClaim.Nodes),
+	}
+	diff.Versions, err = CompareVersions(oldClaim.Versions, newClaim.Versions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, test := range sortedTests(newStates) {
+		oldState, ok := oldStates[test]
+		if !ok {
+			diff.NewTests = append(diff.NewTests, test)
+			continue
+		}
+		newState := newStates[test]
+		if oldState != newState {
+			diff.StateChanges = append(diff.StateChanges, StateChange{
+				Test:       test,
+				OldState:   oldState,
+				NewState:   newState,
+				Regression: claimutil.IsFailure(newState) && !claimutil.IsFailure(oldState),
+			})
+		}
+	}
+	for _, test := range sortedTests(oldStates) {
+		if _, ok := newStates[test]; !ok {
+			diff.RemovedTests = append(diff.RemovedTests, test)
+		}
+	}
+	return diff, nil
+}
+
+// Regressions returns the tests which fail in the new claim but did not in the old one.
+func (d *Diff) Regressions() []StateChange {
+	regressions := []StateChange{}
+	for _, change := range d.StateChanges {
+		if change.Regression {
+			regressions = append(regressions, change)
+		}
+	}
+	return regressions
+}
+
+// IsEmpty returns true when the claims do not differ.
+func (d *Diff) IsEmpty() bool {
+	return len(d.StateChanges) == 0 && len(d.NewTests) == 0 && len(d.RemovedTests) == 0 && len(d.Versions) == 0 &&
+		len(d.Configurations) == 0 && len(d.Nodes) == 0
+}
+
+// WriteJSON writes the diff as indented JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+// WriteText writes the diff in a human readable form.  The values of the added and removed objects are left out, as
+// they may be large, e.g. the hardware information of a node.
+func (d *Diff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if d.IsEmpty() {
+		b.WriteString("The claims do not differ.\n")
+	}
+	if len(d.StateChanges) > 0 {
+		fmt.Fprintf(&b, "Tests which changed state (%d, %d regressions):\n", len(d.StateChanges), len(d.Regressions()))
+		for _, change := range d.StateChanges {
+			marker := " "
+			if change.Regression {
+				marker = "!"
+			}
+			fmt.Fprintf(&b, " %s %s: %s -> %s\n", marker, change.Test, change.OldState, change.NewState)
+		}
+	}
+	writeTests(&b, "New tests", d.NewTests)
+	writeTests(&b, "Removed tests", d.RemovedTests)
+	writeChanges(&b, "Versions", d.Versions)
+	writeChanges(&b, "Configurations", d.Configurations)
+	writeChanges(&b, "Nodes", d.Nodes)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeTests(b *strings.Builder, title string, tests []string) {
+	if len(tests) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s (%d):\n", title, len(tests))
+	for _, test := range tests {
+		fmt.Fprintf(b, "   %s\n", test)
+	}
+}
+
+func writeChanges(b *strings.Builder, title string, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s (%d changes):\n", title, len(changes))
+	for _, change := range changes {
+		switch change.Kind {
+		case ChangeAdded:
+			fmt.Fprintf(b, " + %s\n", change.Path)
+		case ChangeRemoved:
+			fmt.Fprintf(b, " - %s\n", change.Path)
+		default:
+			fmt.Fprintf(b, " ~ %s: %s -> %s\n", change.Path, formatValue(change.OldValue), formatValue(change.NewValue))
+		}
+	}
+}
+
+func formatValue(value interface{}) string {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(payload)
+}
+
+// testStates returns the aggregated state of every test of c, by test name.
+func testStates(c *claim.Claim) (map[string]string, error) {
+	results, err := claimutil.GetResults(c)
+	if err != nil {
+		return nil, err
+	}
+	states := map[string]string{}
+	for key, testResults := range results {
+		states[claimutil.TestName(key, testResults)] = claimutil.AggregateState(testResults)
+	}
+	return states, nil
+}
+
+func sortedTests(states map[string]string) []string {
+	tests := make([]string, 0, len(states))
+	for test := range states {
+		tests = append(tests, test)
+	}
+	sort.Strings(tests)
+	return tests
+}
+
+// CompareVersions returns the changes between two claim versions.
+func CompareVersions(oldVersions, newVersions *claim.Versions) ([]Change, error) {
+	oldValue, err := toGeneric(oldVersions)
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := toGeneric(newVersions)
+	if err != nil {
+		return nil, err
+	}
+	return CompareValues("", oldValue, newValue), nil
+}
+
+// toGeneric converts value to its generic JSON representation, e.g. a map[string]interface{} for a struct.
+func toGeneric(value interface{}) (interface{}, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	err = json.Unmarshal(payload, &generic)
+	return generic, err
+}
+
+// CompareValues returns the changes between two generic JSON values, down to the leaves.  The elements of lists of
+// objects are matched by identity (see elementKeys), so that a discovered pod which is added in the middle of a list
+// reports a single change.
+func CompareValues(path string, oldValue, newValue interface{}) []Change {
+	changes := []Change{}
+	switch {
+	case oldValue == nil && newValue == nil:
+	case oldValue == nil:
+		changes = append(changes, Change{Path: path, Kind: ChangeAdded, NewValue: newValue})
+	case newValue == nil:
+		changes = append(changes, Change{Path: path, Kind: ChangeRemoved, OldValue: oldValue})
+	default:
+		oldMap, oldIsMap := asMap(oldValue)
+		newMap, newIsMap := asMap(newValue)
+		if oldIsMap && newIsMap {
+			return compareMaps(path, oldMap, newMap)
+		}
+		if oldList, ok := oldValue.([]interface{}); ok {
+			if newList, ok := newValue.([]interface{}); ok {
+				return compareLists(path, oldList, newList)
+			}
+		}
+		if formatValue(oldValue) != formatValue(newValue) {
+			changes = append(changes, Change{Path: path, Kind: ChangeModified, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes
+}
+
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, true
+	}
+	// Configurations and Nodes may hold typed values when the claim is built in memory rather than read from a file.
+	if _, ok := value.([]interface{}); ok {
+		return nil, false
+	}
+	generic, err := toGeneric(value)
+	if err != nil {
+		return nil, false
+	}
+	m, ok := generic.(map[string]interface{})
+	return m, ok
+}
+
+func compareMaps(path string, oldMap, newMap map[string]interface{}) []Change {
+	keys := map[string]bool{}
+	for key := range oldMap {
+		keys[key] = true
+	}
+	for key := range newMap {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	changes := []Change{}
+	for _, key := range sortedKeys {
+		changes = append(changes, CompareValues(childPath(path, key), oldMap[key], newMap[key])...)
+	}
+	return changes
+}
+
+func compareLists(path string, oldList, newList []interface{}) []Change {
+	oldKeys, oldOk := elementKeys(oldList)
+	newKeys, newOk := elementKeys(newList)
+	if !oldOk || !newOk {
+		changes := []Change{}
+		for i := 0; i < len(oldList) || i < len(newList); i++ {
+			var oldElement, newElement interface{}
+			if i < len(oldList) {
+				oldElement = oldList[i]
+			}
+			if i < len(newList) {
+				newElement = newList[i]
+			}
+			changes = append(changes, CompareValues(fmt.Sprintf("%s[%d]", path, i), oldElement, newElement)...)
+		}
+		return changes
+	}
+
+	oldElements := map[string]interface{}{}
+	for i, key := range oldKeys {
+		oldElements[key] = oldList[i]
+	}
+	newElements := map[string]interface{}{}
+	for i, key := range newKeys {
+		newElements[key] = newList[i]
+	}
+	changes := []Change{}
+	for i, key := range oldKeys {
+		changes = append(changes, CompareValues(fmt.Sprintf("%s[%s]", path, key), oldList[i], newElements[key])...)
+	}
+	for i, key := range newKeys {
+		if _, ok := oldElements[key]; !ok {
+			changes = append(changes, CompareValues(fmt.Sprintf("%s[%s]", path, key), nil, newList[i])...)
+		}
+	}
+	return changes
+}
+
+// elementKeys returns the identity of every element of list, e.g. namespace/name for a pod or an operator.  It returns
+// false when an element has no identity or two elements share one, in which case the list is compared by index.
+func elementKeys(list []interface{}) ([]string, bool) {
+	keys := make([]string, 0, len(list))
+	seen := map[string]bool{}
+	for _, element := range list {
+		key := elementKey(element)
+		if key == "" || seen[key] {
+			return nil, false
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, true
+}
+
+func elementKey(element interface{}) string {
+	object, ok := element.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if identifier, ok := object["ContainerIdentifier"].(map[string]interface{}); ok {
+		object = identifier
+	}
+	var parts []string
+	for _, field := range []string{"namespace", "podName", "name", "containerName"} {
+		if value, ok := object[field].(string); ok && value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func childPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package claimdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+)
+
+func result(name, state string) []claim.Result {
+	return []claim.Result{{
+		State:  state,
+		TestID: &claim.Identifier{Url: "http://test-network-function.com/testcases/" + name, Version: "v1.0.0"},
+	}}
+}
+
+func parse(t *testing.T, payload string) interface{} {
+	var value interface{}
+	assert.Nil(t, json.Unmarshal([]byte(payload), &value))
+	return value
+}
+
+func testClaims(t *testing.T) (oldClaim, newClaim *claim.Claim) {
+	oldClaim = &claim.Claim{
+		Versions: &claim.Versions{Tnf: "v3.0.0", Ocp: "4.9.10"},
+		Results: map[string]interface{}{
+			"a": result("a", "passed"),
+			"b": result("b", "failed"),
+			"c": result("c", "skipped"),
+			"d": result("d", "passed"),
+		},
+		Configurations: map[string]interface{}{
+			"testTarget": parse(t, `{"podsUnderTest": [
+				{"namespace": "tnf", "name": "test-0", "containercount": 1},
+				{"namespace": "tnf", "name": "test-1", "containercount": 1}
+			]}`),
+		},
+		Nodes: map[string]interface{}{
+			"cniPlugins": parse(t, `{"worker-0.example.com": [{"name": "multus"}]}`),
+		},
+	}
+	newClaim = &claim.Claim{
+		Versions: &claim.Versions{Tnf: "v3.1.0", Ocp: "4.9.10"},
+		Results: map[string]interface{}{
+			"a": result("a", "failed"),
+			"b": result("b", "passed"),
+			"c": result("c", "panicked"),
+			"e": result("e", "passed"),
+		},
+		Configurations: map[string]interface{}{
+			"testTarget": parse(t, `{"podsUnderTest": [
+				{"namespace": "tnf", "name": "test-new", "containercount": 1},
+				{"namespace": "tnf", "name": "test-0", "containercount": 2}
+			]}`),
+		},
+		Nodes: map[string]interface{}{
+			"cniPlugins": parse(t, `{"worker-0.example.com": [{"name": "multus"}, {"name": "bridge"}]}`),
+		},
+	}
+	return oldClaim, newClaim
+}
+
+func TestCompare(t *testing.T) {
+	const url = "http://test-network-function.com/testcases/"
+	oldClaim, newClaim := testClaims(t)
+	diff, err := Compare(oldClaim, newClaim)
+	assert.Nil(t, err)
+	assert.Equal(t, []StateChange{
+		{Test: url + "a", OldState: "passed", NewState: "failed", Regression: true},
+		{Test: url + "b", OldState: "failed", NewState: "passed", Regression: false},
+		{Test: url + "c", OldState: "skipped", NewState: "panicked", Regression: true},
+	}, diff.StateChanges)
+	assert.Len(t, diff.Regressions(), 2)
+	assert.Equal(t, []string{url + "e"}, diff.NewTests)
+	assert.Equal(t, []string{url + "d"}, diff.RemovedTests)
+	assert.Equal(t, []Change{{Path: "tnf", Kind: ChangeModified, OldValue: "v3.0.0", NewValue: "v3.1.0"}}, diff.Versions)
+	assert.Equal(t, []Change{
+		{Path: "testTarget.podsUnderTest[tnf/test-0].containercount", Kind: ChangeModified, OldValue: 1.0, NewValue: 2.0},
+		{Path: "testTarget.podsUnderTest[tnf/test-1]", Kind: ChangeRemoved,
+			OldValue: map[string]interface{}{"namespace": "tnf", "name": "test-1", "containercount": 1.0}},
+		{Path: "testTarget.podsUnderTest[tnf/test-new]", Kind: ChangeAdded,
+			NewValue: map[string]interface{}{"namespace": "tnf", "name": "test-new", "containercount": 1.0}},
+	}, diff.Configurations)
+	assert.Equal(t, []Change{
+		{Path: `cniPlugins["worker-0.example.com"][bridge]`, Kind: ChangeAdded, NewValue: map[string]interface{}{"name": "bridge"}},
+	}, diff.Nodes)
+
+	var text bytes.Buffer
+	assert.Nil(t, diff.WriteText(&text))
+	assert.Contains(t, text.String(), "Tests which changed state (3, 2 regressions):\n ! "+url+"a: passed -> failed\n")
+	assert.Contains(t, text.String(), " ~ tnf: \"v3.0.0\" -> \"v3.1.0\"\n")
+	assert.Contains(t, text.String(), " - testTarget.podsUnderTest[tnf/test-1]\n")
+
+	var payload bytes.Buffer
+	assert.Nil(t, diff.WriteJSON(&payload))
+	decoded := &Diff{}
+	assert.Nil(t, json.Unmarshal(payload.Bytes(), decoded))
+	assert.Equal(t, diff.StateChanges, decoded.StateChanges)
+}
+
+func TestCompare_Identical(t *testing.T) {
+	oldClaim, _ := testClaims(t)
+	diff, err := Compare(oldClaim, oldClaim)
+	assert.Nil(t, err)
+	assert.True(t, diff.IsEmpty())
+	assert.Empty(t, diff.Regressions())
+
+	var text bytes.Buffer
+	assert.Nil(t, diff.WriteText(&text))
+	assert.Equal(t, "The claims do not differ.\n", text.String())
+}
+
+func TestCompareLists_ByIndex(t *testing.T) {
+	changes := CompareValues("list", []interface{}{"a", "b"}, []interface{}{"a", "c", "d"})
+	assert.Equal(t, []Change{
+		{Path: "list[1]", Kind: ChangeModified, OldValue: "b", NewValue: "c"},
+		{Path: "list[2]", Kind: ChangeAdded, NewValue: "d"},
+	}, changes)
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+/*
+Package claimmerge aggregates the claim files of several invocations of the test suite, e.g. the intrusive tests run in
+a maintenance window and the other tests run in CI, into a single claim.
+
+As the claim schema does not allow additional fields, the provenance of the merged claim is stored in its raw results,
+under MergeInfoKey, next to the JUnit results: the metadata and versions of every source, the source of every result,
+and the conflicts between the sources.
+*/
+package claimmerge
+
+import (
+	"fmt"
+
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimdiff"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+)
+
+const (
+	// MergeInfoKey is the raw results key of the MergeInfo of a merged claim.
+	MergeInfoKey = "claimMerge"
+
+	// SectionVersions is the Conflict section of the versions.
+	SectionVersions = "versions"
+	// SectionConfigurations is the Conflict section of the configurations.
+	SectionConfigurations = "configurations"
+	// SectionNodes is the Conflict section of the nodes.
+	SectionNodes = "nodes"
+)
+
+// Source is a claim to merge.
+type Source struct {
+	// Name identifies the source in the MergeInfo, typically the path of the claim file.
+	Name string
+	Root *claim.Root
+}
+
+// SourceInfo describes a merged Source.
+type SourceInfo struct {
+	Name      string          `json:"name"`
+	StartTime string          `json:"startTime"`
+	EndTime   string          `json:"endTime"`
+	Versions  *claim.Versions `json:"versions"`
+	// Results lists the result keys of the source.
+	Results []string `json:"results"`
+}
+
+// Conflict is a value of a Source which differs from the first source, whose value is kept in the merged claim.
+type Conflict struct {
+	Source  string `json:"source"`
+	Section string `json:"section"`
+	claimdiff.Change
+}
+
+// MergeInfo is the provenance of a merged claim.
+type MergeInfo struct {
+	Sources []SourceInfo `json:"sources"`
+	// ResultSources is the source name of every result, by result key, in the order of the results.
+	ResultSources map[string][]string `json:"resultSources"`
+	Conflicts     []Conflict          `json:"conflicts"`
+}
+
+// HasConflicts returns true when the sources conflict in one of sections, e.g. SectionVersions when the sources were
+// run with different versions.
+func (m *MergeInfo) HasConflicts(sections ...string) bool {
+	for i := range m.Conflicts {
+		for _, section := range sections {
+			if m.Conflicts[i].Section == section {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Merge merges sources into a new claim.  The results are the union of the results of the sources: the results of a
+// key present in several sources are appended in source order.  The metadata spans from the earliest start time to
+// the latest end time.  The versions, configurations and nodes are those of the first source, and any difference in
+// the other sources is reported as a Conflict.  A raw result key present in several sources is suffixed with the name
+// of the source for all but the first one, except claimutil.ResultDetailsKey, which follows the merged results.
+func Merge(sources []Source) (*claim.Root, *MergeInfo, error) {
+	if len(sources) == 0 {
+		return nil, nil, fmt.Errorf("no claim to merge")
+	}
+	for i := range sources {
+		if sources[i].Root == nil || sources[i].Root.Claim == nil {
+			return nil, nil, fmt.Errorf("the claim %s is empty", sources[i].Name)
+		}
+	}
+	first := sources[0].Root.Claim
+	merged := &claim.Claim{
+		Metadata:       &claim.Metadata{},
+		Versions:       first.Versions,
+		Configurations: first.Configurations,
+		Nodes:          first.Nodes,
+		RawResults:     map[string]interface{}{},
+		Results:        map[string]interface{}{},
+	}
+	info := &MergeInfo{
+		Sources:       []SourceInfo{},
+		ResultSources: map[string][]string{},
+		Conflicts:     []Conflict{},
+	}
+	results := map[string][]claimutil.Result{}
+
+	for i := range sources {
+		source := &sources[i]
+		c := source.Root.Claim
+		sourceResults, err := claimutil.GetResults(c)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid claim %s: %s", source.Name, err)
+		}
+		sourceInfo := SourceInfo{Name: source.Name, Versions: c.Versions, Results: claimutil.SortedKeys(sourceResults)}
+		if c.Metadata != nil {
+			sourceInfo.StartTime = c.Metadata.StartTime
+			sourceInfo.EndTime = c.Metadata.EndTime
+			mergeMetadata(merged.Metadata, c.Metadata)
+		}
+		info.Sources = append(info.Sources, sourceInfo)
+
+		for _, key := range sourceInfo.Results {
+			results[key] = append(results[key], sourceResults[key]...)
+			for range sourceResults[key] {
+				info.ResultSources[key] = append(info.ResultSources[key], source.Name)
+			}
+		}
+		for key, value := range c.RawResults {
+			if key == claimutil.ResultDetailsKey {
+				// The details of the results are stored again with the merged results.
+				continue
+			}
+			if _, ok := merged.RawResults[key]; ok {
+				key = fmt.Sprintf("%s-%s", key, source.Name)
+			}
+			merged.RawResults[key] = value
+		}
+
+		if i > 0 {
+			conflicts, err := findConflicts(source.Name, first, c)
+			if err != nil {
+				return nil, nil, err
+			}
+			info.Conflicts = append(info.Conflicts, conflicts...)
+		}
+	}
+
+	claimutil.SetResults(merged, results)
+	merged.RawResults[MergeInfoKey] = info
+	return &claim.Root{Claim: merged}, info, nil
+}
+
+// mergeMetadata extends merged to span metadata.  The times are UTC and share a single format, so they are compared
+// as strings.
+func mergeMetadata(merged, metadata *claim.Metadata) {
+	if merged.StartTime == "" || (metadata.StartTime != "" && metadata.StartTime < merged.StartTime) {
+		merged.StartTime = metadata.StartTime
+	}
+	if metadata.EndTime > merged.EndTime {
+		merged.EndTime = metadata.EndTime
+	}
+}
+
+func findConflicts(sourceName string, first, c *claim.Claim) ([]Conflict, error) {
+	conflicts := []Conflict{}
+	addConflicts := func(section string, changes []claimdiff.Change) {
+		for _, change := range changes {
+			conflicts = append(conflicts, Conflict{Source: sourceName, Section: section, Change: change})
+		}
+	}
+	versionChanges, err := claimdiff.CompareVersions(first.Versions, c.Versions)
+	if err != nil {
+		return nil, err
+	}
+	addConflicts(SectionVersions, versionChanges)
+	addConflicts(SectionConfigurations, claimdiff.CompareValues("", first.Configurations, c.Configurations))
+	addConflicts(SectionNodes, claimdiff.CompareValues("", first.Nodes, c.Nodes))
+	return conflicts, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package claimmerge
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimdiff"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+func testSource(name, start, end, tnfVersion string, states map[string]string) Source {
+	results := map[string]interface{}{}
+	for key, state := range states {
+		results[key] = []claim.Result{{State: state, TestText: name}}
+	}
+	return Source{Name: name, Root: &claim.Root{Claim: &claim.Claim{
+		Metadata:       &claim.Metadata{StartTime: start, EndTime: end},
+		Versions:       &claim.Versions{Tnf: tnfVersion},
+		Configurations: map[string]interface{}{"targetNameSpaces": []interface{}{map[string]interface{}{"name": "tnf"}}},
+		Nodes:          map[string]interface{}{},
+		RawResults:     map[string]interface{}{"cnf-certification-test": name},
+		Results:        results,
+	}}}
+}
+
+func TestMerge(t *testing.T) {
+	lifecycle := testSource("lifecycle.json", "2022-03-02T01:00:00+00:00", "2022-03-02T02:00:00+00:00", "v3.2.0",
+		map[string]string{"lifecycle-pod-recreation": "passed", "access-control-namespace": "skipped"})
+	ci := testSource("ci.json", "2022-03-01T10:00:00+00:00", "2022-03-01T11:00:00+00:00", "v3.2.0",
+		map[string]string{"access-control-namespace": "passed", "networking-icmpv4-connectivity": "failed"})
+
+	merged, info, err := Merge([]Source{lifecycle, ci})
+	assert.Nil(t, err)
+	assert.Equal(t, &claim.Metadata{StartTime: "2022-03-01T10:00:00+00:00", EndTime: "2022-03-02T02:00:00+00:00"},
+		merged.Claim.Metadata)
+	assert.False(t, info.HasConflicts(SectionVersions, SectionConfigurations, SectionNodes))
+
+	results, err := claimutil.GetResults(merged.Claim)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"access-control-namespace", "lifecycle-pod-recreation", "networking-icmpv4-connectivity"},
+		claimutil.SortedKeys(results))
+	assert.Equal(t, []claimutil.Result{
+		{Result: claim.Result{State: "skipped", TestText: "lifecycle.json"}},
+		{Result: claim.Result{State: "passed", TestText: "ci.json"}},
+	}, results["access-control-namespace"])
+	assert.Equal(t, []string{"lifecycle.json", "ci.json"}, info.ResultSources["access-control-namespace"])
+	assert.Equal(t, []string{"ci.json"}, info.ResultSources["networking-icmpv4-connectivity"])
+
+	assert.Len(t, info.Sources, 2)
+	assert.Equal(t, "lifecycle.json", info.Sources[0].Name)
+	assert.Equal(t, "2022-03-01T10:00:00+00:00", info.Sources[1].StartTime)
+	assert.Equal(t, []string{"access-control-namespace", "networking-icmpv4-connectivity"}, info.Sources[1].Results)
+
+	assert.Equal(t, "lifecycle.json", merged.Claim.RawResults["cnf-certification-test"])
+	assert.Equal(t, "ci.json", merged.Claim.RawResults["cnf-certification-test-ci.json"])
+	assert.Equal(t, info, merged.Claim.RawResults[MergeInfoKey])
+
+	// The merged claim is a valid claim.
+	claimPath := filepath.Join(t.TempDir(), "claim.json")
+	assert.Nil(t, claimutil.WriteClaimFile(claimPath, merged))
+	_, err = claimutil.ReadClaimFile(claimPath)
+	assert.Nil(t, err)
+}
+
+func TestMerge_ResultDetails(t *testing.T) {
+	objects := []tnf.NonCompliantObject{{Kind: tnf.KindPod, Namespace: "tnf", Name: "test-0", Reason: "HostNetwork"}}
+	first := testSource("a.json", "", "", "v3.2.0", map[string]string{"access-control-host-resource": "passed"})
+	second := testSource("b.json", "", "", "v3.2.0", map[string]string{})
+	claimutil.SetResults(second.Root.Claim, map[string][]claimutil.Result{"access-control-host-resource": {
+		{Result: claim.Result{State: "failed"}, NonCompliantObjects: objects},
+	}})
+
+	// The objects follow their result, after the result of the first claim.
+	merged, _, err := Merge([]Source{first, second})
+	assert.Nil(t, err)
+	assert.NotContains(t, merged.Claim.RawResults, claimutil.ResultDetailsKey+"-b.json")
+	results, err := claimutil.GetResults(merged.Claim)
+	assert.Nil(t, err)
+	assert.Nil(t, results["access-control-host-resource"][0].NonCompliantObjects)
+	assert.Equal(t, objects, results["access-control-host-resource"][1].NonCompliantObjects)
+}
+
+func TestMerge_Conflicts(t *testing.T) {
+	first := testSource("a.json", "", "", "v3.2.0", map[string]string{})
+	second := testSource("b.json", "", "", "v3.3.0", map[string]string{})
+	second.Root.Claim.Configurations["targetNameSpaces"] = []interface{}{map[string]interface{}{"name": "other"}}
+
+	merged, info, err := Merge([]Source{first, second})
+	assert.Nil(t, err)
+	assert.Equal(t, "v3.2.0", merged.Claim.Versions.Tnf)
+	assert.True(t, info.HasConflicts(SectionVersions))
+	assert.True(t, info.HasConflicts(SectionConfigurations))
+	assert.False(t, info.HasConflicts(SectionNodes))
+	assert.Equal(t, Conflict{Source: "b.json", Section: SectionVersions, Change: claimdiff.Change{
+		Path: "tnf", Kind: claimdiff.ChangeModified, OldValue: "v3.2.0", NewValue: "v3.3.0"}}, info.Conflicts[0])
+	assert.Len(t, info.Conflicts, 3)
+}
+
+func TestMerge_Errors(t *testing.T) {
+	_, _, err := Merge(nil)
+	assert.NotNil(t, err)
+	_, _, err = Merge([]Source{{Name: "empty.json", Root: &claim.Root{}}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package claimutil provides helpers to read, write and inspect claim files.
+package claimutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+const (
+	// StatePassed is the claim.Result state of a passed test.
+	StatePassed = "passed"
+	// StateSkipped is the claim.Result state of a skipped test.
+	StateSkipped = "skipped"
+	// StatePending is the claim.Result state of a pending test.
+	StatePending = "pending"
+	// StateFailed is the claim.Result state of a failed test.
+	StateFailed = "failed"
+
+	claimFilePermissions = 0644
+
+	// ResultDetailsKey is the key of the claim raw results holding the ResultDetails of the claim results, see
+	// SetResults.
+	ResultDetailsKey = "resultDetails"
+)
+
+// Result is a claim.Result with the objects which failed the test case, and the tests retried by the test case.  The
+// claim schema does not allow any field next to the claim.Result ones, so they are stored in the claim raw results,
+// see SetResults.  Aborted is not written to the claim.
+type Result struct {
+	claim.Result
+	NonCompliantObjects []tnf.NonCompliantObject
+	RetriedTests        []tnf.RetriedTest
+	// Aborted is true when the test case stopped the run of the next ones, e.g. to keep the cluster under test usable.
+	Aborted bool
+}
+
+// MarshalJSON encodes the claim.Result JSON object of r.
+func (r Result) MarshalJSON() ([]byte, error) { //nolint:gocritic // Results are stored by value in claims
+	return json.Marshal(&r.Result)
+}
+
+// UnmarshalJSON decodes a claim.Result JSON object, without any detail.
+func (r *Result) UnmarshalJSON(payload []byte) error {
+	r.NonCompliantObjects = nil
+	r.RetriedTests = nil
+	r.Aborted = false
+	return json.Unmarshal(payload, &r.Result)
+}
+
+// ResultDetails are the fields of a Result which claim.Result does not have.
+type ResultDetails struct {
+	NonCompliantObjects []tnf.NonCompliantObject `json:"nonCompliantObjects,omitempty"`
+	RetriedTests        []tnf.RetriedTest        `json:"retriedTests,omitempty"`
+}
+
+// details returns the ResultDetails of r, and false when it has none.
+func (r *Result) details() (ResultDetails, bool) {
+	details := ResultDetails{NonCompliantObjects: r.NonCompliantObjects, RetriedTests: r.RetriedTests}
+	return details, len(details.NonCompliantObjects) > 0 || len(details.RetriedTests) > 0
+}
+
+// ReadClaimFile reads and decodes a claim file.
+func ReadClaimFile(claimPath string) (*claim.Root, error) {
+	contents, err := os.ReadFile(claimPath)
+	if err != nil {
+		return nil, err
+	}
+	claimRoot := &claim.Root{}
+	err = json.Unmarshal(contents, claimRoot)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the claim file %s: %s", claimPath, err)
+	}
+	if claimRoot.Claim == nil {
+		return nil, fmt.Errorf("the claim file %s has no claim", claimPath)
+	}
+	return claimRoot, nil
+}
+
+// WriteClaimFile encodes claimRoot to a claim file.
+func WriteClaimFile(claimPath string, claimRoot *claim.Root) error {
+	payload, err := json.MarshalIndent(claimRoot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(claimPath, payload, claimFilePermissions)
+}
+
+// GetResults decodes the results of c, which are stored as generic JSON values in claim.Claim, with their
+// ResultDetails.
+func GetResults(c *claim.Claim) (map[string][]Result, error) {
+	results := map[string][]Result{}
+	if c.Results == nil {
+		return results, nil
+	}
+	payload, err := json.Marshal(c.Results)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(payload, &results)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the claim results: %s", err)
+	}
+	details := map[string][]ResultDetails{}
+	if value, ok := c.RawResults[ResultDetailsKey]; ok {
+		payload, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(payload, &details)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the claim result details: %s", err)
+		}
+	}
+	for key, keyDetails := range details {
+		keyResults := results[key]
+		for i := 0; i < len(keyDetails) && i < len(keyResults); i++ {
+			keyResults[i].NonCompliantObjects = keyDetails[i].NonCompliantObjects
+			keyResults[i].RetriedTests = keyDetails[i].RetriedTests
+		}
+	}
+	return results, nil
+}
+
+// SetResults stores results into c.  The claim.Result of the results are stored in claim.Claim.Results, and their
+// ResultDetails in the claim raw results under ResultDetailsKey, keyed like the results, with one entry per result.
+func SetResults(c *claim.Claim, results map[string][]Result) {
+	claimResults := make(map[string]interface{}, len(results))
+	details := map[string][]ResultDetails{}
+	for key, keyResults := range results {
+		claimResults[key] = keyResults
+		keyDetails := make([]ResultDetails, len(keyResults))
+		found := false
+		for i := range keyResults {
+			var ok bool
+			keyDetails[i], ok = keyResults[i].details()
+			found = found || ok
+		}
+		if found {
+			details[key] = keyDetails
+		}
+	}
+	c.Results = claimResults
+	if c.RawResults == nil {
+		c.RawResults = map[string]interface{}{}
+	}
+	delete(c.RawResults, ResultDetailsKey)
+	if len(details) > 0 {
+		c.RawResults[ResultDetailsKey] = details
+	}
+}
+
+// SortedKeys returns the keys of results, sorted.
+func SortedKeys(results map[string][]Result) []string {
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsFailure returns true when state is neither passed, skipped nor pending, e.g. failed, panicked or interrupted.
+func IsFailure(state string) bool {
+	return state != StatePassed && state != StateSkipped && state != StatePending
+}
+
+// AggregateState returns the overall state of the results of a test, which may run once per suite invocation: the
+// first failure state if any, passed if any result passed, and the state of the first result otherwise.
+func AggregateState(results []Result) string {
+	state := ""
+	for i := range results {
+		switch {
+		case IsFailure(results[i].State):
+			return results[i].State
+		case results[i].State == StatePassed:
+			state = StatePassed
+		case state == "":
+			state = results[i].State
+		}
+	}
+	return state
+}
+
+// TestName returns the URL of the identifier of the results, or key when the results have none.
+func TestName(key string, results []Result) string {
+	for i := range results {
+		if results[i].TestID != nil && results[i].TestID.Url != "" {
+			return results[i].TestID.Url
+		}
+	}
+	return key
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package claimutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+func results(states ...string) []Result {
+	results := []Result{}
+	for _, state := range states {
+		results = append(results, Result{Result: claim.Result{State: state}})
+	}
+	return results
+}
+
+func TestAggregateState(t *testing.T) {
+	testCases := []struct {
+		states   []string
+		expected string
+	}{
+		{states: []string{}, expected: ""},
+		{states: []string{StatePassed}, expected: StatePassed},
+		{states: []string{StateSkipped, StatePassed}, expected: StatePassed},
+		{states: []string{StatePassed, StateFailed, StatePassed}, expected: StateFailed},
+		{states: []string{StateSkipped, "panicked"}, expected: "panicked"},
+		{states: []string{StatePending, StateSkipped}, expected: StatePending},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, AggregateState(results(tc.states...)))
+	}
+}
+
+func TestTestName(t *testing.T) {
+	assert.Equal(t, "key", TestName("key", results(StatePassed)))
+	assert.Equal(t, "http://test-network-function.com/testcases/a", TestName("key", []Result{
+		{Result: claim.Result{State: StatePassed, TestID: &claim.Identifier{Url: "http://test-network-function.com/testcases/a"}}},
+	}))
+}
+
+func TestReadWriteClaimFile(t *testing.T) {
+	claimPath := filepath.Join(t.TempDir(), "claim.json")
+	claimRoot := &claim.Root{Claim: &claim.Claim{
+		Metadata: &claim.Metadata{StartTime: "start", EndTime: "end"},
+		Versions: &claim.Versions{Tnf: "v1"},
+		Results:  map[string]interface{}{"key": results(StateFailed)},
+	}}
+	assert.Nil(t, WriteClaimFile(claimPath, claimRoot))
+
+	readRoot, err := ReadClaimFile(claimPath)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", readRoot.Claim.Versions.Tnf)
+	readResults, err := GetResults(readRoot.Claim)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string][]Result{"key": results(StateFailed)}, readResults)
+	assert.Equal(t, []string{"key"}, SortedKeys(readResults))
+
+	assert.Nil(t, os.WriteFile(claimPath, []byte(`{}`), claimFilePermissions))
+	_, err = ReadClaimFile(claimPath)
+	assert.NotNil(t, err)
+	_, err = ReadClaimFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
+
+func TestResult_JSON(t *testing.T) {
+	result := Result{
+		Result: claim.Result{State: StateFailed, CapturedTestOutput: "checking test-0\n"},
+		NonCompliantObjects: []tnf.NonCompliantObject{
+			{Kind: tnf.KindPod, Namespace: "tnf", Name: "test-0", Reason: "HostNetwork"},
+		},
+		RetriedTests: []tnf.RetriedTest{{Name: "request", Attempts: []tnf.Attempt{{Number: 1, Result: tnf.SUCCESS}}}},
+		Aborted:      true,
+	}
+
+	// The details are not part of the claim result, see SetResults.
+	payload, err := json.Marshal(result)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(payload), "test-0\"")
+	assert.NotContains(t, string(payload), "request")
+	assert.Nil(t, json.Unmarshal(payload, &claim.Result{}))
+	decoded := result
+	assert.Nil(t, json.Unmarshal(payload, &decoded))
+	assert.Equal(t, Result{Result: result.Result}, decoded)
+}
+
+func TestSetResults(t *testing.T) {
+	objects := []tnf.NonCompliantObject{{Kind: tnf.KindPod, Namespace: "tnf", Name: "test-0", Reason: "HostNetwork"}}
+	retried := []tnf.RetriedTest{{Name: "request", Attempts: []tnf.Attempt{
+		{Number: 1, Result: tnf.ERROR, Error: "connection refused", Backoff: time.Second}, {Number: 2, Result: tnf.SUCCESS},
+	}}}
+	results := map[string][]Result{
+		"failed": {{Result: claim.Result{State: StatePassed}}, {Result: claim.Result{State: StateFailed}, NonCompliantObjects: objects}},
+		"passed": {{Result: claim.Result{State: StatePassed}, RetriedTests: retried}},
+		"plain":  results(StatePassed),
+	}
+	c := &claim.Claim{
+		Metadata:   &claim.Metadata{StartTime: "start", EndTime: "end"},
+		Versions:   &claim.Versions{Tnf: "v1"},
+		RawResults: map[string]interface{}{"cnf-certification-test": "junit"},
+	}
+	SetResults(c, results)
+	assert.Equal(t, map[string][]ResultDetails{
+		"failed": {{}, {NonCompliantObjects: objects}},
+		"passed": {{RetriedTests: retried}},
+	}, c.RawResults[ResultDetailsKey])
+	assert.Equal(t, "junit", c.RawResults["cnf-certification-test"])
+
+	// The details are stored in the claim raw results, keyed like the results.
+	claimPath := filepath.Join(t.TempDir(), "claim.json")
+	assert.Nil(t, WriteClaimFile(claimPath, &claim.Root{Claim: c}))
+	payload, err := os.ReadFile(claimPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(payload), `"resultDetails": {`)
+	assert.Contains(t, string(payload), `"nonCompliantObjects": [`)
+	assert.Contains(t, string(payload), `"retriedTests": [`)
+	readRoot, err := ReadClaimFile(claimPath)
+	assert.Nil(t, err)
+	readResults, err := GetResults(readRoot.Claim)
+	assert.Nil(t, err)
+	assert.Equal(t, results, readResults)
+
+	// The details of results which are no longer stored are removed.
+	SetResults(c, map[string][]Result{"plain": results["plain"]})
+	assert.NotContains(t, c.RawResults, ResultDetailsKey)
+}
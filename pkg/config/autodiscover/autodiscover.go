@@ -25,7 +25,6 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
-	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/utils"
 )
 
@@ -69,20 +68,22 @@ func buildLabelQuery(label configsections.Label) string {
 	return fullLabelName
 }
 
-var executeOcGetCommand = func(resourceType, labelQuery, namespace string) string {
+var executeOcGetCommand = func(resourceType, labelQuery, namespace string) (string, error) {
 	ocCommandToExecute := fmt.Sprintf(ocCommand, resourceType, namespace, labelQuery)
-	match := utils.ExecuteCommandAndValidate(ocCommandToExecute, ocCommandTimeOut, interactive.GetContext(expectersVerboseModeEnabled), func() {
+	match, err := utils.ExecuteLocalCommand(ocCommandToExecute, ocCommandTimeOut)
+	if err != nil {
 		log.Error("can't run command: ", ocCommandToExecute)
-	})
-	return match
+	}
+	return match, err
 }
 
-var executeOcGetAllCommand = func(resourceType, labelQuery string) string {
+var executeOcGetAllCommand = func(resourceType, labelQuery string) (string, error) {
 	ocCommandToExecute := fmt.Sprintf(ocAllCommand, resourceType, labelQuery)
-	match := utils.ExecuteCommandAndValidate(ocCommandToExecute, ocCommandTimeOut, interactive.GetContext(expectersVerboseModeEnabled), func() {
+	match, err := utils.ExecuteLocalCommand(ocCommandToExecute, ocCommandTimeOut)
+	if err != nil {
 		log.Error("can't run command: ", ocCommandToExecute)
-	})
-	return match
+	}
+	return match, err
 }
 
 // getContainersByLabel builds `configsections.Container`s from containers in pods matching a label.
@@ -17,10 +17,10 @@
 package autodiscover
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
@@ -43,71 +43,109 @@ const (
 	dsRetryIntervalSecs = 5
 )
 
+var (
+	// debugDaemonSetTimeout is how long CheckDebugDaemonset waits for the debug pods.
+	debugDaemonSetTimeout = dsTimeoutMins * time.Minute
+	// debugDaemonSetRetryInterval is the interval between two checks of the debug pods.
+	debugDaemonSetRetryInterval = dsRetryIntervalSecs * time.Second
+)
+
 // FindDebugPods completes a `configsections.TestPartner.ContainersDebugList` from the current state of the cluster,
-// using labels and annotations to populate the data, if it's not fully configured
-func FindDebugPods(tp *configsections.TestPartner) {
+// using labels and annotations to populate the data, if it's not fully configured.  An error is returned when there
+// is no debug pod.
+func FindDebugPods(tp *configsections.TestPartner) error {
 	label := configsections.Label{Name: debugLabelName, Value: debugLabelValue}
 	pods, err := GetPodsByLabelByNamespace(label, defaultNamespace)
 	if err != nil {
-		log.Panic("can't find debug pods. Error: ", err)
+		return fmt.Errorf("can't find debug pods: %w", err)
 	}
 	if len(pods.Items) == 0 {
-		log.Panic("can't find debug pods, make sure daemonset debug is deployed properly")
+		return errors.New("can't find debug pods, make sure daemonset debug is deployed properly")
 	}
 	for _, pod := range pods.Items {
 		tp.ContainersDebugList = append(tp.ContainersDebugList, buildContainers(pod)[0])
 	}
+	return nil
 }
 
 // AddDebugLabel add debug label to node
-func AddDebugLabel(nodeName string) {
+func AddDebugLabel(nodeName string) error {
 	log.Info("add label ", nodeLabelName, "=", nodeLabelValue, " to node ", nodeName)
 	ocCommand := fmt.Sprintf(addlabelCommand, nodeName, nodeLabelName, nodeLabelValue)
-	_ = utils.ExecuteCommandAndValidate(ocCommand, ocCommandTimeOut, interactive.GetContext(expectersVerboseModeEnabled), func() {
-		log.Error("error in adding label to node ", nodeName)
-	})
+	if _, err := utils.ExecuteLocalCommand(ocCommand, ocCommandTimeOut); err != nil {
+		return fmt.Errorf("error in adding label to node %s: %w", nodeName, err)
+	}
+	return nil
 }
 
-// AddDebugLabel remove debug label from node
-func DeleteDebugLabel(nodeName string) {
+// DeleteDebugLabel remove debug label from node
+func DeleteDebugLabel(nodeName string) error {
 	log.Info("delete label ", nodeLabelName, "=", nodeLabelValue, "to node ", nodeName)
 	ocCommand := fmt.Sprintf(deletelabelCommand, nodeName, nodeLabelName)
-	_ = utils.ExecuteCommandAndValidate(ocCommand, ocCommandTimeOut, interactive.GetContext(expectersVerboseModeEnabled), func() {
-		log.Error("error in removing label from node ", nodeName)
-	})
+	if _, err := utils.ExecuteLocalCommand(ocCommand, ocCommandTimeOut); err != nil {
+		return fmt.Errorf("error in removing label from node %s: %w", nodeName, err)
+	}
+	return nil
 }
 
 // CheckDebugDaemonset checks if the debug pods are deployed properly
-// the function will try DefaultTimeout/time.Second times
-func CheckDebugDaemonset(expectedDebugPods int) {
-	gomega.Eventually(func() bool {
-		log.Debug("check debug daemonset status")
-		return checkDebugPodsReadiness(expectedDebugPods)
-	}, dsTimeoutMins*time.Minute, dsRetryIntervalSecs*time.Second).Should(gomega.Equal(true))
-}
-
-// checkDebugPodsReadiness helper function that returns true if the daemonset debug is deployed properly
-func checkDebugPodsReadiness(expectedDebugPods int) bool {
-	context := interactive.GetContext(expectersVerboseModeEnabled)
-	tester := ds.NewDaemonSet(DefaultTimeout, debugDaemonSet, defaultNamespace)
-	test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+// the check is retried every dsRetryIntervalSecs for up to dsTimeoutMins, and returns an error if the debug pods are
+// still not ready
+func CheckDebugDaemonset(expectedDebugPods int) error {
+	context, err := interactive.NewShellContext(expectersVerboseModeEnabled)
 	if err != nil {
-		log.Error("can't run test to detect daemonset status")
-		return false
+		return fmt.Errorf("can't run test to detect daemonset status: %w", err)
 	}
-	_, err = test.Run()
+	log.Debug("check debug daemonset status")
+	tester := &debugDaemonSetTester{DaemonSet: ds.NewDaemonSet(DefaultTimeout, debugDaemonSet, defaultNamespace), expectedDebugPods: expectedDebugPods}
+	retrier := tnf.WithRetry(tester, debugDaemonSetRetryPolicy())
+	test, err := tnf.NewTest(context.GetExpecter(), retrier, []reel.Handler{retrier}, context.GetErrorChannel())
 	if err != nil {
-		return false
+		return fmt.Errorf("can't run test to detect daemonset status: %w", err)
+	}
+	result, err := test.Run()
+	if result == tnf.SUCCESS {
+		log.Info("daemonset is ready")
+		return nil
+	}
+	message := fmt.Sprintf("the debug daemonset does not have %d ready pods after %d attempts", expectedDebugPods, len(retrier.Attempts()))
+	if err != nil {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+	return errors.New(message)
+}
+
+// debugDaemonSetRetryPolicy returns the policy checking the debug pods every debugDaemonSetRetryInterval for up to
+// debugDaemonSetTimeout.
+func debugDaemonSetRetryPolicy() tnf.RetryPolicy {
+	return tnf.RetryPolicy{
+		MaxAttempts:    int(debugDaemonSetTimeout / debugDaemonSetRetryInterval),
+		InitialBackoff: debugDaemonSetRetryInterval,
+		RetryOnTimeout: true,
 	}
-	dsStatus := tester.GetStatus()
-	if expectedDebugPods == dsStatus.Desired &&
+}
+
+// debugDaemonSetTester is the DaemonSet tester of the debug daemonset, whose result is an ERROR, which is retried, until
+// the debug pods are ready.
+type debugDaemonSetTester struct {
+	*ds.DaemonSet
+	expectedDebugPods int
+}
+
+// Result returns the result of the DaemonSet tester, or ERROR if the debug pods are not ready.
+func (t *debugDaemonSetTester) Result() int {
+	result := t.DaemonSet.Result()
+	if result == tnf.SUCCESS && !debugPodsReady(t.GetStatus(), t.expectedDebugPods) {
+		return tnf.ERROR
+	}
+	return result
+}
+
+// debugPodsReady returns true if the daemonset debug is deployed properly
+func debugPodsReady(dsStatus ds.Status, expectedDebugPods int) bool { //nolint:gocritic // Status is returned by value
+	return expectedDebugPods == dsStatus.Desired &&
 		dsStatus.Desired == dsStatus.Current &&
 		dsStatus.Current == dsStatus.Available &&
 		dsStatus.Available == dsStatus.Ready &&
-		dsStatus.Misscheduled == 0 {
-		log.Info("daemonset is ready")
-		return true
-	}
-	log.Warn("daemonset is not ready")
-	return false
+		dsStatus.Misscheduled == 0
 }
@@ -17,11 +17,16 @@
 package autodiscover
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	ds "github.com/test-network-function/test-network-function/pkg/tnf/handlers/daemonset"
 )
 
 func TestFindDebugPods(t *testing.T) {
@@ -53,18 +58,62 @@ func TestFindDebugPods(t *testing.T) {
 	for _, tc := range testCases {
 		tp := &configsections.TestPartner{}
 
-		executeOcGetCommand = func(resourceType, labelQuery, namespace string) string {
+		executeOcGetCommand = func(resourceType, labelQuery, namespace string) (string, error) {
 			output, _ := os.ReadFile(tc.jsonFileName)
-			return string(output)
+			return string(output), nil
 		}
 
+		err := FindDebugPods(tp)
 		if tc.expectedDebugPodAmount > 0 {
-			FindDebugPods(tp)
+			assert.Nil(t, err)
 			assert.Len(t, tp.ContainersDebugList, 1) // Only assuming one debug pod in the test YAML
 			assert.Equal(t, tc.expectedPodName, tp.ContainersDebugList[0].PodName)
 			assert.Equal(t, tc.expectedContainerName, tp.ContainersDebugList[0].ContainerName)
 		} else {
-			assert.Panics(t, func() { FindDebugPods(tp) })
+			assert.NotNil(t, err)
+			assert.Empty(t, tp.ContainersDebugList)
 		}
 	}
+
+	executeOcGetCommand = func(resourceType, labelQuery, namespace string) (string, error) {
+		return "", errors.New("connection refused")
+	}
+	err := FindDebugPods(&configsections.TestPartner{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+// fakeOc puts an oc script running script first in the PATH.  The script gets the number of the call as $n.
+func fakeOc(t *testing.T, script string) {
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "calls")
+	contents := fmt.Sprintf("#!/bin/sh\nn=$(cat %s 2>/dev/null || echo 0); n=$((n + 1)); echo $n > %s\n%s\n", counter, counter, script)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "oc"), []byte(contents), 0o755)) //nolint:gosec // An executable script
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SHELL", "/bin/sh")
+}
+
+func TestCheckDebugDaemonset(t *testing.T) {
+	origTimeout, origInterval := debugDaemonSetTimeout, debugDaemonSetRetryInterval
+	defer func() {
+		debugDaemonSetTimeout, debugDaemonSetRetryInterval = origTimeout, origInterval
+	}()
+	debugDaemonSetTimeout = 5 * time.Millisecond
+	debugDaemonSetRetryInterval = time.Millisecond
+
+	// The debug pods are ready at the third check.
+	fakeOc(t, `if [ $n -lt 3 ]; then echo "debug 2 2 2 1 0"; else echo "debug 2 2 2 2 0"; fi`)
+	assert.Nil(t, CheckDebugDaemonset(2))
+
+	fakeOc(t, `echo "debug 2 2 2 1 0"`)
+	err := CheckDebugDaemonset(2)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "2 ready pods after 5 attempts")
+}
+
+func TestDebugPodsReady(t *testing.T) {
+	assert.True(t, debugPodsReady(ds.Status{Desired: 2, Current: 2, Available: 2, Ready: 2}, 2))
+	assert.False(t, debugPodsReady(ds.Status{Desired: 2, Current: 2, Available: 2, Ready: 2}, 3))
+	assert.False(t, debugPodsReady(ds.Status{Desired: 2, Current: 2, Available: 2, Ready: 1}, 2))
+	assert.False(t, debugPodsReady(ds.Status{Desired: 2, Current: 2, Available: 2, Ready: 2, Misscheduled: 1}, 2))
 }
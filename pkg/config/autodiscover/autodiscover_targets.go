@@ -29,7 +29,6 @@ import (
 	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
 	"github.com/test-network-function/test-network-function/pkg/tnf/testcases"
-	"github.com/test-network-function/test-network-function/pkg/utils"
 )
 
 const (
@@ -56,7 +55,7 @@ func FindTestTarget(labels []configsections.Label, target *configsections.TestTa
 	}
 	for _, l := range labels {
 		pods, err := GetPodsByLabel(l)
-		if err == nil {
+		if err == nil && pods != nil {
 			for _, pod := range pods.Items {
 				if ns[pod.Metadata.Namespace] {
 					target.PodsUnderTest = append(target.PodsUnderTest, buildPodUnderTest(pod))
@@ -90,18 +89,25 @@ func FindTestTarget(labels []configsections.Label, target *configsections.TestTa
 	}
 
 	csvs, err := GetCSVsByLabel(operatorLabelName, anyLabelValue)
-	if err != nil {
-		log.Warnf("an error (%s) occurred when looking for operators by label", err)
-	}
-	for _, csv := range csvs.Items {
-		if ns[csv.Metadata.Namespace] {
-			csv := csv
-			target.Operators = append(target.Operators, buildOperatorFromCSVResource(&csv, false))
+	if err != nil || csvs == nil {
+		log.Warnf("an error (%v) occurred when looking for operators by label", err)
+	} else {
+		for _, csv := range csvs.Items {
+			if ns[csv.Metadata.Namespace] {
+				csv := csv
+				target.Operators = append(target.Operators, buildOperatorFromCSVResource(&csv, false))
+			}
 		}
 	}
-	dps := FindTestPodSetsByLabel(labels, string(configsections.Deployment))
+	dps, err := FindTestPodSetsByLabel(labels, string(configsections.Deployment))
+	if err != nil {
+		log.Warnf("an error (%s) occurred when looking for deployments by label", err)
+	}
 	target.DeploymentsUnderTest = appendPodsets(dps, ns)
-	stateFulSet := FindTestPodSetsByLabel(labels, string(configsections.StateFulSet))
+	stateFulSet, err := FindTestPodSetsByLabel(labels, string(configsections.StateFulSet))
+	if err != nil {
+		log.Warnf("an error (%s) occurred when looking for statefulsets by label", err)
+	}
 	target.StateFulSetUnderTest = appendPodsets(stateFulSet, ns)
 	target.Nodes = GetNodesList()
 	target.HelmChart = GethelmCharts(skipHelmChartList, ns)
@@ -173,10 +179,16 @@ func appendPodsets(podsets []configsections.PodSet, ns map[string]bool) (podSet
 func GetNodesList() (nodes map[string]configsections.Node) {
 	nodes = make(map[string]configsections.Node)
 	var nodeNames []string
-	context := interactive.GetContext(expectersVerboseModeEnabled)
+	context, err := interactive.NewShellContext(expectersVerboseModeEnabled)
+	if err != nil {
+		log.Error("Unable to get node list ", ". Error: ", err)
+		return
+	}
 	tester := nodenames.NewNodeNames(DefaultTimeout, map[string]*string{configsections.MasterLabel: nil})
-	test, _ := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-	_, err := test.Run()
+	test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+	if err == nil {
+		_, err = test.Run()
+	}
 	if err != nil {
 		log.Error("Unable to get node list ", ". Error: ", err)
 		return
@@ -190,8 +202,10 @@ func GetNodesList() (nodes map[string]configsections.Node) {
 	}
 
 	tester = nodenames.NewNodeNames(DefaultTimeout, map[string]*string{configsections.WorkerLabel: nil})
-	test, _ = tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-	_, err = test.Run()
+	test, err = tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+	if err == nil {
+		_, err = test.Run()
+	}
 	if err != nil {
 		log.Error("Unable to get node list ", ". Error: ", err)
 	} else {
@@ -214,8 +228,8 @@ func GetNodesList() (nodes map[string]configsections.Node) {
 }
 
 // FindTestPodSetsByLabel uses the containers' namespace to get its parent deployment/statefulset. Filters out non CNF test podsets,deployment/statefulset,
-// currently partner and fs_diff ones.
-func FindTestPodSetsByLabel(targetLabels []configsections.Label, resourceTypeDeployment string) (podsets []configsections.PodSet) {
+// currently partner and fs_diff ones.  An error is returned when the podsets or their autoscalers cannot be listed.
+func FindTestPodSetsByLabel(targetLabels []configsections.Label, resourceTypeDeployment string) (podsets []configsections.PodSet, err error) {
 	configType := configsections.Deployment
 	if resourceTypeDeployment == string(configsections.StateFulSet) {
 		configType = configsections.StateFulSet
@@ -223,22 +237,25 @@ func FindTestPodSetsByLabel(targetLabels []configsections.Label, resourceTypeDep
 	for _, label := range targetLabels {
 		podsetResourceList, err := GetTargetPodSetsByLabel(label, resourceTypeDeployment)
 		if err != nil {
-			log.Error("Unable to get deployment list  Error: ", err)
-		} else {
-			for _, podsetResource := range podsetResourceList.Items {
-				podset := configsections.PodSet{
-					Name:      podsetResource.GetName(),
-					Namespace: podsetResource.GetNamespace(),
-					Replicas:  podsetResource.GetReplicas(),
-					Hpa:       podsetResource.GetHpa(),
-					Type:      configType,
-				}
-
-				podsets = append(podsets, podset)
+			return nil, fmt.Errorf("unable to get the %s list: %w", resourceTypeDeployment, err)
+		}
+		for _, podsetResource := range podsetResourceList.Items {
+			hpa, err := podsetResource.GetHpa()
+			if err != nil {
+				return nil, fmt.Errorf("unable to get the hpa of %s %s (ns %s): %w", resourceTypeDeployment, podsetResource.GetName(), podsetResource.GetNamespace(), err)
+			}
+			podset := configsections.PodSet{
+				Name:      podsetResource.GetName(),
+				Namespace: podsetResource.GetNamespace(),
+				Replicas:  podsetResource.GetReplicas(),
+				Hpa:       hpa,
+				Type:      configType,
 			}
+
+			podsets = append(podsets, podset)
 		}
 	}
-	return podsets
+	return podsets, nil
 }
 
 // buildPodUnderTest builds a single `configsections.Pod` from a PodResource
@@ -249,6 +266,9 @@ func buildPodUnderTest(pr *PodResource) (podUnderTest *configsections.Pod) {
 	podUnderTest.Name = pr.Metadata.Name
 	podUnderTest.ServiceAccount = pr.Spec.ServiceAccount
 	podUnderTest.ContainerCount = len(pr.Spec.Containers)
+	for _, container := range pr.Spec.Containers {
+		podUnderTest.ContainerNames = append(podUnderTest.ContainerNames, container.Name)
+	}
 	podUnderTest.DefaultNetworkDevice, err = pr.getDefaultNetworkDeviceFromAnnotations()
 	if err != nil {
 		log.Warnf("error encountered getting default network device: %s", err)
@@ -312,7 +332,10 @@ func buildOperatorFromCSVResource(csv *CSVResource, istest bool) (op *configsect
 		if err != nil {
 			log.Errorf("Failed to get operator bundle and index image for csv %s (ns %s), error: %s", op.Name, op.Namespace, err)
 		}
-		op.Packag, op.Org, op.Version = csv.PackOrgVersion(op.Name)
+		op.Packag, op.Org, op.Version, err = csv.PackOrgVersion(op.Name)
+		if err != nil {
+			log.Errorf("Failed to get the package and version of csv %s (ns %s), error: %s", op.Name, op.Namespace, err)
+		}
 	}
 
 	return op
@@ -336,7 +359,10 @@ func getConfiguredOperatorTests() []string {
 
 var getCsvInstallPlanNames = func(csvName, csvNamespace string) ([]string, error) {
 	installPlanCmd := fmt.Sprintf("oc get installplan -n %s | grep %q | awk '{ print $1 }'", csvNamespace, csvName)
-	out := execCommandOutput(installPlanCmd)
+	out, err := execCommandOutput(installPlanCmd)
+	if err != nil {
+		return []string{}, err
+	}
 	if out == "" {
 		return []string{}, errors.New("installplan not found")
 	}
@@ -354,7 +380,10 @@ var getInstallPlanData = func(installPlanName, namespace string) (bundleImagePat
 		"'{{range .items}}{{ if eq .metadata.name %q}}{{ range .status.bundleLookups }}"+
 		"{{ .path }},{{ .catalogSourceRef.name }},{{ .catalogSourceRef.namespace }}{{end}}{{end}}{{end}}'", namespace, installPlanName)
 
-	out := execCommandOutput(infoFromInstallPlanCmd)
+	out, err := execCommandOutput(infoFromInstallPlanCmd)
+	if err != nil {
+		return "", "", "", err
+	}
 	installPlanFields := strings.Split(out, ",")
 	if len(installPlanFields) != installPlanNumFields {
 		return "", "", "", fmt.Errorf("invalid installplan info: %s", out)
@@ -366,7 +395,10 @@ var getInstallPlanData = func(installPlanName, namespace string) (bundleImagePat
 var getCatalogSourceImageIndex = func(catalogSourceName, catalogSourceNamespace string) (string, error) {
 	const nullOutput = "null"
 	indexImageCmd := fmt.Sprintf("oc get catalogsource -n %s %s -o json | jq -r .spec.image", catalogSourceNamespace, catalogSourceName)
-	indexImage := execCommandOutput(indexImageCmd)
+	indexImage, err := execCommandOutput(indexImageCmd)
+	if err != nil {
+		return "", err
+	}
 	if indexImage == "" {
 		return "", fmt.Errorf("failed to get index image for catalogsource %s (ns %s)", catalogSourceName, catalogSourceNamespace)
 	}
@@ -407,17 +439,7 @@ func getCsvInstallPlans(csvName, csvNamespace string) (installPlans []configsect
 
 // getClusterCrdNames returns a list of crd names found in the cluster.
 func getClusterCrdNames() ([]string, error) {
-	out := utils.ExecuteCommandAndValidate(ocGetClusterCrdNamesCommand, ocCommandTimeOut, interactive.GetContext(expectersVerboseModeEnabled), func() {
-		log.Error("can't run command: ", ocGetClusterCrdNamesCommand)
-	})
-
-	var crdNamesList []string
-	err := jsonUnmarshal([]byte(out), &crdNamesList)
-	if err != nil {
-		return nil, err
-	}
-
-	return crdNamesList, nil
+	return GetDiscoveryProvider().ListCrdNames()
 }
 
 // FindTestCrdNames gets a list of CRD names based on configured groups.
@@ -26,7 +26,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
-	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/utils"
 )
 
@@ -83,11 +82,11 @@ func TestFindTestCrdNames(t *testing.T) {
 	}
 
 	// Spoof the executeCommand func
-	origFunc := utils.ExecuteCommandAndValidate
-	utils.ExecuteCommandAndValidate = func(command string, timeout time.Duration, context *interactive.Context, failureCallbackFun func()) string {
+	origFunc := utils.ExecuteLocalCommand
+	utils.ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
 		fileContents, err := os.ReadFile("testdata/crd_output.json")
 		assert.Nil(t, err)
-		return string(fileContents)
+		return string(fileContents), nil
 	}
 
 	for _, tc := range testCases {
@@ -104,7 +103,7 @@ func TestFindTestCrdNames(t *testing.T) {
 		}
 	}
 
-	utils.ExecuteCommandAndValidate = origFunc
+	utils.ExecuteLocalCommand = origFunc
 	jsonUnmarshal = json.Unmarshal
 }
 
@@ -198,13 +197,14 @@ func TestFindTestPodSetsByLabel(t *testing.T) {
 	for _, tc := range testCases {
 		// spoof the output from execCommandOutput
 		origFunc := execCommandOutput
-		execCommandOutput = func(command string) string {
+		execCommandOutput = func(command string) (string, error) {
 			output, err := os.ReadFile(tc.filename)
 			assert.Nil(t, err)
-			return string(output)
+			return string(output), nil
 		}
 
-		podsets := FindTestPodSetsByLabel(tc.targetLabels, tc.resourceTypeDeployment)
+		podsets, err := FindTestPodSetsByLabel(tc.targetLabels, tc.resourceTypeDeployment)
+		assert.Nil(t, err)
 
 		if len(tc.expectedPodSets) > 0 {
 			// Note: We are assuming that [0] is populated with the data we need.
@@ -219,6 +219,21 @@ func TestFindTestPodSetsByLabel(t *testing.T) {
 	}
 }
 
+func TestFindTestPodSetsByLabel_Error(t *testing.T) {
+	origFunc := execCommandOutput
+	defer func() {
+		execCommandOutput = origFunc
+	}()
+	execCommandOutput = func(command string) (string, error) {
+		return "", errors.New("oc not found")
+	}
+
+	podsets, err := FindTestPodSetsByLabel([]configsections.Label{{Name: "app", Value: "mydeploy"}}, string(configsections.Deployment))
+	assert.Nil(t, podsets)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "oc not found")
+}
+
 //nolint: funlen
 func TestGetCsvInstallPlanNames(t *testing.T) {
 	originalExecCommandOutput := execCommandOutput
@@ -231,23 +246,23 @@ func TestGetCsvInstallPlanNames(t *testing.T) {
 		csvNamespace                string
 		expectedError               string
 		expectedPlanNames           []string
-		mockedExecCommandOutputFunc func(cmd string) string
+		mockedExecCommandOutputFunc func(cmd string) (string, error)
 	}{
 		{
 			csvName:           "csvexample1",
 			csvNamespace:      "csvns1",
 			expectedError:     "",
 			expectedPlanNames: []string{"installPlan1"},
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return "installPlan1"
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "installPlan1", nil
 			},
 		},
 		{
 			csvName:           "csvexample1",
 			csvNamespace:      "csvns1",
 			expectedPlanNames: []string{"installPlan1", "installPlan2"},
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return "installPlan1\ninstallPlan2"
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "installPlan1\ninstallPlan2", nil
 			},
 		},
 		{
@@ -255,8 +270,8 @@ func TestGetCsvInstallPlanNames(t *testing.T) {
 			csvNamespace:      "csvns1",
 			expectedError:     "installplan not found",
 			expectedPlanNames: []string{},
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return ""
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "", nil
 			},
 		},
 	}
@@ -288,7 +303,7 @@ func TestGetInstallPlanData(t *testing.T) {
 		expectedBundleImage            string
 		expectedCatalogSourceName      string
 		expectedCatalogSourceNamespace string
-		mockedExecCommandOutputFunc    func(cmd string) string
+		mockedExecCommandOutputFunc    func(cmd string) (string, error)
 	}{
 		{
 			installPlanName:                "install-1",
@@ -297,8 +312,8 @@ func TestGetInstallPlanData(t *testing.T) {
 			expectedBundleImage:            "http://bundle-csvexample1-in-csvns1:sha",
 			expectedCatalogSourceName:      "catalogName1",
 			expectedCatalogSourceNamespace: "catalogNamespace1",
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return "http://bundle-csvexample1-in-csvns1:sha,catalogName1,catalogNamespace1"
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "http://bundle-csvexample1-in-csvns1:sha,catalogName1,catalogNamespace1", nil
 			},
 		},
 		{
@@ -308,8 +323,8 @@ func TestGetInstallPlanData(t *testing.T) {
 			expectedBundleImage:            "",
 			expectedCatalogSourceName:      "",
 			expectedCatalogSourceNamespace: "",
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return "invalid-output"
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "invalid-output", nil
 			},
 		},
 	}
@@ -342,15 +357,15 @@ func TestGetCatalogSourceImageIndex(t *testing.T) {
 		catalogNamespace            string
 		expectedError               string
 		expectedImageIndex          string
-		mockedExecCommandOutputFunc func(cmd string) string
+		mockedExecCommandOutputFunc func(cmd string) (string, error)
 	}{
 		{
 			catalogName:        "catalogName1",
 			catalogNamespace:   "ns1",
 			expectedError:      "",
 			expectedImageIndex: "http://index1-csvexample2-in-csvns2:sha",
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return "http://index1-csvexample2-in-csvns2:sha"
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "http://index1-csvexample2-in-csvns2:sha", nil
 			},
 		},
 		{
@@ -358,8 +373,8 @@ func TestGetCatalogSourceImageIndex(t *testing.T) {
 			catalogNamespace:   "ns1",
 			expectedError:      "",
 			expectedImageIndex: "",
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return "null"
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "null", nil
 			},
 		},
 		{
@@ -367,8 +382,8 @@ func TestGetCatalogSourceImageIndex(t *testing.T) {
 			catalogNamespace:   "ns3",
 			expectedError:      "failed to get index image for catalogsource catalogName3 (ns ns3)",
 			expectedImageIndex: "",
-			mockedExecCommandOutputFunc: func(cmd string) string {
-				return ""
+			mockedExecCommandOutputFunc: func(cmd string) (string, error) {
+				return "", nil
 			},
 		},
 	}
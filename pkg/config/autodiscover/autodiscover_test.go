@@ -109,9 +109,9 @@ func TestGetContainersByLabel(t *testing.T) {
 		executeOcGetAllCommand = origCommand
 	}()
 	for _, tc := range testCases {
-		executeOcGetAllCommand = func(resourceType, labelQuery string) string {
+		executeOcGetAllCommand = func(resourceType, labelQuery string) (string, error) {
 			file, _ := os.ReadFile(tc.filename)
-			return string(file)
+			return string(file), nil
 		}
 		containers, _ := getContainersByLabel(configsections.Label{
 			Prefix: tc.prefix,
@@ -183,9 +183,9 @@ func TestGetContainerIdentifiersByLabel(t *testing.T) {
 	}()
 
 	for _, tc := range testCases {
-		executeOcGetAllCommand = func(resourceType, labelQuery string) string {
+		executeOcGetAllCommand = func(resourceType, labelQuery string) (string, error) {
 			file, _ := os.ReadFile(tc.filename)
-			return string(file)
+			return string(file), nil
 		}
 
 		identifiers, err := getContainerIdentifiersByLabel(configsections.Label{
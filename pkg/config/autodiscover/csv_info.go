@@ -20,8 +20,6 @@ import (
 	"fmt"
 	"strings"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
 )
 
@@ -62,16 +60,28 @@ func (csv *CSVResource) GetAnnotationValue(annotationKey string, v interface{})
 	}
 	return err
 }
-func (csv *CSVResource) PackOrgVersion(subscription string) (org, packag, version string) {
+
+// PackOrgVersion returns the package, the catalog source and the version of the CSV installed by subscription.
+func (csv *CSVResource) PackOrgVersion(subscription string) (packag, org, version string, err error) {
 	ocCmd := fmt.Sprintf("oc get subscriptions.operators.coreos.com -A -o go-template='{{range .items}}{{if .status.installedCSV}}{{if eq .status.installedCSV %q}}{{.spec.source}} {{.status.currentCSV}}{{end}}{{end}}{{end}}'", subscription)
-	out := execCommandOutput(ocCmd)
+	out, err := execCommandOutput(ocCmd)
+	if err != nil {
+		return "", "", "", err
+	}
 	orgNameVer := strings.Split(out, " ")
+	if len(orgNameVer) < 2 { //nolint:gomnd // source and CSV
+		return "", "", "", fmt.Errorf("invalid subscription info: %s", out)
+	}
 	org = orgNameVer[0]
-	nameVersion := strings.SplitN(orgNameVer[1], ".", 2) //nolint:gomnd // ok
+	const nameVersionFields = 2
+	nameVersion := strings.SplitN(orgNameVer[1], ".", nameVersionFields)
+	if len(nameVersion) < nameVersionFields {
+		return "", "", "", fmt.Errorf("invalid CSV name: %s", orgNameVer[1])
+	}
 	packag = orgNameVer[1]
 	version = nameVersion[1]
 
-	return packag, org, version
+	return packag, org, version, nil
 }
 
 func (csv *CSVResource) annotationUnmarshalError(annotationKey string, err error) error {
@@ -82,33 +92,11 @@ func (csv *CSVResource) annotationUnmarshalError(annotationKey string, err error
 // GetCSVsByLabelByNamespace will return all CSVs with a given label value. If `labelValue` is an empty string, all CSVs with that
 // label will be returned, regardless of the labels value.
 func GetCSVsByLabelByNamespace(labelName, labelValue, namespace string) (*CSVList, error) {
-	out := executeOcGetCommand(resourceTypeCSV, buildLabelQuery(configsections.Label{Prefix: tnfLabelPrefix, Name: labelName, Value: labelValue}), namespace)
-
-	log.Debug("JSON output for all pods labeled with: ", labelName)
-	log.Debug("Command: ", out)
-
-	var csvList CSVList
-	err := jsonUnmarshal([]byte(out), &csvList)
-	if err != nil {
-		return nil, err
-	}
-
-	return &csvList, nil
+	return GetDiscoveryProvider().ListCSVs(namespace, configsections.Label{Prefix: tnfLabelPrefix, Name: labelName, Value: labelValue})
 }
 
 // GetCSVsByLabel will return all CSVs with a given label value. If `labelValue` is an empty string, all CSVs with that
 // label will be returned, regardless of the labels value.
 func GetCSVsByLabel(labelName, labelValue string) (*CSVList, error) {
-	out := executeOcGetAllCommand(resourceTypeCSV, buildLabelQuery(configsections.Label{Prefix: tnfLabelPrefix, Name: labelName, Value: labelValue}))
-
-	log.Debug("JSON output for all pods labeled with: ", labelName)
-	log.Debug("Command: ", out)
-
-	var csvList CSVList
-	err := jsonUnmarshal([]byte(out), &csvList)
-	if err != nil {
-		return nil, err
-	}
-
-	return &csvList, nil
+	return GetDiscoveryProvider().ListCSVs("", configsections.Label{Prefix: tnfLabelPrefix, Name: labelName, Value: labelValue})
 }
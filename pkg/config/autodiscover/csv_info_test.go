@@ -110,10 +110,10 @@ func TestGetCSVsByLabel(t *testing.T) {
 
 	for _, tc := range testCases {
 		origFunc := executeOcGetAllCommand
-		executeOcGetAllCommand = func(resourceType, labelQuery string) string {
+		executeOcGetAllCommand = func(resourceType, labelQuery string) (string, error) {
 			output, err := os.ReadFile(path.Join(filePath, tc.filename))
 			assert.Nil(t, err)
-			return string(output)
+			return string(output), nil
 		}
 
 		outputList, err := GetCSVsByLabel(tc.label, tc.value)
@@ -153,10 +153,10 @@ func TestGetCSVsByNamespace(t *testing.T) {
 
 	for _, tc := range testCases {
 		origFunc := executeOcGetAllCommand
-		executeOcGetCommand = func(resourceType, labelQuery, namespace string) string {
+		executeOcGetCommand = func(resourceType, labelQuery, namespace string) (string, error) {
 			output, err := os.ReadFile(path.Join(filePath, tc.filename))
 			assert.Nil(t, err)
-			return string(output)
+			return string(output), nil
 		}
 
 		outputList, err := GetCSVsByLabelByNamespace(tc.label, tc.value, "testnamespace")
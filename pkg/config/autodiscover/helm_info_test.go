@@ -25,7 +25,6 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/utils"
 )
 
@@ -53,7 +52,7 @@ func TestGetClusterHelmCharts(t *testing.T) {
 
 	for _, tc := range testCases {
 		// Setup the mock functions
-		utils.ExecuteCommand = func(command string, timeout time.Duration, context *interactive.Context) (string, error) {
+		utils.ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
 			contents, err := os.ReadFile(testHelmChartPath)
 			assert.Nil(t, err)
 			return string(contents), nil
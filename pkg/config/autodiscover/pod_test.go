@@ -31,6 +31,7 @@ func TestBuildPodUnderTest(t *testing.T) {
 
 	assert.Equal(t, "tnf", orchestratorPod.Namespace)
 	assert.Equal(t, "I'mAPodName", orchestratorPod.Name)
+	assert.Equal(t, []string{"I'mAContainer"}, orchestratorPod.ContainerNames)
 	assert.NotEqual(t, "I'mAContainer", orchestratorPod.Name)
 	// no tests set on pod and the config file will not be loaded from the unit test context: no tests should be set.
 	assert.Equal(t, []string{}, orchestratorPod.Tests)
@@ -24,16 +24,17 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
-	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/utils"
 )
 
 var (
 	jsonUnmarshal     = json.Unmarshal
-	execCommandOutput = func(command string) string {
-		return utils.ExecuteCommandAndValidate(command, ocCommandTimeOut, interactive.GetContext(expectersVerboseModeEnabled), func() {
+	execCommandOutput = func(command string) (string, error) {
+		out, err := utils.ExecuteLocalCommand(command, ocCommandTimeOut)
+		if err != nil {
 			log.Error("can't run command: ", command)
-		})
+		}
+		return out, err
 	}
 )
 
@@ -75,12 +76,21 @@ func (podset *PodSetResource) GetReplicas() int {
 func (podset *PodSetResource) GetLabels() map[string]string {
 	return podset.Metadata.Labels
 }
-func (podset *PodSetResource) GetHpa() configsections.Hpa {
+
+// GetHpa returns the horizontal pod autoscaler of the podset, if any.
+func (podset *PodSetResource) GetHpa() (configsections.Hpa, error) {
 	template := fmt.Sprintf("go-template='{{ range .items }}{{ if eq .spec.scaleTargetRef.name %q }}{{.spec.minReplicas}},{{.spec.maxReplicas}},{{.metadata.name}}{{ end }}{{ end }}'", podset.GetName())
 	ocCmd := fmt.Sprintf("oc get hpa -n %s -o %s", podset.GetNamespace(), template)
-	out := execCommandOutput(ocCmd)
+	out, err := execCommandOutput(ocCmd)
+	if err != nil {
+		return configsections.Hpa{}, err
+	}
 	if out != "" {
+		const hpaNumFields = 3
 		out := strings.Split(out, ",")
+		if len(out) < hpaNumFields {
+			return configsections.Hpa{}, fmt.Errorf("invalid hpa info: %s", strings.Join(out, ","))
+		}
 		min, _ := strconv.Atoi(out[0])
 		max, _ := strconv.Atoi(out[1])
 		hpaNmae := out[2]
@@ -88,41 +98,17 @@ func (podset *PodSetResource) GetHpa() configsections.Hpa {
 			MinReplicas: min,
 			MaxReplicas: max,
 			HpaName:     hpaNmae,
-		}
+		}, nil
 	}
-	return configsections.Hpa{}
+	return configsections.Hpa{}, nil
 }
 
 // GetTargetPodSetsByNamespace will return all podsets(deployments/statefulset )that have pods with a given label.
 func GetTargetPodSetsByNamespace(namespace string, targetLabel configsections.Label, resourceTypePodSet string) (*PodSetList, error) {
-	labelQuery := fmt.Sprintf("%q==%q", buildLabelName(targetLabel.Prefix, targetLabel.Name), targetLabel.Value)
-	jqArgs := fmt.Sprintf("'[.items[] | select(.spec.template.metadata.labels.%s)]'", labelQuery)
-	ocCmd := fmt.Sprintf("oc get %s -n %s -o json | jq %s", resourceTypePodSet, namespace, jqArgs)
-
-	out := execCommandOutput(ocCmd)
-
-	var podsetList PodSetList
-	err := jsonUnmarshal([]byte(out), &podsetList.Items)
-	if err != nil {
-		return nil, err
-	}
-
-	return &podsetList, nil
+	return GetDiscoveryProvider().ListPodSets(namespace, resourceTypePodSet, targetLabel)
 }
 
-// GetTargetDeploymentsByLabel will return all deployments/statefulsets that have pods with a given label.
+// GetTargetPodSetsByLabel will return all deployments/statefulsets that have pods with a given label.
 func GetTargetPodSetsByLabel(targetLabel configsections.Label, resourceTypePodSet string) (*PodSetList, error) {
-	labelQuery := fmt.Sprintf("%q==%q", buildLabelName(targetLabel.Prefix, targetLabel.Name), targetLabel.Value)
-	jqArgs := fmt.Sprintf("'[.items[] | select(.spec.template.metadata.labels.%s)]'", labelQuery)
-	ocCmd := fmt.Sprintf("oc get %s -A -o json | jq %s", resourceTypePodSet, jqArgs)
-
-	out := execCommandOutput(ocCmd)
-
-	var podsetList PodSetList
-	err := jsonUnmarshal([]byte(out), &podsetList.Items)
-	if err != nil {
-		return nil, err
-	}
-
-	return &podsetList, nil
+	return GetDiscoveryProvider().ListPodSets("", resourceTypePodSet, targetLabel)
 }
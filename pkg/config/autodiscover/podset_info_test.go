@@ -93,14 +93,14 @@ func TestGetTargetDeploymentByNamespace(t *testing.T) {
 	for _, tc := range testCases {
 		// Setup the mock functions
 		if tc.badExec {
-			execCommandOutput = func(command string) string {
-				return ""
+			execCommandOutput = func(command string) (string, error) {
+				return "", errors.New("this is an error")
 			}
 		} else {
-			execCommandOutput = func(command string) string {
+			execCommandOutput = func(command string) (string, error) {
 				contents, err := os.ReadFile(testJQFilePath)
 				assert.Nil(t, err)
-				return string(contents)
+				return string(contents), nil
 			}
 		}
 		if tc.badJSONUnmarshal {
@@ -0,0 +1,204 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package autodiscover
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
+	"github.com/test-network-function/test-network-function/pkg/utils"
+)
+
+const (
+	// discoveryBackendEnvVar selects the DiscoveryProvider used by autodiscovery.
+	discoveryBackendEnvVar = "TNF_AUTODISCOVER_BACKEND"
+	// DiscoveryBackendOc lists cluster resources by running `oc get ... -o json` in a local shell.
+	DiscoveryBackendOc = "oc"
+	// DiscoveryBackendClient lists cluster resources through the Kubernetes API using client-go.
+	DiscoveryBackendClient = "client"
+)
+
+// DiscoveryProvider lists the cluster resources autodiscovery is built on.  An empty namespace means all namespaces.
+// The returned lists use the same `PodResource`/`PodSetResource`/`CSVResource` types regardless of the backend, so the
+// builders on top of them do not need to know where the data came from.
+type DiscoveryProvider interface {
+	// ListPods returns the pods matching label.
+	ListPods(namespace string, label configsections.Label) (*PodList, error)
+
+	// ListPodSets returns the deployments or statefulsets (resourceType) whose pod template matches label.
+	ListPodSets(namespace, resourceType string, label configsections.Label) (*PodSetList, error)
+
+	// ListCSVs returns the ClusterServiceVersions matching label.
+	ListCSVs(namespace string, label configsections.Label) (*CSVList, error)
+
+	// ListCrdNames returns the names of all the CustomResourceDefinitions in the cluster.
+	ListCrdNames() ([]string, error)
+
+	// ListHelmCharts returns the deployed helm releases in all namespaces.
+	ListHelmCharts() (*HelmSetList, error)
+}
+
+// discoveryProvider is the DiscoveryProvider used by autodiscovery, see GetDiscoveryProvider.
+var discoveryProvider DiscoveryProvider
+
+// SetDiscoveryProvider overrides the DiscoveryProvider used by autodiscovery.
+func SetDiscoveryProvider(provider DiscoveryProvider) {
+	discoveryProvider = provider
+}
+
+// GetDiscoveryProvider returns the DiscoveryProvider used by autodiscovery.  Unless one was set with
+// SetDiscoveryProvider, it is chosen from the TNF_AUTODISCOVER_BACKEND environment variable, defaulting to the oc
+// backend.  If the client backend cannot be configured, autodiscovery falls back to the oc backend.
+func GetDiscoveryProvider() DiscoveryProvider {
+	if discoveryProvider != nil {
+		return discoveryProvider
+	}
+	backend := strings.ToLower(os.Getenv(discoveryBackendEnvVar))
+	if snapshot.IsActive() && backend != "" && backend != DiscoveryBackendOc {
+		// Snapshots only hold the commands run in the interactive sessions.
+		log.Warnf("%s discovery backend is not supported with snapshots, using %s", backend, DiscoveryBackendOc)
+		backend = DiscoveryBackendOc
+	}
+	switch backend {
+	case DiscoveryBackendClient:
+		provider, err := NewClientDiscoveryProviderFromKubeconfig()
+		if err != nil {
+			log.Errorf("unable to create the %s discovery backend (error: %s), falling back to %s", DiscoveryBackendClient, err, DiscoveryBackendOc)
+			discoveryProvider = &ocDiscoveryProvider{}
+		} else {
+			discoveryProvider = provider
+		}
+	case "", DiscoveryBackendOc:
+		discoveryProvider = &ocDiscoveryProvider{}
+	default:
+		log.Warnf("unknown %s value %q, using the %s discovery backend", discoveryBackendEnvVar, backend, DiscoveryBackendOc)
+		discoveryProvider = &ocDiscoveryProvider{}
+	}
+	return discoveryProvider
+}
+
+// ocDiscoveryProvider is the DiscoveryProvider running `oc`/`kubectl`/`helm` commands in a local shell.
+type ocDiscoveryProvider struct{}
+
+// ListPods runs `oc get pods -o json` for the label in the given namespace, or in all of them.
+func (p *ocDiscoveryProvider) ListPods(namespace string, label configsections.Label) (*PodList, error) {
+	var out string
+	var err error
+	if namespace == "" {
+		out, err = executeOcGetAllCommand(resourceTypePods, buildLabelQuery(label))
+	} else {
+		out, err = executeOcGetCommand(resourceTypePods, buildLabelQuery(label), namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("JSON output for all pods labeled with: ", label)
+	log.Debug("Command: ", out)
+
+	var podList PodList
+	err = jsonUnmarshal([]byte(out), &podList)
+	if err != nil {
+		return nil, err
+	}
+	return &podList, nil
+}
+
+// ListPodSets runs `oc get <resourceType> -o json` piped into a jq filter on the pod template labels.
+func (p *ocDiscoveryProvider) ListPodSets(namespace, resourceType string, label configsections.Label) (*PodSetList, error) {
+	labelQuery := fmt.Sprintf("%q==%q", buildLabelName(label.Prefix, label.Name), label.Value)
+	jqArgs := fmt.Sprintf("'[.items[] | select(.spec.template.metadata.labels.%s)]'", labelQuery)
+	namespaceArg := "-A"
+	if namespace != "" {
+		namespaceArg = "-n " + namespace
+	}
+	ocCmd := fmt.Sprintf("oc get %s %s -o json | jq %s", resourceType, namespaceArg, jqArgs)
+
+	out, err := execCommandOutput(ocCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var podsetList PodSetList
+	err = jsonUnmarshal([]byte(out), &podsetList.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &podsetList, nil
+}
+
+// ListCSVs runs `oc get csv -o json` for the label in the given namespace, or in all of them.
+func (p *ocDiscoveryProvider) ListCSVs(namespace string, label configsections.Label) (*CSVList, error) {
+	var out string
+	var err error
+	if namespace == "" {
+		out, err = executeOcGetAllCommand(resourceTypeCSV, buildLabelQuery(label))
+	} else {
+		out, err = executeOcGetCommand(resourceTypeCSV, buildLabelQuery(label), namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("JSON output for all CSVs labeled with: ", label)
+	log.Debug("Command: ", out)
+
+	var csvList CSVList
+	err = jsonUnmarshal([]byte(out), &csvList)
+	if err != nil {
+		return nil, err
+	}
+	return &csvList, nil
+}
+
+// ListCrdNames runs `kubectl get crd -o json` and extracts the names with jq.
+func (p *ocDiscoveryProvider) ListCrdNames() ([]string, error) {
+	out, err := utils.ExecuteLocalCommand(ocGetClusterCrdNamesCommand, ocCommandTimeOut)
+	if err != nil {
+		log.Error("can't run command: ", ocGetClusterCrdNamesCommand)
+		return nil, err
+	}
+
+	var crdNamesList []string
+	err = jsonUnmarshal([]byte(out), &crdNamesList)
+	if err != nil {
+		return nil, err
+	}
+	return crdNamesList, nil
+}
+
+// ListHelmCharts runs `helm list -A -o json`.
+func (p *ocDiscoveryProvider) ListHelmCharts() (*HelmSetList, error) {
+	var helmList HelmSetList
+
+	out, err := utils.ExecuteLocalCommand("helm list -A -o json", ocCommandTimeOut)
+	if err != nil {
+		return &helmList, err
+	}
+
+	if out != "" {
+		err := jsonUnmarshal([]byte(out), &helmList.Items)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &helmList, nil
+}
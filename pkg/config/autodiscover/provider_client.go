@@ -0,0 +1,390 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package autodiscover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// listPageSize is the number of objects requested per page from the API server.
+	listPageSize = 500
+	// helmReleaseSelector selects the secrets helm v3 stores its releases in.
+	helmReleaseSelector   = "owner=helm"
+	helmReleaseKey        = "release"
+	helmReleaseVersionKey = "version"
+	helmStatusDeployed    = "deployed"
+	helmStatusFailed      = "failed"
+	resourceTypeCRD       = "customresourcedefinitions"
+	resourceTypeSecrets   = "secrets"
+)
+
+var (
+	csvGVR = schema.GroupVersionResource{Group: "operators.coreos.com", Version: "v1alpha1", Resource: "clusterserviceversions"}
+	crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: resourceTypeCRD}
+	// gzipMagic is the header helm uses to tell compressed releases from plain JSON ones.
+	gzipMagic = []byte{0x1f, 0x8b, 0x08}
+)
+
+// APIError is returned by the client-go DiscoveryProvider when a request to the API server fails.
+type APIError struct {
+	// Resource is the type of the objects being listed.
+	Resource string
+	// Namespace is the namespace being listed, empty for all namespaces.
+	Namespace string
+	// Reason is the machine readable reason reported by the API server, if any.
+	Reason metav1.StatusReason
+	// Code is the HTTP status code reported by the API server, if any.
+	Code int32
+	// Err is the underlying client-go error.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	namespace := e.Namespace
+	if namespace == "" {
+		namespace = "all namespaces"
+	}
+	return fmt.Sprintf("unable to list %s in %s (reason: %s, code: %d): %s", e.Resource, namespace, e.Reason, e.Code, e.Err)
+}
+
+// Unwrap returns the underlying client-go error.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func newAPIError(resource, namespace string, err error) *APIError {
+	apiErr := &APIError{Resource: resource, Namespace: namespace, Reason: apierrors.ReasonForError(err), Err: err}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		apiErr.Code = status.Status().Code
+	}
+	return apiErr
+}
+
+// ClientDiscoveryProvider is the DiscoveryProvider using the Kubernetes API through client-go.  Typed objects are
+// listed page by page with server side label selectors and converted to the autodiscovery resource types.
+type ClientDiscoveryProvider struct {
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+}
+
+// NewClientDiscoveryProvider creates a ClientDiscoveryProvider from existing clients.  Operator and CRD resources are
+// listed through the dynamic client.
+func NewClientDiscoveryProvider(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *ClientDiscoveryProvider {
+	return &ClientDiscoveryProvider{clientset: clientset, dynamicClient: dynamicClient}
+}
+
+// NewClientDiscoveryProviderFromKubeconfig creates a ClientDiscoveryProvider using the kubeconfig from the KUBECONFIG
+// environment variable or the default location, or the in-cluster configuration if there is none.
+func NewClientDiscoveryProviderFromKubeconfig() (*ClientDiscoveryProvider, error) {
+	restConfig, err := LoadRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientDiscoveryProvider(clientset, dynamicClient), nil
+}
+
+// LoadRestConfig returns the client configuration from the kubeconfig of the KUBECONFIG environment variable or the
+// default location, or the in-cluster configuration if there is none.
+func LoadRestConfig() (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		var inClusterErr error
+		restConfig, inClusterErr = rest.InClusterConfig()
+		if inClusterErr != nil {
+			return nil, fmt.Errorf("unable to load kubeconfig (%s) or in-cluster config (%s)", err, inClusterErr)
+		}
+	}
+	return restConfig, nil
+}
+
+// listPages calls list with an increasing continue token until the server reports there are no more pages.
+func listPages(list func(opts metav1.ListOptions) (string, error), labelSelector string) error {
+	opts := metav1.ListOptions{LabelSelector: labelSelector, Limit: listPageSize}
+	for {
+		next, err := list(opts)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		opts.Continue = next
+	}
+}
+
+// convertObject converts a typed or unstructured object to one of the autodiscovery resource types.
+func convertObject(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), ocCommandTimeOut)
+}
+
+// ListPods lists the pods matching label.
+func (p *ClientDiscoveryProvider) ListPods(namespace string, label configsections.Label) (*PodList, error) {
+	podList := &PodList{}
+	err := listPages(func(opts metav1.ListOptions) (string, error) {
+		ctx, cancel := requestContext()
+		defer cancel()
+		pods, err := p.clientset.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			return "", newAPIError(resourceTypePods, namespace, err)
+		}
+		for i := range pods.Items {
+			pod := &PodResource{}
+			if err := convertObject(&pods.Items[i], pod); err != nil {
+				return "", err
+			}
+			podList.Items = append(podList.Items, pod)
+		}
+		return pods.Continue, nil
+	}, buildLabelQuery(label))
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("listed %d pods labeled with %v", len(podList.Items), label)
+	return podList, nil
+}
+
+// ListPodSets lists the deployments or statefulsets whose pod template matches label.  The pod template labels cannot
+// be selected server side, so every object of the resource type is listed and filtered.
+func (p *ClientDiscoveryProvider) ListPodSets(namespace, resourceType string, label configsections.Label) (*PodSetList, error) {
+	labelName := buildLabelName(label.Prefix, label.Name)
+	podsetList := &PodSetList{}
+	appendPodSet := func(obj interface{}, templateLabels map[string]string) error {
+		if value, ok := templateLabels[labelName]; !ok || value != label.Value {
+			return nil
+		}
+		podset := PodSetResource{}
+		if err := convertObject(obj, &podset); err != nil {
+			return err
+		}
+		podsetList.Items = append(podsetList.Items, podset)
+		return nil
+	}
+
+	var list func(opts metav1.ListOptions) (string, error)
+	switch resourceType {
+	case string(configsections.Deployment):
+		list = func(opts metav1.ListOptions) (string, error) {
+			ctx, cancel := requestContext()
+			defer cancel()
+			deployments, err := p.clientset.AppsV1().Deployments(namespace).List(ctx, opts)
+			if err != nil {
+				return "", newAPIError(resourceType, namespace, err)
+			}
+			for i := range deployments.Items {
+				if err := appendPodSet(&deployments.Items[i], deployments.Items[i].Spec.Template.Labels); err != nil {
+					return "", err
+				}
+			}
+			return deployments.Continue, nil
+		}
+	case string(configsections.StateFulSet):
+		list = func(opts metav1.ListOptions) (string, error) {
+			ctx, cancel := requestContext()
+			defer cancel()
+			statefulSets, err := p.clientset.AppsV1().StatefulSets(namespace).List(ctx, opts)
+			if err != nil {
+				return "", newAPIError(resourceType, namespace, err)
+			}
+			for i := range statefulSets.Items {
+				if err := appendPodSet(&statefulSets.Items[i], statefulSets.Items[i].Spec.Template.Labels); err != nil {
+					return "", err
+				}
+			}
+			return statefulSets.Continue, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported podset resource type %q", resourceType)
+	}
+
+	if err := listPages(list, ""); err != nil {
+		return nil, err
+	}
+	return podsetList, nil
+}
+
+// ListCSVs lists the ClusterServiceVersions matching label.
+func (p *ClientDiscoveryProvider) ListCSVs(namespace string, label configsections.Label) (*CSVList, error) {
+	csvList := &CSVList{}
+	err := listPages(func(opts metav1.ListOptions) (string, error) {
+		ctx, cancel := requestContext()
+		defer cancel()
+		csvs, err := p.dynamicClient.Resource(csvGVR).Namespace(namespace).List(ctx, opts)
+		if err != nil {
+			return "", newAPIError(resourceTypeCSV, namespace, err)
+		}
+		for i := range csvs.Items {
+			csv := CSVResource{}
+			if err := convertObject(csvs.Items[i].Object, &csv); err != nil {
+				return "", err
+			}
+			csvList.Items = append(csvList.Items, csv)
+		}
+		return csvs.GetContinue(), nil
+	}, buildLabelQuery(label))
+	if err != nil {
+		return nil, err
+	}
+	return csvList, nil
+}
+
+// ListCrdNames lists the names of all the CustomResourceDefinitions in the cluster.
+func (p *ClientDiscoveryProvider) ListCrdNames() ([]string, error) {
+	var crdNames []string
+	err := listPages(func(opts metav1.ListOptions) (string, error) {
+		ctx, cancel := requestContext()
+		defer cancel()
+		crds, err := p.dynamicClient.Resource(crdGVR).List(ctx, opts)
+		if err != nil {
+			return "", newAPIError(resourceTypeCRD, "", err)
+		}
+		for i := range crds.Items {
+			crdNames = append(crdNames, crds.Items[i].GetName())
+		}
+		return crds.GetContinue(), nil
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	return crdNames, nil
+}
+
+// helmRelease holds the fields of a helm v3 release needed to build a HelmChart.
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// decodeHelmRelease decodes the release helm v3 stores, base64 encoded and usually gzipped, in its secrets.
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		decoded, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	release := &helmRelease{}
+	err = json.Unmarshal(decoded, release)
+	return release, err
+}
+
+// ListHelmCharts lists the latest revision of every helm release, keeping the ones `helm list` shows by default.
+func (p *ClientDiscoveryProvider) ListHelmCharts() (*HelmSetList, error) {
+	latest := make(map[string]*helmRelease)
+	err := listPages(func(opts metav1.ListOptions) (string, error) {
+		ctx, cancel := requestContext()
+		defer cancel()
+		secrets, err := p.clientset.CoreV1().Secrets("").List(ctx, opts)
+		if err != nil {
+			return "", newAPIError(resourceTypeSecrets, "", err)
+		}
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			release, err := decodeHelmRelease(secret.Data[helmReleaseKey])
+			if err != nil {
+				log.Warnf("unable to decode helm release secret %s/%s: %s", secret.Namespace, secret.Name, err)
+				continue
+			}
+			if release.Version == 0 {
+				release.Version, _ = strconv.Atoi(secret.Labels[helmReleaseVersionKey])
+			}
+			key := release.Namespace + "/" + release.Name
+			if prev, ok := latest[key]; !ok || prev.Version < release.Version {
+				latest[key] = release
+			}
+		}
+		return secrets.Continue, nil
+	}, helmReleaseSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	helmList := &HelmSetList{}
+	for _, release := range latest {
+		if release.Info.Status != helmStatusDeployed && release.Info.Status != helmStatusFailed {
+			continue
+		}
+		helmList.Items = append(helmList.Items, HelmChart{
+			Name:      release.Name,
+			Namespace: release.Namespace,
+			Chart:     release.Chart.Metadata.Name + "-" + release.Chart.Metadata.Version,
+		})
+	}
+	sort.Slice(helmList.Items, func(i, j int) bool {
+		if helmList.Items[i].Namespace != helmList.Items[j].Namespace {
+			return helmList.Items[i].Namespace < helmList.Items[j].Namespace
+		}
+		return helmList.Items[i].Name < helmList.Items[j].Name
+	})
+	return helmList, nil
+}
@@ -0,0 +1,228 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package autodiscover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var testTargetLabel = configsections.Label{Prefix: "test-network-function.com", Name: "generic", Value: "target"}
+
+func newTestPod(name, namespace string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName:           "worker-0",
+			ServiceAccountName: "test-sa",
+			Containers:         []corev1.Container{{Name: "test", Image: "quay.io/testnetworkfunction/cnf-test-partner:latest"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			PodIPs:            []corev1.PodIP{{IP: "10.0.0.1"}},
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "test", ContainerID: "cri-o://abcd"}},
+		},
+	}
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		csvGVR: "ClusterServiceVersionList",
+		crdGVR: "CustomResourceDefinitionList",
+	}, objects...)
+}
+
+func newUnstructured(apiVersion, kind, name, namespace string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestClientDiscoveryProviderListPods(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newTestPod("target", "tnf", map[string]string{"test-network-function.com/generic": "target"}),
+		newTestPod("other", "tnf", map[string]string{"app": "other"}),
+		newTestPod("target", "other-ns", map[string]string{"test-network-function.com/generic": "target"}),
+	)
+	provider := NewClientDiscoveryProvider(clientset, newFakeDynamicClient())
+
+	pods, err := provider.ListPods("tnf", testTargetLabel)
+	assert.Nil(t, err)
+	assert.Len(t, pods.Items, 1)
+
+	pods, err = provider.ListPods("", testTargetLabel)
+	assert.Nil(t, err)
+	assert.Len(t, pods.Items, 2)
+
+	// The builders work on top of the converted objects.
+	pod := pods.Items[0]
+	assert.Equal(t, "target", pod.Metadata.Name)
+	assert.Equal(t, "test-sa", pod.Spec.ServiceAccount)
+	assert.Equal(t, []string{"10.0.0.1"}, pod.getDefaultPodIPAddresses())
+	containers := buildContainers(pod)
+	assert.Len(t, containers, 1)
+	assert.Equal(t, "abcd", containers[0].ContainerUID)
+	assert.Equal(t, "cri-o", containers[0].ContainerRuntime)
+	assert.Equal(t, "cnf-test-partner", containers[0].ImageSource.Name)
+}
+
+func TestClientDiscoveryProviderPagination(t *testing.T) {
+	labels := map[string]string{"test-network-function.com/generic": "target"}
+	pages := []*corev1.PodList{
+		{ListMeta: metav1.ListMeta{Continue: "page2"}, Items: []corev1.Pod{*newTestPod("pod-1", "tnf", labels)}},
+		{Items: []corev1.Pod{*newTestPod("pod-2", "tnf", labels)}},
+	}
+	calls := 0
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		page := pages[calls]
+		calls++
+		return true, page, nil
+	})
+
+	pods, err := NewClientDiscoveryProvider(clientset, newFakeDynamicClient()).ListPods("tnf", testTargetLabel)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, pods.Items, 2)
+	assert.Equal(t, "pod-2", pods.Items[1].Metadata.Name)
+}
+
+func TestClientDiscoveryProviderAPIError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("no access"))
+	})
+
+	_, err := NewClientDiscoveryProvider(clientset, newFakeDynamicClient()).ListPods("tnf", testTargetLabel)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, metav1.StatusReasonForbidden, apiErr.Reason)
+	assert.Equal(t, int32(403), apiErr.Code)
+	assert.Equal(t, "tnf", apiErr.Namespace)
+	assert.True(t, apierrors.IsForbidden(err))
+}
+
+func TestClientDiscoveryProviderListPodSets(t *testing.T) {
+	newDeployment := func(name string, templateLabels map[string]string) *appsv1.Deployment {
+		replicas := int32(2)
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tnf"}}
+		deployment.Spec.Replicas = &replicas
+		deployment.Spec.Template.Labels = templateLabels
+		return deployment
+	}
+	clientset := fake.NewSimpleClientset(
+		newDeployment("target", map[string]string{"test-network-function.com/generic": "target"}),
+		newDeployment("wrong-value", map[string]string{"test-network-function.com/generic": "other"}),
+		newDeployment("unlabeled", nil),
+	)
+	provider := NewClientDiscoveryProvider(clientset, newFakeDynamicClient())
+
+	podsets, err := provider.ListPodSets("", string(configsections.Deployment), testTargetLabel)
+	assert.Nil(t, err)
+	assert.Len(t, podsets.Items, 1)
+	assert.Equal(t, "target", podsets.Items[0].GetName())
+	assert.Equal(t, 2, podsets.Items[0].GetReplicas())
+
+	podsets, err = provider.ListPodSets("", string(configsections.StateFulSet), testTargetLabel)
+	assert.Nil(t, err)
+	assert.Empty(t, podsets.Items)
+
+	_, err = provider.ListPodSets("", "daemonset", testTargetLabel)
+	assert.NotNil(t, err)
+}
+
+func TestClientDiscoveryProviderListCSVsAndCRDs(t *testing.T) {
+	dynamicClient := newFakeDynamicClient(
+		newUnstructured("operators.coreos.com/v1alpha1", "ClusterServiceVersion", "etcdoperator.v0.9.4", "tnf",
+			map[string]string{"test-network-function.com/operator": "target"}),
+		newUnstructured("operators.coreos.com/v1alpha1", "ClusterServiceVersion", "unlabeled.v1.0.0", "tnf", nil),
+		newUnstructured("apiextensions.k8s.io/v1", "CustomResourceDefinition", "crdexamples.test-network-function.com", "", nil),
+	)
+	provider := NewClientDiscoveryProvider(fake.NewSimpleClientset(), dynamicClient)
+
+	csvs, err := provider.ListCSVs("", configsections.Label{Prefix: tnfLabelPrefix, Name: operatorLabelName, Value: anyLabelValue})
+	assert.Nil(t, err)
+	assert.Len(t, csvs.Items, 1)
+	assert.Equal(t, "etcdoperator.v0.9.4", csvs.Items[0].Metadata.Name)
+
+	crdNames, err := provider.ListCrdNames()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"crdexamples.test-network-function.com"}, crdNames)
+}
+
+func newHelmReleaseSecret(t *testing.T, name, version, status string) *corev1.Secret {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(`{"name":"my-test1","namespace":"tnf","version":` + version +
+		`,"info":{"status":"` + status + `"},"chart":{"metadata":{"name":"my-test1","version":"1.13.` + version + `"}}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tnf", Labels: map[string]string{"owner": "helm", "version": version}},
+		Data:       map[string][]byte{helmReleaseKey: []byte(base64.StdEncoding.EncodeToString(compressed.Bytes()))},
+	}
+}
+
+func TestClientDiscoveryProviderListHelmCharts(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		newHelmReleaseSecret(t, "sh.helm.release.v1.my-test1.v1", "1", "superseded"),
+		newHelmReleaseSecret(t, "sh.helm.release.v1.my-test1.v2", "2", "deployed"),
+	)
+	charts, err := NewClientDiscoveryProvider(clientset, newFakeDynamicClient()).ListHelmCharts()
+	assert.Nil(t, err)
+	assert.Equal(t, []HelmChart{{Name: "my-test1", Namespace: "tnf", Chart: "my-test1-1.13.2"}}, charts.Items)
+
+	name, version := getHelmNameVersion(charts.Items[0].Chart)
+	assert.Equal(t, "my-test1", name)
+	assert.Equal(t, "1.13.2", version)
+}
+
+func TestGetPodsByLabelWithDiscoveryProvider(t *testing.T) {
+	defer SetDiscoveryProvider(nil)
+	terminating := newTestPod("terminating", "tnf", map[string]string{"test-network-function.com/generic": "target"})
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+	SetDiscoveryProvider(NewClientDiscoveryProvider(fake.NewSimpleClientset(
+		newTestPod("target", "tnf", map[string]string{"test-network-function.com/generic": "target"}),
+		terminating,
+	), newFakeDynamicClient()))
+
+	pods, err := GetPodsByLabel(testTargetLabel)
+	assert.Nil(t, err)
+	assert.Len(t, pods.Items, 1)
+	assert.Equal(t, "target", pods.Items[0].Metadata.Name)
+}
@@ -17,15 +17,19 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
-	"github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/config/autodiscover"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/parallel"
 	"gopkg.in/yaml.v2"
 )
 
@@ -39,6 +43,9 @@ var (
 	// testEnvironment is the singleton instance of `TestEnvironment`, accessed through `GetTestEnvironment`
 	testEnvironment             TestEnvironment
 	expectersVerboseModeEnabled = false
+
+	// errConfigurationFile is the error of Load when the configuration file cannot be loaded.
+	errConfigurationFile = errors.New("unable to load configuration file")
 )
 
 // getConfigurationFilePathFromEnvironment returns the test configuration file.
@@ -47,9 +54,17 @@ func getConfigurationFilePathFromEnvironment() string {
 	if environmentSourcedConfigurationFilePath != "" {
 		return environmentSourcedConfigurationFilePath
 	}
+	if snapshotConfigurationFilePath := snapshot.ConfigFilePath(); snapshotConfigurationFilePath != "" {
+		return snapshotConfigurationFilePath
+	}
 	return defaultConfigurationFilePath
 }
 
+// GetConfigurationFilePath returns the path of the test configuration file used by the current run.
+func GetConfigurationFilePath() string {
+	return getConfigurationFilePathFromEnvironment()
+}
+
 type NodeConfig struct {
 	// same Name as the one inside Node structure
 	Name string
@@ -105,29 +120,54 @@ type TestEnvironment struct {
 	loaded bool
 	// set when an intrusive test has done something that would cause Pod/Container to be recreated
 	needsRefresh bool
-	// context for executing command in local shell
-	localShell *interactive.Context
+	// sessions holds the sessions to the local shell, the containers under test and the debug pods, shared by the
+	// tests and by the workers of the parallel runners
+	sessions *interactive.SessionPool
 }
 
-func (env *TestEnvironment) GetLocalShellContext() *interactive.Context {
-	if env.localShell == nil {
-		context, err := interactive.SpawnShell(interactive.CreateGoExpectSpawner(), DefaultTimeout, interactive.Verbose(expectersVerboseModeEnabled), interactive.SendTimeout(DefaultTimeout))
-		gomega.Expect(err).To(gomega.BeNil())
-		gomega.Expect(context).ToNot(gomega.BeNil())
-		gomega.Expect(context.GetExpecter()).ToNot(gomega.BeNil())
-		env.localShell = context
+// LocalShellContext returns the shared session to the local shell, or an error if it could not be started.
+func (env *TestEnvironment) LocalShellContext() (*interactive.Context, error) {
+	context, err := env.getSessions().Get(interactive.LocalTarget())
+	if err != nil {
+		return nil, err
+	}
+	if context == nil || context.GetExpecter() == nil {
+		return nil, errors.New("no session to the local shell")
 	}
-	return env.localShell
+	return context, nil
+}
+
+// GetLocalShellContext returns the shared session to the local shell, and fails the test if it could not be started.
+func (env *TestEnvironment) GetLocalShellContext() *interactive.Context {
+	context, err := env.LocalShellContext()
+	tnf.FailOnError(err)
+	return context
 }
 
 func (env *TestEnvironment) CloseLocalShellContext() {
-	if env.localShell != nil {
-		err := (*env.localShell.GetExpecter()).Close()
-		if err != nil {
-			log.Warnf("Failed to close local shell context due to %v", err)
+	if env.sessions != nil {
+		env.sessions.Remove(interactive.LocalTarget())
+	}
+}
+
+// getSessions returns the pool of the shared sessions.  The liveness probes are disabled while a snapshot is recorded
+// or replayed, since they would not match between the two.
+func (env *TestEnvironment) getSessions() *interactive.SessionPool {
+	if env.sessions == nil {
+		env.sessions = interactive.NewSessionPool(DefaultTimeout, interactive.Verbose(expectersVerboseModeEnabled), interactive.SendTimeout(DefaultTimeout))
+		if snapshot.IsActive() {
+			env.sessions.ProbeInterval = 0
 		}
-		env.localShell = nil
 	}
+	return env.sessions
+}
+
+// GetSessionReconnections returns how many times the shared sessions were reconnected, by target.
+func (env *TestEnvironment) GetSessionReconnections() map[string]int {
+	if env.sessions == nil {
+		return map[string]int{}
+	}
+	return env.sessions.Reconnections()
 }
 
 // loadConfigFromFile loads a config file once.
@@ -150,20 +190,56 @@ func (env *TestEnvironment) loadConfigFromFile(filePath string) error {
 	return nil
 }
 
-// LoadAndRefresh loads the config file if not loaded already and performs autodiscovery if needed
-func (env *TestEnvironment) LoadAndRefresh() {
-	if !env.loaded {
+// Load loads the config file if not loaded already and performs autodiscovery if needed.  An error is returned when
+// the config file cannot be loaded or the test environment cannot be discovered.
+func (env *TestEnvironment) Load() error {
+	switch {
+	case !env.loaded:
 		filePath := getConfigurationFilePathFromEnvironment()
 		log.Debugf("GetConfigInstance before config loaded, loading from file: %s", filePath)
 		err := env.loadConfigFromFile(filePath)
 		if err != nil {
-			log.Fatalf("unable to load configuration file: %s", err)
+			return fmt.Errorf("%w: %s", errConfigurationFile, err)
 		}
-		env.doAutodiscover()
-	} else if env.needsRefresh {
+		configureOcSessionBackend()
+		return env.doAutodiscover()
+	case env.needsRefresh:
 		env.reset()
-		env.doAutodiscover()
+		return env.doAutodiscover()
+	case env.sessions != nil:
+		env.sessions.Check()
 	}
+	return nil
+}
+
+// LoadAndRefresh loads the config file if not loaded already and performs autodiscovery if needed.  The test run is
+// aborted if the config file cannot be loaded, and the test fails if the autodiscovery does, see Load.
+func (env *TestEnvironment) LoadAndRefresh() {
+	err := env.Load()
+	if errors.Is(err, errConfigurationFile) {
+		log.Fatal(err)
+	}
+	tnf.FailOnError(err)
+}
+
+// configureOcSessionBackend makes the container sessions use the Kubernetes exec API when TNF_OC_SESSION_BACKEND
+// requests it.  The oc subprocesses are kept when the cluster configuration cannot be loaded, or when a snapshot is
+// replayed, since the replay does not connect to the containers.
+func configureOcSessionBackend() {
+	if interactive.GetOcSessionBackend() != interactive.OcSessionBackendExec || snapshot.IsReplaying() {
+		return
+	}
+	restConfig, err := autodiscover.LoadRestConfig()
+	if err != nil {
+		log.Errorf("unable to create the %s container session backend (error: %s), falling back to %s", interactive.OcSessionBackendExec, err, interactive.OcSessionBackendOc)
+		return
+	}
+	factory, err := interactive.NewSPDYExecutorFactory(restConfig)
+	if err != nil {
+		log.Errorf("unable to create the %s container session backend (error: %s), falling back to %s", interactive.OcSessionBackendExec, err, interactive.OcSessionBackendOc)
+		return
+	}
+	interactive.SetOcSpawnFunc(interactive.NewKubeExecSpawnFunc(factory))
 }
 
 // Resets the environment during the drain test since all the connections are affected
@@ -175,7 +251,9 @@ func (env *TestEnvironment) reset() {
 	// Delete Oc debug sessions before re-creating them
 	for name, node := range env.NodesUnderTest {
 		if node.debug {
-			autodiscover.DeleteDebugLabel(name)
+			if err := autodiscover.DeleteDebugLabel(name); err != nil {
+				log.Error(err)
+			}
 		}
 	}
 	env.NameSpacesUnderTest = nil
@@ -187,6 +265,7 @@ func (env *TestEnvironment) reset() {
 // Resets the environment during the intrusive tests since all the connections are affected
 func (env *TestEnvironment) ResetOc() {
 	log.Debug("Reset Oc sessions")
+	env.CloseSessionPool()
 	// Delete Oc debug sessions before re-creating them
 	for _, node := range env.NodesUnderTest {
 		if node.HasDebugPod() {
@@ -205,7 +284,52 @@ func (env *TestEnvironment) ResetOc() {
 	}
 }
 
-func (env *TestEnvironment) doAutodiscover() {
+// GetSortedContainersUnderTest returns the containers under test ordered by namespace, pod and container name, so that
+// the iterations over them are reproducible.
+func (env *TestEnvironment) GetSortedContainersUnderTest() []*configsections.Container {
+	containers := make([]*configsections.Container, 0, len(env.ContainersUnderTest))
+	for _, cut := range env.ContainersUnderTest {
+		containers = append(containers, cut)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		a, b := containers[i].ContainerIdentifier, containers[j].ContainerIdentifier
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.PodName != b.PodName {
+			return a.PodName < b.PodName
+		}
+		return a.ContainerName < b.ContainerName
+	})
+	return containers
+}
+
+// GetSortedNodesUnderTest returns the nodes under test ordered by name.
+func (env *TestEnvironment) GetSortedNodesUnderTest() []*NodeConfig {
+	nodes := make([]*NodeConfig, 0, len(env.NodesUnderTest))
+	for _, node := range env.NodesUnderTest {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+// GetParallelRunner returns a parallel.Runner bounded by TNF_PARALLELISM.  The workers of the runners take their
+// sessions from the shared sessions pool, and keep them for the whole test run.
+func (env *TestEnvironment) GetParallelRunner() *parallel.Runner {
+	return parallel.NewRunner(parallel.Limit(), env.getSessions())
+}
+
+// CloseSessionPool ends the sessions of the parallel runners.
+func (env *TestEnvironment) CloseSessionPool() {
+	if env.sessions != nil {
+		env.sessions.RemoveSlots()
+	}
+}
+
+func (env *TestEnvironment) doAutodiscover() error {
 	log.Debug("start auto discovery")
 	for _, ns := range env.Config.TargetNameSpaces {
 		env.NameSpacesUnderTest = append(env.NameSpacesUnderTest, ns.Name)
@@ -224,12 +348,19 @@ func (env *TestEnvironment) doAutodiscover() {
 	for _, cid := range env.Config.ExcludeContainersFromMultusConnectivityTests {
 		env.ContainersToExcludeFromMultusConnectivityTests[cid] = ""
 	}
-	env.ContainersUnderTest = env.createContainerMapWithOcSession(env.Config.ContainerList)
+	var err error
+	env.ContainersUnderTest, err = env.createContainerMapWithOcSession(env.Config.ContainerList, interactive.TargetContainer)
+	if err != nil {
+		return err
+	}
 	env.PodsUnderTest = env.Config.PodsUnderTest
 
 	// Discover nodes early on since they might be used to run commands by discovery
 	// But after getting a node list in FindTestTarget() and a container under test list in env.ContainersUnderTest
-	env.discoverNodes()
+	err = env.discoverNodes()
+	if err != nil {
+		return err
+	}
 
 	for _, cid := range env.Config.Partner.ContainersDebugList {
 		env.ContainersToExcludeFromConnectivityTests[cid.ContainerIdentifier] = ""
@@ -244,11 +375,12 @@ func (env *TestEnvironment) doAutodiscover() {
 	log.Infof("Test Configuration: %+v", *env)
 
 	env.needsRefresh = false
+	return nil
 }
 
 // labelNodes add label to specific nodes so that node selector in debug daemonset
 // can be scheduled
-func (env *TestEnvironment) labelNodes() {
+func (env *TestEnvironment) labelNodes() error {
 	var masterNode, workerNode string
 	// make sure at least one worker and one master has debug set to true
 	for name, node := range env.NodesUnderTest {
@@ -278,9 +410,12 @@ func (env *TestEnvironment) labelNodes() {
 	// label all nodes
 	for nodeName, node := range env.NodesUnderTest {
 		if node.debug {
-			autodiscover.AddDebugLabel(nodeName)
+			if err := autodiscover.AddDebugLabel(nodeName); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
 // create Nodes data from podset
@@ -316,41 +451,66 @@ func (env *TestEnvironment) AttachDebugPodsToNodes() {
 // discoverNodes find all the nodes in the cluster
 // label the ones with deployment
 // attach them to debug pods
-func (env *TestEnvironment) discoverNodes() {
+func (env *TestEnvironment) discoverNodes() error {
 	env.NodesUnderTest = env.createNodes(env.Config.Nodes)
+	if len(env.NodesUnderTest) == 0 {
+		// No debug pod can ever be ready, e.g. the cluster is not reachable, do not wait for them
+		return errors.New("no nodes found in the cluster")
+	}
 
 	expectedDebugPods := 0
 	// Wait for the previous deployment's pod to fully terminate
-	autodiscover.CheckDebugDaemonset(expectedDebugPods)
-	env.labelNodes()
+	err := autodiscover.CheckDebugDaemonset(expectedDebugPods)
+	if err != nil {
+		return err
+	}
+	err = env.labelNodes()
+	if err != nil {
+		return err
+	}
 
 	for _, node := range env.NodesUnderTest {
 		if node.debug {
 			expectedDebugPods++
 		}
 	}
-	autodiscover.CheckDebugDaemonset(expectedDebugPods)
-	autodiscover.FindDebugPods(&env.Config.Partner)
+	err = autodiscover.CheckDebugDaemonset(expectedDebugPods)
+	if err != nil {
+		return err
+	}
+	err = autodiscover.FindDebugPods(&env.Config.Partner)
+	if err != nil {
+		return err
+	}
 	for _, debugPod := range env.Config.Partner.ContainersDebugList {
 		env.ContainersToExcludeFromConnectivityTests[debugPod.ContainerIdentifier] = ""
 		env.ContainersToExcludeFromMultusConnectivityTests[debugPod.ContainerIdentifier] = ""
 	}
-	env.DebugContainers = env.createContainerMapWithOcSession(env.Config.Partner.ContainersDebugList)
+	env.DebugContainers, err = env.createContainerMapWithOcSession(env.Config.Partner.ContainersDebugList, interactive.TargetNode)
+	if err != nil {
+		return err
+	}
 
 	env.AttachDebugPodsToNodes()
+	return nil
 }
 
 // createContainerMapWithOcSession contains the general steps involved in creating "oc" sessions and other configuration. A map of the
-// aggregate information is returned.
-func (env *TestEnvironment) createContainerMapWithOcSession(containers []configsections.Container) map[configsections.ContainerIdentifier]*configsections.Container {
+// aggregate information is returned.  kind is the interactive.Target kind of the sessions in the pool.
+func (env *TestEnvironment) createContainerMapWithOcSession(containers []configsections.Container, kind string) (map[configsections.ContainerIdentifier]*configsections.Container, error) {
 	containerMap := make(map[configsections.ContainerIdentifier]*configsections.Container)
 	for i := range containers {
 		c := &containers[i]
 		log.Debugf("Creating shell session for pod %s - container %s (ns %s)", c.PodName, c.ContainerName, c.Namespace)
-		c.Oc = configsections.GetOcSession(c.PodName, c.ContainerName, c.Namespace, DefaultTimeout, interactive.Verbose(expectersVerboseModeEnabled), interactive.SendTimeout(DefaultTimeout))
+		target := interactive.Target{Kind: kind, Namespace: c.Namespace, Pod: c.PodName, Container: c.ContainerName}
+		oc, err := env.getSessions().GetOc(target)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the session to %s: %w", target, err)
+		}
+		c.Oc = oc
 		containerMap[c.ContainerIdentifier] = c
 	}
-	return containerMap
+	return containerMap, nil
 }
 
 // SetNeedsRefresh marks the config stale so that the next getInstance call will redo discovery
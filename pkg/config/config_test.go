@@ -17,6 +17,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -25,7 +26,6 @@ import (
 	"github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
 	"github.com/test-network-function/test-network-function/pkg/config/configsections"
-	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 	"github.com/test-network-function/test-network-function/pkg/utils"
 )
 
@@ -253,12 +253,12 @@ func TestReset(t *testing.T) {
 			debug: true,
 		},
 	}
-	origFunc := utils.ExecuteCommandAndValidate
-	utils.ExecuteCommandAndValidate = func(command string, timeout time.Duration, context *interactive.Context, failureCallbackFun func()) string {
-		return ""
+	origFunc := utils.ExecuteLocalCommand
+	utils.ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
+		return "", nil
 	}
 	defer func() {
-		utils.ExecuteCommandAndValidate = origFunc
+		utils.ExecuteLocalCommand = origFunc
 	}()
 	testEnv.reset()
 	assert.Equal(t, testEnv.Config.Partner, configsections.TestPartner{})
@@ -295,15 +295,51 @@ func TestLabelNodes(t *testing.T) {
 		},
 	}
 
-	origFunc := utils.ExecuteCommandAndValidate
-	utils.ExecuteCommandAndValidate = func(command string, timeout time.Duration, context *interactive.Context, failureCallbackFun func()) string {
-		return ""
+	origFunc := utils.ExecuteLocalCommand
+	utils.ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
+		return "", nil
 	}
 	defer func() {
-		utils.ExecuteCommandAndValidate = origFunc
+		utils.ExecuteLocalCommand = origFunc
 	}()
 
-	testEnv.labelNodes()
+	assert.Nil(t, testEnv.labelNodes())
 	assert.True(t, testEnv.NodesUnderTest["node1"].debug)
 	assert.True(t, testEnv.NodesUnderTest["node2"].debug)
 }
+
+func TestLabelNodesError(t *testing.T) {
+	testEnv := &TestEnvironment{}
+	testEnv.NodesUnderTest = map[string]*NodeConfig{
+		"node1": {
+			Name: "node1",
+			Node: configsections.Node{
+				Labels: []string{
+					configsections.WorkerLabel,
+				},
+			},
+		},
+	}
+
+	origFunc := utils.ExecuteLocalCommand
+	utils.ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
+		return "", errors.New("forbidden")
+	}
+	defer func() {
+		utils.ExecuteLocalCommand = origFunc
+	}()
+
+	err := testEnv.labelNodes()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "node1")
+	assert.Contains(t, err.Error(), "forbidden")
+}
+
+func TestLoadConfigurationFileError(t *testing.T) {
+	t.Setenv(configurationFilePathEnvironmentVariableKey, "testdata/missing.yml")
+	testEnv := &TestEnvironment{}
+	err := testEnv.Load()
+	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, errConfigurationFile))
+	assert.Contains(t, err.Error(), "missing.yml")
+}
@@ -16,6 +16,8 @@
 
 package configsections
 
+import "time"
+
 // Label ns/name/value for resource lookup
 type Label struct {
 	Prefix string `yaml:"prefix" json:"prefix"`
@@ -89,6 +91,17 @@ type TestConfiguration struct {
 	// AcceptedKernelTaints
 	AcceptedKernelTaints []AcceptedKernelTaintsInfo `yaml:"acceptedKernelTaints,omitempty" json:"acceptedKernelTaints,omitempty"`
 	SkipHelmChartList    []SkipHelmChartList        `yaml:"skipHelmChartList,omitempty" json:"skipHelmChartList,omitempty"`
+	// TimeBudget bounds the duration of the test run and of the test suites.
+	TimeBudget TimeBudget `yaml:"timeBudget,omitempty" json:"timeBudget,omitempty"`
+}
+
+// TimeBudget is the time allowed to the test run and to the test suites, e.g. 2h.  A zero duration is unbounded.  The
+// tests which are running when a budget is exhausted are cancelled, and the following ones are skipped.
+type TimeBudget struct {
+	// Run is the time budget of the whole test run.
+	Run time.Duration `yaml:"run,omitempty" json:"run,omitempty"`
+	// Suites maps a test suite, e.g. lifecycle, to its time budget, which starts with its first test.
+	Suites map[string]time.Duration `yaml:"suites,omitempty" json:"suites,omitempty"`
 }
 
 // TestPartner contains the helper containers that can be used to facilitate tests
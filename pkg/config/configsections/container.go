@@ -20,8 +20,8 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 )
 
@@ -72,15 +72,20 @@ func GetOcSession(pod, container, namespace string, timeout time.Duration, optio
 	// Oc reference from the goroutine through a channel.  Performs basic sanity checking that the Oc session is set up
 	// correctly.
 	var containerOc *interactive.Oc
+	var err error
 	ocChan := make(chan *interactive.Oc)
 
 	goExpectSpawner := interactive.NewGoExpectSpawner()
 	var spawner interactive.Spawner = goExpectSpawner
 
 	go func() {
-		oc, outCh, err := interactive.SpawnOc(&spawner, pod, container, namespace, timeout, options...)
-		gomega.Expect(outCh).ToNot(gomega.BeNil())
-		gomega.Expect(err).To(gomega.BeNil())
+		oc, outCh, spawnErr := interactive.SpawnOc(&spawner, pod, container, namespace, timeout, options...)
+		if spawnErr != nil {
+			// The test case is failed by the calling goroutine, which can end it.
+			err = spawnErr
+			ocChan <- nil
+			return
+		}
 		// Set up a go routine which reads from the error channel
 		go func() {
 			log.Debugf("start watching the session with container %s/%s", oc.GetPodName(), oc.GetPodContainerName())
@@ -96,7 +101,10 @@ func GetOcSession(pod, container, namespace string, timeout time.Duration, optio
 
 	containerOc = <-ocChan
 
-	gomega.Expect(containerOc).ToNot(gomega.BeNil())
+	tnf.FailOnError(err)
+	if containerOc == nil {
+		tnf.CurrentReporter().Fail(fmt.Sprintf("no session to container %s/%s", pod, container))
+	}
 
 	return containerOc
 }
@@ -30,6 +30,9 @@ type Pod struct {
 	// ContainerCount is the count of containers inside the pod
 	ContainerCount int `yaml:"containercount" json:"containercount"`
 
+	// ContainerNames are the names of the containers inside the pod, in the order of the pod spec
+	ContainerNames []string `yaml:"containernames,omitempty" json:"containernames,omitempty"`
+
 	// Tests this is list of test that need to run against the Pod.
 	Tests []string `yaml:"tests" json:"tests"`
 
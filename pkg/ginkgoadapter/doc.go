@@ -0,0 +1,22 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+/*
+Package ginkgoadapter runs the checks and the tests of the suite from the Ginkgo specs.  Reporter makes the running spec
+the tnf.Reporter of the tests, RunSpec and RunCheck run a check in the running spec, and GetNonCompliantObjects and
+GetRetriedTests read the entries the tests attached to the report of a spec.  It is the only package of pkg/ depending on
+Ginkgo, so that the checks can run without it.
+*/
+package ginkgoadapter
@@ -0,0 +1,104 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+package ginkgoadapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/ginkgo/v2/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+var (
+	// addReportEntry and currentSpecReport are the Ginkgo functions, replaced in the tests.
+	addReportEntry    = ginkgo.AddReportEntry
+	currentSpecReport = ginkgo.CurrentSpecReport
+)
+
+// Reporter is the tnf.Reporter of the Ginkgo suites.  The output is written to the GinkgoWriter, the entries are
+// attached to the report of the running spec, which is failed or skipped with ginkgo.Fail and ginkgo.Skip.
+type Reporter struct{}
+
+// Printf writes a line to the GinkgoWriter.
+func (Reporter) Printf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format+"\n", args...)
+	_, err := ginkgo.GinkgoWriter.Write([]byte(message))
+	if err != nil {
+		log.Errorf("Ginkgo writer could not write msg '%s' because: %s", message, err)
+	}
+}
+
+// AddEntry attaches value to the report of the running spec, if any.
+func (Reporter) AddEntry(name string, value interface{}) bool {
+	if currentSpecReport().LeafNodeType == types.NodeTypeInvalid {
+		return false
+	}
+	addReportEntry(name, value, types.ReportEntryVisibilityNever)
+	return true
+}
+
+// Fail fails the running spec.
+func (Reporter) Fail(message string) {
+	ginkgo.Fail(message, 1)
+}
+
+// Skip skips the running spec.
+func (Reporter) Skip(message string) {
+	ginkgo.Skip(message, 1)
+}
+
+// GetNonCompliantObjects returns the objects reported by tnf.ReportNonCompliantObject during the spec of report.
+func GetNonCompliantObjects(report types.SpecReport) []tnf.NonCompliantObject { //nolint:gocritic // From Ginkgo
+	objects := []tnf.NonCompliantObject{}
+	for _, entry := range report.ReportEntries {
+		if entry.Name != tnf.NonCompliantObjectEntryName {
+			continue
+		}
+		if object, ok := entry.Value.GetRawValue().(tnf.NonCompliantObject); ok {
+			objects = append(objects, object)
+			continue
+		}
+		// The raw value is lost when the report comes from another Ginkgo process.
+		object := tnf.NonCompliantObject{}
+		if err := json.Unmarshal([]byte(entry.Value.AsJSON), &object); err == nil {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// GetRetriedTests returns the tests retried during the spec of report.
+func GetRetriedTests(report types.SpecReport) []tnf.RetriedTest { //nolint:gocritic // From Ginkgo
+	tests := []tnf.RetriedTest{}
+	for _, entry := range report.ReportEntries {
+		if entry.Name != tnf.RetriedTestEntryName {
+			continue
+		}
+		if test, ok := entry.Value.GetRawValue().(tnf.RetriedTest); ok {
+			tests = append(tests, test)
+			continue
+		}
+		// The raw value is lost when the report comes from another Ginkgo process.
+		test := tnf.RetriedTest{}
+		if err := json.Unmarshal([]byte(entry.Value.AsJSON), &test); err == nil {
+			tests = append(tests, test)
+		}
+	}
+	return tests
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package ginkgoadapter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+// stubReportEntries records the report entries of the spec returned by currentSpecReport in the returned report.
+func stubReportEntries(t *testing.T, spec types.SpecReport) *types.SpecReport {
+	report := &types.SpecReport{}
+	origAddReportEntry, origCurrentSpecReport := addReportEntry, currentSpecReport
+	t.Cleanup(func() { addReportEntry, currentSpecReport = origAddReportEntry, origCurrentSpecReport })
+	addReportEntry = func(name string, args ...interface{}) {
+		report.ReportEntries = append(report.ReportEntries, types.ReportEntry{
+			Name:       name,
+			Value:      types.WrapEntryValue(args[0]),
+			Visibility: args[1].(types.ReportEntryVisibility),
+		})
+	}
+	currentSpecReport = func() types.SpecReport { return spec }
+	return report
+}
+
+func TestReporter_AddEntry(t *testing.T) {
+	object := tnf.NonCompliantObject{Kind: tnf.KindPod, Namespace: "tnf", Name: "test-0", Reason: "HostNetwork"}
+
+	// Outside of a spec, nothing is attached.
+	report := stubReportEntries(t, types.SpecReport{})
+	assert.False(t, Reporter{}.AddEntry(tnf.NonCompliantObjectEntryName, object))
+	assert.Empty(t, report.ReportEntries)
+
+	report = stubReportEntries(t, types.SpecReport{LeafNodeType: types.NodeTypeIt})
+	assert.True(t, Reporter{}.AddEntry(tnf.NonCompliantObjectEntryName, object))
+	report.ReportEntries = append(report.ReportEntries, types.ReportEntry{Name: "other"})
+	assert.Equal(t, []tnf.NonCompliantObject{object}, GetNonCompliantObjects(*report))
+	assert.Equal(t, types.ReportEntryVisibilityNever, report.ReportEntries[0].Visibility)
+}
+
+func TestGetNonCompliantObjects_FromJSON(t *testing.T) {
+	value := types.WrapEntryValue(nil)
+	value.AsJSON = `{"kind":"Container","namespace":"tnf","name":"test-0","container":"test","reason":"NoLogOutput"}`
+	report := types.SpecReport{ReportEntries: types.ReportEntries{{Name: tnf.NonCompliantObjectEntryName, Value: value}}}
+	assert.Equal(t, []tnf.NonCompliantObject{
+		{Kind: tnf.KindContainer, Namespace: "tnf", Name: "test-0", Container: "test", Reason: "NoLogOutput"},
+	}, GetNonCompliantObjects(report))
+}
+
+func TestGetRetriedTests(t *testing.T) {
+	attempts := []tnf.Attempt{{Number: 1, Result: tnf.ERROR, Backoff: time.Second}, {Number: 2, Result: tnf.SUCCESS}}
+	report := stubReportEntries(t, types.SpecReport{LeafNodeType: types.NodeTypeIt})
+	Reporter{}.AddEntry(tnf.RetriedTestEntryName, tnf.RetriedTest{Name: "test", Attempts: attempts})
+	assert.Equal(t, []tnf.RetriedTest{{Name: "test", Attempts: attempts}}, GetRetriedTests(*report))
+
+	value := types.WrapEntryValue(nil)
+	value.AsJSON = `{"name":"test","attempts":[{"number":1,"result":0,"duration":0,"backoff":1000000000},{"number":2,"result":1,"duration":0}]}`
+	fromJSON := types.SpecReport{ReportEntries: types.ReportEntries{{Name: tnf.RetriedTestEntryName, Value: value}}}
+	assert.Equal(t, []tnf.RetriedTest{{Name: "test", Attempts: attempts}}, GetRetriedTests(fromJSON))
+}
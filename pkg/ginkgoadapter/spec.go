@@ -0,0 +1,63 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package ginkgoadapter
+
+import (
+	"github.com/onsi/ginkgo/v2"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/config"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+var (
+	// specReporter gets the output and the entries of the checks run by RunSpec.
+	specReporter tnf.Reporter = Reporter{}
+	// skip, fail and abort end the Ginkgo spec of the checks run by RunSpec.
+	skip  = func(message string) { ginkgo.Skip(message) }
+	fail  = func(message string) { ginkgo.Fail(message) }
+	abort = func(message string) { ginkgo.AbortSuite(message) }
+)
+
+// RunSpec runs the check with the identifier id against env from a Ginkgo spec, which is what the Ginkgo suites wrap.
+// The output of the check is written to the claim file, its non-compliant objects and retried tests are attached to
+// the report of the spec, and the spec is skipped or failed like the check, with its reason.  The suite is aborted
+// when the check aborts the run, see checks.T.Abortf.  The check runs in the context of the running spec, see
+// tnf.SetContextFunc.
+func RunSpec(env *config.TestEnvironment, id claim.Identifier) {
+	check, err := checks.Lookup(id.Url)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	RunCheck(env, check)
+}
+
+// RunCheck runs check against env from a Ginkgo spec like RunSpec, for the checks which are not registered, e.g. the
+// ones of a test case configured in testconfigure.yml.
+func RunCheck(env *config.TestEnvironment, check *checks.Check) {
+	result := checks.RunCheckWithReporter(tnf.CurrentContext(), env, check, specReporter)
+	switch {
+	case result.Aborted:
+		abort(result.FailureReason)
+	case result.State == claimutil.StateSkipped:
+		skip(result.FailureReason)
+	case result.State == claimutil.StateFailed:
+		fail(result.FailureReason)
+	}
+}
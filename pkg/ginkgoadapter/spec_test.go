@@ -0,0 +1,110 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package ginkgoadapter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/checks"
+	"github.com/test-network-function/test-network-function/pkg/config"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+)
+
+func testIdentifier(name string) claim.Identifier {
+	return claim.Identifier{Url: "http://test-network-function.com/testcases/ginkgoadapter/" + name, Version: "v1.0.0"}
+}
+
+// registerOnce registers the checks that are not registered yet, as the registry cannot be reset from this package.
+func registerOnce(registered ...checks.Check) {
+	for _, check := range registered {
+		if _, err := checks.Lookup(check.ID.Url); err != nil {
+			checks.Register(check)
+		}
+	}
+}
+
+// recordingReporter records the output and the entries of the checks.
+type recordingReporter struct {
+	Reporter
+	lines   []string
+	entries map[string][]interface{}
+}
+
+func (r *recordingReporter) Printf(format string, args ...interface{}) {
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingReporter) AddEntry(name string, value interface{}) bool {
+	if r.entries == nil {
+		r.entries = map[string][]interface{}{}
+	}
+	r.entries[name] = append(r.entries[name], value)
+	return true
+}
+
+func TestRunSpec(t *testing.T) {
+	var skipped, failed, aborted []string
+	reporter := &recordingReporter{}
+	origReporter, origSkip, origFail, origAbort := specReporter, skip, fail, abort
+	t.Cleanup(func() {
+		specReporter, skip, fail, abort = origReporter, origSkip, origFail, origAbort
+	})
+	specReporter = reporter
+	skip = func(message string) { skipped = append(skipped, message) }
+	fail = func(message string) { failed = append(failed, message) }
+	abort = func(message string) { aborted = append(aborted, message) }
+
+	object := tnf.NonCompliantObject{Kind: tnf.KindNode, Name: "worker-0", Reason: "Test"}
+	registerOnce(
+		checks.Check{ID: testIdentifier("pass"), Run: func(t *checks.T) { t.Printf("passed") }},
+		checks.Check{ID: testIdentifier("skip"), Run: func(t *checks.T) { t.Skipf("nothing to check") }},
+		checks.Check{ID: testIdentifier("fail"), Run: func(t *checks.T) {
+			tnf.ReportNonCompliantObject(object)
+			t.Failf("1 node failed")
+		}},
+		checks.Check{ID: testIdentifier("abort"), Run: func(t *checks.T) { t.Abortf("node not restored") }},
+	)
+	env := &config.TestEnvironment{}
+
+	RunSpec(env, testIdentifier("pass"))
+	assert.Equal(t, []string{"passed"}, reporter.lines)
+	assert.Empty(t, skipped)
+	assert.Empty(t, failed)
+
+	RunSpec(env, testIdentifier("skip"))
+	assert.Equal(t, []string{"nothing to check"}, skipped)
+	assert.Empty(t, failed)
+
+	RunSpec(env, testIdentifier("fail"))
+	assert.Equal(t, []string{"1 node failed"}, failed)
+	assert.Equal(t, []interface{}{object}, reporter.entries[tnf.NonCompliantObjectEntryName])
+
+	RunSpec(env, testIdentifier("unknown"))
+	assert.Len(t, failed, 2)
+	assert.Contains(t, failed[1], "unknown")
+
+	RunSpec(env, testIdentifier("abort"))
+	assert.Equal(t, []string{"node not restored"}, aborted)
+	assert.Len(t, failed, 2)
+
+	// An unregistered check runs like a registered one.
+	RunCheck(env, &checks.Check{ID: testIdentifier("unregistered"), Run: func(t *checks.T) { t.Skipf("not configured") }})
+	assert.Equal(t, []string{"nothing to check", "not configured"}, skipped)
+}
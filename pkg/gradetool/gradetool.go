@@ -56,9 +56,18 @@ type GradeResult struct {
 	Fail    []identifier.Identifier
 }
 
-// GenerateGrade outputs a grade file based on input test results and input grading policy
+// GenerateGrade outputs a grade file based on input test results and input grading policy.  The policy is either a
+// Policy, conforming to the policy schema, or a WeightedPolicy, conforming to the v2 policy schema.
 func GenerateGrade(resultsPath, policyPath, outputPath string) error {
-	err := validatePolicySchema(policyPath)
+	weighted, err := isWeightedPolicy(policyPath)
+	if err != nil {
+		return err
+	}
+	if weighted {
+		return generateWeightedGrade(resultsPath, policyPath, outputPath)
+	}
+
+	err = validateSchema(policyPath, policySchemaPath)
 	if err != nil {
 		return err
 	}
@@ -193,8 +202,8 @@ func validatePolicy(policyObj *Policy) error {
 	return nil
 }
 
-func validatePolicySchema(policyPath string) error {
-	validationResult, err := jsonschema.ValidateJSONFileAgainstSchema(policyPath, policySchemaPath)
+func validateSchema(policyPath, schemaPath string) error {
+	validationResult, err := jsonschema.ValidateJSONFileAgainstSchema(policyPath, schemaPath)
 	if err != nil || !validationResult.Valid() {
 		validationErrors := []gojsonschema.ResultError{}
 		if validationResult != nil {
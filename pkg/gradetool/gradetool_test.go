@@ -40,6 +40,7 @@ var (
 
 func TestMain(m *testing.M) {
 	policySchemaPath = path.Join("..", "..", policySchemaPath)
+	weightedPolicySchemaPath = path.Join("..", "..", weightedPolicySchemaPath)
 	os.Exit(m.Run())
 }
 
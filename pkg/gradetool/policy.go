@@ -0,0 +1,419 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gradetool
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
+)
+
+const (
+	// OutcomePassed is the outcome of a test whose results all passed.
+	OutcomePassed = "passed"
+	// OutcomeFailed is the outcome of a test with at least one result which did not pass nor was skipped.
+	OutcomeFailed = "failed"
+	// OutcomeSkipped is the outcome of a test whose results were all skipped.
+	OutcomeSkipped = "skipped"
+	// OutcomeMissing is the outcome of a test which has no result in the claim.
+	OutcomeMissing = "missing"
+
+	// SkippedFail grades skipped tests as failed.
+	SkippedFail = "fail"
+	// SkippedPass grades skipped tests as passed.
+	SkippedPass = "pass"
+	// SkippedExclude leaves skipped tests out of the grade.
+	SkippedExclude = "exclude"
+
+	defaultTestWeight = 1.0
+	waiverDateLayout  = "2006-01-02"
+)
+
+var (
+	weightedPolicySchemaPath = path.Join("schemas", "gradetool-policy-v2-schema.json")
+	// now is the time the waivers are checked against.
+	now = time.Now
+)
+
+// WeightedTest is a test of a WeightedGrade.
+type WeightedTest struct {
+	ID identifier.Identifier `json:"id"`
+	// Weight is the share of the test in the score of the grade, which must be positive.  Defaults to 1 when not set.
+	Weight *float64 `json:"weight,omitempty"`
+	// Optional tests lower the score of the grade when they do not pass, but do not prevent the grade from being
+	// proposed.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// WeightedGrade is a single grade of a WeightedPolicy.
+type WeightedGrade struct {
+	Name string `json:"name"`
+	// MinimumScore is the score, between 0 and 1, the grade must reach to be proposed.
+	MinimumScore float64        `json:"minimumScore,omitempty"`
+	Tests        []WeightedTest `json:"tests"`
+}
+
+// Waiver accepts a test which did not pass as if it passed, until Expires.
+type Waiver struct {
+	ID            identifier.Identifier `json:"id"`
+	Expires       string                `json:"expires"`
+	Justification string                `json:"justification"`
+}
+
+// WeightedPolicy is the object in a policy file conforming to the v2 policy schema.  The grades are progressive, from
+// the lowest to the highest.
+type WeightedPolicy struct {
+	Grades  []WeightedGrade `json:"grades"`
+	Waivers []Waiver        `json:"waivers,omitempty"`
+	// Skipped is how skipped tests are graded, one of SkippedFail (the default), SkippedPass or SkippedExclude.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// TestGrade is how a single test contributed to a WeightedGradeResult.
+type TestGrade struct {
+	ID       identifier.Identifier `json:"id"`
+	Outcome  string                `json:"outcome"`
+	Weight   float64               `json:"weight"`
+	Optional bool                  `json:"optional"`
+	// Counted is false when the test is left out of the grade, i.e. a skipped test with SkippedExclude.
+	Counted bool `json:"counted"`
+	// Accepted is true when the test counts as passed, either by its outcome or by a waiver.
+	Accepted bool `json:"accepted"`
+	// Waiver is the waiver which applied to the test, if any.
+	Waiver *Waiver `json:"waiver,omitempty"`
+	// Reason explains why the test was accepted or not.
+	Reason string `json:"reason"`
+}
+
+// WeightedGradeResult is the output object of a WeightedGrade.
+type WeightedGradeResult struct {
+	Name    string `json:"name"`
+	Propose bool   `json:"propose"`
+	// Score is the accepted weight divided by the counted weight, 1 when no test is counted.
+	Score        float64     `json:"score"`
+	MinimumScore float64     `json:"minimumScore"`
+	Tests        []TestGrade `json:"tests"`
+	// Reasons explains why the grade was or was not proposed.
+	Reasons []string `json:"reasons"`
+}
+
+// WeightedGradingOutput is the output of a WeightedPolicy.
+type WeightedGradingOutput struct {
+	// ProposedGrade is the highest proposed grade, empty when no grade is proposed.
+	ProposedGrade string                `json:"proposedGrade"`
+	Grades        []WeightedGradeResult `json:"grades"`
+	// ExpiredWaivers lists the waivers of the policy which were not applied because they expired.
+	ExpiredWaivers []Waiver `json:"expiredWaivers"`
+}
+
+// isWeightedPolicy returns true when the grades of the policy are a list, i.e. the policy is a WeightedPolicy rather
+// than a Policy.
+func isWeightedPolicy(policyPath string) (bool, error) {
+	policyObj := map[string]json.RawMessage{}
+	err := unmarshalFromFile(policyPath, &policyObj)
+	if err != nil {
+		return false, err
+	}
+	grades, ok := policyObj["grades"]
+	return ok && bytes.HasPrefix(bytes.TrimSpace(grades), []byte("[")), nil
+}
+
+func generateWeightedGrade(resultsPath, policyPath, outputPath string) error {
+	err := validateSchema(policyPath, weightedPolicySchemaPath)
+	if err != nil {
+		return err
+	}
+
+	policyObj := WeightedPolicy{}
+	err = unmarshalFromFile(policyPath, &policyObj)
+	if err != nil {
+		return err
+	}
+
+	err = validateWeightedPolicy(&policyObj)
+	if err != nil {
+		return err
+	}
+
+	claimObj := claimRoot{}
+	err = unmarshalFromFile(resultsPath, &claimObj)
+	if err != nil {
+		return err
+	}
+
+	outcomes, err := collectOutcomes(claimObj.Claim.Results)
+	if err != nil {
+		return err
+	}
+
+	gradingOutput, err := doWeightedGrading(&policyObj, outcomes)
+	if err != nil {
+		return err
+	}
+
+	return generateOutput(gradingOutput, outputPath)
+}
+
+func validateWeightedPolicy(policyObj *WeightedPolicy) error {
+	gradeNames := map[string]bool{}
+	for _, grade := range policyObj.Grades {
+		if gradeNames[grade.Name] {
+			return fmt.Errorf("duplicate grade name %s in policy", grade.Name)
+		}
+		gradeNames[grade.Name] = true
+
+		testURLs := map[string]bool{}
+		for _, test := range grade.Tests {
+			if testURLs[test.ID.URL] {
+				return fmt.Errorf("duplicate test %s in grade %s", test.ID.URL, grade.Name)
+			}
+			testURLs[test.ID.URL] = true
+			if test.Weight != nil && *test.Weight <= 0 {
+				return fmt.Errorf("invalid weight %v of test %s in grade %s, it must be positive", *test.Weight, test.ID.URL, grade.Name)
+			}
+		}
+	}
+	waivedURLs := map[string]bool{}
+	for _, waiver := range policyObj.Waivers {
+		if waivedURLs[waiver.ID.URL] {
+			return fmt.Errorf("duplicate waiver for test %s in policy", waiver.ID.URL)
+		}
+		waivedURLs[waiver.ID.URL] = true
+		if _, err := parseWaiverExpiry(waiver.Expires); err != nil {
+			return fmt.Errorf("invalid expiry of the waiver for test %s: %s", waiver.ID.URL, err)
+		}
+	}
+	return nil
+}
+
+// parseWaiverExpiry parses a date, which expires at the end of the day in UTC, or an RFC 3339 time.
+func parseWaiverExpiry(expires string) (time.Time, error) {
+	if date, err := time.Parse(waiverDateLayout, expires); err == nil {
+		return date.AddDate(0, 0, 1), nil
+	}
+	return time.Parse(time.RFC3339, expires)
+}
+
+// claimRoot is the subset of claim.Root needed for grading.  The results are decoded loosely, as older claim files
+// have a "passed" field instead of a "state" field.
+type claimRoot struct {
+	Claim struct {
+		Results map[string][]claimResult `json:"results"`
+	} `json:"claim"`
+}
+
+type claimResult struct {
+	Passed *bool                  `json:"passed"`
+	State  string                 `json:"state"`
+	TestID *identifier.Identifier `json:"testID"`
+}
+
+// outcome returns the outcome of a single result.
+func (r *claimResult) outcome() (string, error) {
+	switch {
+	case r.State != "":
+		switch r.State {
+		case "passed":
+			return OutcomePassed, nil
+		case "skipped", "pending":
+			return OutcomeSkipped, nil
+		default:
+			return OutcomeFailed, nil
+		}
+	case r.Passed != nil:
+		if *r.Passed {
+			return OutcomePassed, nil
+		}
+		return OutcomeFailed, nil
+	default:
+		return "", fmt.Errorf("the test result has neither a 'state' nor a 'passed' field")
+	}
+}
+
+// collectOutcomes returns the outcome of every test of the results, by test URL.  A test fails when any of its results
+// failed, and is skipped when all of its results were skipped.  The results are keyed by the identifier of the test in
+// older claim files, and carry it in their "testID" field in newer ones.
+func collectOutcomes(results map[string][]claimResult) (map[string]string, error) {
+	outcomes := map[string]string{}
+	for key, keyResults := range results {
+		for i := range keyResults {
+			result := &keyResults[i]
+			url := ""
+			if result.TestID != nil {
+				url = result.TestID.URL
+			} else {
+				id := identifier.Identifier{}
+				if err := json.Unmarshal([]byte(key), &id); err != nil {
+					return nil, fmt.Errorf("the test result %s has no test identifier", key)
+				}
+				url = id.URL
+			}
+			resultOutcome, err := result.outcome()
+			if err != nil {
+				return nil, fmt.Errorf("invalid result of test %s: %s", url, err)
+			}
+			outcomes[url] = combineOutcomes(outcomes[url], resultOutcome)
+		}
+	}
+	return outcomes, nil
+}
+
+func combineOutcomes(current, next string) string {
+	switch {
+	case current == "" || current == OutcomeSkipped:
+		return next
+	case next == OutcomeFailed:
+		return OutcomeFailed
+	default:
+		return current
+	}
+}
+
+func doWeightedGrading(policy *WeightedPolicy, outcomes map[string]string) (*WeightedGradingOutput, error) {
+	gradingOutput := &WeightedGradingOutput{Grades: []WeightedGradeResult{}, ExpiredWaivers: []Waiver{}}
+
+	activeWaivers := map[string]*Waiver{}
+	expiredWaivers := map[string]*Waiver{}
+	for i := range policy.Waivers {
+		waiver := &policy.Waivers[i]
+		expiry, err := parseWaiverExpiry(waiver.Expires)
+		if err != nil {
+			return nil, err
+		}
+		if now().Before(expiry) {
+			activeWaivers[waiver.ID.URL] = waiver
+		} else {
+			expiredWaivers[waiver.ID.URL] = waiver
+			gradingOutput.ExpiredWaivers = append(gradingOutput.ExpiredWaivers, *waiver)
+		}
+	}
+
+	previousGrade := ""
+	previousGradeProposed := true
+	for _, grade := range policy.Grades {
+		gradeResult := gradeTests(grade, policy.Skipped, outcomes, activeWaivers, expiredWaivers)
+		if !previousGradeProposed {
+			gradeResult.Propose = false
+			gradeResult.Reasons = append(gradeResult.Reasons,
+				fmt.Sprintf("the previous grade %s is not proposed", previousGrade))
+		}
+		if gradeResult.Propose {
+			gradingOutput.ProposedGrade = grade.Name
+			gradeResult.Reasons = append(gradeResult.Reasons, fmt.Sprintf(
+				"all the mandatory tests are accepted and the score %.2f reaches the minimum score %.2f",
+				gradeResult.Score, gradeResult.MinimumScore))
+		}
+		gradingOutput.Grades = append(gradingOutput.Grades, gradeResult)
+		previousGrade = grade.Name
+		previousGradeProposed = gradeResult.Propose
+	}
+	return gradingOutput, nil
+}
+
+// gradeTests grades the tests of a single grade, regardless of the previous grades.
+func gradeTests(grade WeightedGrade, skipped string, outcomes map[string]string,
+	waivers, expiredWaivers map[string]*Waiver) WeightedGradeResult {
+	gradeResult := WeightedGradeResult{
+		Name:         grade.Name,
+		Propose:      true,
+		MinimumScore: grade.MinimumScore,
+		Tests:        []TestGrade{},
+		Reasons:      []string{},
+	}
+	countedWeight, acceptedWeight := 0.0, 0.0
+	for _, test := range grade.Tests {
+		testGrade := gradeTest(test, skipped, outcomes, waivers, expiredWaivers)
+		if testGrade.Counted {
+			countedWeight += testGrade.Weight
+			if testGrade.Accepted {
+				acceptedWeight += testGrade.Weight
+			}
+		}
+		if testGrade.Counted && !testGrade.Accepted && !test.Optional {
+			gradeResult.Propose = false
+			gradeResult.Reasons = append(gradeResult.Reasons,
+				fmt.Sprintf("mandatory test %s is not accepted: %s", test.ID.URL, testGrade.Reason))
+		}
+		gradeResult.Tests = append(gradeResult.Tests, testGrade)
+	}
+
+	gradeResult.Score = 1
+	if countedWeight > 0 {
+		gradeResult.Score = acceptedWeight / countedWeight
+	}
+	if gradeResult.Score < grade.MinimumScore {
+		gradeResult.Propose = false
+		gradeResult.Reasons = append(gradeResult.Reasons, fmt.Sprintf("the score %.2f is below the minimum score %.2f",
+			gradeResult.Score, grade.MinimumScore))
+	}
+	return gradeResult
+}
+
+func gradeTest(test WeightedTest, skipped string, outcomes map[string]string,
+	waivers, expiredWaivers map[string]*Waiver) TestGrade {
+	testGrade := TestGrade{
+		ID:       test.ID,
+		Outcome:  OutcomeMissing,
+		Weight:   defaultTestWeight,
+		Optional: test.Optional,
+		Counted:  true,
+	}
+	if test.Weight != nil {
+		testGrade.Weight = *test.Weight
+	}
+	if outcome, ok := outcomes[test.ID.URL]; ok {
+		testGrade.Outcome = outcome
+	}
+
+	switch testGrade.Outcome {
+	case OutcomePassed:
+		testGrade.Accepted = true
+		testGrade.Reason = "the test passed"
+		return testGrade
+	case OutcomeSkipped:
+		switch skipped {
+		case SkippedPass:
+			testGrade.Accepted = true
+			testGrade.Reason = "the test was skipped, which the policy grades as passed"
+			return testGrade
+		case SkippedExclude:
+			testGrade.Counted = false
+			testGrade.Reason = "the test was skipped, which the policy excludes from the grade"
+			return testGrade
+		default:
+			testGrade.Reason = "the test was skipped, which the policy grades as failed"
+		}
+	case OutcomeMissing:
+		testGrade.Reason = "the test has no result"
+	default:
+		testGrade.Reason = "the test failed"
+	}
+
+	if waiver, ok := waivers[test.ID.URL]; ok {
+		testGrade.Accepted = true
+		testGrade.Waiver = waiver
+		testGrade.Reason = fmt.Sprintf("%s, but is waived until %s: %s", testGrade.Reason, waiver.Expires,
+			waiver.Justification)
+	} else if waiver, ok := expiredWaivers[test.ID.URL]; ok {
+		testGrade.Reason = fmt.Sprintf("%s, and its waiver expired on %s", testGrade.Reason, waiver.Expires)
+	}
+	return testGrade
+}
@@ -0,0 +1,159 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package gradetool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
+)
+
+const (
+	stateClaim            = testDataPath + "claim-state.json"
+	weightedPolicy        = testDataPath + "policy-weighted.json"
+	weightedSkippedPolicy = testDataPath + "policy-weighted-skipped.json"
+	weightedBadPolicy     = testDataPath + "policy-weighted-no-justification.json"
+	weightedOutPath       = testDataPath + "out-weighted.json"
+)
+
+func stubNow(t *testing.T, date string) {
+	stubbed, err := time.Parse(waiverDateLayout, date)
+	assert.Nil(t, err)
+	now = func() time.Time { return stubbed }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestGenerateGrade_WeightedPolicy(t *testing.T) {
+	stubNow(t, "2022-06-01")
+	err := GenerateGrade(goodClaim, weightedPolicy, testOutPath)
+	assert.Nil(t, err)
+	assertFilesMatch(t, weightedOutPath, testOutPath)
+}
+
+func TestGenerateGrade_WeightedPolicyErrorInput(t *testing.T) {
+	err := GenerateGrade(goodClaim, weightedBadPolicy, testOutPath)
+	assert.NotNil(t, err)
+	err = GenerateGrade(badClaim, weightedPolicy, testOutPath)
+	assert.NotNil(t, err)
+}
+
+func TestGenerateGrade_WeightedPolicySkipped(t *testing.T) {
+	err := GenerateGrade(stateClaim, weightedSkippedPolicy, testOutPath)
+	assert.Nil(t, err)
+	output := WeightedGradingOutput{}
+	assert.Nil(t, unmarshalFromFile(testOutPath, &output))
+	assert.Equal(t, "good", output.ProposedGrade)
+	assert.Len(t, output.Grades, 1)
+	grade := output.Grades[0]
+	assert.True(t, grade.Propose)
+	assert.Equal(t, 0.5, grade.Score)
+	assert.Equal(t, []string{OutcomePassed, OutcomeSkipped, OutcomeFailed},
+		[]string{grade.Tests[0].Outcome, grade.Tests[1].Outcome, grade.Tests[2].Outcome})
+	assert.False(t, grade.Tests[1].Counted)
+}
+
+func TestDoWeightedGrading(t *testing.T) {
+	stubNow(t, "2022-06-01")
+	id := func(name string) WeightedTest {
+		return WeightedTest{ID: testIdentifier(name)}
+	}
+	half := 0.5
+	testCases := []struct {
+		policy        WeightedPolicy
+		outcomes      map[string]string
+		expectedGrade string
+		expectedScore []float64
+	}{
+		{ // a failed optional test lowers the score below the minimum
+			policy: WeightedPolicy{Grades: []WeightedGrade{
+				{Name: "good", Tests: []WeightedTest{id("a")}},
+				{Name: "better", MinimumScore: 0.8, Tests: []WeightedTest{id("b"), {ID: testIdentifier("c"), Optional: true, Weight: &half}}},
+			}},
+			outcomes:      map[string]string{"a": OutcomePassed, "b": OutcomePassed, "c": OutcomeFailed},
+			expectedGrade: "good",
+			expectedScore: []float64{1, 2.0 / 3},
+		},
+		{ // skipped tests fail by default, unless waived
+			policy: WeightedPolicy{
+				Grades:  []WeightedGrade{{Name: "good", Tests: []WeightedTest{id("a"), id("b")}}},
+				Waivers: []Waiver{{ID: testIdentifier("b"), Expires: "2022-06-01", Justification: "j"}},
+			},
+			outcomes:      map[string]string{"a": OutcomeSkipped, "b": OutcomeSkipped},
+			expectedGrade: "",
+			expectedScore: []float64{0.5},
+		},
+		{ // skipped tests may pass, higher grades need the lower ones
+			policy: WeightedPolicy{
+				Grades:  []WeightedGrade{{Name: "good", Tests: []WeightedTest{id("a")}}, {Name: "better", Tests: []WeightedTest{}}},
+				Skipped: SkippedPass,
+			},
+			outcomes:      map[string]string{"a": OutcomeSkipped},
+			expectedGrade: "better",
+			expectedScore: []float64{1, 1},
+		},
+		{ // a missing test fails, and the following grades are not proposed
+			policy: WeightedPolicy{
+				Grades: []WeightedGrade{{Name: "good", Tests: []WeightedTest{id("a")}}, {Name: "better", Tests: []WeightedTest{}}},
+			},
+			outcomes:      map[string]string{},
+			expectedGrade: "",
+			expectedScore: []float64{0, 1},
+		},
+	}
+	for _, tc := range testCases {
+		output, err := doWeightedGrading(&tc.policy, tc.outcomes)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedGrade, output.ProposedGrade)
+		for i, score := range tc.expectedScore {
+			assert.InDelta(t, score, output.Grades[i].Score, 1e-9)
+			assert.NotEmpty(t, output.Grades[i].Reasons)
+		}
+	}
+}
+
+func TestValidateWeightedPolicy_Weight(t *testing.T) {
+	for _, weight := range []float64{0, -1} {
+		weight := weight
+		policy := WeightedPolicy{Grades: []WeightedGrade{{Name: "good", Tests: []WeightedTest{{ID: testIdentifier("a"), Weight: &weight}}}}}
+		assert.NotNil(t, validateWeightedPolicy(&policy))
+	}
+	policy := WeightedPolicy{Grades: []WeightedGrade{{Name: "good", Tests: []WeightedTest{{ID: testIdentifier("a")}}}}}
+	assert.Nil(t, validateWeightedPolicy(&policy))
+}
+
+func TestCollectOutcomes(t *testing.T) {
+	passed, failed := true, false
+	a, b := testIdentifier("a"), testIdentifier("b")
+	outcomes, err := collectOutcomes(map[string][]claimResult{
+		`{"url":"c","version":"v1.0.0"}`: {{Passed: &failed}, {Passed: &passed}},
+		"a":                              {{State: "skipped", TestID: &a}, {State: "passed", TestID: &a}},
+		"b":                              {{State: "skipped", TestID: &b}, {State: "pending", TestID: &b}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": OutcomePassed, "b": OutcomeSkipped, "c": OutcomeFailed}, outcomes)
+
+	_, err = collectOutcomes(map[string][]claimResult{"a": {{TestID: &a}}})
+	assert.NotNil(t, err)
+	_, err = collectOutcomes(map[string][]claimResult{"a": {{State: "passed"}}})
+	assert.NotNil(t, err)
+}
+
+func testIdentifier(url string) identifier.Identifier {
+	return identifier.Identifier{URL: url, SemanticVersion: "v1.0.0"}
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package report
+
+import (
+	_ "embed" // the HTML template
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+)
+
+const (
+	// The claim.Nodes keys, as set by the test suite.
+	nodeSummaryKey = "nodeSummary"
+	cniPluginsKey  = "cniPlugins"
+	nodesHwInfoKey = "nodesHwInfo"
+	csiDriverKey   = "csiDriver"
+
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+//go:embed templates/report.html
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"anchor":   anchor,
+	"duration": func(nanoseconds int) string { return time.Duration(nanoseconds).String() },
+	"stateClass": func(state string) string {
+		switch {
+		case state == claimutil.StatePassed:
+			return "passed"
+		case claimutil.IsFailure(state):
+			return "failed"
+		default:
+			return "skipped"
+		}
+	},
+}).Parse(htmlTemplateText))
+
+// htmlReport is the data of the HTML template.
+type htmlReport struct {
+	Metadata *claim.Metadata
+	Versions *claim.Versions
+	Suites   []*Suite
+	Total    Suite
+	Cluster  cluster
+}
+
+// cluster is the cluster section of the report, built from claim.Nodes.  The raw values are kept as they may not have
+// the expected shape, e.g. when the data could not be collected.
+type cluster struct {
+	Nodes         []node
+	CNIPlugins    []cniPlugin
+	HwInfo        []hwInfo
+	CSIDrivers    []csiDriver
+	RawNodes      string
+	RawCNIPlugins string
+	RawHwInfo     string
+	RawCSIDrivers string
+}
+
+type node struct {
+	Name, Roles, OSImage, KernelVersion, KubeletVersion, ContainerRuntime, Architecture, CPU, Memory string
+}
+
+type cniPlugin struct {
+	Name, Type, Version string
+}
+
+type hwInfo struct {
+	Role, NodeName, CPUModel, CPUs, Architecture string
+	PCIDevices                                   int
+}
+
+type csiDriver struct {
+	Name, AttachRequired, PodInfoOnMount, VolumeLifecycleModes string
+}
+
+// WriteHTML renders the claim as a self-contained HTML page: a summary per suite, the results of every test with
+// their catalog description, and the cluster the tests were run on.
+func WriteHTML(w io.Writer, claimRoot *claim.Root) error {
+	c := claimRoot.Claim
+	suites, err := GetSuites(c)
+	if err != nil {
+		return err
+	}
+	report := htmlReport{
+		Metadata: c.Metadata,
+		Versions: c.Versions,
+		Suites:   suites,
+		Total:    Suite{Name: "total"},
+		Cluster:  newCluster(c.Nodes),
+	}
+	if report.Metadata == nil {
+		report.Metadata = &claim.Metadata{}
+	}
+	if report.Versions == nil {
+		report.Versions = &claim.Versions{}
+	}
+	for _, suite := range suites {
+		report.Total.Passed += suite.Passed
+		report.Total.Failed += suite.Failed
+		report.Total.Skipped += suite.Skipped
+	}
+	return htmlTemplate.Execute(w, &report)
+}
+
+func newCluster(nodes map[string]interface{}) cluster {
+	return cluster{
+		Nodes:         getNodes(nodes[nodeSummaryKey]),
+		CNIPlugins:    getCNIPlugins(nodes[cniPluginsKey]),
+		HwInfo:        getHwInfo(nodes[nodesHwInfoKey]),
+		CSIDrivers:    getCSIDrivers(nodes[csiDriverKey]),
+		RawNodes:      toJSON(nodes[nodeSummaryKey]),
+		RawCNIPlugins: toJSON(nodes[cniPluginsKey]),
+		RawHwInfo:     toJSON(nodes[nodesHwInfoKey]),
+		RawCSIDrivers: toJSON(nodes[csiDriverKey]),
+	}
+}
+
+// getNodes extracts the nodes of the output of `oc get nodes -o json`.
+func getNodes(nodeSummary interface{}) []node {
+	nodes := []node{}
+	for _, item := range getList(nodeSummary, "items") {
+		roles := []string{}
+		if labels, ok := get(item, "metadata", "labels").(map[string]interface{}); ok {
+			for label := range labels {
+				if strings.HasPrefix(label, nodeRoleLabelPrefix) {
+					roles = append(roles, strings.TrimPrefix(label, nodeRoleLabelPrefix))
+				}
+			}
+		}
+		sort.Strings(roles)
+		nodes = append(nodes, node{
+			Name:             getString(item, "metadata", "name"),
+			Roles:            strings.Join(roles, ", "),
+			OSImage:          getString(item, "status", "nodeInfo", "osImage"),
+			KernelVersion:    getString(item, "status", "nodeInfo", "kernelVersion"),
+			KubeletVersion:   getString(item, "status", "nodeInfo", "kubeletVersion"),
+			ContainerRuntime: getString(item, "status", "nodeInfo", "containerRuntimeVersion"),
+			Architecture:     getString(item, "status", "nodeInfo", "architecture"),
+			CPU:              getString(item, "status", "capacity", "cpu"),
+			Memory:           getString(item, "status", "capacity", "memory"),
+		})
+	}
+	return nodes
+}
+
+func getCNIPlugins(plugins interface{}) []cniPlugin {
+	cniPlugins := []cniPlugin{}
+	if list, ok := plugins.([]interface{}); ok {
+		for _, plugin := range list {
+			cniPlugins = append(cniPlugins, cniPlugin{
+				Name:    getString(plugin, "name"),
+				Type:    getString(plugin, "type"),
+				Version: getString(plugin, "version"),
+			})
+		}
+	}
+	return cniPlugins
+}
+
+// getHwInfo extracts the hardware of the master and worker nodes inspected by the test suite.
+func getHwInfo(nodesHwInfo interface{}) []hwInfo {
+	hwInfos := []hwInfo{}
+	for _, role := range []string{"Master", "Worker"} {
+		info := get(nodesHwInfo, role)
+		if info == nil || getString(info, "NodeName") == "" {
+			continue
+		}
+		lspci, _ := get(info, "Lspci").([]interface{})
+		hwInfos = append(hwInfos, hwInfo{
+			Role:         strings.ToLower(role),
+			NodeName:     getString(info, "NodeName"),
+			CPUModel:     getString(info, "Lscpu", "Model name"),
+			CPUs:         getString(info, "Lscpu", "CPU(s)"),
+			Architecture: getString(info, "Lscpu", "Architecture"),
+			PCIDevices:   len(lspci),
+		})
+	}
+	return hwInfos
+}
+
+// getCSIDrivers extracts the drivers of the output of `oc get csidriver -o json`.
+func getCSIDrivers(drivers interface{}) []csiDriver {
+	csiDrivers := []csiDriver{}
+	for _, item := range getList(drivers, "items") {
+		csiDrivers = append(csiDrivers, csiDriver{
+			Name:                 getString(item, "metadata", "name"),
+			AttachRequired:       getString(item, "spec", "attachRequired"),
+			PodInfoOnMount:       getString(item, "spec", "podInfoOnMount"),
+			VolumeLifecycleModes: getString(item, "spec", "volumeLifecycleModes"),
+		})
+	}
+	return csiDrivers
+}
+
+// get returns the value at path in a generic JSON value, or nil.
+func get(value interface{}, path ...string) interface{} {
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func getList(value interface{}, path ...string) []interface{} {
+	list, _ := get(value, path...).([]interface{})
+	return list
+}
+
+// getString returns the value at path in a generic JSON value as a string: lists are comma separated, and any other
+// value is formatted as is.
+func getString(value interface{}, path ...string) string {
+	switch typed := get(value, path...).(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case []interface{}:
+		items := []string{}
+		for _, item := range typed {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func toJSON(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	payload, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(payload)
+}
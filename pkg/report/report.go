@@ -0,0 +1,147 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package report renders a claim file for the people and the tools who did not run the test suites.
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+const (
+	// CatalogURL is the online test case catalog.  The anchor of a test case is its name.
+	CatalogURL = "https://github.com/test-network-function/test-network-function/blob/main/CATALOG.md"
+
+	// otherSuite is the suite of the results with no test identifier, e.g. from older claim files.
+	otherSuite = "other"
+)
+
+// Test is a test case of a claim, with its catalog description.
+type Test struct {
+	// Key is the key of the test results in the claim.
+	Key string
+	// Suite and Name are derived from the test identifier, e.g. access-control and host-resource.
+	Suite string
+	Name  string
+	// ID is the test identifier, nil for the results of older claim files.
+	ID      *claim.Identifier
+	State   string
+	Results []claimutil.Result
+	// Description is the catalog entry of the test, the zero value when the test is not in the catalog.
+	Description identifiers.TestCaseDescription
+}
+
+// Label returns the catalog label of the test, e.g. access-control-host-resource.
+func (t *Test) Label() string {
+	return t.Suite + "-" + t.Name
+}
+
+// CatalogLink returns the link to the online catalog description of the test.
+func (t *Test) CatalogLink() string {
+	return CatalogURL + "#" + t.Name
+}
+
+// Suite is the tests of a claim which belong to the same test suite.
+type Suite struct {
+	Name  string
+	Tests []*Test
+	// Passed, Failed and Skipped count the tests by state.  A pending test is counted as skipped.
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+// GetSuites returns the tests of c grouped by suite, sorted by suite and test name.
+func GetSuites(c *claim.Claim) ([]*Suite, error) {
+	results, err := claimutil.GetResults(c)
+	if err != nil {
+		return nil, err
+	}
+
+	suites := map[string]*Suite{}
+	for _, key := range claimutil.SortedKeys(results) {
+		test := newTest(key, results[key])
+		suite, ok := suites[test.Suite]
+		if !ok {
+			suite = &Suite{Name: test.Suite}
+			suites[test.Suite] = suite
+		}
+		suite.Tests = append(suite.Tests, test)
+		switch {
+		case test.State == claimutil.StatePassed:
+			suite.Passed++
+		case claimutil.IsFailure(test.State):
+			suite.Failed++
+		default:
+			suite.Skipped++
+		}
+	}
+
+	sortedSuites := make([]*Suite, 0, len(suites))
+	for _, suite := range suites {
+		sort.SliceStable(suite.Tests, func(i, j int) bool {
+			return suite.Tests[i].Name < suite.Tests[j].Name
+		})
+		sortedSuites = append(sortedSuites, suite)
+	}
+	sort.Slice(sortedSuites, func(i, j int) bool {
+		return sortedSuites[i].Name < sortedSuites[j].Name
+	})
+	return sortedSuites, nil
+}
+
+func newTest(key string, results []claimutil.Result) *Test {
+	test := &Test{
+		Key:     key,
+		Suite:   otherSuite,
+		Name:    key,
+		State:   claimutil.AggregateState(results),
+		Results: results,
+	}
+	for i := range results {
+		if results[i].TestID != nil {
+			test.ID = results[i].TestID
+			break
+		}
+	}
+	if test.ID == nil {
+		return test
+	}
+	if suiteAndTest := identifiers.GetSuiteAndTestFromIdentifier(*test.ID); suiteAndTest != nil {
+		test.Suite, test.Name = suiteAndTest[0], suiteAndTest[1]
+	} else {
+		test.Name = test.ID.Url
+	}
+	test.Description = identifiers.Catalog[*test.ID]
+	return test
+}
+
+// anchor returns an HTML id made of the characters of value which are allowed in a URL fragment.
+func anchor(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+func testResult(id claim.Identifier, state string) claim.Result {
+	return claim.Result{State: state, TestID: &id, Duration: 1500000000}
+}
+
+func testClaim(t *testing.T) *claim.Root {
+	var nodes map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"nodeSummary": {"items": [{
+			"metadata": {"name": "master-0", "labels": {"node-role.kubernetes.io/master": "", "kubernetes.io/os": "linux"}},
+			"status": {"nodeInfo": {"osImage": "RHCOS 4.9", "kernelVersion": "4.18.0", "kubeletVersion": "v1.22.3",
+				"containerRuntimeVersion": "cri-o://1.22.1", "architecture": "amd64"}, "capacity": {"cpu": "8", "memory": "32Gi"}}
+		}]},
+		"cniPlugins": [{"name": "multus-cni-network", "type": "multus", "version": "0.3.1"}],
+		"nodesHwInfo": {"Master": {"NodeName": "master-0", "Lscpu": {"Model name": "Xeon <Gold>", "CPU(s)": "8"},
+			"Lspci": ["00:00.0 Host bridge", "00:01.0 VGA"]}, "Worker": {"NodeName": ""}},
+		"csiDriver": {"items": [{"metadata": {"name": "csi.example.com"}, "spec": {"attachRequired": true,
+			"volumeLifecycleModes": ["Persistent", "Ephemeral"]}}]}
+	}`), &nodes))
+
+	failed := testResult(identifiers.TestHostResourceIdentifier, "failed")
+	failed.FailureReason = "pod tnf/test-0 uses <hostNetwork>"
+	failed.CapturedTestOutput = "checking tnf/test-0"
+	return &claim.Root{Claim: &claim.Claim{
+		Metadata: &claim.Metadata{StartTime: "2022-03-01T10:00:00+00:00", EndTime: "2022-03-01T11:00:00+00:00"},
+		Versions: &claim.Versions{Tnf: "v3.2.0", Ocp: "4.9.10"},
+		Nodes:    nodes,
+		Results: map[string]interface{}{
+			"access-control-host-resource": []claim.Result{
+				testResult(identifiers.TestHostResourceIdentifier, "passed"), failed,
+			},
+			"access-control-namespace": []claim.Result{testResult(identifiers.TestNamespaceBestPracticesIdentifier, "passed")},
+			"lifecycle-pod-recreation": []claim.Result{testResult(identifiers.TestPodRecreationIdentifier, "skipped")},
+			"legacy":                   []claim.Result{{State: "passed"}},
+		},
+	}}
+}
+
+func TestGetSuites(t *testing.T) {
+	suites, err := GetSuites(testClaim(t).Claim)
+	assert.Nil(t, err)
+	assert.Len(t, suites, 3)
+	assert.Equal(t, "access-control", suites[0].Name)
+	assert.Equal(t, []int{1, 1, 0}, []int{suites[0].Passed, suites[0].Failed, suites[0].Skipped})
+	assert.Equal(t, "host-resource", suites[0].Tests[0].Name)
+	assert.Equal(t, "failed", suites[0].Tests[0].State)
+	assert.Equal(t, "access-control-host-resource", suites[0].Tests[0].Label())
+	assert.Equal(t, CatalogURL+"#host-resource", suites[0].Tests[0].CatalogLink())
+	assert.Equal(t, identifiers.Catalog[identifiers.TestHostResourceIdentifier], suites[0].Tests[0].Description)
+	assert.Equal(t, "lifecycle", suites[1].Name)
+	assert.Equal(t, 1, suites[1].Skipped)
+	assert.Equal(t, otherSuite, suites[2].Name)
+	assert.Equal(t, "legacy", suites[2].Tests[0].Name)
+	assert.Nil(t, suites[2].Tests[0].ID)
+}
+
+func TestWriteHTML(t *testing.T) {
+	var page bytes.Buffer
+	assert.Nil(t, WriteHTML(&page, testClaim(t)))
+	html := page.String()
+
+	assert.Contains(t, html, `<a href="#suite-access-control">access-control</a></td><td class="passed">1</td><td class="failed">1</td>`)
+	assert.Contains(t, html, `<th>Total</th><th class="passed">2</th><th class="failed">1</th><th class="skipped">1</th>`)
+	assert.Contains(t, html, `<a href="`+CatalogURL+`#host-resource">host-resource</a>`)
+	assert.Contains(t, html, "pod tnf/test-0 uses &lt;hostNetwork&gt;")
+	assert.Contains(t, html, "checking tnf/test-0")
+	assert.Contains(t, html, "in 1.5s")
+	assert.Contains(t, html, "<td>master-0</td><td>master</td><td>RHCOS 4.9</td>")
+	assert.Contains(t, html, "<td>multus-cni-network</td><td>multus</td><td>0.3.1</td>")
+	assert.Contains(t, html, "<td>master</td><td>master-0</td><td>Xeon &lt;Gold&gt;</td><td>8</td><td></td><td>2</td>")
+	assert.NotContains(t, html, "<td>worker</td>")
+	assert.Contains(t, html, "<td>csi.example.com</td><td>true</td><td></td><td>Persistent, Ephemeral</td>")
+	assert.NotContains(t, html, "<script")
+}
+
+func TestWriteHTML_EmptyClaim(t *testing.T) {
+	var page bytes.Buffer
+	assert.Nil(t, WriteHTML(&page, &claim.Root{Claim: &claim.Claim{}}))
+	assert.Contains(t, page.String(), "No node summary in the claim.")
+}
@@ -0,0 +1,276 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+const (
+	// SARIFVersion is the version of the SARIF format written by WriteSARIF.
+	SARIFVersion = "2.1.0"
+	// SARIFSchemaURI is the JSON schema of SARIFVersion.
+	SARIFSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	sarifToolName       = "test-network-function"
+	sarifToolURI        = "https://github.com/test-network-function/test-network-function"
+	sarifLevelError     = "error"
+	sarifLocationKind   = "resource"
+	sarifFingerprintKey = "nonCompliantObject/v1"
+)
+
+// The subset of the SARIF 2.1.0 object model written by WriteSARIF.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool        sarifTool         `json:"tool"`
+		Invocations []sarifInvocation `json:"invocations,omitempty"`
+		Results     []sarifResult     `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string                 `json:"id"`
+		ShortDescription *sarifMessage          `json:"shortDescription,omitempty"`
+		FullDescription  *sarifMessage          `json:"fullDescription,omitempty"`
+		Help             *sarifMessage          `json:"help,omitempty"`
+		HelpURI          string                 `json:"helpUri,omitempty"`
+		Properties       map[string]interface{} `json:"properties,omitempty"`
+	}
+
+	sarifInvocation struct {
+		ExecutionSuccessful bool   `json:"executionSuccessful"`
+		StartTimeUTC        string `json:"startTimeUtc,omitempty"`
+		EndTimeUTC          string `json:"endTimeUtc,omitempty"`
+	}
+
+	sarifResult struct {
+		RuleID              string                 `json:"ruleId"`
+		RuleIndex           int                    `json:"ruleIndex"`
+		Level               string                 `json:"level"`
+		Message             sarifMessage           `json:"message"`
+		Locations           []sarifLocation        `json:"locations,omitempty"`
+		PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+		Properties          map[string]interface{} `json:"properties,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+	}
+
+	sarifLogicalLocation struct {
+		Name               string `json:"name"`
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+)
+
+// WriteSARIF converts the claim to a SARIF log for the tools which aggregate the findings of several scanners.  Every
+// test case of the catalog, and of the claim, is a rule.  Every non-compliant object of a failed test is a result, with
+// a namespace/pod/container logical location, and a failed test which reported no object is a result of its own.
+func WriteSARIF(w io.Writer, claimRoot *claim.Root) error {
+	c := claimRoot.Claim
+	suites, err := GetSuites(c)
+	if err != nil {
+		return err
+	}
+
+	rules, ruleIndexes := catalogRules()
+	run := sarifRun{Results: []sarifResult{}}
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			ruleID := test.Label()
+			ruleIndex, ok := ruleIndexes[ruleID]
+			if !ok {
+				ruleIndex = len(rules)
+				ruleIndexes[ruleID] = ruleIndex
+				rules = append(rules, testRule(test))
+			}
+			run.Results = append(run.Results, testResults(test, ruleID, ruleIndex)...)
+		}
+	}
+
+	run.Tool.Driver = sarifDriver{Name: sarifToolName, InformationURI: sarifToolURI, Rules: rules}
+	if c.Versions != nil {
+		run.Tool.Driver.Version = c.Versions.Tnf
+	}
+	if c.Metadata != nil {
+		run.Invocations = []sarifInvocation{{
+			ExecutionSuccessful: true,
+			StartTimeUTC:        sarifTime(c.Metadata.StartTime),
+			EndTimeUTC:          sarifTime(c.Metadata.EndTime),
+		}}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{Schema: SARIFSchemaURI, Version: SARIFVersion, Runs: []sarifRun{run}})
+}
+
+// catalogRules returns the rules of the test cases of the catalog, sorted by ID, and the index of each rule ID.
+func catalogRules() (rules []sarifRule, indexes map[string]int) {
+	rules = []sarifRule{}
+	for id := range identifiers.Catalog {
+		id := id
+		rules = append(rules, testRule(newTest(id.Url, []claimutil.Result{{Result: claim.Result{TestID: &id}}})))
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	indexes = map[string]int{}
+	for i := range rules {
+		indexes[rules[i].ID] = i
+	}
+	return rules, indexes
+}
+
+func testRule(test *Test) sarifRule {
+	rule := sarifRule{
+		ID:         test.Label(),
+		Properties: map[string]interface{}{"tags": []string{test.Suite}},
+	}
+	if test.ID == nil {
+		return rule
+	}
+	rule.HelpURI = test.CatalogLink()
+	rule.Properties["identifier"] = test.ID.Url
+	rule.Properties["version"] = test.ID.Version
+	if description := test.Description.Description; description != "" {
+		rule.ShortDescription = &sarifMessage{Text: firstSentence(description)}
+		rule.FullDescription = &sarifMessage{Text: description}
+	}
+	if test.Description.Remediation != "" {
+		rule.Help = &sarifMessage{Text: test.Description.Remediation}
+	}
+	if test.Description.Type != "" {
+		rule.Properties["tags"] = []string{test.Suite, test.Description.Type}
+	}
+	if test.Description.BestPracticeReference != "" {
+		rule.Properties["bestPracticeReference"] = test.Description.BestPracticeReference
+	}
+	return rule
+}
+
+// testResults returns a result per non-compliant object of the failed results of test, or a single result when the
+// test failed without reporting objects.
+func testResults(test *Test, ruleID string, ruleIndex int) []sarifResult {
+	results := []sarifResult{}
+	failureReason := ""
+	for i := range test.Results {
+		result := &test.Results[i]
+		if !claimutil.IsFailure(result.State) {
+			continue
+		}
+		if failureReason == "" {
+			failureReason = strings.TrimSpace(result.FailureReason)
+		}
+		for _, object := range result.NonCompliantObjects {
+			results = append(results, objectResult(object, ruleID, ruleIndex))
+		}
+	}
+	if len(results) > 0 || !claimutil.IsFailure(test.State) {
+		return results
+	}
+	if failureReason == "" {
+		failureReason = "The test case " + test.State + "."
+	}
+	return []sarifResult{{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevelError,
+		Message:   sarifMessage{Text: failureReason},
+	}}
+}
+
+func objectResult(object tnf.NonCompliantObject, ruleID string, ruleIndex int) sarifResult { //nolint:gocritic // From claimutil.Result
+	parts := []string{}
+	for _, part := range []string{object.Namespace, object.Name, object.Container} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	fullyQualifiedName := strings.Join(parts, "/")
+	name := object.Kind
+	if len(parts) > 0 {
+		name = parts[len(parts)-1]
+	}
+	properties := map[string]interface{}{"kind": object.Kind, "reason": object.Reason}
+	if object.Details != "" {
+		properties["details"] = object.Details
+	}
+	return sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevelError,
+		Message:   sarifMessage{Text: object.String()},
+		Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+			Name:               name,
+			FullyQualifiedName: fullyQualifiedName,
+			Kind:               sarifLocationKind,
+		}}}},
+		PartialFingerprints: map[string]string{
+			sarifFingerprintKey: strings.Join([]string{ruleID, object.Kind, fullyQualifiedName, object.Reason}, "/"),
+		},
+		Properties: properties,
+	}
+}
+
+// firstSentence returns the text up to the first period followed by a space, or the whole text.
+func firstSentence(text string) string {
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// sarifTime converts a claim time, e.g. 2022-03-01T10:00:00+00:00, to the UTC format of SARIF, or returns "" when the
+// time cannot be parsed.
+func sarifTime(claimTime string) string {
+	parsed, err := time.Parse(time.RFC3339, claimTime)
+	if err != nil {
+		return ""
+	}
+	return parsed.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
+)
+
+func writeSARIF(t *testing.T, claimRoot *claim.Root) *sarifLog {
+	var output bytes.Buffer
+	assert.Nil(t, WriteSARIF(&output, claimRoot))
+	log := &sarifLog{}
+	assert.Nil(t, json.Unmarshal(output.Bytes(), log))
+	assert.Len(t, log.Runs, 1)
+	return log
+}
+
+func TestWriteSARIF(t *testing.T) {
+	claimRoot := testClaim(t)
+	failed := testResult(identifiers.TestLoggingIdentifier, "failed")
+	failed.FailureReason = "2 containers don't have any log to stdout/stderr."
+	results, err := claimutil.GetResults(claimRoot.Claim)
+	assert.Nil(t, err)
+	results["observability-container-logging"] = []claimutil.Result{{
+		Result: failed,
+		NonCompliantObjects: []tnf.NonCompliantObject{
+			{Kind: tnf.KindContainer, Namespace: "tnf", Name: "test-0", Container: "test", Reason: "NoLogOutput"},
+			{Kind: tnf.KindHelmChart, Name: "chart", Reason: "HelmChartNotCertified", Details: "version 1.0"},
+		},
+	}}
+	results["legacy-failure"] = []claimutil.Result{{Result: claim.Result{State: "failed"}}}
+	claimutil.SetResults(claimRoot.Claim, results)
+
+	log := writeSARIF(t, claimRoot)
+	assert.Equal(t, SARIFVersion, log.Version)
+	run := log.Runs[0]
+	assert.Equal(t, "v3.2.0", run.Tool.Driver.Version)
+	assert.Equal(t, "2022-03-01T10:00:00Z", run.Invocations[0].StartTimeUTC)
+
+	// The catalog rules, then the rules of the tests which are not in the catalog.
+	rules := run.Tool.Driver.Rules
+	assert.Len(t, rules, len(identifiers.Catalog)+2)
+	assert.Equal(t, "other-legacy", rules[len(rules)-2].ID)
+	assert.Equal(t, "other-legacy-failure", rules[len(rules)-1].ID)
+
+	assert.Len(t, run.Results, 4)
+	for _, result := range run.Results {
+		assert.Equal(t, result.RuleID, rules[result.RuleIndex].ID)
+		assert.Equal(t, sarifLevelError, result.Level)
+	}
+
+	hostResource := run.Results[0]
+	assert.Equal(t, "access-control-host-resource", hostResource.RuleID)
+	assert.Equal(t, "pod tnf/test-0 uses <hostNetwork>", hostResource.Message.Text)
+	assert.Empty(t, hostResource.Locations)
+	rule := rules[hostResource.RuleIndex]
+	description := identifiers.Catalog[identifiers.TestHostResourceIdentifier]
+	assert.Equal(t, description.Description, rule.FullDescription.Text)
+	assert.Equal(t, description.Remediation, rule.Help.Text)
+	assert.Equal(t, CatalogURL+"#host-resource", rule.HelpURI)
+
+	container := run.Results[1]
+	assert.Equal(t, "observability-container-logging", container.RuleID)
+	assert.Equal(t, "Container tnf/test-0/test: NoLogOutput", container.Message.Text)
+	assert.Equal(t, []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+		Name: "test", FullyQualifiedName: "tnf/test-0/test", Kind: sarifLocationKind,
+	}}}}, container.Locations)
+	assert.Equal(t, "observability-container-logging/Container/tnf/test-0/test/NoLogOutput",
+		container.PartialFingerprints[sarifFingerprintKey])
+
+	chart := run.Results[2]
+	assert.Equal(t, "chart", chart.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, "version 1.0", chart.Properties["details"])
+
+	legacy := run.Results[3]
+	assert.Equal(t, "other-legacy-failure", legacy.RuleID)
+	assert.Equal(t, "The test case failed.", legacy.Message.Text)
+}
+
+func TestWriteSARIF_EmptyClaim(t *testing.T) {
+	log := writeSARIF(t, &claim.Root{Claim: &claim.Claim{}})
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, len(identifiers.Catalog))
+	assert.NotNil(t, log.Runs[0].Results)
+	assert.Empty(t, log.Runs[0].Results)
+	assert.Empty(t, log.Runs[0].Invocations)
+}
+
+func TestFirstSentence(t *testing.T) {
+	assert.Equal(t, "Checks the pods.", firstSentence("Checks the pods. Then the containers."))
+	assert.Equal(t, "Checks v1.2 pods", firstSentence("Checks v1.2 pods"))
+}
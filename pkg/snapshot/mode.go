@@ -0,0 +1,138 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+)
+
+const (
+	// ModeEnvironmentVariableKey selects the snapshot mode of a test run, either ModeCapture or ModeReplay.
+	ModeEnvironmentVariableKey = "TNF_SNAPSHOT_MODE"
+	// DirEnvironmentVariableKey is the snapshot directory of a test run.
+	DirEnvironmentVariableKey = "TNF_SNAPSHOT_DIR"
+	// ModeCapture records every session of the run into the snapshot directory.
+	ModeCapture = "capture"
+	// ModeReplay answers every session of the run from the snapshot directory.
+	ModeReplay = "replay"
+)
+
+var (
+	active     *Snapshot
+	activeMode string
+)
+
+// StartCapture records every session spawned from now on into dir.  The commands are added to the snapshot already
+// in dir, if any, so that a run of the suites can complete the snapshot taken by `tnf snapshot capture`.
+func StartCapture(dir string) (*Snapshot, error) {
+	s, err := Open(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		s, err = Create(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	interactive.SetSpawnFuncWrapper(CaptureWrapper(s))
+	active, activeMode = s, ModeCapture
+	log.Infof("snapshot: capturing sessions into %s", dir)
+	return s, nil
+}
+
+// StartReplay answers every session spawned from now on from the snapshot in dir.
+func StartReplay(dir string) (*Snapshot, error) {
+	s, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	interactive.SetSpawnFuncWrapper(ReplayWrapper(s))
+	active, activeMode = s, ModeReplay
+	log.Infof("snapshot: replaying sessions from %s (%d commands)", dir, len(s.manifest.Entries))
+	return s, nil
+}
+
+// Stop ends the capture or replay.  The manifest of a capture is saved.
+func Stop() {
+	interactive.SetSpawnFuncWrapper(nil)
+	if active != nil {
+		if err := active.Close(); err != nil {
+			log.Errorf("snapshot: unable to save the manifest of %s: %s", active.Dir(), err)
+		}
+	}
+	active, activeMode = nil, ""
+}
+
+// StartFromEnvironment starts a capture or a replay as requested by TNF_SNAPSHOT_MODE and TNF_SNAPSHOT_DIR.  It does
+// nothing when TNF_SNAPSHOT_MODE is not set.
+func StartFromEnvironment() error {
+	mode := strings.ToLower(os.Getenv(ModeEnvironmentVariableKey))
+	if mode == "" {
+		return nil
+	}
+	dir := os.Getenv(DirEnvironmentVariableKey)
+	if dir == "" {
+		return fmt.Errorf("%s is set but %s is not", ModeEnvironmentVariableKey, DirEnvironmentVariableKey)
+	}
+	var err error
+	switch mode {
+	case ModeCapture:
+		_, err = StartCapture(dir)
+	case ModeReplay:
+		_, err = StartReplay(dir)
+	default:
+		err = fmt.Errorf("unknown %s value %q, expected %s or %s", ModeEnvironmentVariableKey, mode, ModeCapture, ModeReplay)
+	}
+	return err
+}
+
+// IsActive returns true when the sessions are being captured or replayed.
+func IsActive() bool {
+	return active != nil
+}
+
+// IsReplaying returns true when the sessions are answered from a snapshot instead of a cluster.
+func IsReplaying() bool {
+	return activeMode == ModeReplay
+}
+
+// StoreConfigFile copies the tnf configuration at path into the snapshot being captured, so that the replay uses the
+// same configuration.  It does nothing when no capture is running.
+func StoreConfigFile(path string) error {
+	if activeMode != ModeCapture {
+		return nil
+	}
+	return active.CopyFile(path, ConfigFileName)
+}
+
+// ConfigFilePath returns the copy of the tnf configuration stored in the snapshot being replayed, or an empty string
+// if there is none.
+func ConfigFilePath() string {
+	if !IsReplaying() {
+		return ""
+	}
+	configPath := filepath.Join(active.Dir(), ConfigFileName)
+	if _, err := os.Stat(configPath); err != nil {
+		return ""
+	}
+	return configPath
+}
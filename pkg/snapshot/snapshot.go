@@ -0,0 +1,221 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ManifestFileName is the file listing the recorded commands, relative to the snapshot directory.
+	ManifestFileName = "manifest.json"
+	// ConfigFileName is the copy of the tnf configuration used for the capture, relative to the snapshot directory.
+	ConfigFileName = "tnf_config.yml"
+	// LocalSession is the session name of the local shell.
+	LocalSession = "local"
+
+	manifestVersion = 1
+	outputsDir      = "outputs"
+	dirPermissions  = 0755
+	filePermissions = 0644
+)
+
+// Entry is a command recorded in a session, with every output it produced in capture order.
+type Entry struct {
+	// Session identifies the interactive session the command was sent to, see SessionName.
+	Session string `json:"session"`
+	// Command is the exact text sent to the session.
+	Command string `json:"command"`
+	// Outputs are the names of the files holding each output, relative to the outputs directory.
+	Outputs []string `json:"outputs"`
+}
+
+// Manifest is the index of a snapshot directory.
+type Manifest struct {
+	Version    int      `json:"version"`
+	CapturedAt string   `json:"capturedAt"`
+	Entries    []*Entry `json:"entries"`
+}
+
+type entryKey struct {
+	session string
+	command string
+}
+
+// Snapshot is a directory of recorded session commands and outputs.
+type Snapshot struct {
+	dir      string
+	mutex    sync.Mutex
+	manifest Manifest
+	index    map[entryKey]*Entry
+	// replayed tracks how many outputs of an entry were already replayed.
+	replayed map[entryKey]int
+	// recorded is true when entries were recorded since the manifest was last saved.
+	recorded bool
+}
+
+// SessionName returns the name under which the commands sent to the session spawned with command and args are
+// recorded.  The local shell is recorded as LocalSession, whatever shell is used.
+func SessionName(command string, args []string) string {
+	if len(args) == 0 {
+		return LocalSession
+	}
+	return strings.Join(append([]string{filepath.Base(command)}, args...), " ")
+}
+
+func newSnapshot(dir string) *Snapshot {
+	return &Snapshot{
+		dir:      dir,
+		index:    make(map[entryKey]*Entry),
+		replayed: make(map[entryKey]int),
+	}
+}
+
+// Create creates an empty snapshot in dir, removing any previously recorded commands.
+func Create(dir string) (*Snapshot, error) {
+	err := os.MkdirAll(filepath.Join(dir, outputsDir), dirPermissions)
+	if err != nil {
+		return nil, err
+	}
+	s := newSnapshot(dir)
+	s.manifest = Manifest{Version: manifestVersion, CapturedAt: time.Now().UTC().Format(time.RFC3339)}
+	return s, s.save()
+}
+
+// Open loads the snapshot recorded in dir.
+func Open(dir string) (*Snapshot, error) {
+	contents, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	s := newSnapshot(dir)
+	err = json.Unmarshal(contents, &s.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest in %s: %s", dir, err)
+	}
+	if s.manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s", s.manifest.Version, dir)
+	}
+	for _, entry := range s.manifest.Entries {
+		s.index[entryKey{entry.Session, entry.Command}] = entry
+	}
+	return s, nil
+}
+
+// Dir returns the snapshot directory.
+func (s *Snapshot) Dir() string {
+	return s.dir
+}
+
+// Entries returns a copy of the recorded entries.
+func (s *Snapshot) Entries() []Entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entries := make([]Entry, 0, len(s.manifest.Entries))
+	for _, entry := range s.manifest.Entries {
+		entries = append(entries, *entry)
+	}
+	return entries
+}
+
+// Record appends output to the outputs of command in session.  Identical outputs are stored once.  The manifest is
+// only saved by Close.
+func (s *Snapshot) Record(session, command string, output []byte) error {
+	sum := sha256.Sum256(output)
+	fileName := hex.EncodeToString(sum[:])
+	err := os.WriteFile(filepath.Join(s.dir, outputsDir, fileName), output, filePermissions)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := entryKey{session, command}
+	entry, ok := s.index[key]
+	if !ok {
+		entry = &Entry{Session: session, Command: command}
+		s.index[key] = entry
+		s.manifest.Entries = append(s.manifest.Entries, entry)
+	}
+	entry.Outputs = append(entry.Outputs, fileName)
+	s.recorded = true
+	return nil
+}
+
+// Close saves the manifest if entries were recorded since it was last saved.
+func (s *Snapshot) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.recorded {
+		return nil
+	}
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.recorded = false
+	return nil
+}
+
+// Next returns the next recorded output of command in session.  Outputs are replayed in capture order and the last
+// one is repeated once they are exhausted, so that polling loops end the same way they did during the capture.
+func (s *Snapshot) Next(session, command string) ([]byte, error) {
+	s.mutex.Lock()
+	key := entryKey{session, command}
+	entry, ok := s.index[key]
+	if !ok || len(entry.Outputs) == 0 {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("no output recorded for command %q in session %q", command, session)
+	}
+	position := s.replayed[key]
+	if position < len(entry.Outputs)-1 {
+		s.replayed[key] = position + 1
+	}
+	fileName := entry.Outputs[position]
+	s.mutex.Unlock()
+	return os.ReadFile(filepath.Join(s.dir, outputsDir, fileName))
+}
+
+// CopyFile stores a copy of the file at path in the snapshot directory under name.
+func (s *Snapshot) CopyFile(path, name string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.dir, name), contents, filePermissions)
+}
+
+// save writes the manifest through a temporary file so that an interrupted capture leaves a readable snapshot.
+func (s *Snapshot) save() error {
+	contents, err := json.MarshalIndent(&s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(s.dir, ManifestFileName)
+	err = os.WriteFile(manifestPath+".tmp", contents, filePermissions)
+	if err != nil {
+		return err
+	}
+	return os.Rename(manifestPath+".tmp", manifestPath)
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package snapshot_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
+)
+
+func TestSessionName(t *testing.T) {
+	assert.Equal(t, snapshot.LocalSession, snapshot.SessionName("/bin/bash", nil))
+	assert.Equal(t, "oc rsh -n tnf -c test test-0", snapshot.SessionName("/usr/bin/oc", []string{"rsh", "-n", "tnf", "-c", "test", "test-0"}))
+}
+
+func TestRecordAndNext(t *testing.T) {
+	dir := t.TempDir()
+	s, err := snapshot.Create(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Record(snapshot.LocalSession, "oc get pods\n", []byte("first")))
+	assert.Nil(t, s.Record(snapshot.LocalSession, "oc get pods\n", []byte("second")))
+	assert.Nil(t, s.Record("oc rsh pod", "ls\n", []byte("first")))
+
+	// The manifest is saved once, on Close.
+	reopened, err := snapshot.Open(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, reopened.Entries())
+	assert.Nil(t, s.Close())
+
+	// The outputs are replayed in capture order from a reopened snapshot, the last one being repeated.
+	s, err = snapshot.Open(dir)
+	assert.Nil(t, err)
+	assert.Len(t, s.Entries(), 2)
+	for _, expected := range []string{"first", "second", "second"} {
+		output, err := s.Next(snapshot.LocalSession, "oc get pods\n")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, string(output))
+	}
+	output, err := s.Next("oc rsh pod", "ls\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "first", string(output))
+
+	// Identical outputs are stored once.
+	files, err := os.ReadDir(filepath.Join(dir, "outputs"))
+	assert.Nil(t, err)
+	assert.Len(t, files, 2)
+
+	_, err = s.Next(snapshot.LocalSession, "oc get nodes\n")
+	assert.NotNil(t, err)
+}
+
+func TestOpenErrors(t *testing.T) {
+	_, err := snapshot.Open(t.TempDir())
+	assert.True(t, os.IsNotExist(err))
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, snapshot.ManifestFileName), []byte(`{"version":99}`), 0600))
+	_, err = snapshot.Open(dir)
+	assert.NotNil(t, err)
+}
+
+func TestStoreConfigFile(t *testing.T) {
+	defer snapshot.Stop()
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("targetNameSpaces: []\n"), 0600))
+	dir := t.TempDir()
+
+	// Nothing is stored nor replayed outside of a snapshot run.
+	assert.Nil(t, snapshot.StoreConfigFile(configPath))
+	assert.Equal(t, "", snapshot.ConfigFilePath())
+
+	_, err := snapshot.StartCapture(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, snapshot.StoreConfigFile(configPath))
+	assert.True(t, snapshot.IsActive())
+	assert.False(t, snapshot.IsReplaying())
+
+	_, err = snapshot.StartReplay(dir)
+	assert.Nil(t, err)
+	assert.True(t, snapshot.IsReplaying())
+	assert.Equal(t, filepath.Join(dir, snapshot.ConfigFileName), snapshot.ConfigFilePath())
+}
+
+func TestStartFromEnvironment(t *testing.T) {
+	defer snapshot.Stop()
+	t.Setenv(snapshot.ModeEnvironmentVariableKey, "")
+	assert.Nil(t, snapshot.StartFromEnvironment())
+	assert.False(t, snapshot.IsActive())
+
+	t.Setenv(snapshot.ModeEnvironmentVariableKey, snapshot.ModeReplay)
+	t.Setenv(snapshot.DirEnvironmentVariableKey, "")
+	assert.NotNil(t, snapshot.StartFromEnvironment())
+
+	t.Setenv(snapshot.DirEnvironmentVariableKey, t.TempDir())
+	assert.NotNil(t, snapshot.StartFromEnvironment())
+
+	t.Setenv(snapshot.ModeEnvironmentVariableKey, "record")
+	assert.NotNil(t, snapshot.StartFromEnvironment())
+
+	t.Setenv(snapshot.ModeEnvironmentVariableKey, snapshot.ModeCapture)
+	assert.Nil(t, snapshot.StartFromEnvironment())
+	assert.True(t, snapshot.IsActive())
+}
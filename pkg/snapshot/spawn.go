@@ -0,0 +1,284 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	// missingOutputExitCode is the exit status replayed for a command that was not captured.
+	missingOutputExitCode = 127
+	readBufferSize        = 4096
+)
+
+var (
+	// commandTerminator ends every command wrapped by reel.WrapTestCommand.
+	commandTerminator = []byte(fmt.Sprintf("echo %s %s$?\n", reel.EndOfTestSentinel, reel.ExitKeyword))
+	// outputTerminator ends the output of every command wrapped by reel.WrapTestCommand.
+	outputTerminator = regexp.MustCompile(fmt.Sprintf("%s %s[0-9]+\n", reel.EndOfTestSentinel, reel.ExitKeyword))
+)
+
+// commandSplitter splits the data sent to a session into the commands wrapped by reel.WrapTestCommand.
+type commandSplitter struct {
+	pending []byte
+}
+
+func (c *commandSplitter) split(data []byte) []string {
+	c.pending = append(c.pending, data...)
+	var commands []string
+	for {
+		index := bytes.Index(c.pending, commandTerminator)
+		if index < 0 {
+			return commands
+		}
+		end := index + len(commandTerminator)
+		commands = append(commands, string(c.pending[:end]))
+		c.pending = c.pending[end:]
+	}
+}
+
+// captureSession records the commands sent to a session spawned by the wrapped SpawnFunc along with their output.
+type captureSession struct {
+	interactive.SpawnFunc
+	snapshot *Snapshot
+	session  string
+
+	mutex    sync.Mutex
+	splitter commandSplitter
+	// sent holds the commands waiting for their output, in the order they were sent.
+	sent   []string
+	output []byte
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+type captureWriter struct {
+	io.WriteCloser
+	session *captureSession
+}
+
+// Write queues the commands found in p before forwarding it, so that the output cannot arrive before the command.
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.session.mutex.Lock()
+	w.session.sent = append(w.session.sent, w.session.splitter.split(p)...)
+	w.session.mutex.Unlock()
+	return w.WriteCloser.Write(p)
+}
+
+// StdinPipe wraps the session stdin to queue the commands sent.
+func (c *captureSession) StdinPipe() (io.WriteCloser, error) {
+	stdin, err := c.SpawnFunc.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	return &captureWriter{WriteCloser: stdin, session: c}, nil
+}
+
+// StdoutPipe wraps the session stdout to record the output of the queued commands.
+func (c *captureSession) StdoutPipe() (io.Reader, error) {
+	stdout, err := c.SpawnFunc.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	return io.TeeReader(stdout, writerFunc(c.recordOutput)), nil
+}
+
+func (c *captureSession) recordOutput(p []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.output = append(c.output, p...)
+	for {
+		loc := outputTerminator.FindIndex(c.output)
+		if loc == nil {
+			return len(p), nil
+		}
+		if len(c.sent) == 0 {
+			log.Warnf("snapshot: discarding output not matching any command in session %q", c.session)
+		} else {
+			err := c.snapshot.Record(c.session, c.sent[0], c.output[:loc[1]])
+			if err != nil {
+				log.Errorf("snapshot: unable to record command %q in session %q: %s", c.sent[0], c.session, err)
+			}
+			c.sent = c.sent[1:]
+		}
+		c.output = c.output[loc[1]:]
+	}
+}
+
+type captureSpawnFunc struct {
+	interactive.SpawnFunc
+	snapshot *Snapshot
+}
+
+// Command creates the session with the wrapped SpawnFunc and records it.
+func (c *captureSpawnFunc) Command(name string, arg ...string) *interactive.SpawnFunc {
+	inner := c.SpawnFunc.Command(name, arg...)
+	var session interactive.SpawnFunc = &captureSession{SpawnFunc: *inner, snapshot: c.snapshot, session: SessionName(name, arg)}
+	return &session
+}
+
+// CaptureWrapper returns an interactive.SpawnFuncWrapper recording every spawned session into s.
+func CaptureWrapper(s *Snapshot) interactive.SpawnFuncWrapper {
+	return func(sFunc interactive.SpawnFunc) interactive.SpawnFunc {
+		return &captureSpawnFunc{SpawnFunc: sFunc, snapshot: s}
+	}
+}
+
+// replaySession answers the commands sent to it with the outputs recorded in a snapshot, without spawning a process.
+type replaySession struct {
+	snapshot *Snapshot
+	session  string
+	args     []string
+
+	stdinReader  *io.PipeReader
+	stdinWriter  *io.PipeWriter
+	stdoutReader *io.PipeReader
+	stdoutWriter *io.PipeWriter
+	stderrReader *io.PipeReader
+	stderrWriter *io.PipeWriter
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+func newReplaySession(s *Snapshot, name string, args []string) *replaySession {
+	r := &replaySession{
+		snapshot: s,
+		session:  SessionName(name, args),
+		args:     append([]string{name}, args...),
+		done:     make(chan struct{}),
+	}
+	r.stdinReader, r.stdinWriter = io.Pipe()
+	r.stdoutReader, r.stdoutWriter = io.Pipe()
+	r.stderrReader, r.stderrWriter = io.Pipe()
+	return r
+}
+
+// Command creates another replayed session.
+func (r *replaySession) Command(name string, arg ...string) *interactive.SpawnFunc {
+	var session interactive.SpawnFunc = newReplaySession(r.snapshot, name, arg)
+	return &session
+}
+
+// Start starts answering the commands sent to the session.
+func (r *replaySession) Start() error {
+	go r.serve()
+	return nil
+}
+
+func (r *replaySession) serve() {
+	var splitter commandSplitter
+	buffer := make([]byte, readBufferSize)
+	for {
+		n, err := r.stdinReader.Read(buffer)
+		for _, command := range splitter.split(buffer[:n]) {
+			r.reply(command)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (r *replaySession) reply(command string) {
+	output, err := r.snapshot.Next(r.session, command)
+	if err != nil {
+		log.Warnf("snapshot: %s", err)
+		output = []byte(fmt.Sprintf("%s\n%s %s%d\n", err, reel.EndOfTestSentinel, reel.ExitKeyword, missingOutputExitCode))
+	}
+	_, err = r.stdoutWriter.Write(output)
+	if err != nil {
+		log.Debugf("snapshot: session %q closed before the output of %q was read: %s", r.session, command, err)
+	}
+}
+
+// StdinPipe returns the end the commands are sent to.
+func (r *replaySession) StdinPipe() (io.WriteCloser, error) {
+	return r.stdinWriter, nil
+}
+
+// StdoutPipe returns the end the recorded outputs are read from.
+func (r *replaySession) StdoutPipe() (io.Reader, error) {
+	return r.stdoutReader, nil
+}
+
+// StderrPipe returns a reader that stays empty until the session is closed.
+func (r *replaySession) StderrPipe() (io.Reader, error) {
+	return r.stderrReader, nil
+}
+
+// Wait blocks until the session is closed.
+func (r *replaySession) Wait() error {
+	<-r.done
+	return nil
+}
+
+// Close ends the session.
+func (r *replaySession) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.stdinReader.Close()
+		r.stdoutWriter.Close()
+		r.stderrWriter.Close()
+	})
+	return nil
+}
+
+// IsRunning returns true until the session is closed.
+func (r *replaySession) IsRunning() bool {
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Args returns the command and arguments the session was spawned with.
+func (r *replaySession) Args() []string {
+	return r.args
+}
+
+type replaySpawnFunc struct {
+	interactive.SpawnFunc
+	snapshot *Snapshot
+}
+
+// Command creates a replayed session instead of using the wrapped SpawnFunc, so no process is started.
+func (r *replaySpawnFunc) Command(name string, arg ...string) *interactive.SpawnFunc {
+	var session interactive.SpawnFunc = newReplaySession(r.snapshot, name, arg)
+	return &session
+}
+
+// ReplayWrapper returns an interactive.SpawnFuncWrapper answering every spawned session from s.
+func ReplayWrapper(s *Snapshot) interactive.SpawnFuncWrapper {
+	return func(sFunc interactive.SpawnFunc) interactive.SpawnFunc {
+		return &replaySpawnFunc{SpawnFunc: sFunc, snapshot: s}
+	}
+}
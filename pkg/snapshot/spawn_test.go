@@ -0,0 +1,92 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package snapshot_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const testTimeout = 5 * time.Second
+
+var outputRegex = regexp.MustCompile(fmt.Sprintf(`(?s)(.*)%s %s(\d+)`, reel.EndOfTestSentinel, reel.ExitKeyword))
+
+// run sends the wrapped command to a new /bin/sh session and returns its output and exit status.
+func run(t *testing.T, commands ...string) []string {
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, testTimeout)
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+	defer expecter.Close()
+
+	var outputs []string
+	for _, command := range commands {
+		assert.Nil(t, expecter.Send(reel.WrapTestCommand(command)))
+		_, match, err := expecter.Expect(outputRegex, testTimeout)
+		assert.Nil(t, err)
+		outputs = append(outputs, match[1]+" "+match[2])
+	}
+	return outputs
+}
+
+func TestCaptureAndReplay(t *testing.T) {
+	defer snapshot.Stop()
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+
+	_, err := snapshot.StartCapture(dir)
+	assert.Nil(t, err)
+	captured := run(t, "echo hello", "echo $((40 + 2))", "false")
+	assert.Equal(t, "hello\n 0", captured[0])
+	assert.Equal(t, "42\n 0", captured[1])
+	assert.Equal(t, " 1", captured[2])
+	snapshot.Stop()
+
+	s, err := snapshot.StartReplay(dir)
+	assert.Nil(t, err)
+	assert.Len(t, s.Entries(), 3)
+	assert.Equal(t, snapshot.LocalSession, s.Entries()[0].Session)
+
+	// The replay answers the same commands without a shell, whatever their order.
+	t.Setenv("SHELL", "/nonexistent/shell")
+	replayed := run(t, "false", "echo hello", "echo $((40 + 2))")
+	assert.Equal(t, []string{captured[2], captured[0], captured[1]}, replayed)
+
+	// Commands missing from the snapshot fail.
+	replayed = run(t, "echo other")
+	assert.Regexp(t, `(?s)^no output recorded .* 127$`, replayed[0])
+}
+
+func TestReplaySessionClose(t *testing.T) {
+	s, err := snapshot.Create(t.TempDir())
+	assert.Nil(t, err)
+	var sFunc interactive.SpawnFunc = &interactive.ExecSpawnFunc{}
+	session := *snapshot.ReplayWrapper(s)(sFunc).Command("oc", "rsh", "pod")
+	assert.Equal(t, []string{"oc", "rsh", "pod"}, session.Args())
+	assert.Nil(t, session.Start())
+	assert.True(t, session.IsRunning())
+	assert.Nil(t, session.Close())
+	assert.False(t, session.IsRunning())
+	assert.Nil(t, session.Wait())
+}
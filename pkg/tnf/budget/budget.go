@@ -0,0 +1,81 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package budget enforces the time budget of a test run and of its test suites through contexts.
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+)
+
+// Budget provides the contexts of a test run and of its test suites, which are done when the run is stopped or when
+// their time budget is exhausted.
+type Budget struct {
+	run       context.Context
+	cancelRun context.CancelFunc
+	suites    map[string]time.Duration
+
+	mutex         sync.Mutex
+	suiteContexts map[string]context.Context
+	cancelSuites  []context.CancelFunc
+}
+
+// New creates the Budget of a test run starting now.  The run is stopped when parent is done, e.g. on interrupt.
+func New(parent context.Context, timeBudget configsections.TimeBudget) *Budget { //nolint:gocritic // From the configuration
+	b := &Budget{suites: timeBudget.Suites, suiteContexts: map[string]context.Context{}}
+	if timeBudget.Run > 0 {
+		b.run, b.cancelRun = context.WithTimeout(parent, timeBudget.Run)
+	} else {
+		b.run, b.cancelRun = context.WithCancel(parent)
+	}
+	return b
+}
+
+// Run returns the context of the test run.
+func (b *Budget) Run() context.Context {
+	return b.run
+}
+
+// Suite returns the context of the test suite suite.  The time budget of the suite starts with the first call.
+func (b *Budget) Suite(suite string) context.Context {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if ctx, ok := b.suiteContexts[suite]; ok {
+		return ctx
+	}
+	ctx := b.run
+	if timeout := b.suites[suite]; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(b.run, timeout)
+		b.cancelSuites = append(b.cancelSuites, cancel)
+	}
+	b.suiteContexts[suite] = ctx
+	return ctx
+}
+
+// Stop cancels the test run and its suites, releasing their timers.
+func (b *Budget) Stop() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, cancel := range b.cancelSuites {
+		cancel()
+	}
+	b.cancelRun()
+}
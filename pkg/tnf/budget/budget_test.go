@@ -0,0 +1,66 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/config/configsections"
+)
+
+func TestBudget(t *testing.T) {
+	b := New(context.Background(), configsections.TimeBudget{
+		Run:    time.Hour,
+		Suites: map[string]time.Duration{"lifecycle": time.Millisecond},
+	})
+	defer b.Stop()
+
+	_, ok := b.Run().Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, b.Run(), b.Suite("access-control"))
+
+	lifecycle := b.Suite("lifecycle")
+	assert.Same(t, lifecycle, b.Suite("lifecycle"))
+	<-lifecycle.Done()
+	assert.Equal(t, context.DeadlineExceeded, lifecycle.Err())
+	assert.Nil(t, b.Run().Err())
+}
+
+func TestBudget_Unbounded(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	b := New(parent, configsections.TimeBudget{})
+	suite := b.Suite("lifecycle")
+	_, ok := suite.Deadline()
+	assert.False(t, ok)
+
+	// Interrupting the run cancels the suites.
+	cancel()
+	<-suite.Done()
+	assert.Equal(t, context.Canceled, suite.Err())
+	b.Stop()
+}
+
+func TestBudget_Stop(t *testing.T) {
+	b := New(context.Background(), configsections.TimeBudget{Suites: map[string]time.Duration{"lifecycle": time.Hour}})
+	suite := b.Suite("lifecycle")
+	b.Stop()
+	assert.NotNil(t, suite.Err())
+	assert.NotNil(t, b.Run().Err())
+}
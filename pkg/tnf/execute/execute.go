@@ -0,0 +1,103 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+// Package execute runs one-shot commands directly with os/exec.  Unlike the interactive sessions, there is no prompt
+// emulation and no output matching: the standard output, standard error and exit code are returned as they are.
+package execute
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// shell runs the commands, which may use pipes and redirections.
+var shell = "/bin/sh"
+
+// Result is the outcome of a command which ran to completion.
+type Result struct {
+	Command  string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Succeeded returns true when the command exited with 0.
+func (r *Result) Succeeded() bool {
+	return r.ExitCode == 0
+}
+
+// Err returns nil when the command succeeded, and an error with the exit code and the standard error otherwise.
+func (r *Result) Err() error {
+	if r.Succeeded() {
+		return nil
+	}
+	return fmt.Errorf("command %q exited with %d: %s", r.Command, r.ExitCode, strings.TrimSpace(r.Stderr))
+}
+
+// Run runs command with /bin/sh -c, and waits for it to exit.  It returns an error when the command cannot be
+// started, or when it is killed because timeout elapsed (a zero timeout is unbounded) or ctx is done.  A command
+// exiting with a non-zero code is not an error, see Result.Err.
+func Run(ctx context.Context, command string, timeout time.Duration) (*Result, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	log.Debugf("Executing command: %s", command)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// The command runs in its own process group, so that the processes of a pipeline are killed together.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	var err error
+	select {
+	case err = <-exited:
+	case <-ctx.Done():
+		if killErr := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); killErr != nil {
+			log.Warnf("Failed to kill the command %q: %v", command, killErr)
+		}
+		<-exited
+		return nil, fmt.Errorf("command %q stopped: %w", command, ctx.Err())
+	}
+
+	result := &Result{Command: command, Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		return nil, err
+	}
+	log.Debugf("Command %q exited with %d", command, result.ExitCode)
+	return result, nil
+}
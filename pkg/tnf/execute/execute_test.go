@@ -0,0 +1,92 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package execute
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		command  string
+		expected Result
+	}{
+		{
+			command:  "echo hello; echo world",
+			expected: Result{Stdout: "hello\nworld\n"},
+		},
+		{
+			command:  "echo out; echo err >&2; exit 3",
+			expected: Result{Stdout: "out\n", Stderr: "err\n", ExitCode: 3},
+		},
+		{
+			// No escaping of the quotes, new lines and backslashes.
+			command:  `printf '%s\n' "a \"quoted\" \\ line"`,
+			expected: Result{Stdout: "a \"quoted\" \\ line\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		result, err := Run(context.Background(), tc.command, time.Second)
+		assert.Nil(t, err)
+		tc.expected.Command = tc.command
+		assert.Equal(t, &tc.expected, result)
+	}
+}
+
+func TestRun_LargeOutput(t *testing.T) {
+	const size = 8 * 1024 * 1024
+	result, err := Run(context.Background(), "head -c 8388608 /dev/zero | tr '\\0' x", 10*time.Second)
+	assert.Nil(t, err)
+	assert.Len(t, result.Stdout, size)
+	assert.True(t, result.Succeeded())
+}
+
+func TestRun_Timeout(t *testing.T) {
+	start := time.Now()
+	// The whole pipeline is killed.
+	result, err := Run(context.Background(), "sleep 30 | cat", 50*time.Millisecond)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := Run(ctx, "sleep 30", 0)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestRun_StartError(t *testing.T) {
+	defer func(orig string) { shell = orig }(shell)
+	shell = "/nonexistent/sh"
+	_, err := Run(context.Background(), "true", time.Second)
+	assert.NotNil(t, err)
+}
+
+func TestResult_Err(t *testing.T) {
+	assert.Nil(t, (&Result{Command: "true"}).Err())
+	err := (&Result{Command: "false", Stderr: "failed\n", ExitCode: 1}).Err()
+	assert.True(t, strings.HasSuffix(err.Error(), "exited with 1: failed"))
+}
@@ -23,7 +23,7 @@ import (
 	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
 	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
-	"github.com/test-network-function/test-network-function/test-network-function/common"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
 )
 
 const (
@@ -56,7 +56,7 @@ const (
 // NewAutomountService returns a new automountservice handler struct.
 func NewAutomountService(options ...func(*AutomountService)) *AutomountService {
 	as := &AutomountService{
-		timeout: common.DefaultTimeout,
+		timeout: environment.DefaultTimeout,
 		result:  tnf.ERROR,
 		token:   TokenNotSet,
 	}
@@ -17,9 +17,12 @@
 package clusterrolebinding
 
 import (
+	"bufio"
+	"io"
 	"strings"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
 	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
@@ -27,6 +30,8 @@ import (
 
 const (
 	crbRegex = "(?s).+"
+	// maxLineSize bounds the size of a line of the command output.
+	maxLineSize = 1024 * 1024
 )
 
 // ClusterRoleBinding holds information derived from running "oc get clusterrolebindings" on the command line.
@@ -85,18 +90,26 @@ func (crb *ClusterRoleBinding) ReelFirst() *reel.Step {
 
 // ReelMatch ensures that there are no ClusterRoleBindings matched in the command output.
 func (crb *ClusterRoleBinding) ReelMatch(_, _, match string) *reel.Step {
+	return crb.parse(strings.NewReader(match))
+}
+
+// ReelMatchOutput is ReelMatch for a streamed step, reading the lines of the output instead of copying it to a string.
+func (crb *ClusterRoleBinding) ReelMatchOutput(_ string, output *reel.Output) *reel.Step {
+	return crb.parse(output.Reader())
+}
+
+// parse saves the bindings listed in the command output.
+func (crb *ClusterRoleBinding) parse(output io.Reader) *reel.Step {
 	const (
 		nameIdx = 0
 	)
 
-	lines := strings.Split(match, "\n")[1:] // First line is the headers/titles line
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(nil, maxLineSize)
+	scanner.Scan() // First line is the headers/titles line
 
-		fields := strings.Fields(line)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
 
 		if len(fields) == 0 {
 			continue
@@ -105,7 +118,10 @@ func (crb *ClusterRoleBinding) ReelMatch(_, _, match string) *reel.Step {
 		crb.clusterRoleBindings = append(crb.clusterRoleBindings, fields[nameIdx])
 	}
 
-	if len(crb.clusterRoleBindings) == 0 {
+	if err := scanner.Err(); err != nil {
+		log.Errorf("Cannot read the command output. Error: %s", err)
+		crb.result = tnf.ERROR
+	} else if len(crb.clusterRoleBindings) == 0 {
 		crb.result = tnf.SUCCESS
 	} else {
 		crb.result = tnf.FAILURE
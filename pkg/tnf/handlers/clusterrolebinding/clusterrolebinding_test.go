@@ -17,6 +17,8 @@
 package clusterrolebinding_test
 
 import (
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
@@ -24,6 +26,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
 	crb "github.com/test-network-function/test-network-function/pkg/tnf/handlers/clusterrolebinding"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
 )
 
 func Test_NewClusterRoleBinding(t *testing.T) {
@@ -80,6 +84,26 @@ func Test_ReelMatchFail(t *testing.T) {
 	assert.Len(t, newCrb.GetClusterRoleBindings(), 3)
 }
 
+func Test_ReelMatchOutput(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf 'NAME SERVICE_ACCOUNTS\ntest-other [map[kind:ServiceAccount name:otherServiceAccount namespace:testPodNamespace ]]\\n'\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "oc"), []byte(script), 0o755)) //nolint:gosec // An executable script
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SHELL", "/bin/sh")
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, testTimeoutDuration)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+
+	newCrb := crb.NewClusterRoleBinding(testTimeoutDuration, testServiceAccount, testPodNamespace)
+	test, err := tnf.NewTest(context.GetExpecter(), newCrb, []reel.Handler{newCrb}, context.GetErrorChannel())
+	assert.Nil(t, err)
+	result, err := test.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.SUCCESS, result)
+	assert.Len(t, newCrb.GetClusterRoleBindings(), 0)
+}
+
 // Just ensure there are no panics.
 func Test_ReelEof(t *testing.T) {
 	newCrb := crb.NewClusterRoleBinding(testTimeoutDuration, testServiceAccount, testPodNamespace)
@@ -22,7 +22,11 @@ import (
 
 	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
 	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/intcondition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/numbercondition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/quantitycondition"
 	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/stringcondition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/structuredcondition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/versioncondition"
 )
 
 const (
@@ -35,12 +39,20 @@ const (
 )
 
 // Assertion provides the ability to assert a Condition for the string extracted from GroupIdx of the parent
-// ResultContext Pattern
+// ResultContext Pattern.  Alternatively, an Assertion may combine nested Assertions using exactly one of AllOf, AnyOf
+// or Not, in which case GroupIdx and Condition are unused.  Since each nested Assertion has its own GroupIdx, a
+// combinator may relate several groups of the same match.
 type Assertion struct {
 	// GroupIdx is the index in the match string used in the Assertion.
 	GroupIdx int `json:"groupIdx" yaml:"groupIdx"`
 	// Condition is the condition.Condition asserted in this Assertion.
 	Condition *condition.Condition `json:"condition" yaml:"condition"`
+	// AllOf is a list of Assertions which must all evaluate as true.
+	AllOf []Assertion `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	// AnyOf is a list of Assertions of which at least one must evaluate as true.
+	AnyOf []Assertion `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	// Not is an Assertion which must evaluate as false.
+	Not *Assertion `json:"not,omitempty" yaml:"not,omitempty"`
 }
 
 // unmarshalGroupIdxJSON is a helper function used to json.Unmarshal an Assertion.  The implementation is meant to mock
@@ -107,6 +119,117 @@ func (a *Assertion) unmarshalIntComparisonCondition(conditionJSONMessage *json.R
 	return nil
 }
 
+// unmarshalRegexCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// stringcondition.RegexCondition.
+func (a *Assertion) unmarshalRegexCondition(conditionJSONMessage *json.RawMessage) error {
+	var regexCondition stringcondition.RegexCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &regexCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = regexCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalInCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// stringcondition.InCondition.
+func (a *Assertion) unmarshalInCondition(conditionJSONMessage *json.RawMessage) error {
+	var inCondition stringcondition.InCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &inCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = inCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalNumberRangeCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// numbercondition.RangeCondition.
+func (a *Assertion) unmarshalNumberRangeCondition(conditionJSONMessage *json.RawMessage) error {
+	var numberRangeCondition numbercondition.RangeCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &numberRangeCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = numberRangeCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalSemverCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// versioncondition.SemverCondition.
+func (a *Assertion) unmarshalSemverCondition(conditionJSONMessage *json.RawMessage) error {
+	var semverCondition versioncondition.SemverCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &semverCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = semverCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalBytesRangeCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// quantitycondition.BytesRangeCondition.
+func (a *Assertion) unmarshalBytesRangeCondition(conditionJSONMessage *json.RawMessage) error {
+	var bytesRangeCondition quantitycondition.BytesRangeCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &bytesRangeCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = bytesRangeCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalDurationRangeCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// quantitycondition.DurationRangeCondition.
+func (a *Assertion) unmarshalDurationRangeCondition(conditionJSONMessage *json.RawMessage) error {
+	var durationRangeCondition quantitycondition.DurationRangeCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &durationRangeCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = durationRangeCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalJSONPathCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// structuredcondition.JSONPathCondition.
+func (a *Assertion) unmarshalJSONPathCondition(conditionJSONMessage *json.RawMessage) error {
+	var jsonPathCondition structuredcondition.JSONPathCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &jsonPathCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = jsonPathCondition
+	a.Condition = &cond
+	return nil
+}
+
+// unmarshalCELCondition is a custom strategy used to json.Unmarshal an Assertion utilizing
+// structuredcondition.CELCondition.
+func (a *Assertion) unmarshalCELCondition(conditionJSONMessage *json.RawMessage) error {
+	var celCondition structuredcondition.CELCondition
+	if err := json.Unmarshal(*conditionJSONMessage, &celCondition); err != nil {
+		return err
+	}
+	var cond condition.Condition = celCondition
+	a.Condition = &cond
+	return nil
+}
+
+// conditionUnmarshalers maps each known condition type to the custom strategy used to json.Unmarshal it.
+var conditionUnmarshalers = map[string]func(*Assertion, *json.RawMessage) error{
+	stringcondition.EqualsConditionKey:          (*Assertion).unmarshalEqualsCondition,
+	intcondition.IsIntConditionKey:              (*Assertion).unmarshalIsIntCondition,
+	intcondition.ComparisonConditionKey:         (*Assertion).unmarshalIntComparisonCondition,
+	stringcondition.RegexConditionKey:           (*Assertion).unmarshalRegexCondition,
+	stringcondition.InConditionKey:              (*Assertion).unmarshalInCondition,
+	numbercondition.RangeConditionKey:           (*Assertion).unmarshalNumberRangeCondition,
+	versioncondition.SemverConditionKey:         (*Assertion).unmarshalSemverCondition,
+	quantitycondition.BytesRangeConditionKey:    (*Assertion).unmarshalBytesRangeCondition,
+	quantitycondition.DurationRangeConditionKey: (*Assertion).unmarshalDurationRangeCondition,
+	structuredcondition.JSONPathConditionKey:    (*Assertion).unmarshalJSONPathCondition,
+	structuredcondition.CELConditionKey:         (*Assertion).unmarshalCELCondition,
+}
+
 // unmarshalConditionJSON is a custom strategy used to json.Unmarshal an Assertion utilizing
 // any known condition.Condition.
 func (a *Assertion) unmarshalConditionJSON(objMap map[string]*json.RawMessage) error {
@@ -122,22 +245,11 @@ func (a *Assertion) unmarshalConditionJSON(objMap map[string]*json.RawMessage) e
 		if err != nil {
 			return err
 		}
-		switch typ {
-		case stringcondition.EqualsConditionKey:
-			if err := a.unmarshalEqualsCondition(conditionJSONMessage); err != nil {
-				return err
-			}
-		case intcondition.IsIntConditionKey:
-			if err := a.unmarshalIsIntCondition(conditionJSONMessage); err != nil {
-				return err
-			}
-		case intcondition.ComparisonConditionKey:
-			if err := a.unmarshalIntComparisonCondition(conditionJSONMessage); err != nil {
-				return err
-			}
-		default:
+		unmarshal, ok := conditionUnmarshalers[typ]
+		if !ok {
 			return fmt.Errorf("unrecognized condition type: \"%s\"", typ)
 		}
+		return unmarshal(a, conditionJSONMessage)
 	}
 	return nil
 }
@@ -150,6 +262,10 @@ func (a *Assertion) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	if isCombinator, err := a.unmarshalCombinatorJSON(objMap); isCombinator || err != nil {
+		return err
+	}
+
 	if err = a.unmarshalGroupIdxJSON(objMap); err != nil {
 		return err
 	}
@@ -129,3 +129,8 @@ func determineLogicType(logicMap map[string]*json.RawMessage) (string, error) {
 	}
 	return "", fmt.Errorf("mandatory \"%s\" key is missing", TypeKey)
 }
+
+// Interpolate returns a copy of the Assertions whose conditions are interpolated, see condition.Interpolator.
+func (a *Assertions) Interpolate(interpolate func(string) (string, error)) ([]Assertion, error) {
+	return interpolateAll(a.Assertions, interpolate)
+}
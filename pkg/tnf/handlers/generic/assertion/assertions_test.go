@@ -56,6 +56,24 @@ var assertionsTestCases = map[string]assertionsTestCase{
 		expectedEvaluationError:  false,
 	},
 
+	// Positive Test:  The regex, in, semver, numberRange, bytesRange and durationRange conditions "and"-ed together.
+	"extended_conditions_positive_test": {
+		match:                    "worker-0 v4.11.2 1.5 512Mi 1m30s",
+		regex:                    *regexp.MustCompile(`(\S+) v(\S+) (\S+) (\S+) (\S+)`),
+		expectedUnmarshalError:   false,
+		expectedEvaluationResult: true,
+		expectedEvaluationError:  false,
+	},
+
+	// Positive Test:  "either hostNetwork is false or the namespace is allowlisted", nesting allOf, anyOf and not.
+	"combinators_positive_test": {
+		match:                    "namespace=openshift-sdn hostNetwork=true",
+		regex:                    *regexp.MustCompile(`namespace=(\S+) hostNetwork=(\S+)`),
+		expectedUnmarshalError:   false,
+		expectedEvaluationResult: true,
+		expectedEvaluationError:  false,
+	},
+
 	// Negative Test:  When bad JSON is given.
 	"not_json": {
 		expectedUnmarshalError:       true,
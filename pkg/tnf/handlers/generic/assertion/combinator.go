@@ -0,0 +1,177 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package assertion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+)
+
+const (
+	// AllOfKey is the JSON key which indicates that all the nested assertions must evaluate as true.
+	AllOfKey = "allOf"
+	// AnyOfKey is the JSON key which indicates that at least one of the nested assertions must evaluate as true.
+	AnyOfKey = "anyOf"
+	// NotKey is the JSON key which indicates that the nested assertion must evaluate as false.
+	NotKey = "not"
+)
+
+// combinatorKeys lists the JSON keys of the Assertion combinators.
+var combinatorKeys = []string{AllOfKey, AnyOfKey, NotKey}
+
+// IsCombinator returns whether the Assertion combines nested Assertions rather than asserting a Condition.
+func (a *Assertion) IsCombinator() bool {
+	return a.AllOf != nil || a.AnyOf != nil || a.Not != nil
+}
+
+// Evaluate evaluates the Assertion against a match.  A combinator short-circuits as soon as its result is known, and
+// any error encountered while evaluating a nested Assertion is returned.
+func (a *Assertion) Evaluate(match string, regex *regexp.Regexp) (bool, error) {
+	switch {
+	case a.AllOf != nil:
+		for i := range a.AllOf {
+			result, err := a.AllOf[i].Evaluate(match, regex)
+			if !result || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	case a.AnyOf != nil:
+		for i := range a.AnyOf {
+			result, err := a.AnyOf[i].Evaluate(match, regex)
+			if err != nil {
+				return false, err
+			}
+			if result {
+				return true, nil
+			}
+		}
+		return false, nil
+	case a.Not != nil:
+		result, err := a.Not.Evaluate(match, regex)
+		if err != nil {
+			return false, err
+		}
+		return !result, nil
+	case a.Condition == nil:
+		return false, errors.New("assertion has neither a condition nor a combinator")
+	default:
+		return (*a.Condition).Evaluate(match, regex, a.GroupIdx)
+	}
+}
+
+// interpolate returns a copy of the Assertion whose conditions, including those of nested Assertions, are
+// interpolated.  See condition.Interpolator.
+func (a *Assertion) interpolate(interpolate func(string) (string, error)) (Assertion, error) {
+	interpolated := *a
+	var err error
+	switch {
+	case a.AllOf != nil:
+		interpolated.AllOf, err = interpolateAll(a.AllOf, interpolate)
+	case a.AnyOf != nil:
+		interpolated.AnyOf, err = interpolateAll(a.AnyOf, interpolate)
+	case a.Not != nil:
+		var not Assertion
+		not, err = a.Not.interpolate(interpolate)
+		interpolated.Not = &not
+	case a.Condition != nil:
+		interpolator, ok := (*a.Condition).(condition.Interpolator)
+		if !ok {
+			break
+		}
+		var cond condition.Condition
+		cond, err = interpolator.Interpolate(interpolate)
+		interpolated.Condition = &cond
+	}
+	return interpolated, err
+}
+
+// interpolateAll interpolates each of assertions into a new slice.
+func interpolateAll(assertions []Assertion, interpolate func(string) (string, error)) ([]Assertion, error) {
+	interpolated := make([]Assertion, len(assertions))
+	for i := range assertions {
+		var err error
+		if interpolated[i], err = assertions[i].interpolate(interpolate); err != nil {
+			return nil, err
+		}
+	}
+	return interpolated, nil
+}
+
+// unmarshalCombinatorJSON is a custom strategy used to json.Unmarshal an Assertion combinator.  It returns whether the
+// payload is a combinator, in which case it must not also define a groupIdx or a condition.
+func (a *Assertion) unmarshalCombinatorJSON(objMap map[string]*json.RawMessage) (bool, error) {
+	var found []string
+	for _, key := range combinatorKeys {
+		if _, ok := objMap[key]; ok {
+			found = append(found, key)
+		}
+	}
+	if len(found) == 0 {
+		return false, nil
+	}
+	if len(found) > 1 {
+		return true, fmt.Errorf("assertion combines %q, but only one of %q is allowed", found, combinatorKeys)
+	}
+	for _, key := range []string{GroupIndexKey, ConditionKey} {
+		if _, ok := objMap[key]; ok {
+			return true, fmt.Errorf("assertion combinator %q cannot also define %q", found[0], key)
+		}
+	}
+
+	var err error
+	switch found[0] {
+	case AllOfKey:
+		err = unmarshalNestedAssertions(objMap[AllOfKey], AllOfKey, &a.AllOf)
+	case AnyOfKey:
+		err = unmarshalNestedAssertions(objMap[AnyOfKey], AnyOfKey, &a.AnyOf)
+	case NotKey:
+		a.Not = &Assertion{}
+		err = json.Unmarshal(*objMap[NotKey], a.Not)
+	}
+	return true, err
+}
+
+// unmarshalNestedAssertions is a helper used to json.Unmarshal the non-empty array of Assertions of a combinator.
+func unmarshalNestedAssertions(jsonMessage *json.RawMessage, key string, assertions *[]Assertion) error {
+	if err := json.Unmarshal(*jsonMessage, assertions); err != nil {
+		return err
+	}
+	if len(*assertions) == 0 {
+		return fmt.Errorf("assertion combinator %q requires at least one assertion", key)
+	}
+	return nil
+}
+
+// MarshalJSON serializes an Assertion, omitting the groupIdx and condition of a combinator.
+func (a Assertion) MarshalJSON() ([]byte, error) {
+	if a.IsCombinator() {
+		return json.Marshal(struct {
+			AllOf []Assertion `json:"allOf,omitempty"`
+			AnyOf []Assertion `json:"anyOf,omitempty"`
+			Not   *Assertion  `json:"not,omitempty"`
+		}{AllOf: a.AllOf, AnyOf: a.AnyOf, Not: a.Not})
+	}
+	return json.Marshal(struct {
+		GroupIdx  int                  `json:"groupIdx"`
+		Condition *condition.Condition `json:"condition"`
+	}{GroupIdx: a.GroupIdx, Condition: a.Condition})
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package assertion_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/assertion"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/intcondition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/stringcondition"
+)
+
+var hostNetworkRegex = regexp.MustCompile(`namespace=(\S+) hostNetwork=(\S+)`)
+
+// equals is a helper which asserts that group groupIdx equals expected.
+func equals(groupIdx int, expected string) assertion.Assertion {
+	var cond condition.Condition = stringcondition.NewEqualsCondition(expected)
+	return assertion.Assertion{GroupIdx: groupIdx, Condition: &cond}
+}
+
+// hostNetworkAllowed asserts that either hostNetwork is false or the pod is in the allowlisted namespace.
+var hostNetworkAllowed = assertion.Assertion{AnyOf: []assertion.Assertion{
+	equals(2, "false"),
+	equals(1, "openshift-sdn"),
+}}
+
+func TestAssertion_Evaluate(t *testing.T) {
+	var isInt condition.Condition = intcondition.NewIsIntCondition()
+	notAnInt := assertion.Assertion{GroupIdx: 1, Condition: &isInt}
+
+	testCases := map[string]struct {
+		assertion      assertion.Assertion
+		match          string
+		expectedResult bool
+		expectedError  bool
+	}{
+		"anyOf_first":       {assertion: hostNetworkAllowed, match: "namespace=default hostNetwork=false", expectedResult: true},
+		"anyOf_second":      {assertion: hostNetworkAllowed, match: "namespace=openshift-sdn hostNetwork=true", expectedResult: true},
+		"anyOf_none":        {assertion: hostNetworkAllowed, match: "namespace=default hostNetwork=true", expectedResult: false},
+		"not":               {assertion: assertion.Assertion{Not: &hostNetworkAllowed}, match: "namespace=default hostNetwork=true", expectedResult: true},
+		"allOf_nested":      {assertion: assertion.Assertion{AllOf: []assertion.Assertion{hostNetworkAllowed, equals(1, "default")}}, match: "namespace=default hostNetwork=false", expectedResult: true},
+		"allOf_false":       {assertion: assertion.Assertion{AllOf: []assertion.Assertion{hostNetworkAllowed, equals(1, "default")}}, match: "namespace=openshift-sdn hostNetwork=true", expectedResult: false},
+		"allOf_error":       {assertion: assertion.Assertion{AllOf: []assertion.Assertion{equals(1, "default"), notAnInt}}, match: "namespace=default hostNetwork=true", expectedError: true},
+		"not_error":         {assertion: assertion.Assertion{Not: &notAnInt}, match: "namespace=default hostNetwork=true", expectedError: true},
+		"anyOf_error":       {assertion: assertion.Assertion{AnyOf: []assertion.Assertion{equals(1, "x"), notAnInt}}, match: "namespace=default hostNetwork=true", expectedError: true},
+		"missing_condition": {assertion: assertion.Assertion{GroupIdx: 1}, match: "namespace=default hostNetwork=true", expectedError: true},
+	}
+	for name, testCase := range testCases {
+		actualResult, actualError := testCase.assertion.Evaluate(testCase.match, hostNetworkRegex)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
+
+func TestAssertion_UnmarshalJSONCombinators(t *testing.T) {
+	var a assertion.Assertion
+	err := json.Unmarshal([]byte(`{"not": {"anyOf": [
+		{"groupIdx": 2, "condition": {"type": "equals", "expected": "false"}},
+		{"groupIdx": 1, "condition": {"type": "equals", "expected": "openshift-sdn"}}
+	]}}`), &a)
+	assert.Nil(t, err)
+	assert.True(t, a.IsCombinator())
+	assert.Len(t, a.Not.AnyOf, 2)
+	assert.Equal(t, 1, a.Not.AnyOf[1].GroupIdx)
+
+	result, err := a.Evaluate("namespace=default hostNetwork=true", hostNetworkRegex)
+	assert.Nil(t, err)
+	assert.True(t, result)
+
+	testCases := map[string]string{
+		`{"allOf": [], "anyOf": []}`:                                     `assertion combines ["allOf" "anyOf"], but only one of ["allOf" "anyOf" "not"] is allowed`,
+		`{"allOf": [{"groupIdx": 1}], "groupIdx": 1}`:                    `assertion combinator "allOf" cannot also define "groupIdx"`,
+		`{"anyOf": []}`:                                                  `assertion combinator "anyOf" requires at least one assertion`,
+		`{"not": {"condition": {"type": "isInt"}}}`:                      `required field "groupIdx" is missing from the JSON payload`,
+		`{"allOf": [{"groupIdx": 1, "condition": {"type": "unknown"}}]}`: `unrecognized condition type: "unknown"`,
+	}
+	for payload, expectedError := range testCases {
+		err = json.Unmarshal([]byte(payload), &assertion.Assertion{})
+		assert.EqualError(t, err, expectedError, payload)
+	}
+}
+
+func TestAssertion_MarshalJSON(t *testing.T) {
+	contents, err := json.Marshal(assertion.Assertion{Not: &hostNetworkAllowed})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"not": {"anyOf": [
+		{"groupIdx": 2, "condition": {"type": "equals", "expected": "false"}},
+		{"groupIdx": 1, "condition": {"type": "equals", "expected": "openshift-sdn"}}
+	]}}`, string(contents))
+
+	var roundTripped assertion.Assertion
+	assert.Nil(t, json.Unmarshal(contents, &roundTripped))
+	result, err := roundTripped.Evaluate("namespace=default hostNetwork=true", hostNetworkRegex)
+	assert.Nil(t, err)
+	assert.True(t, result)
+}
+
+func TestAssertions_InterpolateCombinators(t *testing.T) {
+	assertions := &assertion.Assertions{Assertions: []assertion.Assertion{
+		{Not: &assertion.Assertion{AnyOf: []assertion.Assertion{equals(1, "%{namespace}")}}},
+	}}
+	interpolated, err := assertions.Interpolate(func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%{namespace}", "default"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, stringcondition.EqualsCondition{Type: stringcondition.EqualsConditionKey, Expected: "default"},
+		*interpolated[0].Not.AnyOf[0].Condition)
+	// The original assertions are left unchanged.
+	assert.Equal(t, "%{namespace}", (*assertions.Assertions[0].Not.AnyOf[0].Condition).(*stringcondition.EqualsCondition).Expected)
+}
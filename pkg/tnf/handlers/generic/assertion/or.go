@@ -40,8 +40,8 @@ func NewOrBooleanLogic() *OrBooleanLogic {
 
 // Evaluate evaluates an arbitrarily sized array of Assertion and ensures at least one assertion passes.
 func (o OrBooleanLogic) Evaluate(assertions []Assertion, match string, regex *regexp.Regexp) (bool, error) {
-	for _, assertion := range assertions {
-		assertionResult, err := (*assertion.Condition).Evaluate(match, regex, assertion.GroupIdx)
+	for i := range assertions {
+		assertionResult, err := assertions[i].Evaluate(match, regex)
 		if err != nil {
 			return false, err
 		}
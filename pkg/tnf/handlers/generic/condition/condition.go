@@ -16,7 +16,10 @@
 
 package condition
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+)
 
 const (
 	// TypeKey is the JSON key indicating a condition payload.
@@ -31,3 +34,20 @@ type Condition interface {
 	// Evaluate evaluates a Condition implementation for groupIdx group of a matched expression.
 	Evaluate(match string, regex *regexp.Regexp, groupIdx int) (bool, error)
 }
+
+// Interpolator is implemented by the Conditions whose operands may reference the variables captured by a test, e.g.
+// an expected string of "%{podIP}".
+type Interpolator interface {
+
+	// Interpolate returns a copy of the Condition whose string operands are passed through interpolate.
+	Interpolate(interpolate func(string) (string, error)) (Condition, error)
+}
+
+// GroupMatch returns the string captured by group groupIdx of the regex match of match.
+func GroupMatch(match string, regex *regexp.Regexp, groupIdx int) (string, error) {
+	matches := regex.FindStringSubmatch(match)
+	if groupIdx < 0 || groupIdx >= len(matches) {
+		return "", fmt.Errorf("matches \"%s\" has no index: %d", matches, groupIdx)
+	}
+	return matches[groupIdx], nil
+}
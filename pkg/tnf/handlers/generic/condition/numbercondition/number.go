@@ -0,0 +1,76 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package numbercondition
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+)
+
+const (
+	// RangeConditionKey is the sentinel key identifying a numeric range check.
+	RangeConditionKey = "numberRange"
+)
+
+// RangeCondition is an implementation of the condition.Condition interface which converts a match string to a float,
+// then checks that it lies within the inclusive range [Min, Max].  Either bound may be omitted to leave that side of
+// the range open, but not both.
+type RangeCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Min is the inclusive lower bound of the range.
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	// Max is the inclusive upper bound of the range.
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// NewRangeCondition creates a RangeCondition.  Pass nil to leave a bound open.
+func NewRangeCondition(min, max *float64) *RangeCondition {
+	return &RangeCondition{Type: RangeConditionKey, Min: min, Max: max}
+}
+
+// Evaluate evaluates whether a match can be converted to a float which lies within the range.
+func (r RangeCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	if r.Min == nil && r.Max == nil {
+		return false, errors.New("range has neither a min nor a max")
+	}
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(foundMatch), 64)
+	if err != nil {
+		return false, fmt.Errorf("match \"%s\" cannot be converted to a number", foundMatch)
+	}
+	return InRange(val, r.Min, r.Max), nil
+}
+
+// InRange returns whether val lies within the inclusive range [min, max], where a nil bound is open.
+func InRange(val float64, min, max *float64) bool {
+	if min != nil && val < *min {
+		return false
+	}
+	if max != nil && val > *max {
+		return false
+	}
+	return true
+}
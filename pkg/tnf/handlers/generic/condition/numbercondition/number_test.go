@@ -0,0 +1,65 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package numbercondition_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/numbercondition"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestNewRangeCondition(t *testing.T) {
+	c := numbercondition.NewRangeCondition(float(1), nil)
+	assert.Equal(t, numbercondition.RangeConditionKey, c.Type)
+	assert.Equal(t, 1.0, *c.Min)
+	assert.Nil(t, c.Max)
+}
+
+func TestRangeCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		min            *float64
+		max            *float64
+		match          string
+		matchIdx       int
+		expectedResult bool
+		expectedError  bool
+	}{
+		"within_range":        {min: float(0.5), max: float(1.5), match: "load: 1.25", matchIdx: 1, expectedResult: true},
+		"lower_bound":         {min: float(0.5), max: float(1.5), match: "load: 0.5", matchIdx: 1, expectedResult: true},
+		"upper_bound":         {min: float(0.5), max: float(1.5), match: "load: 1.5", matchIdx: 1, expectedResult: true},
+		"below_range":         {min: float(0.5), max: float(1.5), match: "load: 0.49", matchIdx: 1, expectedResult: false},
+		"above_range":         {min: float(0.5), max: float(1.5), match: "load: 2", matchIdx: 1, expectedResult: false},
+		"open_max":            {min: float(-1), match: "load: 1e6", matchIdx: 1, expectedResult: true},
+		"open_min":            {max: float(-1), match: "load: -3", matchIdx: 1, expectedResult: true},
+		"no_bounds":           {match: "load: 1", matchIdx: 1, expectedError: true},
+		"not_a_number":        {min: float(0), match: "load: high", matchIdx: 1, expectedError: true},
+		"index_out_of_bounds": {min: float(0), match: "load: 1", matchIdx: 2, expectedError: true},
+	}
+	regex := regexp.MustCompile(`load: (\S+)`)
+	for name, testCase := range testCases {
+		c := numbercondition.NewRangeCondition(testCase.min, testCase.max)
+		actualResult, actualError := c.Evaluate(testCase.match, regex, testCase.matchIdx)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
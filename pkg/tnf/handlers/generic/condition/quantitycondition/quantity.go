@@ -0,0 +1,186 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package quantitycondition
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/numbercondition"
+)
+
+const (
+	// BytesRangeConditionKey is the sentinel key identifying a byte quantity range check.
+	BytesRangeConditionKey = "bytesRange"
+	// DurationRangeConditionKey is the sentinel key identifying a duration range check.
+	DurationRangeConditionKey = "durationRange"
+)
+
+var (
+	bytesRegex = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([A-Za-z]*)$`)
+
+	// byteUnits maps both the decimal (SI) and binary (IEC) unit suffixes to their multipliers.  The suffixes used by
+	// Kubernetes resource quantities ("k", "Mi") and by common tools ("kB", "MiB", "G") are all accepted.  "kB" is
+	// binary, as in /proc/meminfo.
+	byteUnits = map[string]float64{
+		"": 1, "B": 1,
+		"k": 1e3, "K": 1e3, "kB": 1 << 10, "KB": 1e3,
+		"M": 1e6, "MB": 1e6,
+		"G": 1e9, "GB": 1e9,
+		"T": 1e12, "TB": 1e12,
+		"P": 1e15, "PB": 1e15,
+		"Ki": 1 << 10, "KiB": 1 << 10,
+		"Mi": 1 << 20, "MiB": 1 << 20,
+		"Gi": 1 << 30, "GiB": 1 << 30,
+		"Ti": 1 << 40, "TiB": 1 << 40,
+		"Pi": 1 << 50, "PiB": 1 << 50,
+	}
+)
+
+// ParseBytes converts a byte quantity such as "512Mi", "1.5 GB" or "4096" to a number of bytes.
+func ParseBytes(quantity string) (float64, error) {
+	matches := bytesRegex.FindStringSubmatch(strings.TrimSpace(quantity))
+	if matches == nil {
+		return 0, fmt.Errorf("\"%s\" is not a byte quantity", quantity)
+	}
+	multiplier, ok := byteUnits[matches[2]]
+	if !ok {
+		return 0, fmt.Errorf("\"%s\" has an unknown byte unit: %s", quantity, matches[2])
+	}
+	val, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	return val * multiplier, nil
+}
+
+// parseDuration converts a duration such as "1m30s" to a number of nanoseconds.
+func parseDuration(duration string) (float64, error) {
+	val, err := time.ParseDuration(strings.TrimSpace(duration))
+	if err != nil {
+		return 0, err
+	}
+	return float64(val), nil
+}
+
+// evaluateRange parses the match and the min and max bounds using parse, then checks that the match lies within the
+// inclusive range.  An empty bound is open.
+func evaluateRange(match string, regex *regexp.Regexp, matchIdx int, min, max string, parse func(string) (float64, error)) (bool, error) {
+	if min == "" && max == "" {
+		return false, errors.New("range has neither a min nor a max")
+	}
+	var bounds [2]*float64
+	for i, bound := range []string{min, max} {
+		if bound == "" {
+			continue
+		}
+		val, err := parse(bound)
+		if err != nil {
+			return false, fmt.Errorf("invalid range bound: %w", err)
+		}
+		bounds[i] = &val
+	}
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	val, err := parse(foundMatch)
+	if err != nil {
+		return false, err
+	}
+	return numbercondition.InRange(val, bounds[0], bounds[1]), nil
+}
+
+// interpolateBounds passes both bounds through interpolate.
+func interpolateBounds(min, max string, interpolate func(string) (string, error)) (string, string, error) {
+	min, err := interpolate(min)
+	if err != nil {
+		return "", "", err
+	}
+	max, err = interpolate(max)
+	if err != nil {
+		return "", "", err
+	}
+	return min, max, nil
+}
+
+// BytesRangeCondition is an implementation of the condition.Condition interface which converts a match string to a
+// number of bytes, then checks that it lies within the inclusive range [Min, Max].  Min, Max and the match may all use
+// decimal ("k", "MB") or binary ("Ki", "MiB") units.  Either bound may be omitted, but not both.
+type BytesRangeCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Min is the inclusive lower bound of the range, e.g. "1Gi".
+	Min string `json:"min,omitempty" yaml:"min,omitempty"`
+	// Max is the inclusive upper bound of the range, e.g. "2Gi".
+	Max string `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// NewBytesRangeCondition creates a BytesRangeCondition.  Pass "" to leave a bound open.
+func NewBytesRangeCondition(min, max string) *BytesRangeCondition {
+	return &BytesRangeCondition{Type: BytesRangeConditionKey, Min: min, Max: max}
+}
+
+// Evaluate evaluates whether a match is a byte quantity which lies within the range.
+func (b BytesRangeCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	return evaluateRange(match, regex, matchIdx, b.Min, b.Max, ParseBytes)
+}
+
+// Interpolate returns a copy of the BytesRangeCondition whose bounds are interpolated.
+func (b BytesRangeCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	var err error
+	if b.Min, b.Max, err = interpolateBounds(b.Min, b.Max, interpolate); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DurationRangeCondition is an implementation of the condition.Condition interface which converts a match string to a
+// duration, then checks that it lies within the inclusive range [Min, Max].  Min, Max and the match use the
+// time.ParseDuration format, e.g. "1m30s" or "250ms".  Either bound may be omitted, but not both.
+type DurationRangeCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Min is the inclusive lower bound of the range.
+	Min string `json:"min,omitempty" yaml:"min,omitempty"`
+	// Max is the inclusive upper bound of the range.
+	Max string `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// NewDurationRangeCondition creates a DurationRangeCondition.  Pass "" to leave a bound open.
+func NewDurationRangeCondition(min, max string) *DurationRangeCondition {
+	return &DurationRangeCondition{Type: DurationRangeConditionKey, Min: min, Max: max}
+}
+
+// Evaluate evaluates whether a match is a duration which lies within the range.
+func (d DurationRangeCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	return evaluateRange(match, regex, matchIdx, d.Min, d.Max, parseDuration)
+}
+
+// Interpolate returns a copy of the DurationRangeCondition whose bounds are interpolated.
+func (d DurationRangeCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	var err error
+	if d.Min, d.Max, err = interpolateBounds(d.Min, d.Max, interpolate); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
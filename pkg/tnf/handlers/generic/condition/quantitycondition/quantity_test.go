@@ -0,0 +1,122 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package quantitycondition_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/quantitycondition"
+)
+
+func TestParseBytes(t *testing.T) {
+	testCases := map[string]float64{
+		"4096":    4096,
+		"512B":    512,
+		"1k":      1000,
+		"2048 kB": 2048 * 1024,
+		"1.5 GB":  1.5e9,
+		"512Mi":   512 * 1024 * 1024,
+		"2GiB":    2 * 1024 * 1024 * 1024,
+		" 10Ki  ": 10 * 1024,
+	}
+	for quantity, expected := range testCases {
+		actual, err := quantitycondition.ParseBytes(quantity)
+		assert.Nil(t, err, quantity)
+		assert.Equal(t, expected, actual, quantity)
+	}
+
+	for _, quantity := range []string{"", "Mi", "12 apples", "-1Gi", "1.2.3M"} {
+		_, err := quantitycondition.ParseBytes(quantity)
+		assert.NotNil(t, err, quantity)
+	}
+}
+
+func TestBytesRangeCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		min            string
+		max            string
+		match          string
+		matchIdx       int
+		expectedResult bool
+		expectedError  bool
+	}{
+		"within_range":        {min: "1Gi", max: "2Gi", match: "memory: 1536Mi", matchIdx: 1, expectedResult: true},
+		"mixed_units":         {min: "1G", max: "1Gi", match: "memory: 1050000000", matchIdx: 1, expectedResult: true},
+		"upper_bound":         {min: "1Gi", max: "2Gi", match: "memory: 2Gi", matchIdx: 1, expectedResult: true},
+		"above_range":         {min: "1Gi", max: "2Gi", match: "memory: 2.1Gi", matchIdx: 1, expectedResult: false},
+		"open_max":            {min: "1Gi", match: "memory: 1Ti", matchIdx: 1, expectedResult: true},
+		"open_min":            {max: "1Gi", match: "memory: 2Gi", matchIdx: 1, expectedResult: false},
+		"no_bounds":           {match: "memory: 1Gi", matchIdx: 1, expectedError: true},
+		"invalid_bound":       {min: "lots", match: "memory: 1Gi", matchIdx: 1, expectedError: true},
+		"invalid_match":       {min: "1Gi", match: "memory: unlimited", matchIdx: 1, expectedError: true},
+		"index_out_of_bounds": {min: "1Gi", match: "memory: 1Gi", matchIdx: 2, expectedError: true},
+	}
+	regex := regexp.MustCompile(`memory: (\S+)`)
+	for name, testCase := range testCases {
+		c := quantitycondition.NewBytesRangeCondition(testCase.min, testCase.max)
+		assert.Equal(t, quantitycondition.BytesRangeConditionKey, c.Type)
+		actualResult, actualError := c.Evaluate(testCase.match, regex, testCase.matchIdx)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
+
+func TestDurationRangeCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		min            string
+		max            string
+		match          string
+		expectedResult bool
+		expectedError  bool
+	}{
+		"within_range":  {min: "1s", max: "1m", match: "took 1.5s", expectedResult: true},
+		"lower_bound":   {min: "1s", max: "1m", match: "took 1000ms", expectedResult: true},
+		"below_range":   {min: "1s", max: "1m", match: "took 999ms", expectedResult: false},
+		"above_range":   {max: "1m", match: "took 1m0.5s", expectedResult: false},
+		"invalid_match": {max: "1m", match: "took 5", expectedError: true},
+		"no_bounds":     {match: "took 5s", expectedError: true},
+	}
+	regex := regexp.MustCompile(`took (\S+)`)
+	for name, testCase := range testCases {
+		c := quantitycondition.NewDurationRangeCondition(testCase.min, testCase.max)
+		assert.Equal(t, quantitycondition.DurationRangeConditionKey, c.Type)
+		actualResult, actualError := c.Evaluate(testCase.match, regex, 1)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
+
+func TestDurationRangeCondition_Interpolate(t *testing.T) {
+	c := quantitycondition.NewDurationRangeCondition("%{min}", "1m")
+	interpolated, err := c.Interpolate(func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%{min}", "10s"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, quantitycondition.DurationRangeCondition{Type: quantitycondition.DurationRangeConditionKey, Min: "10s", Max: "1m"}, interpolated)
+
+	_, err = quantitycondition.NewBytesRangeCondition("1Gi", "%{max}").Interpolate(func(s string) (string, error) {
+		if s == "%{max}" {
+			return "", errors.New("undefined")
+		}
+		return s, nil
+	})
+	assert.NotNil(t, err)
+}
@@ -19,11 +19,17 @@ package stringcondition
 import (
 	"fmt"
 	"regexp"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
 )
 
 const (
 	// EqualsConditionKey is the sentinel key identifying a string == comparison.
 	EqualsConditionKey = "equals"
+	// InConditionKey is the sentinel key identifying a string set membership test.
+	InConditionKey = "in"
+	// RegexConditionKey is the sentinel key identifying a regular expression match.
+	RegexConditionKey = "regex"
 )
 
 // EqualsCondition is an implementation of the condition.Condition interface which evaluates string equality of a match
@@ -50,3 +56,93 @@ func (e EqualsCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx i
 	foundMatch := matches[matchIdx]
 	return e.Expected == foundMatch, nil
 }
+
+// Interpolate returns a copy of the EqualsCondition whose Expected value is interpolated.
+func (e EqualsCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	expected, err := interpolate(e.Expected)
+	if err != nil {
+		return nil, err
+	}
+	e.Expected = expected
+	return e, nil
+}
+
+// RegexCondition is an implementation of the condition.Condition interface which evaluates whether a match matches the
+// regular expression Pattern.  Pattern is unanchored, so use "^" and "$" to match the whole group.
+type RegexCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Pattern is the regular expression the match is tested against.
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
+// NewRegexCondition creates a RegexCondition.
+func NewRegexCondition(pattern string) *RegexCondition {
+	return &RegexCondition{Type: RegexConditionKey, Pattern: pattern}
+}
+
+// Evaluate evaluates whether a match matches Pattern.
+func (r RegexCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	pattern, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+	return pattern.MatchString(foundMatch), nil
+}
+
+// Interpolate returns a copy of the RegexCondition whose Pattern is interpolated.  Interpolated values are inserted
+// verbatim, so captured text containing regular expression metacharacters is not escaped.
+func (r RegexCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	pattern, err := interpolate(r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	r.Pattern = pattern
+	return r, nil
+}
+
+// InCondition is an implementation of the condition.Condition interface which evaluates whether a match is equal to
+// one of Values.
+type InCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Values is the set of accepted string values.
+	Values []string `json:"values" yaml:"values"`
+}
+
+// NewInCondition creates an InCondition.
+func NewInCondition(values ...string) *InCondition {
+	return &InCondition{Type: InConditionKey, Values: values}
+}
+
+// Evaluate evaluates whether a match is one of Values.
+func (i InCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	for _, value := range i.Values {
+		if value == foundMatch {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Interpolate returns a copy of the InCondition whose Values are interpolated.
+func (i InCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	values := make([]string, len(i.Values))
+	for idx, value := range i.Values {
+		interpolated, err := interpolate(value)
+		if err != nil {
+			return nil, err
+		}
+		values[idx] = interpolated
+	}
+	i.Values = values
+	return i, nil
+}
@@ -17,7 +17,9 @@
 package stringcondition_test
 
 import (
+	"errors"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,3 +81,68 @@ func TestEqualsCondition_Evaluate(t *testing.T) {
 		assert.Equal(t, testCase.expectedError, actualError != nil)
 	}
 }
+
+func TestEqualsCondition_Interpolate(t *testing.T) {
+	c := stringcondition.NewEqualsCondition("%{pid}")
+	interpolated, err := c.Interpolate(func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%{pid}", "1234"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, stringcondition.EqualsCondition{Type: stringcondition.EqualsConditionKey, Expected: "1234"}, interpolated)
+	// The original condition is left unchanged.
+	assert.Equal(t, "%{pid}", c.Expected)
+
+	_, err = c.Interpolate(func(string) (string, error) {
+		return "", errors.New("undefined")
+	})
+	assert.NotNil(t, err)
+}
+
+func TestRegexCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		pattern        string
+		matchIdx       int
+		expectedResult bool
+		expectedError  bool
+	}{
+		"matches":             {pattern: `^Run\w*$`, matchIdx: 1, expectedResult: true},
+		"does_not_match":      {pattern: `^Pend`, matchIdx: 1, expectedResult: false},
+		"invalid_pattern":     {pattern: `(`, matchIdx: 1, expectedError: true},
+		"index_out_of_bounds": {pattern: `.*`, matchIdx: 2, expectedError: true},
+	}
+	regex := regexp.MustCompile(`status: (\w+)`)
+	for name, testCase := range testCases {
+		c := stringcondition.NewRegexCondition(testCase.pattern)
+		assert.Equal(t, stringcondition.RegexConditionKey, c.Type)
+		actualResult, actualError := c.Evaluate("status: Running", regex, testCase.matchIdx)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
+
+func TestInCondition_Evaluate(t *testing.T) {
+	regex := regexp.MustCompile(`status: (\w+)`)
+	c := stringcondition.NewInCondition("Running", "Succeeded")
+	assert.Equal(t, stringcondition.InConditionKey, c.Type)
+
+	result, err := c.Evaluate("status: Succeeded", regex, 1)
+	assert.Nil(t, err)
+	assert.True(t, result)
+
+	result, err = c.Evaluate("status: Failed", regex, 1)
+	assert.Nil(t, err)
+	assert.False(t, result)
+
+	_, err = c.Evaluate("status: Failed", regex, 3)
+	assert.NotNil(t, err)
+}
+
+func TestInCondition_Interpolate(t *testing.T) {
+	c := stringcondition.NewInCondition("%{node}", "localhost")
+	interpolated, err := c.Interpolate(func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%{node}", "worker-0"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, stringcondition.InCondition{Type: stringcondition.InConditionKey, Values: []string{"worker-0", "localhost"}}, interpolated)
+	assert.Equal(t, "%{node}", c.Values[0])
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package structuredcondition
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+)
+
+const (
+	// CELConditionKey is the sentinel key identifying a CEL expression over a decoded document.
+	CELConditionKey = "cel"
+	// DocumentVariable is the name of the CEL variable holding the decoded document.
+	DocumentVariable = "self"
+)
+
+// CELCondition is an implementation of the condition.Condition interface which decodes a match as a document, then
+// evaluates a boolean Common Expression Language (CEL) expression over it.  The document is bound to the variable
+// "self", e.g. "!has(self.spec.hostNetwork) || !self.spec.hostNetwork".  Integral numbers are CEL ints and other
+// numbers are CEL doubles.
+type CELCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Format is the format of the document, either FormatJSON (default) or FormatYAML.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Expression is the CEL expression, which must evaluate to a bool.
+	Expression string `json:"expression" yaml:"expression"`
+}
+
+// NewCELCondition creates a CELCondition.
+func NewCELCondition(format, expression string) *CELCondition {
+	return &CELCondition{Type: CELConditionKey, Format: format, Expression: expression}
+}
+
+// Evaluate evaluates Expression over the document matched by group matchIdx.
+func (c CELCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	document, err := Decode(foundMatch, c.Format)
+	if err != nil {
+		return false, err
+	}
+
+	env, err := cel.NewEnv(cel.Declarations(decls.NewVar(DocumentVariable, decls.Dyn)))
+	if err != nil {
+		return false, err
+	}
+	ast, issues := env.Compile(c.Expression)
+	if issues != nil && issues.Err() != nil {
+		return false, fmt.Errorf("invalid CEL expression %q: %w", c.Expression, issues.Err())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return false, fmt.Errorf("invalid CEL expression %q: %w", c.Expression, err)
+	}
+	out, _, err := program.Eval(map[string]interface{}{DocumentVariable: document})
+	if err != nil {
+		return false, fmt.Errorf("cannot evaluate CEL expression %q: %w", c.Expression, err)
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("CEL expression %q evaluated to %v, not a bool", c.Expression, out.Value())
+	}
+	return result, nil
+}
+
+// Interpolate returns a copy of the CELCondition whose Expression is interpolated.
+func (c CELCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	expression, err := interpolate(c.Expression)
+	if err != nil {
+		return nil, err
+	}
+	c.Expression = expression
+	return c, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package structuredcondition_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/structuredcondition"
+)
+
+func TestNewCELCondition(t *testing.T) {
+	c := structuredcondition.NewCELCondition(structuredcondition.FormatJSON, "true")
+	assert.Equal(t, structuredcondition.CELConditionKey, c.Type)
+	assert.Equal(t, structuredcondition.FormatJSON, c.Format)
+	assert.Equal(t, "true", c.Expression)
+}
+
+func TestCELCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		format         string
+		expression     string
+		document       string
+		expectedResult bool
+		expectedError  bool
+	}{
+		"bool":             {expression: "self.spec.hostNetwork", document: podJSON, expectedResult: true},
+		"has":              {expression: "!has(self.spec.hostPID) || !self.spec.hostPID", document: podJSON, expectedResult: true},
+		"int":              {expression: "self.spec.containers.all(c, c.securityContext.runAsUser > 0)", document: podJSON, expectedResult: true},
+		"double":           {expression: "self.spec.containers.exists(c, c.resources.limits.cpu > 0.3)", document: podJSON, expectedResult: true},
+		"string":           {expression: `self.metadata.name.startsWith("test-")`, document: podJSON, expectedResult: true},
+		"size":             {expression: "size(self.spec.containers) == 3", document: podJSON, expectedResult: false},
+		"yaml":             {format: structuredcondition.FormatYAML, expression: "self.spec.containers[0].securityContext.runAsUser == 0", document: podYAML, expectedResult: true},
+		"not_a_bool":       {expression: "self.metadata.name", document: podJSON, expectedError: true},
+		"invalid":          {expression: "self.spec.(", document: podJSON, expectedError: true},
+		"missing_key":      {expression: "self.spec.hostPID", document: podJSON, expectedError: true},
+		"invalid_document": {expression: "true", document: "{", expectedError: true},
+	}
+	for name, testCase := range testCases {
+		c := structuredcondition.NewCELCondition(testCase.format, testCase.expression)
+		actualResult, actualError := c.Evaluate(testCase.document, documentRegex, 1)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
+
+func TestCELCondition_Interpolate(t *testing.T) {
+	c := structuredcondition.NewCELCondition("", `self.metadata.name == "%{pod}"`)
+	interpolated, err := c.Interpolate(func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%{pod}", "test-pod"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, structuredcondition.CELCondition{Type: structuredcondition.CELConditionKey, Expression: `self.metadata.name == "test-pod"`}, interpolated)
+}
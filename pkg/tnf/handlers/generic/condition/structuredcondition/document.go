@@ -0,0 +1,101 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package structuredcondition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FormatJSON is the sentinel identifying a JSON document.
+	FormatJSON = "json"
+	// FormatYAML is the sentinel identifying a YAML document.  Since JSON is a subset of YAML, a YAML document may also
+	// be given in JSON.
+	FormatYAML = "yaml"
+)
+
+// Decode decodes document in the given format, which defaults to FormatJSON when empty.  Objects are decoded as
+// map[string]interface{} and arrays as []interface{}.  Integral numbers are decoded as int64 and other numbers as
+// float64, so that the decoded document keeps the types of the original.
+func Decode(document, format string) (interface{}, error) {
+	switch format {
+	case "", FormatJSON:
+	case FormatYAML:
+		converted, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode YAML document: %w", err)
+		}
+		document = string(converted)
+	default:
+		return nil, fmt.Errorf("unknown document format: %s", format)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(document)))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("cannot decode %s document: %w", formatName(format), err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("cannot decode %s document: unexpected content after the document", formatName(format))
+	}
+	return convertNumbers(decoded), nil
+}
+
+// formatName returns the name of format used in error messages.
+func formatName(format string) string {
+	if format == FormatYAML {
+		return "YAML"
+	}
+	return "JSON"
+}
+
+// convertNumbers replaces each json.Number in a decoded document by an int64, or by a float64 if it is not integral.
+func convertNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertNumbers(item)
+		}
+	}
+	return value
+}
+
+// normalize converts any JSON serializable value to its decoded form, so that values can be compared regardless of
+// the Go types they were built with.
+func normalize(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(string(encoded), FormatJSON)
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package structuredcondition_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/structuredcondition"
+)
+
+const (
+	podJSON = `{
+  "metadata": {"name": "test-pod", "namespace": "tnf"},
+  "spec": {
+    "hostNetwork": true,
+    "containers": [
+      {"name": "app", "resources": {"limits": {"cpu": 0.5}}, "securityContext": {"runAsUser": 1000}},
+      {"name": "sidecar", "resources": {"limits": {"cpu": 0.25}}, "securityContext": {"runAsUser": 1000}}
+    ]
+  }
+}`
+	podYAML = `metadata:
+  name: test-pod
+  namespace: tnf
+spec:
+  containers:
+  - name: app
+    securityContext:
+      runAsUser: 0
+`
+)
+
+func TestDecode(t *testing.T) {
+	document, err := structuredcondition.Decode(`{"a": [1, 1.5, "x", true, null]}`, "")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{int64(1), 1.5, "x", true, nil}}, document)
+
+	document, err = structuredcondition.Decode(podYAML, structuredcondition.FormatYAML)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), document.(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["securityContext"].(map[string]interface{})["runAsUser"])
+
+	// JSON is valid YAML.
+	_, err = structuredcondition.Decode(podJSON, structuredcondition.FormatYAML)
+	assert.Nil(t, err)
+
+	testCases := map[string]string{
+		`{"a": `:       structuredcondition.FormatJSON,
+		`{"a": 1} {}`:  structuredcondition.FormatJSON,
+		"a: [":         structuredcondition.FormatYAML,
+		`{"a": 1}`:     "xml",
+		"a: 1\nb: 2\n": structuredcondition.FormatJSON,
+	}
+	for document, format := range testCases {
+		_, err = structuredcondition.Decode(document, format)
+		assert.NotNil(t, err, document)
+	}
+}
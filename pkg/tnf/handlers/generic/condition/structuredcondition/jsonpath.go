@@ -0,0 +1,125 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package structuredcondition
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// JSONPathConditionKey is the sentinel key identifying a JSONPath query over a decoded document.
+	JSONPathConditionKey = "jsonPath"
+)
+
+// JSONPathCondition is an implementation of the condition.Condition interface which decodes a match as a document,
+// then queries it using the Kubernetes JSONPath syntax, e.g. "{.spec.containers[*].name}".  The surrounding braces
+// may be omitted.  Without Expected, the condition holds when the query finds at least one value.  With Expected, the
+// condition holds when the query finds at least one value and every value found is equal to Expected, compared by
+// type and value.
+type JSONPathCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Format is the format of the document, either FormatJSON (default) or FormatYAML.
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Path is the JSONPath query.
+	Path string `json:"path" yaml:"path"`
+	// Expected is the expected value of each result of the query, of any JSON type.
+	Expected interface{} `json:"expected,omitempty" yaml:"expected,omitempty"`
+}
+
+// NewJSONPathCondition creates a JSONPathCondition.  Pass a nil expected to check that the path exists.
+func NewJSONPathCondition(format, path string, expected interface{}) *JSONPathCondition {
+	return &JSONPathCondition{Type: JSONPathConditionKey, Format: format, Path: path, Expected: expected}
+}
+
+// Evaluate evaluates the JSONPath query over the document matched by group matchIdx.
+func (j JSONPathCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	document, err := Decode(foundMatch, j.Format)
+	if err != nil {
+		return false, err
+	}
+	values, err := j.query(document)
+	if err != nil {
+		return false, err
+	}
+	if len(values) == 0 {
+		return false, nil
+	}
+	if j.Expected == nil {
+		return true, nil
+	}
+	expected, err := normalize(j.Expected)
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values {
+		if !reflect.DeepEqual(expected, value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// query returns the values found by Path in document.
+func (j JSONPathCondition) query(document interface{}) ([]interface{}, error) {
+	path := j.Path
+	if !strings.Contains(path, "{") {
+		path = "{" + path + "}"
+	}
+	parser := jsonpath.New(JSONPathConditionKey).AllowMissingKeys(true)
+	if err := parser.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", j.Path, err)
+	}
+	results, err := parser.FindResults(document)
+	if err != nil {
+		return nil, fmt.Errorf("cannot evaluate JSONPath %q: %w", j.Path, err)
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+	return values, nil
+}
+
+// Interpolate returns a copy of the JSONPathCondition whose Path, and Expected if it is a string, are interpolated.
+func (j JSONPathCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	path, err := interpolate(j.Path)
+	if err != nil {
+		return nil, err
+	}
+	j.Path = path
+	if expected, ok := j.Expected.(string); ok {
+		if j.Expected, err = interpolate(expected); err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package structuredcondition_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/structuredcondition"
+)
+
+var documentRegex = regexp.MustCompile(`(?s)^(.*)$`)
+
+func TestNewJSONPathCondition(t *testing.T) {
+	c := structuredcondition.NewJSONPathCondition(structuredcondition.FormatYAML, ".spec", nil)
+	assert.Equal(t, structuredcondition.JSONPathConditionKey, c.Type)
+	assert.Equal(t, structuredcondition.FormatYAML, c.Format)
+	assert.Equal(t, ".spec", c.Path)
+	assert.Nil(t, c.Expected)
+}
+
+func TestJSONPathCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		format         string
+		path           string
+		expected       interface{}
+		document       string
+		expectedResult bool
+		expectedError  bool
+	}{
+		"exists":                {path: ".spec.hostNetwork", document: podJSON, expectedResult: true},
+		"does_not_exist":        {path: ".spec.hostPID", document: podJSON, expectedResult: false},
+		"bool":                  {path: "{.spec.hostNetwork}", expected: true, document: podJSON, expectedResult: true},
+		"bool_is_not_string":    {path: ".spec.hostNetwork", expected: "true", document: podJSON, expectedResult: false},
+		"string":                {path: ".metadata.namespace", expected: "tnf", document: podJSON, expectedResult: true},
+		"int_all_match":         {path: ".spec.containers[*].securityContext.runAsUser", expected: 1000, document: podJSON, expectedResult: true},
+		"float_not_all_match":   {path: ".spec.containers[*].resources.limits.cpu", expected: 0.5, document: podJSON, expectedResult: false},
+		"filter":                {path: `.spec.containers[?(@.name=="sidecar")].resources.limits.cpu`, expected: 0.25, document: podJSON, expectedResult: true},
+		"object":                {path: ".metadata", expected: map[string]string{"name": "test-pod", "namespace": "tnf"}, document: podJSON, expectedResult: true},
+		"yaml":                  {format: structuredcondition.FormatYAML, path: ".spec.containers[0].securityContext.runAsUser", expected: 0, document: podYAML, expectedResult: true},
+		"invalid_path":          {path: "{.spec[", document: podJSON, expectedError: true},
+		"invalid_document":      {path: ".spec", document: "not json", expectedError: true},
+		"index_out_of_document": {path: ".spec.containers[5]", document: podJSON, expectedError: true},
+	}
+	for name, testCase := range testCases {
+		c := structuredcondition.NewJSONPathCondition(testCase.format, testCase.path, testCase.expected)
+		actualResult, actualError := c.Evaluate(testCase.document, documentRegex, 1)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+
+	_, err := structuredcondition.NewJSONPathCondition("", ".spec", nil).Evaluate(podJSON, documentRegex, 2)
+	assert.NotNil(t, err)
+}
+
+func TestJSONPathCondition_Interpolate(t *testing.T) {
+	c := structuredcondition.NewJSONPathCondition("", `.items[?(@.name=="%{name}")].status`, "%{status}")
+	interpolated, err := c.Interpolate(func(s string) (string, error) {
+		return strings.NewReplacer("%{name}", "app", "%{status}", "Running").Replace(s), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, structuredcondition.JSONPathCondition{
+		Type:     structuredcondition.JSONPathConditionKey,
+		Path:     `.items[?(@.name=="app")].status`,
+		Expected: "Running",
+	}, interpolated)
+}
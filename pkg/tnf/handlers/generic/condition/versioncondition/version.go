@@ -0,0 +1,87 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package versioncondition
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition"
+)
+
+const (
+	// SemverConditionKey is the sentinel key identifying a semantic version constraint check.
+	SemverConditionKey = "semver"
+)
+
+// kernelVersionRegex matches a kernel version, whose release after the dash starts with a number unlike a pre-release,
+// e.g. "4.18.0-305.el8.x86_64".  The first group is its major.minor.patch version.
+var kernelVersionRegex = regexp.MustCompile(`^(v?\d+\.\d+\.\d+)-\d`)
+
+// SemverCondition is an implementation of the condition.Condition interface which parses a match string as a
+// semantic version, then checks it against Constraint.  Missing minor and patch numbers are treated as zero, so "4.10"
+// is a valid version.  Note that a pre-release version such as "4.10.0-rc.1" only satisfies a constraint which
+// itself contains a pre-release, whereas a kernel version such as "5.14.0-70.22.1.el9_0.x86_64" is checked as its
+// major.minor.patch version.
+type SemverCondition struct {
+	// Type stores the sentinel which represents the type of Condition implemented.
+	Type string `json:"type" yaml:"type"`
+	// Constraint is the version constraint, e.g. ">=4.10 <4.13" or "~1.2 || ^2".
+	Constraint string `json:"constraint" yaml:"constraint"`
+}
+
+// NewSemverCondition creates a SemverCondition.
+func NewSemverCondition(constraint string) *SemverCondition {
+	return &SemverCondition{Type: SemverConditionKey, Constraint: constraint}
+}
+
+// Evaluate evaluates whether a match is a semantic version which satisfies Constraint.
+func (s SemverCondition) Evaluate(match string, regex *regexp.Regexp, matchIdx int) (bool, error) {
+	foundMatch, err := condition.GroupMatch(match, regex, matchIdx)
+	if err != nil {
+		return false, err
+	}
+	constraint, err := semver.NewConstraint(s.Constraint)
+	if err != nil {
+		return false, fmt.Errorf("invalid version constraint %q: %w", s.Constraint, err)
+	}
+	version, err := semver.NewVersion(normalizeVersion(strings.TrimSpace(foundMatch)))
+	if err != nil {
+		return false, fmt.Errorf("match \"%s\" is not a semantic version", foundMatch)
+	}
+	return constraint.Check(version), nil
+}
+
+// normalizeVersion returns the major.minor.patch version of a kernel version, or version as it is.
+func normalizeVersion(version string) string {
+	if matches := kernelVersionRegex.FindStringSubmatch(version); matches != nil {
+		return matches[1]
+	}
+	return version
+}
+
+// Interpolate returns a copy of the SemverCondition whose Constraint is interpolated.
+func (s SemverCondition) Interpolate(interpolate func(string) (string, error)) (condition.Condition, error) {
+	constraint, err := interpolate(s.Constraint)
+	if err != nil {
+		return nil, err
+	}
+	s.Constraint = constraint
+	return s, nil
+}
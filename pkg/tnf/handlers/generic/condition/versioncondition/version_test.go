@@ -0,0 +1,90 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package versioncondition_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/condition/versioncondition"
+)
+
+func TestNewSemverCondition(t *testing.T) {
+	c := versioncondition.NewSemverCondition(">=4.10")
+	assert.Equal(t, versioncondition.SemverConditionKey, c.Type)
+	assert.Equal(t, ">=4.10", c.Constraint)
+}
+
+func TestSemverCondition_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		constraint     string
+		match          string
+		matchIdx       int
+		expectedResult bool
+		expectedError  bool
+	}{
+		"within_range":        {constraint: ">=4.10 <4.13", match: "Server Version: 4.11.3", matchIdx: 1, expectedResult: true},
+		"lower_bound":         {constraint: ">=4.10 <4.13", match: "Server Version: 4.10", matchIdx: 1, expectedResult: true},
+		"upper_bound":         {constraint: ">=4.10 <4.13", match: "Server Version: 4.13.0", matchIdx: 1, expectedResult: false},
+		"v_prefix":            {constraint: "^1.23", match: "Server Version: v1.23.5", matchIdx: 1, expectedResult: true},
+		"alternatives":        {constraint: "4.9.x || >=4.12", match: "Server Version: 4.9.21", matchIdx: 1, expectedResult: true},
+		"prerelease":          {constraint: ">=4.10", match: "Server Version: 4.11.0-rc.1", matchIdx: 1, expectedResult: false},
+		"invalid_constraint":  {constraint: ">=four", match: "Server Version: 4.11.3", matchIdx: 1, expectedError: true},
+		"invalid_version":     {constraint: ">=4.10", match: "Server Version: latest", matchIdx: 1, expectedError: true},
+		"index_out_of_bounds": {constraint: ">=4.10", match: "Server Version: 4.11.3", matchIdx: 2, expectedError: true},
+	}
+	regex := regexp.MustCompile(`Server Version: (\S+)`)
+	for name, testCase := range testCases {
+		c := versioncondition.NewSemverCondition(testCase.constraint)
+		actualResult, actualError := c.Evaluate(testCase.match, regex, testCase.matchIdx)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+		assert.Equal(t, testCase.expectedError, actualError != nil, name)
+	}
+}
+
+func TestSemverCondition_EvaluateKernelVersion(t *testing.T) {
+	testCases := map[string]struct {
+		constraint     string
+		match          string
+		expectedResult bool
+	}{
+		"rhel8":         {constraint: ">=4.18", match: "4.18.0-305.el8.x86_64", expectedResult: true},
+		"rhel8_newer":   {constraint: ">=4.18.0 <5", match: "4.18.0-372.9.1.el8.x86_64", expectedResult: true},
+		"rhel9":         {constraint: ">=5.14", match: "5.14.0-70.22.1.el9_0.x86_64", expectedResult: true},
+		"rhel9_too_old": {constraint: ">=5.15", match: "5.14.0-70.22.1.el9_0.x86_64", expectedResult: false},
+		"ubuntu":        {constraint: "~5.15", match: "5.15.0-1019-aws", expectedResult: true},
+		"realtime":      {constraint: "4.18.x", match: "4.18.0-305.rt7.72.el8.x86_64", expectedResult: true},
+	}
+	regex := regexp.MustCompile(`(\S+)`)
+	for name, testCase := range testCases {
+		c := versioncondition.NewSemverCondition(testCase.constraint)
+		actualResult, actualError := c.Evaluate(testCase.match, regex, 1)
+		assert.Nil(t, actualError, name)
+		assert.Equal(t, testCase.expectedResult, actualResult, name)
+	}
+}
+
+func TestSemverCondition_Interpolate(t *testing.T) {
+	c := versioncondition.NewSemverCondition(">=%{minimum}")
+	interpolated, err := c.Interpolate(func(s string) (string, error) {
+		return strings.ReplaceAll(s, "%{minimum}", "4.10"), nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, versioncondition.SemverCondition{Type: versioncondition.SemverConditionKey, Constraint: ">=4.10"}, interpolated)
+}
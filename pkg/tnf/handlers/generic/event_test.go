@@ -0,0 +1,95 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package generic_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+func TestGeneric_ReelMatchEvent(t *testing.T) {
+	testCases := map[string]struct {
+		command               string
+		expectedResult        int
+		expectedFailureReason string
+		expectedMatches       int
+	}{
+		"success": {
+			command:         "echo out; echo err >&2",
+			expectedResult:  tnf.SUCCESS,
+			expectedMatches: 1,
+		},
+		"exit_code": {
+			command:               "echo out; echo err >&2; false",
+			expectedResult:        tnf.ERROR,
+			expectedFailureReason: "error executing command exit code:1: err",
+		},
+		"no_match": {
+			command:        "echo other",
+			expectedResult: tnf.ERROR,
+		},
+	}
+	t.Setenv("SHELL", "/bin/sh")
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, stateTimeout)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+
+	for name, tc := range testCases {
+		tester, handlers, result, err := generic.NewGenericFromMap(path.Join("testdata", "stderr.json.tpl"), schemaPath,
+			map[string]interface{}{"COMMAND": tc.command})
+		assert.Nil(t, err, name)
+		assert.True(t, result.Valid(), name)
+		g := (*tester).(*generic.Generic)
+		assert.True(t, g.ReelFirst().Stderr, name)
+
+		test, err := tnf.NewTest(context.GetExpecter(), g, handlers, context.GetErrorChannel())
+		assert.Nil(t, err, name)
+		testResult, err := test.Run()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expectedResult, testResult, name)
+		assert.Equal(t, tc.expectedFailureReason, g.FailureReason, name)
+
+		matches := g.GetMatches()
+		assert.Len(t, matches, tc.expectedMatches, name)
+		if len(matches) == 1 {
+			assert.Equal(t, "out", matches[0].Match, name)
+			assert.Equal(t, "err", matches[0].Stderr, name)
+			assert.Equal(t, 0, matches[0].ExitCode, name)
+			assert.Positive(t, int64(matches[0].Duration), name)
+		}
+	}
+}
+
+func TestGeneric_StateStderr(t *testing.T) {
+	g := newStatesGeneric(t)
+	g.States["login"].Stderr = true
+	step := g.ReelFirst()
+	assert.Equal(t, true, step.Stderr)
+
+	// A command exiting with a non-zero code enters the ERROR state.
+	assert.Nil(t, g.ReelMatchEvent(&reel.MatchEvent{Pattern: loginOkPattern, ExitCode: 2, Stderr: "denied"}))
+	assert.Equal(t, generic.StateError, g.CurrentState)
+	assert.Equal(t, tnf.ERROR, g.Result())
+	assert.Equal(t, "error executing command exit code:2: denied", g.FailureReason)
+}
@@ -19,6 +19,7 @@ package generic
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"regexp"
 	"text/template"
@@ -41,7 +42,8 @@ const (
 // of the state machine for a Generic reel.Handler is restricted in this facade, since most common use cases do not need
 // to perform too much heavy lifting that would otherwise require a Custom reel.Handler implementation.  Although
 // Generic is exported for serialization reasons, it is recommended to instantiate new instances of Generic using
-// NewGenericFromJSONFile, is tailored to properly initialize a Generic.
+// NewGenericFromJSONFile, is tailored to properly initialize a Generic.  Multi-step interactions can be defined with
+// named States instead of ReelFirstStep and ResultContexts.
 type Generic struct {
 
 	// Arguments is the Unix command array.  Arguments is optional;  a command can also be issued using ReelFirstStep.
@@ -84,8 +86,24 @@ type Generic struct {
 	// TestTimeout prevents the Test from running forever.
 	TestTimeout time.Duration `json:"testTimeout,omitempty" yaml:"testTimeout,omitempty"`
 
+	// Variables holds the named groups captured by the matches, e.g. "pid" for `(?P<pid>\d+)`.  Variables can be given
+	// initial values.  The steps (Execute) and assertions (condition operands) following a capture can reference a
+	// variable as "%{pid}".
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
+
 	// currentReelMatchResultContexts is used to persist the current ResultContext over multiple invocations of ReelMatch.
 	currentReelMatchResultContexts []*ResultContext
+
+	// States is an optional state machine, used instead of ReelFirstStep, ResultContexts and ReelTimeoutStep for
+	// multi-step interactions.  States are keyed by name.
+	States map[string]*State `json:"states,omitempty" yaml:"states,omitempty"`
+
+	// InitialState is the name of the first state entered, when States is used.
+	InitialState string `json:"initialState,omitempty" yaml:"initialState,omitempty"`
+
+	// CurrentState is the name of the current state, when States is used.  It is the terminal state once the test
+	// has run to completion.
+	CurrentState string `json:"currentState,omitempty" yaml:"currentState,omitempty"`
 }
 
 // init initializes a Generic, including building up the reelMatchResultMap.  reelMatchResultMap is pre-built for
@@ -125,7 +143,10 @@ func (g *Generic) Result() int {
 
 // ReelFirst returns the first step to perform.
 func (g *Generic) ReelFirst() *reel.Step {
-	return g.ReelFirstStep
+	if g.States != nil {
+		return g.enterState(g.InitialState)
+	}
+	return g.nextStep(g.ReelFirstStep)
 }
 
 // findResultContext is an internal helper function used to search an array of ResultContext instances for a given
@@ -141,9 +162,49 @@ func (g *Generic) findResultContext(pattern string) *ResultContext {
 
 // ReelMatch informs of a match event, returning the next step to perform.
 func (g *Generic) ReelMatch(pattern, before, match string) *reel.Step {
-	m := &Match{Pattern: pattern, Before: before, Match: match}
+	return g.reelMatch(&Match{Pattern: pattern, Before: before, Match: match}, captureVariables(pattern, match))
+}
+
+// ReelMatchOutput informs of a match event of a streamed step, returning the next step to perform.  The variables
+// are taken from the submatches found while streaming, rather than by matching pattern again against the output.
+func (g *Generic) ReelMatchOutput(pattern string, output *reel.Output) *reel.Step {
+	return g.reelMatch(newOutputMatch(pattern, output), captureOutputVariables(pattern, output))
+}
+
+// ReelMatchEvent informs of the outcome of a command, returning the next step to perform.  The exit code, standard
+// error and duration of the command are recorded in its Match.  As with any other reel.Handler, a command exiting with
+// a non-zero code is an error, whose FailureReason includes the standard error when the step captures it.
+func (g *Generic) ReelMatchEvent(event *reel.MatchEvent) *reel.Step {
+	if event.ExitCode > 0 {
+		g.FailureReason = fmt.Sprintf("error executing command exit code:%d", event.ExitCode)
+		if event.Stderr != "" {
+			g.FailureReason += ": " + event.Stderr
+		}
+		if g.States != nil {
+			return g.enterState(StateError)
+		}
+		g.TestResult = tnf.ERROR
+		return nil
+	}
+	if event.Pattern == "" {
+		// The command output matches none of the expectations.
+		return nil
+	}
+	m := newOutputMatch(event.Pattern, event.Output)
+	m.ExitCode, m.Stderr, m.Duration = event.ExitCode, event.Stderr, event.Duration
+	return g.reelMatch(m, captureOutputVariables(event.Pattern, event.Output))
+}
+
+// reelMatch records m with its captured variables, and returns the next step to perform.
+func (g *Generic) reelMatch(m *Match, variables map[string]string) *reel.Step {
+	pattern, match := m.Pattern, m.Match
+	m.Variables = g.capture(variables)
 	g.Matches = append(g.Matches, *m)
 
+	if g.States != nil {
+		return g.stateMatch(pattern, match)
+	}
+
 	resultContext := g.findResultContext(pattern)
 	if resultContext == nil {
 		g.FailureReason = "the pattern provided to ReelMatch is not defined in ReelFirst" //nolint:goconst
@@ -152,9 +213,9 @@ func (g *Generic) ReelMatch(pattern, before, match string) *reel.Step {
 	}
 	composedAssertions := resultContext.ComposedAssertions
 	if len(composedAssertions) > 0 {
-		for _, composedAssertion := range composedAssertions {
+		for i := range composedAssertions {
 			regex := regexp.MustCompile(pattern)
-			success, err := (*composedAssertion.Logic).Evaluate(composedAssertion.Assertions, match, regex)
+			success, err := g.evaluateComposedAssertion(&composedAssertions[i], match, regex)
 			if err != nil {
 				// exit immediately on a test error.
 				g.FailureReason = err.Error()
@@ -180,12 +241,27 @@ func (g *Generic) ReelMatch(pattern, before, match string) *reel.Step {
 	}
 
 	g.currentReelMatchResultContexts = resultContext.NextResultContexts
-	return resultContext.NextStep
+	return g.nextStep(resultContext.NextStep)
+}
+
+// nextStep returns step with the references to the variables of the test replaced.  The test errors when a variable
+// is undefined.
+func (g *Generic) nextStep(step *reel.Step) *reel.Step {
+	step, err := g.interpolateStep(step)
+	if err != nil {
+		g.FailureReason = err.Error()
+		g.TestResult = tnf.ERROR
+		return nil
+	}
+	return step
 }
 
 // ReelTimeout informs of a timeout event, returning the next step to perform.
 func (g *Generic) ReelTimeout() *reel.Step {
-	return g.ReelTimeoutStep
+	if g.States != nil {
+		return g.stateTimeout()
+	}
+	return g.nextStep(g.ReelTimeoutStep)
 }
 
 // ReelEOF informs of the eof event.
@@ -270,5 +346,8 @@ func createGeneric(inputBytes []byte, schemaPath string) (*Generic, *gojsonschem
 		return nil, result, err
 	}
 	g.init()
+	if err := g.validateStates(); err != nil {
+		return nil, result, err
+	}
 	return g, result, nil
 }
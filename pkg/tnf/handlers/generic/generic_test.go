@@ -27,6 +27,12 @@ import (
 	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
 )
 
+const (
+	combinatorsPattern = "(?m)^namespace=(\\S+) hostNetwork=(\\S+)$"
+	structuredPattern  = "(?s)(\\{.*\\})"
+	conditionsPattern  = "(?m)^node (\\S+) version (\\S+) load (\\S+) memory (\\S+) uptime (\\S+)$"
+)
+
 var (
 	schemaPath = path.Join("..", "..", "..", "..", "schemas", generic.TestSchemaFileName)
 )
@@ -166,6 +172,131 @@ var newGenericFromJSONFileTestCases = map[string]newGenericFromJSONFileTestCase{
 			},
 		},
 	},
+	// Positive Test:  "testdata/conditions.json" asserts a node summary using the regex, in, semver, numberRange,
+	// bytesRange and durationRange conditions.
+	"conditions": {
+		expectedCreationErr:     false,
+		expectedTester:          true,
+		expectedTimeout:         time.Duration(2000000000),
+		expectedHandlers:        true,
+		expectedHandlersLen:     1,
+		expectedArgs:            nil,
+		expectedInitialResult:   tnf.ERROR,
+		expectedResultIsValid:   true,
+		expectedReelTimeoutStep: nil,
+		expectedReelFirstStep: &reel.Step{
+			Execute: "echo node worker-0 version v1.23.5 load 0.75 memory 1536Mi uptime 72h\n",
+			Expect:  []string{conditionsPattern},
+			Timeout: time.Duration(2000000000),
+		},
+		matchTestCases: []matchTestCase{
+			// Positive Test:  Every condition holds.
+			{
+				inputPattern:        conditionsPattern,
+				inputMatch:          "node worker-0 version v1.23.5 load 0.75 memory 1536Mi uptime 72h",
+				expectedFinalResult: tnf.SUCCESS,
+			},
+			// Positive Test:  The version is outside of the semver constraint.
+			{
+				inputPattern:        conditionsPattern,
+				inputMatch:          "node worker-1 version v1.25.0 load 0.75 memory 1536Mi uptime 72h",
+				expectedFinalResult: tnf.FAILURE,
+			},
+			// Positive Test:  The memory is outside of the bytesRange.
+			{
+				inputPattern:        conditionsPattern,
+				inputMatch:          "node worker-1 version v1.23.5 load 0.75 memory 3Gi uptime 72h",
+				expectedFinalResult: tnf.FAILURE,
+			},
+			// Negative Test:  The uptime is not a duration, so the condition cannot be evaluated.
+			{
+				inputPattern:        conditionsPattern,
+				inputMatch:          "node worker-1 version v1.23.5 load 0.75 memory 1536Mi uptime 3days",
+				expectedFinalResult: tnf.ERROR,
+			},
+		},
+	},
+	// Positive Test:  "testdata/combinators.json" nests the allOf, anyOf and not assertion combinators across groups.
+	"combinators": {
+		expectedCreationErr:     false,
+		expectedTester:          true,
+		expectedTimeout:         time.Duration(2000000000),
+		expectedHandlers:        true,
+		expectedHandlersLen:     1,
+		expectedArgs:            nil,
+		expectedInitialResult:   tnf.ERROR,
+		expectedResultIsValid:   true,
+		expectedReelTimeoutStep: nil,
+		expectedReelFirstStep: &reel.Step{
+			Execute: "echo namespace=openshift-sdn hostNetwork=true\n",
+			Expect:  []string{combinatorsPattern},
+			Timeout: time.Duration(2000000000),
+		},
+		matchTestCases: []matchTestCase{
+			// Positive Test:  hostNetwork is used in an allowlisted namespace.
+			{
+				inputPattern:        combinatorsPattern,
+				inputMatch:          "namespace=openshift-sdn hostNetwork=true",
+				expectedFinalResult: tnf.SUCCESS,
+			},
+			// Positive Test:  hostNetwork is not used.
+			{
+				inputPattern:        combinatorsPattern,
+				inputMatch:          "namespace=default hostNetwork=false",
+				expectedFinalResult: tnf.SUCCESS,
+			},
+			// Positive Test:  hostNetwork is used outside of the allowlisted namespaces.
+			{
+				inputPattern:        combinatorsPattern,
+				inputMatch:          "namespace=default hostNetwork=true",
+				expectedFinalResult: tnf.FAILURE,
+			},
+		},
+	},
+	// Positive Test:  "testdata/structured.json" decodes a pod as JSON and asserts it using the jsonPath and cel
+	// conditions.
+	"structured": {
+		expectedCreationErr:     false,
+		expectedTester:          true,
+		expectedTimeout:         time.Duration(2000000000),
+		expectedHandlers:        true,
+		expectedHandlersLen:     1,
+		expectedArgs:            nil,
+		expectedInitialResult:   tnf.ERROR,
+		expectedResultIsValid:   true,
+		expectedReelTimeoutStep: nil,
+		expectedReelFirstStep: &reel.Step{
+			Execute: "oc get pod test-pod -n tnf -o json\n",
+			Expect:  []string{structuredPattern},
+			Timeout: time.Duration(2000000000),
+		},
+		matchTestCases: []matchTestCase{
+			// Positive Test:  hostNetwork is not set, and every container runs as non-root.
+			{
+				inputPattern:        structuredPattern,
+				inputMatch:          `{"spec": {"containers": [{"securityContext": {"runAsNonRoot": true}}]}}`,
+				expectedFinalResult: tnf.SUCCESS,
+			},
+			// Positive Test:  hostNetwork is set.
+			{
+				inputPattern:        structuredPattern,
+				inputMatch:          `{"spec": {"hostNetwork": true, "containers": [{"securityContext": {"runAsNonRoot": true}}]}}`,
+				expectedFinalResult: tnf.FAILURE,
+			},
+			// Positive Test:  A container may run as root.
+			{
+				inputPattern:        structuredPattern,
+				inputMatch:          `{"spec": {"hostNetwork": false, "containers": [{"securityContext": {"runAsNonRoot": true}}, {"securityContext": {"runAsNonRoot": false}}]}}`,
+				expectedFinalResult: tnf.FAILURE,
+			},
+			// Negative Test:  The output is not a JSON document.
+			{
+				inputPattern:        structuredPattern,
+				inputMatch:          `{"spec": }`,
+				expectedFinalResult: tnf.ERROR,
+			},
+		},
+	},
 	// Negative Test:  The supplied file doesn't exist, so make sure that an appropriate error is emitted.
 	"file_does_not_exist": {
 		expectedCreationErr:     true,
@@ -373,3 +504,15 @@ func TestNewGenericFromTemplate(t *testing.T) {
 		}
 	}
 }
+
+func TestGeneric_RangeConditionWithoutBounds(t *testing.T) {
+	_, _, result, err := generic.NewGenericFromJSONFile(getTestFileLocation("conditions_range_without_bounds"), schemaPath)
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+}
+
+func TestGeneric_CombinatorWithGroupIndex(t *testing.T) {
+	_, _, result, err := generic.NewGenericFromJSONFile(getTestFileLocation("combinators_with_group_index"), schemaPath)
+	assert.EqualError(t, err, `assertion combinator "anyOf" cannot also define "groupIdx"`)
+	assert.False(t, result.Valid())
+}
@@ -16,6 +16,12 @@
 
 package generic
 
+import (
+	"time"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
 // Match follows the Container design pattern, and is used to store the arguments to a reel.Handler's ReelMatch
 // function in a single data transfer object.
 type Match struct {
@@ -28,4 +34,21 @@ type Match struct {
 
 	// Match is the matched string.
 	Match string `json:"match,omitempty" yaml:"match,omitempty"`
+
+	// Variables contains the variables captured by the named groups of Pattern.
+	Variables map[string]string `json:"variables,omitempty" yaml:"variables,omitempty"`
+
+	// ExitCode is the exit code of the command, or -1 when it is unknown.
+	ExitCode int `json:"exitCode,omitempty" yaml:"exitCode,omitempty"`
+
+	// Stderr is the standard error of the command, when the step captures it.
+	Stderr string `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+
+	// Duration is the time from sending the command to the end of its output.
+	Duration time.Duration `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// newOutputMatch returns the Match of pattern in the output of a streamed step.
+func newOutputMatch(pattern string, output *reel.Output) *Match {
+	return &Match{Pattern: pattern, Before: output.Before(), Match: output.Match()}
 }
@@ -0,0 +1,194 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package generic
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/assertion"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	// StateSuccess is the terminal state ending the test with tnf.SUCCESS.
+	StateSuccess = "SUCCESS"
+	// StateFailure is the terminal state ending the test with tnf.FAILURE.
+	StateFailure = "FAILURE"
+	// StateError is the terminal state ending the test with tnf.ERROR.
+	StateError = "ERROR"
+)
+
+// terminalStates maps the terminal states to the result of the test.
+var terminalStates = map[string]int{
+	StateSuccess: tnf.SUCCESS,
+	StateFailure: tnf.FAILURE,
+	StateError:   tnf.ERROR,
+}
+
+// State is a named state of the state machine of a Generic.  Entering a State performs its step, and the pattern
+// matched by the step selects the Transition to the next state.  The test ends when a terminal state (StateSuccess,
+// StateFailure or StateError) is entered.
+type State struct {
+
+	// Execute is an optional Unix command to execute when entering the State.
+	Execute string `json:"execute,omitempty" yaml:"execute,omitempty"`
+
+	// Expect is the in order array of expected regular expressions.  Expect is optional;  it defaults to the patterns
+	// of Transitions.
+	Expect []string `json:"expect,omitempty" yaml:"expect,omitempty"`
+
+	// Timeout is the timeout of the step of the State.
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+
+	// Stderr captures the standard error of Execute apart from its output, see reel.Step.
+	Stderr bool `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+
+	// Transitions selects the next state from the pattern matched.
+	Transitions []*Transition `json:"transitions" yaml:"transitions"`
+
+	// OnTimeout is the terminal state entered upon timeout.  It defaults to StateError.
+	OnTimeout string `json:"onTimeout,omitempty" yaml:"onTimeout,omitempty"`
+}
+
+// Transition is the edge taken out of a State when Pattern is matched.
+type Transition struct {
+
+	// Pattern is the pattern causing a match in reel.Handler ReelMatch.
+	Pattern string `json:"pattern" yaml:"pattern"`
+
+	// ComposedAssertions is an optional means of making many assertion.Assertion claims about the match.  Next is only
+	// entered when all of them hold.
+	ComposedAssertions []assertion.Assertions `json:"composedAssertions,omitempty" yaml:"composedAssertions,omitempty"`
+
+	// Next is the name of the state entered after the match.
+	Next string `json:"next" yaml:"next"`
+
+	// OnFailure is the name of the state entered when the ComposedAssertions do not hold.  It defaults to StateFailure.
+	OnFailure string `json:"onFailure,omitempty" yaml:"onFailure,omitempty"`
+}
+
+// step returns the reel.Step performed when entering the State.
+func (s *State) step() *reel.Step {
+	expect := s.Expect
+	if len(expect) == 0 {
+		for _, transition := range s.Transitions {
+			expect = append(expect, transition.Pattern)
+		}
+	}
+	return &reel.Step{Execute: s.Execute, Expect: expect, Timeout: s.Timeout, Stderr: s.Stderr}
+}
+
+// findTransition returns the Transition taken when pattern is matched, if any.
+func (s *State) findTransition(pattern string) *Transition {
+	for _, transition := range s.Transitions {
+		if transition.Pattern == pattern {
+			return transition
+		}
+	}
+	return nil
+}
+
+// validateStates checks that the state machine only refers to existing states.
+func (g *Generic) validateStates() error {
+	if g.States == nil {
+		return nil
+	}
+	if err := g.validateStateName(g.InitialState, "initialState"); err != nil {
+		return err
+	}
+	for name, state := range g.States {
+		if _, ok := terminalStates[name]; ok {
+			return fmt.Errorf("state %q uses the name of a terminal state", name)
+		}
+		if _, ok := terminalStates[state.OnTimeout]; !ok && state.OnTimeout != "" {
+			return fmt.Errorf("onTimeout of state %q is not a terminal state: %q", name, state.OnTimeout)
+		}
+		for _, transition := range state.Transitions {
+			if err := g.validateStateName(transition.Next, fmt.Sprintf("next of state %q", name)); err != nil {
+				return err
+			}
+			if transition.OnFailure == "" {
+				continue
+			}
+			if err := g.validateStateName(transition.OnFailure, fmt.Sprintf("onFailure of state %q", name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generic) validateStateName(name, field string) error {
+	if _, ok := terminalStates[name]; ok {
+		return nil
+	}
+	if _, ok := g.States[name]; ok {
+		return nil
+	}
+	return fmt.Errorf("%s refers to an undefined state: %q", field, name)
+}
+
+// enterState makes name the current state, returning its step.  Entering a terminal state ends the test.
+func (g *Generic) enterState(name string) *reel.Step {
+	g.CurrentState = name
+	if result, ok := terminalStates[name]; ok {
+		g.TestResult = result
+		return nil
+	}
+	step, err := g.interpolateStep(g.States[name].step())
+	if err != nil {
+		g.FailureReason = err.Error()
+		return g.enterState(StateError)
+	}
+	return step
+}
+
+// stateMatch takes the transition of the current state for pattern.
+func (g *Generic) stateMatch(pattern, match string) *reel.Step {
+	transition := g.States[g.CurrentState].findTransition(pattern)
+	if transition == nil {
+		g.FailureReason = fmt.Sprintf("the pattern provided to ReelMatch is not a transition of state %q", g.CurrentState)
+		return g.enterState(StateError)
+	}
+	regex := regexp.MustCompile(pattern)
+	for i := range transition.ComposedAssertions {
+		success, err := g.evaluateComposedAssertion(&transition.ComposedAssertions[i], match, regex)
+		if err != nil {
+			g.FailureReason = err.Error()
+			return g.enterState(StateError)
+		}
+		if !success {
+			if transition.OnFailure == "" {
+				return g.enterState(StateFailure)
+			}
+			return g.enterState(transition.OnFailure)
+		}
+	}
+	return g.enterState(transition.Next)
+}
+
+// stateTimeout enters the timeout state of the current state.
+func (g *Generic) stateTimeout() *reel.Step {
+	onTimeout := g.States[g.CurrentState].OnTimeout
+	if onTimeout == "" {
+		onTimeout = StateError
+	}
+	return g.enterState(onTimeout)
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package generic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	loginOkPattern = "(?m)^login ok$"
+	deniedPattern  = "(?m)^denied$"
+	countPattern   = "(?m)^count (\\d+)$"
+	donePattern    = "(?m)^done$"
+	anyPattern     = "(?m)^.+$"
+	stateTimeout   = 2 * time.Second
+)
+
+var (
+	loginStep  = &reel.Step{Execute: "echo login ok\n", Expect: []string{loginOkPattern, deniedPattern}, Timeout: stateTimeout}
+	queryStep  = &reel.Step{Execute: "echo count 3\n", Expect: []string{countPattern}, Timeout: stateTimeout}
+	verifyStep = &reel.Step{Execute: "echo done\n", Expect: []string{donePattern, anyPattern}, Timeout: stateTimeout}
+)
+
+// stateMatchTestCase is a match fed to the state machine, and the expected outcome.
+type stateMatchTestCase struct {
+	pattern       string
+	match         string
+	expectedStep  *reel.Step
+	expectedState string
+}
+
+func newStatesGeneric(t *testing.T) *generic.Generic {
+	tester, handlers, result, err := generic.NewGenericFromJSONFile(getTestFileLocation("states"), schemaPath)
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+	assert.Len(t, handlers, 1)
+	return (*tester).(*generic.Generic)
+}
+
+func TestGeneric_States(t *testing.T) {
+	testCases := map[string]struct {
+		matches        []stateMatchTestCase
+		expectedResult int
+	}{
+		"success": {
+			matches: []stateMatchTestCase{
+				{pattern: loginOkPattern, match: "login ok", expectedStep: queryStep, expectedState: "query"},
+				{pattern: countPattern, match: "count 3", expectedStep: verifyStep, expectedState: "verify"},
+				{pattern: donePattern, match: "done", expectedState: generic.StateSuccess},
+			},
+			expectedResult: tnf.SUCCESS,
+		},
+		"failed_assertion_transition": {
+			matches: []stateMatchTestCase{
+				{pattern: loginOkPattern, match: "login ok", expectedStep: queryStep, expectedState: "query"},
+				{pattern: countPattern, match: "count 0", expectedStep: loginStep, expectedState: "login"},
+				{pattern: deniedPattern, match: "denied", expectedState: generic.StateFailure},
+			},
+			expectedResult: tnf.FAILURE,
+		},
+		"assertion_error": {
+			matches: []stateMatchTestCase{
+				{pattern: loginOkPattern, match: "login ok", expectedStep: queryStep, expectedState: "query"},
+				{pattern: countPattern, match: "count x", expectedState: generic.StateError},
+			},
+			expectedResult: tnf.ERROR,
+		},
+		"pattern_without_transition": {
+			matches: []stateMatchTestCase{
+				{pattern: loginOkPattern, match: "login ok", expectedStep: queryStep, expectedState: "query"},
+				{pattern: countPattern, match: "count 1", expectedStep: verifyStep, expectedState: "verify"},
+				{pattern: anyPattern, match: "something else", expectedState: generic.StateError},
+			},
+			expectedResult: tnf.ERROR,
+		},
+	}
+
+	for name, tc := range testCases {
+		g := newStatesGeneric(t)
+		assert.Equal(t, tnf.ERROR, g.Result(), name)
+		assert.Equal(t, loginStep, g.ReelFirst(), name)
+		assert.Equal(t, "login", g.CurrentState, name)
+		for _, match := range tc.matches {
+			assert.Equal(t, match.expectedStep, g.ReelMatch(match.pattern, "", match.match), name)
+			assert.Equal(t, match.expectedState, g.CurrentState, name)
+		}
+		assert.Equal(t, tc.expectedResult, g.Result(), name)
+		assert.Len(t, g.GetMatches(), len(tc.matches), name)
+	}
+}
+
+func TestGeneric_StatesTimeout(t *testing.T) {
+	g := newStatesGeneric(t)
+	g.ReelFirst()
+	assert.Nil(t, g.ReelTimeout())
+	assert.Equal(t, generic.StateFailure, g.CurrentState)
+	assert.Equal(t, tnf.FAILURE, g.Result())
+
+	// The timeout state defaults to ERROR.
+	g = newStatesGeneric(t)
+	g.ReelFirst()
+	g.ReelMatch(loginOkPattern, "", "login ok")
+	assert.Nil(t, g.ReelTimeout())
+	assert.Equal(t, generic.StateError, g.CurrentState)
+	assert.Equal(t, tnf.ERROR, g.Result())
+}
+
+func TestGeneric_StatesInvalid(t *testing.T) {
+	_, _, _, err := generic.NewGenericFromJSONFile(getTestFileLocation("states_undefined_state"), schemaPath)
+	assert.EqualError(t, err, `next of state "query" refers to an undefined state: "missing"`)
+
+	// The states cannot be mixed with the result contexts.
+	_, _, result, err := generic.NewGenericFromJSONFile(getTestFileLocation("states_and_result_contexts"), schemaPath)
+	assert.Nil(t, err)
+	assert.False(t, result.Valid())
+}
+
+func TestGeneric_StatesRun(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, stateTimeout)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+
+	g := newStatesGeneric(t)
+	test, err := tnf.NewTest(context.GetExpecter(), g, []reel.Handler{g}, context.GetErrorChannel())
+	assert.Nil(t, err)
+	result, err := test.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.SUCCESS, result)
+	assert.Equal(t, generic.StateSuccess, g.CurrentState)
+	assert.Len(t, g.GetMatches(), 3)
+}
@@ -0,0 +1,23 @@
+{
+  "identifier": {
+    "url": "http://test-network-function.com/tests/unit/stderr",
+    "version": "v1.0.0"
+  },
+  "description": "Runs a command capturing its standard error.",
+  "reelFirstStep": {
+    "execute": "{{ .COMMAND }}",
+    "expect": [
+      "(?m)^out$"
+    ],
+    "timeout": 2000000000,
+    "stderr": true
+  },
+  "resultContexts": [
+    {
+      "pattern": "(?m)^out$",
+      "defaultResult": 1
+    }
+  ],
+  "testResult": 0,
+  "testTimeout": 2000000000
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package generic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic/assertion"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+// variableReference matches the references to the variables in the steps and assertions, "%{name}", and their escaped
+// form, "%%{".  "%{" is used as neither the shell nor the templates of NewGenericFromTemplate give it a meaning.
+var variableReference = regexp.MustCompile(`%%\{|%\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// captureVariables returns the named groups of pattern in match, e.g. "pid" for `(?P<pid>\d+)`.
+func captureVariables(pattern, match string) map[string]string {
+	regex := regexp.MustCompile(pattern)
+	submatches := regex.FindStringSubmatch(match)
+	if submatches == nil {
+		return nil
+	}
+	return namedSubmatches(regex, func(i int) string { return submatches[i] })
+}
+
+// captureOutputVariables returns the named groups of pattern in the output of a streamed step, from the submatches
+// found while streaming instead of matching pattern again.
+func captureOutputVariables(pattern string, output *reel.Output) map[string]string {
+	if output.Index() == nil {
+		return nil
+	}
+	return namedSubmatches(regexp.MustCompile(pattern), output.Submatch)
+}
+
+// namedSubmatches returns the named groups of regex, whose i-th submatch is submatch(i).
+func namedSubmatches(regex *regexp.Regexp, submatch func(i int) string) map[string]string {
+	var variables map[string]string
+	for i, name := range regex.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if variables == nil {
+			variables = map[string]string{}
+		}
+		variables[name] = submatch(i)
+	}
+	return variables
+}
+
+// capture stores the captured variables in the variables of the test, and returns them.
+func (g *Generic) capture(variables map[string]string) map[string]string {
+	if len(variables) > 0 && g.Variables == nil {
+		g.Variables = map[string]string{}
+	}
+	for name, value := range variables {
+		g.Variables[name] = value
+	}
+	return variables
+}
+
+// interpolate replaces the references to the variables of the test in s by their value.  A reference to an undefined
+// variable is an error.
+func (g *Generic) interpolate(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	var err error
+	interpolated := variableReference.ReplaceAllStringFunc(s, func(reference string) string {
+		if reference == "%%{" {
+			return "%{"
+		}
+		name := reference[2 : len(reference)-1]
+		value, ok := g.Variables[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("reference to an undefined variable: %q", name)
+		}
+		return value
+	})
+	return interpolated, err
+}
+
+// interpolateStep returns step with the references to the variables of the test replaced in Execute.
+func (g *Generic) interpolateStep(step *reel.Step) (*reel.Step, error) {
+	if step == nil {
+		return nil, nil
+	}
+	execute, err := g.interpolate(step.Execute)
+	if err != nil || execute == step.Execute {
+		return step, err
+	}
+	interpolated := *step
+	interpolated.Execute = execute
+	return &interpolated, nil
+}
+
+// evaluateComposedAssertion evaluates composedAssertion against match, after interpolating its conditions.
+func (g *Generic) evaluateComposedAssertion(composedAssertion *assertion.Assertions, match string, regex *regexp.Regexp) (bool, error) {
+	assertions, err := composedAssertion.Interpolate(g.interpolate)
+	if err != nil {
+		return false, err
+	}
+	return (*composedAssertion.Logic).Evaluate(assertions, match, regex)
+}
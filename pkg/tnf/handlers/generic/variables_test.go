@@ -0,0 +1,103 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package generic_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	pidPattern      = "(?m)^hello pid (?P<pid>\\d+) %\\{literal\\}$"
+	checkingPattern = "(?m)^checking (\\d+)$"
+)
+
+func newVariablesGeneric(t *testing.T) *generic.Generic {
+	tester, _, result, err := generic.NewGenericFromJSONFile(getTestFileLocation("variables"), schemaPath)
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+	return (*tester).(*generic.Generic)
+}
+
+func TestGeneric_Variables(t *testing.T) {
+	testCases := map[string]struct {
+		checked        string
+		expectedResult int
+	}{
+		"same_pid":      {checked: "checking 1234", expectedResult: tnf.SUCCESS},
+		"different_pid": {checked: "checking 4321", expectedResult: tnf.FAILURE},
+	}
+	for name, tc := range testCases {
+		g := newVariablesGeneric(t)
+		// The initial variables are interpolated, and "%%{" escapes "%{".
+		assert.Equal(t, "echo hello pid 1234 %{literal}\n", g.ReelFirst().Execute, name)
+
+		step := g.ReelMatch(pidPattern, "", "hello pid 1234 %{literal}")
+		assert.Equal(t, &reel.Step{Execute: "echo checking 1234\n", Expect: []string{checkingPattern}, Timeout: 2 * time.Second}, step, name)
+		assert.Equal(t, map[string]string{"greeting": "hello", "pid": "1234"}, g.Variables, name)
+
+		assert.Nil(t, g.ReelMatch(checkingPattern, "", tc.checked), name)
+		assert.Equal(t, tc.expectedResult, g.Result(), name)
+
+		matches := g.GetMatches()
+		assert.Len(t, matches, 2, name)
+		assert.Equal(t, map[string]string{"pid": "1234"}, matches[0].Variables, name)
+		assert.Nil(t, matches[1].Variables, name)
+	}
+}
+
+func TestGeneric_UndefinedVariable(t *testing.T) {
+	g := &generic.Generic{ReelFirstStep: &reel.Step{Execute: "echo %{pid}\n", Expect: []string{checkingPattern}}}
+	assert.Nil(t, g.ReelFirst())
+	assert.Equal(t, tnf.ERROR, g.Result())
+	assert.Equal(t, `reference to an undefined variable: "pid"`, g.FailureReason)
+
+	// A state referencing an undefined variable ends in the ERROR state.
+	g = newStatesGeneric(t)
+	g.States["query"].Execute = "echo %{count}\n"
+	g.ReelFirst()
+	assert.Nil(t, g.ReelMatch(loginOkPattern, "", "login ok"))
+	assert.Equal(t, generic.StateError, g.CurrentState)
+	assert.Equal(t, `reference to an undefined variable: "count"`, g.FailureReason)
+}
+
+func TestGeneric_VariablesRun(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, stateTimeout)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+
+	g := newVariablesGeneric(t)
+	test, err := tnf.NewTest(context.GetExpecter(), g, []reel.Handler{g}, context.GetErrorChannel())
+	assert.Nil(t, err)
+	result, err := test.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.SUCCESS, result)
+	assert.Equal(t, "1234", g.Variables["pid"])
+	// The streamed output is matched through ReelMatchOutput.
+	matches := g.GetMatches()
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "hello pid 1234 %{literal}", matches[0].Match)
+	assert.Equal(t, map[string]string{"pid": "1234"}, matches[0].Variables)
+}
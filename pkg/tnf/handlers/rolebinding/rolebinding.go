@@ -17,9 +17,12 @@
 package rolebinding
 
 import (
+	"bufio"
+	"io"
 	"strings"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
 	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
@@ -27,6 +30,8 @@ import (
 
 const (
 	rbRegex = "(?s).+"
+	// maxLineSize bounds the size of a line of the command output.
+	maxLineSize = 1024 * 1024
 )
 
 // RoleBinding holds information derived from running "oc get rolebindings" on the command line.
@@ -87,19 +92,27 @@ func (rb *RoleBinding) ReelFirst() *reel.Step {
 
 // ReelMatch ensures that there are no ServiceAccount RoleBindings for a given OpenShift Pod namespace.
 func (rb *RoleBinding) ReelMatch(_, _, match string) *reel.Step {
+	return rb.parse(strings.NewReader(match))
+}
+
+// ReelMatchOutput is ReelMatch for a streamed step, reading the lines of the output instead of copying it to a string.
+func (rb *RoleBinding) ReelMatchOutput(_ string, output *reel.Output) *reel.Step {
+	return rb.parse(output.Reader())
+}
+
+// parse saves the bindings listed in the command output.
+func (rb *RoleBinding) parse(output io.Reader) *reel.Step {
 	const (
 		nsIdx   = 0
 		nameIdx = 1
 	)
 
-	lines := strings.Split(match, "\n")[1:] // First line is the headers/titles line
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(nil, maxLineSize)
+	scanner.Scan() // First line is the headers/titles line
 
-		fields := strings.Fields(line)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
 
 		if len(fields) == 0 {
 			continue
@@ -114,7 +127,10 @@ func (rb *RoleBinding) ReelMatch(_, _, match string) *reel.Step {
 		rb.roleBindings = append(rb.roleBindings, fields[nsIdx]+":"+fields[nameIdx])
 	}
 
-	if len(rb.roleBindings) == 0 {
+	if err := scanner.Err(); err != nil {
+		log.Errorf("Cannot read the command output. Error: %s", err)
+		rb.result = tnf.ERROR
+	} else if len(rb.roleBindings) == 0 {
 		rb.result = tnf.SUCCESS
 	} else {
 		rb.result = tnf.FAILURE
@@ -17,6 +17,8 @@
 package rolebinding_test
 
 import (
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
@@ -24,6 +26,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
 	rb "github.com/test-network-function/test-network-function/pkg/tnf/handlers/rolebinding"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
 )
 
 func Test_NewRoleBinding(t *testing.T) {
@@ -89,6 +93,26 @@ func Test_ReelMatchFail(t *testing.T) {
 	assert.Len(t, newRb.GetRoleBindings(), 2)
 }
 
+func Test_ReelMatchOutput(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf 'NAMESPACE NAME SERVICE_ACCOUNTS\ndefault test-builder map[kind:ServiceAccount name:testServiceAccount namespace:testPodNamespace]\ndefault test-other map[kind:ServiceAccount name:otherServiceAccount namespace:testPodNamespace]\ntestPodNamespace test-deployer map[kind:ServiceAccount name:testServiceAccount namespace:testPodNamespace]\\n'\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "oc"), []byte(script), 0o755)) //nolint:gosec // An executable script
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SHELL", "/bin/sh")
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, testTimeoutDuration)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+
+	newRb := rb.NewRoleBinding(testTimeoutDuration, testServiceAccount, testPodNamespace)
+	test, err := tnf.NewTest(context.GetExpecter(), newRb, []reel.Handler{newRb}, context.GetErrorChannel())
+	assert.Nil(t, err)
+	result, err := test.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.FAILURE, result)
+	assert.Equal(t, []string{"default:test-builder"}, newRb.GetRoleBindings())
+}
+
 // Just ensure there are no panics.
 func Test_ReelEof(t *testing.T) {
 	newRb := rb.NewRoleBinding(testTimeoutDuration, testServiceAccount, testPodNamespace)
@@ -0,0 +1,443 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// ocSessionBackendEnvVar selects how the container sessions of SpawnOc are created.
+	ocSessionBackendEnvVar = "TNF_OC_SESSION_BACKEND"
+	// OcSessionBackendOc runs an `oc rsh` subprocess per container session.
+	OcSessionBackendOc = "oc"
+	// OcSessionBackendExec streams the container sessions through the Kubernetes exec API, without subprocesses.
+	OcSessionBackendExec = "exec"
+
+	// defaultMaxReconnects is the number of consecutive reconnections of a kube exec session before it is given up.
+	defaultMaxReconnects = 3
+	// defaultReconnectDelay is the delay before a lost kube exec session is reconnected.
+	defaultReconnectDelay = time.Second
+	// reconnectResetAfter is how long a stream must last to reset the count of consecutive reconnections.
+	reconnectResetAfter = 30 * time.Second
+)
+
+// kubeExecShell is the command run in the containers, as `oc rsh` does when the standard input is not a terminal.
+var kubeExecShell = []string{"/bin/sh"}
+
+var ocSpawnFunc SpawnFunc
+
+// SetOcSpawnFunc sets the SpawnFunc creating the `oc rsh` sessions, e.g. a KubeExecSpawnFunc.  A nil SpawnFunc
+// restores the `oc` subprocesses.
+func SetOcSpawnFunc(sFunc SpawnFunc) {
+	ocSpawnFunc = sFunc
+}
+
+// GetOcSessionBackend returns the container session backend requested by the TNF_OC_SESSION_BACKEND environment
+// variable, defaulting to the oc backend.
+func GetOcSessionBackend() string {
+	backend := strings.ToLower(os.Getenv(ocSessionBackendEnvVar))
+	switch backend {
+	case "", OcSessionBackendOc:
+		return OcSessionBackendOc
+	case OcSessionBackendExec:
+		return OcSessionBackendExec
+	default:
+		log.Warnf("Unknown %s value %q, using %s", ocSessionBackendEnvVar, backend, OcSessionBackendOc)
+		return OcSessionBackendOc
+	}
+}
+
+// ExecutorFactory creates the remotecommand.Executor running command in a container.
+type ExecutorFactory func(namespace, pod, container string, command []string) (remotecommand.Executor, error)
+
+// NewSPDYExecutorFactory returns an ExecutorFactory using the exec subresource of the pods of the cluster of config.
+func NewSPDYExecutorFactory(config *rest.Config) (ExecutorFactory, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(namespace, pod, container string, command []string) (remotecommand.Executor, error) {
+		req := clientset.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Namespace(namespace).
+			Name(pod).
+			SubResource("exec").
+			VersionedParams(&corev1.PodExecOptions{
+				Container: container,
+				Command:   command,
+				Stdin:     true,
+				Stdout:    true,
+				Stderr:    true,
+			}, scheme.ParameterCodec)
+		return remotecommand.NewSPDYExecutor(config, http.MethodPost, req.URL())
+	}, nil
+}
+
+// KubeExecSpawnFunc is an implementation of SpawnFunc creating the `oc rsh` sessions through the Kubernetes exec API.
+// The sessions are reconnected when their stream ends before they are closed, so the shell state (working directory,
+// variables) does not survive a reconnection.
+type KubeExecSpawnFunc struct {
+	factory ExecutorFactory
+	// MaxReconnects is the number of consecutive reconnections after which a lost session is given up.
+	MaxReconnects int
+	// ReconnectDelay is the delay before a lost session is reconnected.
+	ReconnectDelay time.Duration
+}
+
+// NewKubeExecSpawnFunc creates a KubeExecSpawnFunc using factory to connect to the containers.
+func NewKubeExecSpawnFunc(factory ExecutorFactory) *KubeExecSpawnFunc {
+	return &KubeExecSpawnFunc{factory: factory, MaxReconnects: defaultMaxReconnects, ReconnectDelay: defaultReconnectDelay}
+}
+
+// Command creates a session for `oc rsh -n namespace -c container pod`.  Other commands fail to start.
+func (k *KubeExecSpawnFunc) Command(name string, arg ...string) *SpawnFunc {
+	var session SpawnFunc = newKubeExecSession(k, name, arg)
+	return &session
+}
+
+// Start is not supported, sessions are started from the SpawnFunc returned by Command.
+func (k *KubeExecSpawnFunc) Start() error {
+	return errors.New("no kube exec session to start")
+}
+
+// StdinPipe is not supported, see Start.
+func (k *KubeExecSpawnFunc) StdinPipe() (io.WriteCloser, error) {
+	return nil, errors.New("no kube exec session")
+}
+
+// StdoutPipe is not supported, see Start.
+func (k *KubeExecSpawnFunc) StdoutPipe() (io.Reader, error) {
+	return nil, errors.New("no kube exec session")
+}
+
+// StderrPipe is not supported, see Start.
+func (k *KubeExecSpawnFunc) StderrPipe() (io.Reader, error) {
+	return nil, errors.New("no kube exec session")
+}
+
+// Wait returns immediately, see Start.
+func (k *KubeExecSpawnFunc) Wait() error {
+	return nil
+}
+
+// Close does nothing, see Start.
+func (k *KubeExecSpawnFunc) Close() error {
+	return nil
+}
+
+// IsRunning returns false, see Start.
+func (k *KubeExecSpawnFunc) IsRunning() bool {
+	return false
+}
+
+// Args returns nil, see Start.
+func (k *KubeExecSpawnFunc) Args() []string {
+	return nil
+}
+
+// parseOcRshArgs extracts the container of the arguments built by SpawnOc.
+func parseOcRshArgs(args []string) (namespace, container, pod string, err error) {
+	if len(args) == 0 || args[0] != ocRsh {
+		return "", "", "", fmt.Errorf("unsupported oc arguments %v", args)
+	}
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case ocNamespaceArg, ocContainerArg:
+			if i+1 == len(args) {
+				return "", "", "", fmt.Errorf("missing value of %s in %v", args[i], args)
+			}
+			if args[i] == ocNamespaceArg {
+				namespace = args[i+1]
+			} else {
+				container = args[i+1]
+			}
+			i++
+		default:
+			if pod != "" {
+				return "", "", "", fmt.Errorf("unsupported oc arguments %v", args)
+			}
+			pod = args[i]
+		}
+	}
+	if pod == "" {
+		return "", "", "", fmt.Errorf("missing pod in %v", args)
+	}
+	return namespace, container, pod, nil
+}
+
+// kubeExecSession is a container session streamed through a remotecommand.Executor.
+type kubeExecSession struct {
+	spawnFunc *KubeExecSpawnFunc
+	args      []string
+	namespace string
+	container string
+	pod       string
+	parseErr  error
+
+	// input holds the writes to the standard input until a stream reads them.
+	input chan []byte
+	// mutex protects pending, reconnects and err.
+	mutex sync.Mutex
+	// pending is the part of the input read back from a stream which ended.
+	pending    []byte
+	reconnects int
+	err        error
+
+	stdoutReader, stderrReader *io.PipeReader
+	stdoutWriter, stderrWriter *io.PipeWriter
+
+	started   bool
+	closed    chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newKubeExecSession(k *KubeExecSpawnFunc, name string, args []string) *kubeExecSession {
+	s := &kubeExecSession{
+		spawnFunc: k,
+		args:      append([]string{name}, args...),
+		input:     make(chan []byte),
+		closed:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	s.namespace, s.container, s.pod, s.parseErr = parseOcRshArgs(args)
+	s.stdoutReader, s.stdoutWriter = io.Pipe()
+	s.stderrReader, s.stderrWriter = io.Pipe()
+	return s
+}
+
+// Command creates a new session, see KubeExecSpawnFunc.Command.
+func (s *kubeExecSession) Command(name string, arg ...string) *SpawnFunc {
+	return s.spawnFunc.Command(name, arg...)
+}
+
+// Start connects to the container, and keeps the session connected until it is closed.
+func (s *kubeExecSession) Start() error {
+	if s.parseErr != nil {
+		return s.parseErr
+	}
+	executor, err := s.spawnFunc.factory(s.namespace, s.pod, s.container, kubeExecShell)
+	if err != nil {
+		return err
+	}
+	s.started = true
+	go s.run(executor)
+	return nil
+}
+
+// run streams the session until it is closed, reconnecting when a stream ends.  The session ends after MaxReconnects
+// consecutive reconnections.
+func (s *kubeExecSession) run(executor remotecommand.Executor) {
+	defer close(s.done)
+	defer s.stderrWriter.Close()
+	defer s.stdoutWriter.Close()
+
+	consecutive := 0
+	for {
+		stop := make(chan struct{})
+		start := time.Now()
+		err := executor.Stream(remotecommand.StreamOptions{
+			Stdin:  &kubeExecStdin{session: s, stop: stop},
+			Stdout: s.stdoutWriter,
+			Stderr: s.stderrWriter,
+		})
+		close(stop)
+		if s.isClosed() {
+			return
+		}
+		if err == nil {
+			err = io.EOF
+		}
+		if time.Since(start) >= reconnectResetAfter {
+			consecutive = 0
+		}
+		for {
+			if consecutive >= s.spawnFunc.MaxReconnects {
+				log.Errorf("Exec session to %s/%s lost: %v, giving up after %d reconnections", s.pod, s.container, err, consecutive)
+				s.setErr(err)
+				return
+			}
+			consecutive++
+			log.Warnf("Exec session to %s/%s lost: %v, reconnecting (%d/%d)", s.pod, s.container, err, consecutive, s.spawnFunc.MaxReconnects)
+			select {
+			case <-s.closed:
+				return
+			case <-time.After(s.spawnFunc.ReconnectDelay):
+			}
+			executor, err = s.spawnFunc.factory(s.namespace, s.pod, s.container, kubeExecShell)
+			if err == nil {
+				break
+			}
+		}
+		s.mutex.Lock()
+		s.reconnects++
+		s.mutex.Unlock()
+	}
+}
+
+func (s *kubeExecSession) setErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
+
+func (s *kubeExecSession) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reconnects returns how many times the session was reconnected.
+func (s *kubeExecSession) Reconnects() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.reconnects
+}
+
+// StdinPipe returns the standard input of the session, which is kept across reconnections.
+func (s *kubeExecSession) StdinPipe() (io.WriteCloser, error) {
+	return &kubeExecInput{session: s}, nil
+}
+
+// StdoutPipe returns the standard output of the session, which is kept across reconnections.
+func (s *kubeExecSession) StdoutPipe() (io.Reader, error) {
+	return s.stdoutReader, nil
+}
+
+// StderrPipe returns the standard error of the session, which is kept across reconnections.
+func (s *kubeExecSession) StderrPipe() (io.Reader, error) {
+	return s.stderrReader, nil
+}
+
+// Wait waits for the session to end, and returns the error of the last stream if it was given up.
+func (s *kubeExecSession) Wait() error {
+	if !s.started {
+		return errors.New("kube exec session not started")
+	}
+	<-s.done
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// Close ends the session: the stream gets the end of its standard input, and the output pipes are closed.
+func (s *kubeExecSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.stdoutWriter.Close()
+		s.stderrWriter.Close()
+	})
+	return nil
+}
+
+// IsRunning returns true until the session ends.
+func (s *kubeExecSession) IsRunning() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return s.started
+	}
+}
+
+// Args returns the oc command and arguments the session stands for.
+func (s *kubeExecSession) Args() []string {
+	return s.args
+}
+
+// unread puts data back in front of the input.
+func (s *kubeExecSession) unread(data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pending = append(append([]byte{}, data...), s.pending...)
+}
+
+// kubeExecInput is the standard input of a kubeExecSession.
+type kubeExecInput struct {
+	session *kubeExecSession
+}
+
+// Write queues a copy of data until a stream reads it.
+func (i *kubeExecInput) Write(data []byte) (int, error) {
+	select {
+	case i.session.input <- append([]byte{}, data...):
+		return len(data), nil
+	case <-i.session.closed:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+// Close closes the session.
+func (i *kubeExecInput) Close() error {
+	return i.session.Close()
+}
+
+// kubeExecStdin is the standard input of a stream, which ends with the stream so that the input written afterwards
+// is left to the next stream.
+type kubeExecStdin struct {
+	session *kubeExecSession
+	stop    <-chan struct{}
+}
+
+func (i *kubeExecStdin) Read(p []byte) (int, error) {
+	s := i.session
+	s.mutex.Lock()
+	if len(s.pending) > 0 {
+		n := copy(p, s.pending)
+		s.pending = s.pending[n:]
+		s.mutex.Unlock()
+		return n, nil
+	}
+	s.mutex.Unlock()
+
+	select {
+	case <-i.stop:
+		return 0, io.EOF
+	case <-s.closed:
+		return 0, io.EOF
+	case data := <-s.input:
+		select {
+		case <-i.stop:
+			s.unread(data)
+			return 0, io.EOF
+		default:
+		}
+		n := copy(p, data)
+		if n < len(data) {
+			s.unread(data[n:])
+		}
+		return n, nil
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	expect "github.com/google/goexpect"
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const kubeExecTestTimeout = 5 * time.Second
+
+var errStreamLost = errors.New("stream lost")
+
+// fakeExecutor echoes the lines of its standard input, and ends the stream with errStreamLost on a "drop" line.
+type fakeExecutor struct {
+	fails bool
+}
+
+func (f *fakeExecutor) Stream(options remotecommand.StreamOptions) error {
+	if f.fails {
+		return errStreamLost
+	}
+	scanner := bufio.NewScanner(options.Stdin)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "drop" {
+			return errStreamLost
+		}
+		// The output does not end with the input, which the spawned sessions would drop as its echo.
+		if _, err := fmt.Fprintf(options.Stdout, "out: %s.\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeExecutorFactory records the containers it connects to.
+type fakeExecutorFactory struct {
+	mutex      sync.Mutex
+	containers []string
+	executor   fakeExecutor
+}
+
+func (f *fakeExecutorFactory) create(namespace, pod, container string, command []string) (remotecommand.Executor, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.containers = append(f.containers, fmt.Sprintf("%s/%s/%s %v", namespace, pod, container, command))
+	return &f.executor, nil
+}
+
+func (f *fakeExecutorFactory) connections() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.containers)
+}
+
+func spawnKubeExec(t *testing.T, sFunc *interactive.KubeExecSpawnFunc) expect.Expecter {
+	interactive.SetOcSpawnFunc(sFunc)
+	t.Cleanup(func() {
+		interactive.SetOcSpawnFunc(nil)
+	})
+	context, err := interactive.NewGoExpectSpawner().Spawn("oc", []string{"rsh", "-n", "tnf", "-c", "test", "test-0"}, kubeExecTestTimeout)
+	assert.Nil(t, err)
+	return *context.GetExpecter()
+}
+
+func TestKubeExecSpawnFunc(t *testing.T) {
+	factory := &fakeExecutorFactory{}
+	sFunc := interactive.NewKubeExecSpawnFunc(factory.create)
+	sFunc.ReconnectDelay = time.Millisecond
+	expecter := spawnKubeExec(t, sFunc)
+	defer expecter.Close()
+
+	assert.Nil(t, expecter.Send("hello\n"))
+	_, _, err := expecter.Expect(regexp.MustCompile(`out: hello`), kubeExecTestTimeout)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tnf/test-0/test [/bin/sh]"}, factory.containers)
+
+	// The session is reconnected, and the input sent afterwards goes to the new stream.
+	assert.Nil(t, expecter.Send("drop\n"))
+	assert.Eventually(t, func() bool {
+		return factory.connections() == 2
+	}, kubeExecTestTimeout, time.Millisecond)
+	assert.Nil(t, expecter.Send("again\n"))
+	_, _, err = expecter.Expect(regexp.MustCompile(`out: again`), kubeExecTestTimeout)
+	assert.Nil(t, err)
+}
+
+func TestKubeExecSpawnFunc_GiveUp(t *testing.T) {
+	factory := &fakeExecutorFactory{executor: fakeExecutor{fails: true}}
+	sFunc := interactive.NewKubeExecSpawnFunc(factory.create)
+	sFunc.ReconnectDelay = time.Millisecond
+	sFunc.MaxReconnects = 2
+
+	session := *sFunc.Command("oc", "rsh", "-n", "tnf", "-c", "test", "test-0")
+	stdout, err := session.StdoutPipe()
+	assert.Nil(t, err)
+	go func() {
+		_, _ = bufio.NewReader(stdout).ReadString('\n')
+	}()
+	assert.Nil(t, session.Start())
+	assert.Equal(t, errStreamLost, session.Wait())
+	assert.False(t, session.IsRunning())
+	// The first connection and two reconnections.
+	assert.Equal(t, 3, factory.connections())
+	assert.Equal(t, []string{"oc", "rsh", "-n", "tnf", "-c", "test", "test-0"}, session.Args())
+}
+
+func TestKubeExecSpawnFunc_Close(t *testing.T) {
+	factory := &fakeExecutorFactory{}
+	sFunc := interactive.NewKubeExecSpawnFunc(factory.create)
+
+	session := *sFunc.Command("oc", "rsh", "-n", "tnf", "-c", "test", "test-0")
+	stdin, err := session.StdinPipe()
+	assert.Nil(t, err)
+	assert.Nil(t, session.Start())
+	assert.True(t, session.IsRunning())
+	assert.Nil(t, session.Close())
+	assert.Nil(t, session.Wait())
+	assert.False(t, session.IsRunning())
+	assert.Equal(t, 1, factory.connections())
+	_, err = stdin.Write([]byte("late\n"))
+	assert.NotNil(t, err)
+}
+
+func TestKubeExecSpawnFunc_UnsupportedArgs(t *testing.T) {
+	factory := &fakeExecutorFactory{}
+	sFunc := interactive.NewKubeExecSpawnFunc(factory.create)
+
+	for _, args := range [][]string{
+		{"get", "pods"},
+		{"rsh", "-n", "tnf"},
+		{"rsh", "-n", "tnf", "-c"},
+		{"rsh", "pod-0", "pod-1"},
+	} {
+		session := *sFunc.Command("oc", args...)
+		assert.NotNil(t, session.Start(), args)
+	}
+	assert.Equal(t, 0, factory.connections())
+}
+
+// spawnWithShell spawns command with a shell in place of the subprocesses, and returns the error of the spawn.
+func spawnWithShell(t *testing.T, command string, args ...string) error {
+	var shell interactive.SpawnFunc = &shellOcSpawnFunc{}
+	interactive.SetSpawnFunc(&shell)
+	defer interactive.SetSpawnFunc(nil)
+	context, err := interactive.NewGoExpectSpawner().Spawn(command, args, kubeExecTestTimeout)
+	if err == nil {
+		_ = (*context.GetExpecter()).Close()
+	}
+	return err
+}
+
+func TestSpawn_OcRshOnly(t *testing.T) {
+	factory := &fakeExecutorFactory{}
+	interactive.SetOcSpawnFunc(interactive.NewKubeExecSpawnFunc(factory.create))
+	defer interactive.SetOcSpawnFunc(nil)
+
+	// The other oc commands are subprocesses.
+	assert.Nil(t, spawnWithShell(t, "oc", "get", "pods"))
+	assert.Equal(t, 0, factory.connections())
+	assert.Nil(t, spawnWithShell(t, "oc", "rsh", "-n", "tnf", "-c", "test", "test-0"))
+	assert.Equal(t, 1, factory.connections())
+}
+
+func TestGetOcSessionBackend(t *testing.T) {
+	testCases := map[string]string{
+		"":        interactive.OcSessionBackendOc,
+		"oc":      interactive.OcSessionBackendOc,
+		"EXEC":    interactive.OcSessionBackendExec,
+		"unknown": interactive.OcSessionBackendOc,
+	}
+	for envValue, expected := range testCases {
+		t.Setenv("TNF_OC_SESSION_BACKEND", envValue)
+		assert.Equal(t, expected, interactive.GetOcSessionBackend())
+	}
+}
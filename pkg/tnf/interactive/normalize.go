@@ -0,0 +1,168 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+// maxHeldEscapeSize bounds the size of an incomplete ANSI escape sequence held back until the rest of it is read.
+const maxHeldEscapeSize = 64
+
+// NormalizeOutput normalizes the output of the spawned sessions with normalizers, in order, before goexpect matches
+// it, see reel.Normalizer.  The lines are numbered from the last command sent to the session, so that reel.StripEcho
+// drops its echo.  The transcripts still record the output as it is.  By default, the normalizers are
+// reel.DefaultNormalizers.
+func NormalizeOutput(normalizers ...reel.Normalizer) Option {
+	return func(g *GoExpectSpawner) Option {
+		g.normalizersIsSet = true
+		prev := g.normalizers
+		g.normalizers = normalizers
+		return NormalizeOutput(prev...)
+	}
+}
+
+// normalizePipes returns the standard input and output of a session, the output being normalized by the normalizers
+// of g.
+func (g *GoExpectSpawner) normalizePipes(stdin io.WriteCloser, stdout io.Reader) (io.WriteCloser, io.Reader) {
+	normalizers := g.normalizers
+	if !g.normalizersIsSet {
+		normalizers = reel.DefaultNormalizers()
+	}
+	if len(normalizers) == 0 {
+		return stdin, stdout
+	}
+	reader := &normalizingReader{reader: stdout, normalizers: normalizers}
+	return &commandWriter{WriteCloser: stdin, reader: reader}, reader
+}
+
+// commandWriter tells the normalizingReader of its session which command was sent last.
+type commandWriter struct {
+	io.WriteCloser
+	reader *normalizingReader
+}
+
+func (w *commandWriter) Write(p []byte) (int, error) {
+	w.reader.setCommand(string(p))
+	return w.WriteCloser.Write(p)
+}
+
+// normalizingReader applies normalizers to the lines of the output of a session as it is read.  An incomplete line is
+// not held back, as a prompt may never be followed by a line ending, so it is normalized in parts.
+type normalizingReader struct {
+	reader      io.Reader
+	normalizers []reel.Normalizer
+
+	// mutex protects command and index, which are reset when a command is sent.
+	mutex   sync.Mutex
+	command string
+	index   int
+	// dropped tells whether a part of the current line was dropped, and so is the rest of it.
+	dropped bool
+	// held is the start of an incomplete escape sequence, normalized with the next read.
+	held []byte
+	// normalized is the normalized output not returned yet.
+	normalized []byte
+}
+
+func (r *normalizingReader) setCommand(command string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.command = strings.TrimRight(command, "\n")
+	r.index = 0
+	r.dropped = false
+}
+
+func (r *normalizingReader) Read(p []byte) (int, error) {
+	for len(r.normalized) == 0 {
+		chunk := make([]byte, len(p))
+		n, err := r.reader.Read(chunk)
+		if n > 0 {
+			r.normalized = r.normalize(chunk[:n])
+		}
+		if err != nil {
+			if len(r.normalized) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+	n := copy(p, r.normalized)
+	r.normalized = r.normalized[n:]
+	return n, nil
+}
+
+// normalize returns the normalized lines of chunk.
+func (r *normalizingReader) normalize(chunk []byte) []byte {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	data := append(r.held, chunk...)
+	r.held = nil
+	var normalized bytes.Buffer
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			data = r.holdEscape(data)
+			r.normalizeLine(&normalized, string(data), "")
+			break
+		}
+		r.normalizeLine(&normalized, string(data[:end]), "\n")
+		data = data[end+1:]
+	}
+	return normalized.Bytes()
+}
+
+// holdEscape holds back the incomplete escape sequence ending data, if any, and returns the rest of data.
+func (r *normalizingReader) holdEscape(data []byte) []byte {
+	start := bytes.LastIndexByte(data, '\x1b')
+	if start < 0 || len(data)-start > maxHeldEscapeSize {
+		return data
+	}
+	if stripped, _ := reel.StripANSI("", 0, string(data[start:])); stripped != string(data[start:]) {
+		return data
+	}
+	r.held = append([]byte(nil), data[start:]...)
+	return data[:start]
+}
+
+// normalizeLine writes line, normalized, followed by ending unless it is dropped.  An empty ending means line is a
+// part of a line, whose rest follows.
+func (r *normalizingReader) normalizeLine(normalized *bytes.Buffer, line, ending string) {
+	defer func() {
+		if ending != "" {
+			r.index++
+			r.dropped = false
+		}
+	}()
+	if r.dropped || (line == "" && ending == "") {
+		return
+	}
+	for _, normalizer := range r.normalizers {
+		var keep bool
+		if line, keep = normalizer(r.command, r.index, line); !keep {
+			r.dropped = ending == ""
+			return
+		}
+	}
+	normalized.WriteString(line)
+	normalized.WriteString(ending)
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive_test
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+)
+
+const normalizeTestTimeout = 500 * time.Millisecond
+
+func TestNormalizeOutput(t *testing.T) {
+	defer interactive.SetSpawnFunc(nil)
+	var execSpawnFunc interactive.SpawnFunc = &interactive.ExecSpawnFunc{}
+	interactive.SetSpawnFunc(&execSpawnFunc)
+
+	testCases := map[string]struct {
+		command string
+	}{
+		"ansi_and_crlf": {
+			command: `printf '\033[1mbold\033[0m\r\nnext\r\n'`,
+		},
+		"escape_split_across_reads": {
+			command: `printf 'bold\033'; sleep 0.1; printf '[0m\nnext\n'`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+		recorder, err := interactive.CreateTranscriptFile(transcriptPath)
+		assert.Nil(t, err, name)
+		context, err := interactive.NewGoExpectSpawner().Spawn("/bin/sh", []string{}, testTimeoutDuration, interactive.RecordTranscript(recorder))
+		assert.Nil(t, err, name)
+		expecter := *context.GetExpecter()
+
+		// goexpect matches the normalized output.
+		assert.Nil(t, expecter.Send(testCase.command+"\n"), name)
+		_, _, err = expecter.Expect(regexp.MustCompile(`(?m)^bold\nnext\n`), normalizeTestTimeout)
+		assert.Nil(t, err, name)
+		assert.Nil(t, expecter.Close(), name)
+		assert.Nil(t, recorder.Close(), name)
+
+		// The transcript records the output as it is.
+		events, err := interactive.ReadTranscript(transcriptPath)
+		assert.Nil(t, err, name)
+		var received strings.Builder
+		for _, event := range events {
+			if event.Kind == interactive.TranscriptReceive {
+				received.WriteString(event.Data)
+			}
+		}
+		assert.Contains(t, received.String(), "\033[0m", name)
+	}
+}
+
+func TestNormalizeOutput_Disabled(t *testing.T) {
+	defer interactive.SetSpawnFunc(nil)
+	var execSpawnFunc interactive.SpawnFunc = &interactive.ExecSpawnFunc{}
+	interactive.SetSpawnFunc(&execSpawnFunc)
+
+	context, err := interactive.NewGoExpectSpawner().Spawn("/bin/sh", []string{}, testTimeoutDuration, interactive.NormalizeOutput())
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+	defer expecter.Close()
+
+	assert.Nil(t, expecter.Send(`printf '\033[1mbold\033[0m\r\n'`+"\n"))
+	_, _, err = expecter.Expect(regexp.MustCompile(`(?m)^bold\n`), normalizeTestTimeout)
+	assert.NotNil(t, err)
+}
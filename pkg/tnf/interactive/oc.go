@@ -49,6 +49,8 @@ type Oc struct {
 	*Context
 	// done channel to notify the go routine that monitors the error channel
 	doneChannel chan bool
+	// target is the SessionPool target of the session, when it comes from a SessionPool
+	target *Target
 }
 
 // SpawnOc creates an OpenShift Client subprocess, spawning the appropriate underlying PTY.
@@ -56,7 +58,7 @@ func SpawnOc(spawner *Spawner, pod, container, namespace string, timeout time.Du
 	ocArgs := []string{ocRsh, ocNamespaceArg, namespace, ocContainerArg, container, pod}
 	context, err := (*spawner).Spawn(ocCommand, ocArgs, timeout, opts...)
 	if err != nil {
-		return nil, context.GetErrorChannel(), err
+		return nil, nil, err
 	}
 	errorChannel := context.GetErrorChannel()
 	return &Oc{pod: pod, container: container, namespace: namespace, timeout: timeout, opts: opts, spawnErr: err, Context: context, doneChannel: make(chan bool)}, errorChannel, nil
@@ -82,6 +84,15 @@ func (o *Oc) GetPodNamespace() string {
 	return o.namespace
 }
 
+// Target returns the SessionPool Target of the session.  The sessions which do not come from a SessionPool are
+// considered connected to a container under test.
+func (o *Oc) Target() Target {
+	if o.target != nil {
+		return *o.target
+	}
+	return ContainerTarget(o.namespace, o.pod, o.container)
+}
+
 // GetTimeout returns the timeout for the expect.Expecter.
 func (o *Oc) GetTimeout() time.Duration {
 	return o.timeout
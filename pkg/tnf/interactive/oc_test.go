@@ -62,6 +62,15 @@ var ocTestCases = map[string]ocTestCase{
 		contextReturnValue: &interactive.Context{},
 		expectedSpawnErr:   errSpawnOC,
 	},
+	"error_no_context": {
+		podName:            "test",
+		podContainerName:   "testPod",
+		podNamespace:       "default",
+		options:            []interactive.Option{interactive.Verbose(true)},
+		errReturnValue:     errSpawnOC,
+		contextReturnValue: nil,
+		expectedSpawnErr:   errSpawnOC,
+	},
 }
 
 func TestSpawnOc(t *testing.T) {
@@ -74,8 +83,12 @@ func TestSpawnOc(t *testing.T) {
 		mockSpawner.EXPECT().Spawn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(testCase.contextReturnValue, testCase.errReturnValue)
 
 		var spawner interactive.Spawner = mockSpawner
-		oc, _, err := interactive.SpawnOc(&spawner, testCase.podName, testCase.podContainerName, testCase.podNamespace, ocTestTimeoutDuration, testCase.options...)
+		oc, errorChannel, err := interactive.SpawnOc(&spawner, testCase.podName, testCase.podContainerName, testCase.podNamespace, ocTestTimeoutDuration, testCase.options...)
 		assert.Equal(t, testCase.expectedSpawnErr, err)
+		if testCase.expectedSpawnErr != nil {
+			assert.Nil(t, oc)
+			assert.Nil(t, errorChannel)
+		}
 		if testCase.expectedSpawnErr == nil {
 			assert.Equal(t, testCase.podName, oc.GetPodName())
 			assert.Equal(t, testCase.podContainerName, oc.GetPodContainerName())
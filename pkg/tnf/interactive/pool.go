@@ -0,0 +1,631 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	expect "github.com/google/goexpect"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// TargetLocal is the kind of the local shell sessions.
+	TargetLocal = "local"
+	// TargetContainer is the kind of the sessions to the containers under test.
+	TargetContainer = "container"
+	// TargetNode is the kind of the sessions to the debug pods of the nodes.
+	TargetNode = "node"
+
+	defaultProbeInterval = 30 * time.Second
+	defaultProbeTimeout  = 5 * time.Second
+	defaultIdleTimeout   = 10 * time.Minute
+	defaultMaxIdle       = 64
+	probeCommand         = "echo tnf-session-probe-%d\n"
+	// probeOutput matches the answer to a probe, which is alone on its line unlike the echoed probe command.
+	probeOutput = `(?m)^tnf-session-probe-%d\r?$`
+)
+
+var (
+	// errSessionRemoved is returned by the sessions used after they were removed from their SessionPool.
+	errSessionRemoved = errors.New("session removed from the pool")
+	// ErrSessionLost is wrapped by the error of a Send to a pooled session found lost.  What was sent is not sent again,
+	// the caller, e.g. a tnf.Retrier, decides whether to retry it on the reconnected session.
+	ErrSessionLost = errors.New("session lost")
+)
+
+// Target identifies what a pooled session is connected to.
+type Target struct {
+	Kind      string
+	Namespace string
+	Pod       string
+	Container string
+	// Slot distinguishes the sessions to the same target used concurrently, e.g. by the workers of a parallel run, see
+	// SessionPool.LeaseSlot.  0 is the shared session.
+	Slot int
+}
+
+// LocalTarget returns the Target of the local shell.
+func LocalTarget() Target {
+	return Target{Kind: TargetLocal}
+}
+
+// ContainerTarget returns the Target of a container under test.
+func ContainerTarget(namespace, pod, container string) Target {
+	return Target{Kind: TargetContainer, Namespace: namespace, Pod: pod, Container: container}
+}
+
+// NodeTarget returns the Target of the container of a node debug pod.
+func NodeTarget(namespace, pod, container string) Target {
+	return Target{Kind: TargetNode, Namespace: namespace, Pod: pod, Container: container}
+}
+
+// WithSlot returns the Target of the session of slot to the same target.
+func (t Target) WithSlot(slot int) Target {
+	t.Slot = slot
+	return t
+}
+
+func (t Target) String() string {
+	name := t.Kind
+	if t.Kind != TargetLocal {
+		name = fmt.Sprintf("%s/%s/%s/%s", t.Kind, t.Namespace, t.Pod, t.Container)
+	}
+	if t.Slot > 0 {
+		name = fmt.Sprintf("%s#%d", name, t.Slot)
+	}
+	return name
+}
+
+// spawnTarget creates a session to target.
+var spawnTarget = func(target Target, timeout time.Duration, opts ...Option) (*Context, error) {
+	if target.Kind == TargetLocal {
+		return SpawnShell(CreateGoExpectSpawner(), timeout, opts...)
+	}
+	oc, _, err := SpawnOc(CreateGoExpectSpawner(), target.Pod, target.Container, target.Namespace, timeout, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return oc.Context, nil
+}
+
+// SessionPool keeps one session per Target.  The Context (or Oc) of a target stays valid for the life of the pool:
+// when the underlying session is found dead, because its error channel fired, sending to it failed or it did not
+// answer a liveness probe, the next use reconnects it.  It is safe for concurrent use, but a session must only be used
+// by one goroutine at a time.
+type SessionPool struct {
+	// ProbeInterval is how long a session can stay unused before Check probes it.  0 disables the probes, e.g. when
+	// the sessions are replayed from a snapshot which does not hold the probes.
+	ProbeInterval time.Duration
+	// ProbeTimeout bounds the answer to a probe.
+	ProbeTimeout time.Duration
+	// IdleTimeout is how long a session can stay unused before Check closes it.  It is reopened, without counting as a
+	// reconnection, when it is used again.  0 keeps the sessions open.
+	IdleTimeout time.Duration
+	// MaxIdleSessions is how many sessions can stay open outside of the leased slots.  Check and ReleaseSlot close the
+	// least recently used ones beyond it, which are reopened like the ones closed after IdleTimeout.  0 does not limit
+	// them.
+	MaxIdleSessions int
+
+	timeout time.Duration
+	opts    []Option
+
+	mutex         sync.Mutex
+	sessions      map[Target]*pooledSession
+	reconnections map[string]int
+	probes        int
+	leasedSlots   map[int]bool
+}
+
+// NewSessionPool creates an empty SessionPool, whose sessions are spawned with timeout and opts.
+func NewSessionPool(timeout time.Duration, opts ...Option) *SessionPool {
+	return &SessionPool{
+		ProbeInterval:   defaultProbeInterval,
+		ProbeTimeout:    defaultProbeTimeout,
+		IdleTimeout:     defaultIdleTimeout,
+		MaxIdleSessions: defaultMaxIdle,
+		timeout:         timeout,
+		opts:            opts,
+		sessions:        make(map[Target]*pooledSession),
+		reconnections:   make(map[string]int),
+		leasedSlots:     make(map[int]bool),
+	}
+}
+
+// session returns the pooled session to target, creating it if needed.
+func (p *SessionPool) session(target Target) *pooledSession {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	s, ok := p.sessions[target]
+	if !ok {
+		s = &pooledSession{pool: p, target: target, errorChannel: make(chan error)}
+		var expecter expect.Expecter = s
+		s.context = NewContext(&expecter, s.errorChannel)
+		p.sessions[target] = s
+	}
+	return s
+}
+
+// Get returns the session to target, connecting it if needed.
+func (p *SessionPool) Get(target Target) (*Context, error) {
+	s := p.session(target)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensure(); err != nil {
+		return nil, err
+	}
+	s.lastUsed = time.Now()
+	return s.context, nil
+}
+
+// GetOc returns the session to a container or node target as an Oc.  Closing the Oc removes the target from the pool.
+func (p *SessionPool) GetOc(target Target) (*Oc, error) {
+	if target.Kind == TargetLocal {
+		return nil, fmt.Errorf("no oc session to the %s target", target)
+	}
+	context, err := p.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	s := p.session(target)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.oc == nil {
+		s.oc = &Oc{pod: target.Pod, container: target.Container, namespace: target.Namespace, timeout: p.timeout, opts: p.opts,
+			Context: context, doneChannel: make(chan bool), target: &target}
+		// Oc.Close signals its done channel before closing the session.
+		go func(done <-chan bool) {
+			<-done
+		}(s.oc.doneChannel)
+	}
+	return s.oc, nil
+}
+
+// Remove closes the session to target and forgets it.  Its Context becomes unusable.
+func (p *SessionPool) Remove(target Target) {
+	p.mutex.Lock()
+	s, ok := p.sessions[target]
+	delete(p.sessions, target)
+	p.mutex.Unlock()
+	if ok {
+		s.remove()
+	}
+}
+
+// LeaseSlot reserves a slot, other than the shared one, whose sessions are only used by the caller until ReleaseSlot.
+// The sessions of a released slot stay open, to be reused by the next lease, within MaxIdleSessions.
+func (p *SessionPool) LeaseSlot() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	slot := 1
+	for p.leasedSlots[slot] {
+		slot++
+	}
+	p.leasedSlots[slot] = true
+	return slot
+}
+
+// ReleaseSlot ends the lease of slot, and closes the least recently used idle sessions beyond MaxIdleSessions.
+func (p *SessionPool) ReleaseSlot(slot int) {
+	p.mutex.Lock()
+	delete(p.leasedSlots, slot)
+	p.mutex.Unlock()
+	p.closeIdleSessions()
+}
+
+// RemoveSlots closes and forgets the sessions of every slot other than the shared one, e.g. once the pods they are
+// connected to were recreated.
+func (p *SessionPool) RemoveSlots() {
+	p.mutex.Lock()
+	var removed []*pooledSession
+	for target, s := range p.sessions {
+		if target.Slot > 0 {
+			removed = append(removed, s)
+			delete(p.sessions, target)
+		}
+	}
+	p.mutex.Unlock()
+	for _, s := range removed {
+		s.remove()
+	}
+}
+
+// Close closes all the sessions and forgets them.
+func (p *SessionPool) Close() {
+	p.mutex.Lock()
+	sessions := p.sessions
+	p.sessions = make(map[Target]*pooledSession)
+	p.mutex.Unlock()
+	for _, s := range sessions {
+		s.remove()
+	}
+}
+
+// Check probes the sessions unused for ProbeInterval, reconnecting the ones which do not answer, and closes the
+// sessions unused for IdleTimeout and the least recently used idle sessions beyond MaxIdleSessions.
+func (p *SessionPool) Check() {
+	p.mutex.Lock()
+	sessions := make([]*pooledSession, 0, len(p.sessions))
+	for _, s := range p.sessions {
+		sessions = append(sessions, s)
+	}
+	p.mutex.Unlock()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].target.String() < sessions[j].target.String()
+	})
+	for _, s := range sessions {
+		s.check()
+	}
+	p.closeIdleSessions()
+}
+
+// closeIdleSessions closes the least recently used open sessions outside of the leased slots beyond MaxIdleSessions.
+func (p *SessionPool) closeIdleSessions() {
+	if p.MaxIdleSessions <= 0 {
+		return
+	}
+	p.mutex.Lock()
+	idle := make([]*pooledSession, 0, len(p.sessions))
+	for target, s := range p.sessions {
+		if !p.leasedSlots[target.Slot] {
+			idle = append(idle, s)
+		}
+	}
+	p.mutex.Unlock()
+	lastUsed := make(map[*pooledSession]time.Time, len(idle))
+	open := idle[:0]
+	for _, s := range idle {
+		if used, ok := s.openSince(); ok {
+			lastUsed[s] = used
+			open = append(open, s)
+		}
+	}
+	if len(open) <= p.MaxIdleSessions {
+		return
+	}
+	sort.Slice(open, func(i, j int) bool {
+		return lastUsed[open[i]].After(lastUsed[open[j]])
+	})
+	for _, s := range open[p.MaxIdleSessions:] {
+		s.closeIfUnusedSince(lastUsed[s])
+	}
+}
+
+// Reconnections returns how many times the sessions of every reconnected target were reconnected, including the
+// targets removed since.
+func (p *SessionPool) Reconnections() map[string]int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	reconnections := make(map[string]int, len(p.reconnections))
+	for target, count := range p.reconnections {
+		reconnections[target] = count
+	}
+	return reconnections
+}
+
+func (p *SessionPool) countReconnection(target Target) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.reconnections[target.String()]++
+}
+
+func (p *SessionPool) nextProbe() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.probes++
+	return p.probes
+}
+
+// pooledSession is the expect.Expecter of the Context handed out for a target.  It forwards to the current
+// underlying session, which it replaces when it is lost.
+type pooledSession struct {
+	pool    *SessionPool
+	target  Target
+	context *Context
+	oc      *Oc
+	// errorChannel is the error channel of context, which receives the errors of the underlying sessions.
+	errorChannel chan error
+
+	// mutex serializes the uses of the session, and protects the fields below.
+	mutex    sync.Mutex
+	inner    expect.Expecter
+	lost     chan struct{}
+	closed   chan struct{}
+	wasLost  bool
+	removed  bool
+	lastUsed time.Time
+
+	// activeMutex protects the fields below, which are set during ExpectBatch so that Interrupt does not wait for mutex.
+	activeMutex sync.Mutex
+	active      expect.Expecter
+	interrupted bool
+}
+
+// ensure connects the session if it is not, or if it was lost.
+func (s *pooledSession) ensure() error {
+	if s.removed {
+		return errSessionRemoved
+	}
+	if s.inner != nil {
+		select {
+		case <-s.lost:
+			s.markLost(errors.New("error channel event"))
+		default:
+			return nil
+		}
+	}
+	context, err := spawnTarget(s.target, s.pool.timeout, s.pool.opts...)
+	if err != nil {
+		return fmt.Errorf("unable to connect the session to %s: %w", s.target, err)
+	}
+	s.inner = *context.GetExpecter()
+	s.lost = make(chan struct{})
+	s.closed = make(chan struct{})
+	go watchErrorChannel(context.GetErrorChannel(), s.errorChannel, s.lost, s.closed)
+	if s.wasLost {
+		s.wasLost = false
+		s.pool.countReconnection(s.target)
+		log.Infof("Reconnected the session to %s", s.target)
+	}
+	return nil
+}
+
+// underlying returns the current underlying session, or nil if it is not connected.
+func (s *pooledSession) underlying() expect.Expecter {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.inner
+}
+
+// watchErrorChannel closes lost when errorChannel fires before closed is, and forwards the error to the tests waiting
+// on forward, if any.
+func watchErrorChannel(errorChannel <-chan error, forward chan<- error, lost, closed chan struct{}) {
+	select {
+	case err := <-errorChannel:
+		log.Warnf("Session error: %v", err)
+		close(lost)
+		select {
+		case forward <- err:
+		default:
+		}
+	case <-closed:
+	}
+}
+
+// closeInner closes the underlying session.
+func (s *pooledSession) closeInner() {
+	if s.inner == nil {
+		return
+	}
+	close(s.closed)
+	if err := s.inner.Close(); err != nil {
+		log.Debugf("Closing the session to %s: %v", s.target, err)
+	}
+	s.inner = nil
+}
+
+// markLost closes the underlying session, so that the next use reconnects it.
+func (s *pooledSession) markLost(cause error) {
+	log.Warnf("Session to %s lost: %v", s.target, cause)
+	s.closeInner()
+	s.wasLost = true
+}
+
+// isSessionLost returns true when err means the underlying session ended.
+func isSessionLost(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, io.EOF) || strings.Contains(err.Error(), "Process not running") || strings.Contains(err.Error(), "io.Copy failed")
+}
+
+// Send sends in to the session.  If the session is lost, it returns an error wrapping ErrSessionLost, and the next use
+// reconnects the session.
+func (s *pooledSession) Send(in string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensure(); err != nil {
+		return err
+	}
+	s.lastUsed = time.Now()
+	err := s.inner.Send(in)
+	if err == nil {
+		return nil
+	}
+	s.markLost(err)
+	return fmt.Errorf("%w: unable to send to %s: %v", ErrSessionLost, s.target, err)
+}
+
+// Read reads the output of the underlying session, so that the reel can stream it.  It does not wait for output, see
+// expect.GExpect.Read.
+func (s *pooledSession) Read(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensure(); err != nil {
+		return 0, err
+	}
+	reader, ok := s.inner.(io.Reader)
+	if !ok {
+		return 0, fmt.Errorf("the session to %s can't be read", s.target)
+	}
+	s.lastUsed = time.Now()
+	return reader.Read(p)
+}
+
+// Expect consult expect.Expecter.Expect.
+func (s *pooledSession) Expect(re *regexp.Regexp, timeout time.Duration) (string, []string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensure(); err != nil {
+		return "", nil, err
+	}
+	s.lastUsed = time.Now()
+	output, match, err := s.inner.Expect(re, timeout)
+	if isSessionLost(err) {
+		s.markLost(err)
+	}
+	return output, match, err
+}
+
+// ExpectBatch consult expect.Expecter.ExpectBatch.
+func (s *pooledSession) ExpectBatch(batch []expect.Batcher, timeout time.Duration) ([]expect.BatchRes, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensure(); err != nil {
+		return nil, err
+	}
+	s.lastUsed = time.Now()
+	s.setActive(s.inner)
+	res, err := s.inner.ExpectBatch(batch, timeout)
+	if s.setActive(nil) {
+		// Interrupt closed the underlying session, the next use reconnects it.
+		s.inner = nil
+	} else if isSessionLost(err) {
+		s.markLost(err)
+	}
+	return res, err
+}
+
+// setActive sets the underlying session used by an ExpectBatch in progress, and returns true if the previous one was
+// interrupted.
+func (s *pooledSession) setActive(active expect.Expecter) bool {
+	s.activeMutex.Lock()
+	defer s.activeMutex.Unlock()
+	interrupted := s.interrupted
+	s.active = active
+	s.interrupted = false
+	return interrupted
+}
+
+// Interrupt implements reel.Interrupter: it closes the underlying session used by the ExpectBatch in progress, if any,
+// which ends the batch.  The next use of the session reconnects it.
+func (s *pooledSession) Interrupt() {
+	s.activeMutex.Lock()
+	defer s.activeMutex.Unlock()
+	if s.active == nil || s.interrupted {
+		return
+	}
+	log.Debugf("Interrupting the session to %s", s.target)
+	// The session is closed on purpose, it is not lost.
+	close(s.closed)
+	if err := s.active.Close(); err != nil {
+		log.Debugf("Closing the session to %s: %v", s.target, err)
+	}
+	s.interrupted = true
+}
+
+// ExpectSwitchCase consult expect.Expecter.ExpectSwitchCase.
+func (s *pooledSession) ExpectSwitchCase(cases []expect.Caser, timeout time.Duration) (string, []string, int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.ensure(); err != nil {
+		return "", nil, -1, err
+	}
+	s.lastUsed = time.Now()
+	output, match, index, err := s.inner.ExpectSwitchCase(cases, timeout)
+	if isSessionLost(err) {
+		s.markLost(err)
+	}
+	return output, match, index, err
+}
+
+// Close removes the session from its pool.
+func (s *pooledSession) Close() error {
+	s.pool.mutex.Lock()
+	if s.pool.sessions[s.target] == s {
+		delete(s.pool.sessions, s.target)
+	}
+	s.pool.mutex.Unlock()
+	s.remove()
+	return nil
+}
+
+func (s *pooledSession) remove() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closeInner()
+	s.removed = true
+}
+
+// check probes or closes the session depending on how long it has been unused.
+func (s *pooledSession) check() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.inner == nil || s.removed {
+		return
+	}
+	unused := time.Since(s.lastUsed)
+	if s.pool.IdleTimeout > 0 && unused >= s.pool.IdleTimeout {
+		log.Debugf("Closing the session to %s unused for %s", s.target, unused)
+		s.closeInner()
+		return
+	}
+	if s.pool.ProbeInterval <= 0 || unused < s.pool.ProbeInterval {
+		return
+	}
+	if err := s.probe(); err != nil {
+		s.markLost(err)
+		if err := s.ensure(); err != nil {
+			log.Errorf("%v", err)
+			return
+		}
+	}
+	s.lastUsed = time.Now()
+}
+
+// openSince returns when the session was last used, and false if it is not open.
+func (s *pooledSession) openSince() (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastUsed, s.inner != nil && !s.removed
+}
+
+// closeIfUnusedSince closes the session if it was not used since lastUsed.
+func (s *pooledSession) closeIfUnusedSince(lastUsed time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.inner == nil || s.removed || !s.lastUsed.Equal(lastUsed) {
+		return
+	}
+	log.Debugf("Closing the idle session to %s, beyond %d idle sessions", s.target, s.pool.MaxIdleSessions)
+	s.closeInner()
+}
+
+// probe checks that the session answers an echo.
+func (s *pooledSession) probe() error {
+	select {
+	case <-s.lost:
+		return errors.New("error channel event")
+	default:
+	}
+	probe := s.pool.nextProbe()
+	if err := s.inner.Send(fmt.Sprintf(probeCommand, probe)); err != nil {
+		return err
+	}
+	_, _, err := s.inner.Expect(regexp.MustCompile(fmt.Sprintf(probeOutput, probe)), s.pool.ProbeTimeout)
+	if err != nil {
+		return fmt.Errorf("no answer to the liveness probe: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	expect "github.com/google/goexpect"
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const poolTestTimeout = 5 * time.Second
+
+// shellOcSpawnFunc runs a local shell in place of `oc rsh`.
+type shellOcSpawnFunc struct {
+	interactive.ExecSpawnFunc
+}
+
+func (s *shellOcSpawnFunc) Command(name string, arg ...string) *interactive.SpawnFunc {
+	return s.ExecSpawnFunc.Command("/bin/sh")
+}
+
+func setupPoolTest(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	unitTestMode := interactive.UnitTestMode
+	interactive.UnitTestMode = false
+	interactive.SetOcSpawnFunc(&shellOcSpawnFunc{})
+	t.Cleanup(func() {
+		interactive.UnitTestMode = unitTestMode
+		interactive.SetOcSpawnFunc(nil)
+	})
+}
+
+func echo(expecter expect.Expecter, text string) error {
+	if err := expecter.Send("echo " + text + "\n"); err != nil {
+		return err
+	}
+	_, _, err := expecter.Expect(regexp.MustCompile(text), 500*time.Millisecond)
+	return err
+}
+
+func TestSessionPool_Get(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	assert.Nil(t, echo(*context.GetExpecter(), "hello"))
+	again, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	assert.Same(t, context, again)
+	assert.Empty(t, pool.Reconnections())
+}
+
+func TestSessionPool_ReconnectOnUse(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+
+	// The same context is used after the shell exits.
+	assert.Nil(t, expecter.Send("exit\n"))
+	assert.Eventually(t, func() bool {
+		return echo(expecter, "back") == nil && len(pool.Reconnections()) > 0
+	}, poolTestTimeout, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"local": 1}, pool.Reconnections())
+}
+
+// losingSpawnFunc spawns sessions which are lost, killed with an error, when lose is closed.
+type losingSpawnFunc struct {
+	interactive.SpawnFunc
+	lose <-chan struct{}
+}
+
+func (l *losingSpawnFunc) Command(name string, arg ...string) *interactive.SpawnFunc {
+	var session interactive.SpawnFunc = &losingSpawnFunc{SpawnFunc: *l.SpawnFunc.Command(name, arg...), lose: l.lose}
+	return &session
+}
+
+func (l *losingSpawnFunc) Wait() error {
+	<-l.lose
+	if err := l.SpawnFunc.Close(); err != nil {
+		return err
+	}
+	return errors.New("session lost")
+}
+
+func TestSessionPool_ErrorChannel(t *testing.T) {
+	setupPoolTest(t)
+	lose := make(chan struct{})
+	interactive.SetSpawnFuncWrapper(func(sFunc interactive.SpawnFunc) interactive.SpawnFunc {
+		return &losingSpawnFunc{SpawnFunc: sFunc, lose: lose}
+	})
+	defer interactive.SetSpawnFuncWrapper(nil)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+
+	// The error of the lost underlying session reaches the tests waiting on the error channel of the context.
+	errs := make(chan error, 1)
+	go func() {
+		errs <- <-context.GetErrorChannel()
+	}()
+	// Wait for the receiver, the errors are not queued for later tests.
+	time.Sleep(10 * time.Millisecond)
+	close(lose)
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "session lost")
+	case <-time.After(poolTestTimeout):
+		t.Fatal("no error on the error channel")
+	}
+}
+
+// stoppedSpawnFunc spawns sessions which report that they are not running once stop is closed, so that sending to
+// them fails.
+type stoppedSpawnFunc struct {
+	interactive.SpawnFunc
+	stop <-chan struct{}
+}
+
+func (s *stoppedSpawnFunc) Command(name string, arg ...string) *interactive.SpawnFunc {
+	var session interactive.SpawnFunc = &stoppedSpawnFunc{SpawnFunc: *s.SpawnFunc.Command(name, arg...), stop: s.stop}
+	return &session
+}
+
+func (s *stoppedSpawnFunc) IsRunning() bool {
+	select {
+	case <-s.stop:
+		return false
+	default:
+		return s.SpawnFunc.IsRunning()
+	}
+}
+
+func TestSessionPool_SendLost(t *testing.T) {
+	setupPoolTest(t)
+	stop := make(chan struct{})
+	spawned := 0
+	interactive.SetSpawnFuncWrapper(func(sFunc interactive.SpawnFunc) interactive.SpawnFunc {
+		spawned++
+		if spawned > 1 {
+			return sFunc
+		}
+		return &stoppedSpawnFunc{SpawnFunc: sFunc, stop: stop}
+	})
+	defer interactive.SetSpawnFuncWrapper(nil)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+
+	// What was sent to the lost session is not sent again, the next use reconnects the session.
+	close(stop)
+	assert.ErrorIs(t, expecter.Send("echo lost\n"), interactive.ErrSessionLost)
+	assert.Empty(t, pool.Reconnections())
+	assert.Nil(t, echo(expecter, "back"))
+	assert.Equal(t, map[string]int{"local": 1}, pool.Reconnections())
+	assert.Equal(t, 2, spawned)
+}
+
+func TestSessionPool_Interrupt(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+	interrupter, ok := expecter.(reel.Interrupter)
+	assert.True(t, ok)
+
+	// Interrupting ends the batch in progress, then the session reconnects on its next use.
+	time.AfterFunc(100*time.Millisecond, interrupter.Interrupt)
+	start := time.Now()
+	_, err = expecter.ExpectBatch([]expect.Batcher{&expect.BExp{R: "never"}}, poolTestTimeout)
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), poolTestTimeout)
+	assert.Nil(t, echo(expecter, "back"))
+	assert.Empty(t, pool.Reconnections())
+}
+
+// streamRecorder records whether the reel streamed the output of the step.
+type streamRecorder struct {
+	streamed bool
+	output   string
+}
+
+func (s *streamRecorder) ReelFirst() *reel.Step {
+	return nil
+}
+
+func (s *streamRecorder) ReelMatch(_, _, match string) *reel.Step {
+	s.output = match
+	return nil
+}
+
+func (s *streamRecorder) ReelMatchOutput(pattern string, output *reel.Output) *reel.Step {
+	s.streamed = true
+	return s.ReelMatch(pattern, output.Before(), output.Match())
+}
+
+func (s *streamRecorder) ReelTimeout() *reel.Step {
+	return nil
+}
+
+func (s *streamRecorder) ReelEOF() {
+}
+
+func TestSessionPool_Stream(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+
+	// The output of the pooled session is streamed to the handler.
+	r, err := reel.NewReel(context.GetExpecter(), nil, context.GetErrorChannel())
+	assert.Nil(t, err)
+	handler := &streamRecorder{}
+	step := &reel.Step{Execute: "echo hello", Expect: []string{`hel+o`}, Timeout: poolTestTimeout}
+	assert.Nil(t, r.Step(step, handler))
+	assert.True(t, handler.streamed)
+	assert.Equal(t, "hello", handler.output)
+}
+
+func TestSessionPool_Check(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	pool.ProbeInterval = time.Nanosecond
+	pool.ProbeTimeout = 200 * time.Millisecond
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+
+	// A live session answers the probe.
+	pool.Check()
+	assert.Empty(t, pool.Reconnections())
+
+	assert.Nil(t, expecter.Send("exit\n"))
+	assert.Eventually(t, func() bool {
+		pool.Check()
+		return len(pool.Reconnections()) > 0
+	}, poolTestTimeout, 10*time.Millisecond)
+	assert.Equal(t, map[string]int{"local": 1}, pool.Reconnections())
+	assert.Nil(t, echo(expecter, "back"))
+}
+
+func TestSessionPool_IdleTimeout(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	pool.IdleTimeout = time.Nanosecond
+	context, err := pool.Get(interactive.LocalTarget())
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+	assert.Nil(t, expecter.Send("cd /tmp\n"))
+
+	// The idle session is closed, and a new one is opened when it is used again.
+	pool.Check()
+	assert.Nil(t, expecter.Send("pwd\n"))
+	_, _, err = expecter.Expect(regexp.MustCompile(`(?m)^(.*)$`), poolTestTimeout)
+	assert.Nil(t, err)
+	assert.Empty(t, pool.Reconnections())
+}
+
+func TestSessionPool_MaxIdleSessions(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	pool.MaxIdleSessions = 1
+	first, second := pool.LeaseSlot(), pool.LeaseSlot()
+	var expecters []expect.Expecter
+	for _, slot := range []int{first, second} {
+		context, err := pool.Get(interactive.LocalTarget().WithSlot(slot))
+		assert.Nil(t, err)
+		expecter := *context.GetExpecter()
+		assert.Nil(t, expecter.Send("cd /tmp\n"))
+		expecters = append(expecters, expecter)
+	}
+
+	// The sessions of the leased slots are not idle.
+	pool.Check()
+	assert.Nil(t, pwdIs(expecters[0], "/tmp"))
+	assert.Nil(t, pwdIs(expecters[1], "/tmp"))
+
+	// Beyond the limit, the least recently used idle session is closed, and reopened when it is used again.
+	pool.ReleaseSlot(first)
+	pool.ReleaseSlot(second)
+	assert.NotNil(t, pwdIs(expecters[0], "/tmp"))
+	assert.Nil(t, pwdIs(expecters[1], "/tmp"))
+	assert.Empty(t, pool.Reconnections())
+}
+
+// pwdIs checks the working directory of the shell of expecter.
+func pwdIs(expecter expect.Expecter, dir string) error {
+	if err := expecter.Send("pwd\n"); err != nil {
+		return err
+	}
+	_, _, err := expecter.Expect(regexp.MustCompile(`(?m)^`+regexp.QuoteMeta(dir)+`\r?$`), 500*time.Millisecond)
+	return err
+}
+
+func TestSessionPool_GetOc(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout, interactive.SendTimeout(poolTestTimeout))
+	defer pool.Close()
+	target := interactive.ContainerTarget("tnf", "test-0", "test")
+
+	oc, err := pool.GetOc(target)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-0", oc.GetPodName())
+	assert.Equal(t, "test", oc.GetPodContainerName())
+	assert.Equal(t, "tnf", oc.GetPodNamespace())
+	assert.Equal(t, poolTestTimeout, oc.GetTimeout())
+	assert.Nil(t, echo(*oc.GetExpecter(), "hello"))
+	again, err := pool.GetOc(target)
+	assert.Nil(t, err)
+	assert.Same(t, oc, again)
+
+	// Closing the oc session removes it from the pool.
+	oc.Close()
+	assert.NotNil(t, (*oc.GetExpecter()).Send("echo closed\n"))
+	again, err = pool.GetOc(target)
+	assert.Nil(t, err)
+	assert.NotSame(t, oc, again)
+	assert.Nil(t, echo(*again.GetExpecter(), "again"))
+
+	_, err = pool.GetOc(interactive.LocalTarget())
+	assert.NotNil(t, err)
+}
+
+func TestSessionPool_Remove(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	target := interactive.NodeTarget("tnf", "debug-0", "container-00")
+	context, err := pool.Get(target)
+	assert.Nil(t, err)
+
+	pool.Remove(target)
+	assert.NotNil(t, (*context.GetExpecter()).Send("echo removed\n"))
+	context, err = pool.Get(target)
+	assert.Nil(t, err)
+	assert.Nil(t, echo(*context.GetExpecter(), "hello"))
+
+	pool.Close()
+	assert.NotNil(t, (*context.GetExpecter()).Send("echo closed\n"))
+}
+
+func TestSessionPool_Slots(t *testing.T) {
+	setupPoolTest(t)
+	pool := interactive.NewSessionPool(poolTestTimeout)
+	defer pool.Close()
+	target := interactive.NodeTarget("tnf", "debug-0", "container-00")
+	shared, err := pool.GetOc(target)
+	assert.Nil(t, err)
+
+	// The leased slots are distinct, and released slots are leased again.
+	first, second := pool.LeaseSlot(), pool.LeaseSlot()
+	assert.Equal(t, []int{1, 2}, []int{first, second})
+	pool.ReleaseSlot(first)
+	assert.Equal(t, first, pool.LeaseSlot())
+
+	// A slot has its own session to the target.
+	oc, err := pool.GetOc(shared.Target().WithSlot(first))
+	assert.Nil(t, err)
+	assert.NotSame(t, shared, oc)
+	assert.Equal(t, target.WithSlot(first), oc.Target())
+	assert.Nil(t, echo(*oc.GetExpecter(), "slot"))
+
+	// Removing the slots keeps the shared sessions.
+	pool.RemoveSlots()
+	assert.NotNil(t, (*oc.GetExpecter()).Send("echo removed\n"))
+	assert.Nil(t, echo(*shared.GetExpecter(), "shared"))
+}
+
+func TestTarget_String(t *testing.T) {
+	assert.Equal(t, "local", interactive.LocalTarget().String())
+	assert.Equal(t, "container/tnf/test-0/test", interactive.ContainerTarget("tnf", "test-0", "test").String())
+	assert.Equal(t, "node/tnf/debug-0/container-00", interactive.NodeTarget("tnf", "debug-0", "container-00").String())
+	assert.Equal(t, "local#2", interactive.LocalTarget().WithSlot(2).String())
+}
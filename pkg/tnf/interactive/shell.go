@@ -17,6 +17,8 @@
 package interactive
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -36,13 +38,26 @@ func SpawnShell(spawner *Spawner, timeout time.Duration, opts ...Option) (*Conte
 	return (*spawner).Spawn(shellEnv, args, timeout, opts...)
 }
 
+// NewShellContext spawns a new shell session and returns its context, or an error if the session could not be started.
+func NewShellContext(verbose bool) (*Context, error) {
+	context, err := SpawnShell(CreateGoExpectSpawner(), defaultTimeout, Verbose(verbose), SendTimeout(defaultTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("can't get a proper context for test execution: %w", err)
+	}
+	if context == nil || context.GetExpecter() == nil {
+		return nil, errors.New("can't get a proper context for test execution")
+	}
+	return context, nil
+}
+
 //
 //
-// GetContext spawns a new shell session and returns its context
+// GetContext spawns a new shell session and returns its context.  It panics if the session could not be started, see
+// NewShellContext.
 func GetContext(verbose bool) *Context {
-	context, err := SpawnShell(CreateGoExpectSpawner(), defaultTimeout, Verbose(verbose), SendTimeout(defaultTimeout))
-	if err != nil || context == nil || context.GetExpecter() == nil {
-		log.Panicf("can't get a proper context for test execution")
+	context, err := NewShellContext(verbose)
+	if err != nil {
+		log.Panic(err)
 	}
 	return context
 }
@@ -28,6 +28,7 @@ import (
 
 	expect "github.com/google/goexpect"
 	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
 )
 
 const (
@@ -47,6 +48,17 @@ func SetSpawnFunc(sFunc *SpawnFunc) {
 	spawnFunc = sFunc
 }
 
+// SpawnFuncWrapper decorates the SpawnFunc used to create interactive sessions, e.g. to record or replay them.
+type SpawnFuncWrapper func(sFunc SpawnFunc) SpawnFunc
+
+var spawnFuncWrapper SpawnFuncWrapper
+
+// SetSpawnFuncWrapper sets the SpawnFuncWrapper applied to every session spawned by a GoExpectSpawner.  A nil wrapper
+// disables the decoration.
+func SetSpawnFuncWrapper(wrapper SpawnFuncWrapper) {
+	spawnFuncWrapper = wrapper
+}
+
 // SpawnFunc Abstracts a wrapper interface over the required methods of the exec.Cmd API for testing purposes.
 type SpawnFunc interface {
 	// Command consult exec.Cmd.Command
@@ -186,6 +198,14 @@ type GoExpectSpawner struct {
 	sendTimeoutIsSet bool
 	// sendTimeout is the timeout of send command
 	sendTimeout time.Duration
+
+	// transcriptRecorder records the spawned sessions, when set.
+	transcriptRecorder *TranscriptRecorder
+
+	// normalizersIsSet tracks whether the normalizers option is set.
+	normalizersIsSet bool
+	// normalizers normalize the output of the spawned sessions.
+	normalizers []reel.Normalizer
 }
 
 // Option is a function pointer to enable lightweight optionals for GoExpectSpawner.
@@ -308,6 +328,8 @@ func logCmdMirrorPipe(cmdLine string, pipeToMirror io.Reader, name string, trace
 			if err != nil {
 				// Some Error has happened, goroutine about to exit
 				log.Warnf("Exiting %s log mirroring goroutine for cmd %s. Error: %s", name, cmdLine, err)
+				// Forward the end of the pipe, so that the session ends and reports on its error channel.
+				_ = w.Close()
 				return
 			}
 		}
@@ -318,18 +340,41 @@ func logCmdMirrorPipe(cmdLine string, pipeToMirror io.Reader, name string, trace
 // Spawn creates a subprocess, setting standard input and standard output appropriately.  This is the base method to
 // create any interactive PTY based process.
 func (g *GoExpectSpawner) Spawn(command string, args []string, timeout time.Duration, opts ...Option) (*Context, error) {
+	// The spawned session is kept local, so that concurrent spawns do not share it.
+	baseSpawnFunc := spawnFunc
 	if !UnitTestMode {
 		execSpawnFunc := &ExecSpawnFunc{}
 		var transitionSpawnFunc SpawnFunc = execSpawnFunc
-		spawnFunc = &transitionSpawnFunc
+		baseSpawnFunc = &transitionSpawnFunc
 	}
 
 	for _, opt := range opts {
 		opt(g)
 	}
 
-	spawnFunc = (*spawnFunc).Command(command, args...)
-	stdinPipe, stdoutPipe, stderrPipe, err := g.unpackPipes(spawnFunc)
+	var sFunc SpawnFunc
+	switch {
+	case command == ocCommand && len(args) > 0 && args[0] == ocRsh && ocSpawnFunc != nil:
+		// Only the `oc rsh` sessions have a backend, not the other oc commands.
+		sFunc = ocSpawnFunc
+	case command == sshCommand && len(args) == 1 && !strings.HasPrefix(args[0], "-") && sshSpawnFunc != nil:
+		// Only the `ssh user@host` sessions have a backend, not the ssh commands with options.
+		sFunc = sshSpawnFunc
+	default:
+		sFunc = *baseSpawnFunc
+	}
+	if spawnFuncWrapper != nil {
+		sFunc = spawnFuncWrapper(sFunc)
+	}
+	recorder := g.transcriptRecorder
+	if recorder == nil {
+		recorder = defaultTranscriptRecorder
+	}
+	if recorder != nil {
+		sFunc = &transcriptSpawnFunc{SpawnFunc: sFunc, recorder: recorder}
+	}
+	spawned := sFunc.Command(command, args...)
+	stdinPipe, stdoutPipe, stderrPipe, err := g.unpackPipes(spawned)
 	if err != nil {
 		return nil, err
 	}
@@ -339,12 +384,20 @@ func (g *GoExpectSpawner) Spawn(command string, args []string, timeout time.Dura
 
 	logCmdMirrorPipe(cmdLine, stderrPipe, "STDERR", false)
 	stdoutPipe = logCmdMirrorPipe(cmdLine, stdoutPipe, "STDOUT", true)
+	stdinPipe, stdoutPipe = g.normalizePipes(stdinPipe, stdoutPipe)
 
-	err = g.startCommand(spawnFunc, command, args)
+	err = g.startCommand(spawned, command, args)
 	if err != nil {
 		return nil, err
 	}
-	return g.spawnGeneric(spawnFunc, stdinPipe, stdoutPipe, timeout, g.GetGoExpectOptions()...)
+	spawnedContext, err := g.spawnGeneric(spawned, stdinPipe, stdoutPipe, timeout, g.GetGoExpectOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if recorded, ok := (*spawned).(*transcriptSpawnFunc); ok {
+		recorded.bind(*spawnedContext.GetExpecter())
+	}
+	return spawnedContext, nil
 }
 
 // Helper method which spawns a Context.  The pseudo-terminal (PTY) as well as the underlying goroutine is set up using
@@ -26,9 +26,9 @@ const (
 	sshSeparator = "@"
 )
 
-// SpawnSSH spawns an SSH session to a generic linux host using ssh provided by openssh-clients.  Takes care of
-// establishing the pseudo-terminal (PTY) through expect.SpawnGeneric().
-// TODO: This method currently relies upon passwordless SSH setup beforehand.  Handle all types of auth.
+// SpawnSSH spawns an SSH session to a generic linux host using ssh provided by openssh-clients, or the native client
+// set with SetSSHSpawnFunc.  Takes care of establishing the pseudo-terminal (PTY) through expect.SpawnGeneric().
+// The ssh binary relies upon passwordless SSH setup beforehand, see SSHConfig for the native client.
 func SpawnSSH(spawner *Spawner, user, host string, timeout time.Duration, opts ...Option) (*Context, error) {
 	sshArgs := getSSHString(user, host)
 	return (*spawner).Spawn(sshCommand, []string{sshArgs}, timeout, opts...)
@@ -0,0 +1,569 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// sshBackendEnvVar selects how the sessions of SpawnSSH are created.
+	sshBackendEnvVar = "TNF_SSH_BACKEND"
+	// SSHBackendOpenSSH runs the ssh binary of openssh-clients.
+	SSHBackendOpenSSH = "openssh"
+	// SSHBackendNative uses the SSH client of golang.org/x/crypto/ssh, see SSHSpawnFunc.
+	SSHBackendNative = "native"
+
+	sshKeyFilesEnvVar      = "TNF_SSH_KEY_FILES"
+	sshKnownHostsEnvVar    = "TNF_SSH_KNOWN_HOSTS"
+	sshHostKeyPolicyEnvVar = "TNF_SSH_HOST_KEY_POLICY"
+	sshJumpHostsEnvVar     = "TNF_SSH_JUMP_HOSTS"
+	sshKeepAliveEnvVar     = "TNF_SSH_KEEPALIVE"
+	sshAuthSockEnvVar      = "SSH_AUTH_SOCK"
+
+	// HostKeyPolicyStrict rejects the hosts whose key is not in the known_hosts files.
+	HostKeyPolicyStrict = "strict"
+	// HostKeyPolicyAcceptNew adds the key of unknown hosts to the first known_hosts file, and rejects changed keys.
+	HostKeyPolicyAcceptNew = "accept-new"
+	// HostKeyPolicyInsecure accepts any host key.
+	HostKeyPolicyInsecure = "insecure"
+
+	defaultSSHPort           = "22"
+	defaultSSHConnectTimeout = 30 * time.Second
+	defaultSSHKeepAlive      = 30 * time.Second
+	sshKeepAliveRequest      = "keepalive@openssh.com"
+)
+
+// defaultSSHKeyFiles are the private keys tried when no key file is configured, relative to ~/.ssh.
+var defaultSSHKeyFiles = []string{"id_rsa", "id_ecdsa", "id_ed25519"}
+
+var sshSpawnFunc SpawnFunc
+
+// SetSSHSpawnFunc sets the SpawnFunc creating the ssh sessions, e.g. an SSHSpawnFunc.  A nil SpawnFunc restores the
+// ssh subprocesses.
+func SetSSHSpawnFunc(sFunc SpawnFunc) {
+	sshSpawnFunc = sFunc
+}
+
+// SSHConfig configures the connections of an SSHSpawnFunc.
+type SSHConfig struct {
+	// KeyFiles are the unencrypted private keys offered to the hosts.
+	KeyFiles []string
+	// UseAgent also offers the keys of the agent listening on SSH_AUTH_SOCK.
+	UseAgent bool
+	// KnownHostsFiles are the known_hosts files checked by the host key policy.
+	KnownHostsFiles []string
+	// HostKeyPolicy is one of HostKeyPolicyStrict (the default), HostKeyPolicyAcceptNew or HostKeyPolicyInsecure.
+	HostKeyPolicy string
+	// JumpHosts are the [user@]host[:port] hosts the connections go through, in order, like the ProxyJump option of
+	// ssh.  Their user defaults to the user of the target host.
+	JumpHosts []string
+	// KeepAlive is the interval of the keepalive requests, or 0 to send none.
+	KeepAlive time.Duration
+	// ConnectTimeout bounds the establishment of every connection.
+	ConnectTimeout time.Duration
+}
+
+// GetSSHBackend returns the backend requested by the TNF_SSH_BACKEND environment variable, defaulting to the openssh
+// backend.
+func GetSSHBackend() string {
+	backend := strings.ToLower(os.Getenv(sshBackendEnvVar))
+	switch backend {
+	case "", SSHBackendOpenSSH:
+		return SSHBackendOpenSSH
+	case SSHBackendNative:
+		return SSHBackendNative
+	default:
+		log.Warnf("Unknown %s value %q, using %s", sshBackendEnvVar, backend, SSHBackendOpenSSH)
+		return SSHBackendOpenSSH
+	}
+}
+
+// SSHConfigFromEnvironment returns the SSHConfig set by the TNF_SSH_KEY_FILES and TNF_SSH_JUMP_HOSTS comma separated
+// lists, the TNF_SSH_KNOWN_HOSTS file, the TNF_SSH_HOST_KEY_POLICY and the TNF_SSH_KEEPALIVE interval.  The keys and
+// the known_hosts file default to those of ~/.ssh, and the agent is used when SSH_AUTH_SOCK is set.
+func SSHConfigFromEnvironment() (*SSHConfig, error) {
+	config := &SSHConfig{
+		KeyFiles:       splitList(os.Getenv(sshKeyFilesEnvVar)),
+		UseAgent:       os.Getenv(sshAuthSockEnvVar) != "",
+		HostKeyPolicy:  strings.ToLower(os.Getenv(sshHostKeyPolicyEnvVar)),
+		JumpHosts:      splitList(os.Getenv(sshJumpHostsEnvVar)),
+		KeepAlive:      defaultSSHKeepAlive,
+		ConnectTimeout: defaultSSHConnectTimeout,
+	}
+	if knownHosts := os.Getenv(sshKnownHostsEnvVar); knownHosts != "" {
+		config.KnownHostsFiles = []string{knownHosts}
+	}
+	if keepAlive := os.Getenv(sshKeepAliveEnvVar); keepAlive != "" {
+		interval, err := time.ParseDuration(keepAlive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", sshKeepAliveEnvVar, keepAlive, err)
+		}
+		config.KeepAlive = interval
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil && (config.KeyFiles == nil || config.KnownHostsFiles == nil) {
+		return nil, err
+	}
+	if config.KeyFiles == nil {
+		for _, name := range defaultSSHKeyFiles {
+			path := filepath.Join(home, ".ssh", name)
+			if _, err := os.Stat(path); err == nil {
+				config.KeyFiles = append(config.KeyFiles, path)
+			}
+		}
+	}
+	if config.KnownHostsFiles == nil {
+		config.KnownHostsFiles = []string{filepath.Join(home, ".ssh", "known_hosts")}
+	}
+	return config, nil
+}
+
+// ConfigureSSHBackend makes SpawnSSH use an SSHSpawnFunc configured from the environment when TNF_SSH_BACKEND
+// requests the native backend.
+func ConfigureSSHBackend() error {
+	if GetSSHBackend() != SSHBackendNative {
+		return nil
+	}
+	config, err := SSHConfigFromEnvironment()
+	if err != nil {
+		return err
+	}
+	sFunc, err := NewSSHSpawnFunc(config)
+	if err != nil {
+		return err
+	}
+	SetSSHSpawnFunc(sFunc)
+	return nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SSHSpawnFunc is an implementation of SpawnFunc creating the `ssh user@host` sessions with golang.org/x/crypto/ssh,
+// so no ssh client needs to be installed.  Like ssh without a terminal, the sessions run the login shell of the user
+// without a pseudo-terminal.  The host may be given as host:port.
+type SSHSpawnFunc struct {
+	config        SSHConfig
+	auth          []ssh.AuthMethod
+	hostKeyPolicy ssh.HostKeyCallback
+}
+
+// NewSSHSpawnFunc creates an SSHSpawnFunc, loading the keys and the known_hosts files of config.
+func NewSSHSpawnFunc(config *SSHConfig) (*SSHSpawnFunc, error) {
+	s := &SSHSpawnFunc{config: *config}
+	if s.config.ConnectTimeout == 0 {
+		s.config.ConnectTimeout = defaultSSHConnectTimeout
+	}
+
+	var signers []ssh.Signer
+	for _, path := range config.KeyFiles {
+		signer, err := loadSSHKey(path)
+		if err != nil {
+			return nil, err
+		}
+		if signer != nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		s.auth = append(s.auth, ssh.PublicKeys(signers...))
+	}
+	if config.UseAgent {
+		socket := os.Getenv(sshAuthSockEnvVar)
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to the SSH agent at %s: %w", socket, err)
+		}
+		s.auth = append(s.auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+	if len(s.auth) == 0 {
+		return nil, errors.New("no SSH key or agent to authenticate with")
+	}
+
+	var err error
+	s.hostKeyPolicy, err = newHostKeyCallback(config.HostKeyPolicy, config.KnownHostsFiles)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadSSHKey parses the private key at path.  Keys protected by a passphrase are skipped, since there is no one to
+// type it: they can be added to the agent instead.
+func loadSSHKey(path string) (ssh.Signer, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(pem)
+	var passphraseErr *ssh.PassphraseMissingError
+	if errors.As(err, &passphraseErr) {
+		log.Warnf("Skipping the SSH key %s protected by a passphrase", path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the SSH key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// newHostKeyCallback returns the ssh.HostKeyCallback implementing policy with the known_hosts files.
+func newHostKeyCallback(policy string, knownHostsFiles []string) (ssh.HostKeyCallback, error) {
+	switch policy {
+	case HostKeyPolicyInsecure:
+		log.Warn("SSH host keys are not verified")
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec // Requested by the policy.
+	case "", HostKeyPolicyStrict, HostKeyPolicyAcceptNew:
+	default:
+		return nil, fmt.Errorf("unknown SSH host key policy %q", policy)
+	}
+	if len(knownHostsFiles) == 0 {
+		return nil, errors.New("no known_hosts file to verify the SSH host keys")
+	}
+	if policy == HostKeyPolicyAcceptNew {
+		// The known_hosts file is created for the new keys.
+		if err := os.MkdirAll(filepath.Dir(knownHostsFiles[0]), 0700); err != nil {
+			return nil, err
+		}
+		file, err := os.OpenFile(knownHostsFiles[0], os.O_CREATE|os.O_RDONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		file.Close()
+	}
+	callback, err := knownhosts.New(knownHostsFiles...)
+	if err != nil {
+		return nil, err
+	}
+	if policy != HostKeyPolicyAcceptNew {
+		return callback, nil
+	}
+
+	var mutex sync.Mutex
+	accepted := map[string]ssh.PublicKey{}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			// Known key, or changed key.
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		address := knownhosts.Normalize(hostname)
+		if known, ok := accepted[address]; ok {
+			if string(known.Marshal()) == string(key.Marshal()) {
+				return nil
+			}
+			return fmt.Errorf("host key of %s changed since it was accepted", address)
+		}
+		file, err := os.OpenFile(knownHostsFiles[0], os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if _, err := fmt.Fprintln(file, knownhosts.Line([]string{address}, key)); err != nil {
+			return err
+		}
+		log.Infof("Added the %s host key of %s to %s", key.Type(), address, knownHostsFiles[0])
+		accepted[address] = key
+		return nil
+	}, nil
+}
+
+// Command creates a session for `ssh user@host`.  Other commands fail to start.
+func (s *SSHSpawnFunc) Command(name string, arg ...string) *SpawnFunc {
+	var session SpawnFunc = newSSHSession(s, name, arg)
+	return &session
+}
+
+// Start is not supported, sessions are started from the SpawnFunc returned by Command.
+func (s *SSHSpawnFunc) Start() error {
+	return errors.New("no ssh session to start")
+}
+
+// StdinPipe is not supported, see Start.
+func (s *SSHSpawnFunc) StdinPipe() (io.WriteCloser, error) {
+	return nil, errors.New("no ssh session")
+}
+
+// StdoutPipe is not supported, see Start.
+func (s *SSHSpawnFunc) StdoutPipe() (io.Reader, error) {
+	return nil, errors.New("no ssh session")
+}
+
+// StderrPipe is not supported, see Start.
+func (s *SSHSpawnFunc) StderrPipe() (io.Reader, error) {
+	return nil, errors.New("no ssh session")
+}
+
+// Wait returns immediately, see Start.
+func (s *SSHSpawnFunc) Wait() error {
+	return nil
+}
+
+// Close does nothing, see Start.
+func (s *SSHSpawnFunc) Close() error {
+	return nil
+}
+
+// IsRunning returns false, see Start.
+func (s *SSHSpawnFunc) IsRunning() bool {
+	return false
+}
+
+// Args returns nil, see Start.
+func (s *SSHSpawnFunc) Args() []string {
+	return nil
+}
+
+// parseSSHDestination splits [user@]host[:port] into a user, defaulting to defaultUser, and an address.
+func parseSSHDestination(destination, defaultUser string) (user, address string, err error) {
+	user = defaultUser
+	if i := strings.LastIndex(destination, sshSeparator); i >= 0 {
+		user, destination = destination[:i], destination[i+1:]
+	}
+	if destination == "" || user == "" {
+		return "", "", fmt.Errorf("invalid SSH destination %q", destination)
+	}
+	if _, _, err := net.SplitHostPort(destination); err != nil {
+		destination = net.JoinHostPort(strings.Trim(destination, "[]"), defaultSSHPort)
+	}
+	return user, destination, nil
+}
+
+// dial connects to address through the jump hosts.  It returns the clients of every hop, the last one being the
+// client of address.
+func (s *SSHSpawnFunc) dial(user, address string) ([]*ssh.Client, error) {
+	var clients []*ssh.Client
+	closeAll := func() {
+		for i := len(clients) - 1; i >= 0; i-- {
+			clients[i].Close()
+		}
+	}
+	hops := append(append([]string{}, s.config.JumpHosts...), user+sshSeparator+address)
+	for _, hop := range hops {
+		hopUser, hopAddress, err := parseSSHDestination(hop, user)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		config := &ssh.ClientConfig{
+			User:            hopUser,
+			Auth:            s.auth,
+			HostKeyCallback: s.hostKeyPolicy,
+			Timeout:         s.config.ConnectTimeout,
+		}
+		var client *ssh.Client
+		if len(clients) == 0 {
+			client, err = ssh.Dial("tcp", hopAddress, config)
+		} else {
+			client, err = dialThrough(clients[len(clients)-1], hopAddress, config)
+		}
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("unable to connect to %s: %w", hopAddress, err)
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// dialThrough connects to address with a connection forwarded by jump.
+func dialThrough(jump *ssh.Client, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := jump.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+// sshSession is the login shell of a user on a host.
+type sshSession struct {
+	spawnFunc *SSHSpawnFunc
+	args      []string
+	user      string
+	address   string
+	parseErr  error
+
+	clients []*ssh.Client
+	session *ssh.Session
+
+	stdinReader                *io.PipeReader
+	stdinWriter                *io.PipeWriter
+	stdoutReader, stderrReader *io.PipeReader
+	stdoutWriter, stderrWriter *io.PipeWriter
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newSSHSession(s *SSHSpawnFunc, name string, args []string) *sshSession {
+	session := &sshSession{spawnFunc: s, args: append([]string{name}, args...), done: make(chan struct{})}
+	if len(args) == 1 {
+		session.user, session.address, session.parseErr = parseSSHDestination(args[0], "")
+	} else {
+		session.parseErr = fmt.Errorf("unsupported ssh arguments %v", args)
+	}
+	session.stdinReader, session.stdinWriter = io.Pipe()
+	session.stdoutReader, session.stdoutWriter = io.Pipe()
+	session.stderrReader, session.stderrWriter = io.Pipe()
+	return session
+}
+
+// Command creates a new session, see SSHSpawnFunc.Command.
+func (s *sshSession) Command(name string, arg ...string) *SpawnFunc {
+	return s.spawnFunc.Command(name, arg...)
+}
+
+// Start connects to the host and starts the login shell.
+func (s *sshSession) Start() error {
+	if s.parseErr != nil {
+		return s.parseErr
+	}
+	clients, err := s.spawnFunc.dial(s.user, s.address)
+	if err != nil {
+		return err
+	}
+	s.clients = clients
+	s.session, err = clients[len(clients)-1].NewSession()
+	if err != nil {
+		s.closeClients()
+		return err
+	}
+	s.session.Stdin = s.stdinReader
+	s.session.Stdout = s.stdoutWriter
+	s.session.Stderr = s.stderrWriter
+	if err := s.session.Shell(); err != nil {
+		s.closeClients()
+		return err
+	}
+	if s.spawnFunc.config.KeepAlive > 0 {
+		go s.keepAlive(s.spawnFunc.config.KeepAlive)
+	}
+	return nil
+}
+
+// keepAlive sends keepalive requests until the session ends, and closes the session when the host stops answering.
+func (s *sshSession) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	client := s.clients[len(s.clients)-1]
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if _, _, err := client.SendRequest(sshKeepAliveRequest, true, nil); err != nil {
+				log.Errorf("SSH connection to %s lost: %v", s.address, err)
+				s.closeClients()
+				return
+			}
+		}
+	}
+}
+
+func (s *sshSession) closeClients() {
+	for i := len(s.clients) - 1; i >= 0; i-- {
+		s.clients[i].Close()
+	}
+}
+
+// StdinPipe returns the standard input of the shell.
+func (s *sshSession) StdinPipe() (io.WriteCloser, error) {
+	return s.stdinWriter, nil
+}
+
+// StdoutPipe returns the standard output of the shell.
+func (s *sshSession) StdoutPipe() (io.Reader, error) {
+	return s.stdoutReader, nil
+}
+
+// StderrPipe returns the standard error of the shell.
+func (s *sshSession) StderrPipe() (io.Reader, error) {
+	return s.stderrReader, nil
+}
+
+// Wait waits for the shell to exit, and closes the connections.
+func (s *sshSession) Wait() error {
+	if s.session == nil {
+		return errors.New("ssh session not started")
+	}
+	err := s.session.Wait()
+	close(s.done)
+	s.stdoutWriter.Close()
+	s.stderrWriter.Close()
+	s.closeClients()
+	return err
+}
+
+// Close closes the session and the connections, which ends the shell.
+func (s *sshSession) Close() error {
+	s.closeOnce.Do(func() {
+		s.stdinWriter.Close()
+		if s.session != nil {
+			s.session.Close()
+		}
+		s.closeClients()
+	})
+	return nil
+}
+
+// IsRunning returns true until the shell exits.
+func (s *sshSession) IsRunning() bool {
+	if s.session == nil {
+		return false
+	}
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Args returns the ssh command and arguments the session stands for.
+func (s *sshSession) Args() []string {
+	return s.args
+}
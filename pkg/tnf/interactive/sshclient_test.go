@@ -0,0 +1,463 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const sshTestTimeout = 5 * time.Second
+
+// testSSHServer is an SSH server echoing the lines sent to the shell, and forwarding the direct-tcpip channels of the
+// jump hosts.
+type testSSHServer struct {
+	address    string
+	hostKey    ssh.Signer
+	keepAlives int32
+}
+
+func newTestSSHServer(t *testing.T, authorized ssh.PublicKey) *testSSHServer {
+	hostKey, _ := newTestSSHKey(t)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, fmt.Errorf("unknown key for %s", conn.User())
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	server := &testSSHServer{address: listener.Addr().String(), hostKey: hostKey}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config)
+		}
+	}()
+	return server
+}
+
+func (s *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer serverConn.Close()
+	go func() {
+		for req := range requests {
+			if req.Type == "keepalive@openssh.com" {
+				atomic.AddInt32(&s.keepAlives, 1)
+			}
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}()
+	for newChannel := range channels {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err == nil {
+				go serveTestSession(channel, requests)
+			}
+		case "direct-tcpip":
+			var target struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+				_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			go forwardTestChannel(newChannel, net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+		}
+	}
+}
+
+func serveTestSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	for req := range requests {
+		if req.Type != "shell" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+		go func() {
+			defer channel.Close()
+			scanner := bufio.NewScanner(channel)
+			for scanner.Scan() && scanner.Text() != "exit" {
+				// The output does not end with the input, which the spawned sessions would drop as its echo.
+				fmt.Fprintf(channel, "out: %s.\n", scanner.Text())
+			}
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+		}()
+	}
+}
+
+func forwardTestChannel(newChannel ssh.NewChannel, address string) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	go func() {
+		_, _ = io.Copy(conn, channel)
+		conn.Close()
+	}()
+	_, _ = io.Copy(channel, conn)
+	channel.Close()
+}
+
+// newTestSSHKey returns a new key, and its PEM encoding.
+func newTestSSHKey(t *testing.T) (ssh.Signer, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	assert.Nil(t, err)
+	return signer, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// newTestSSHConfig returns the config of a client with a new key, and whose known_hosts file holds the keys of the
+// servers.
+func newTestSSHConfig(t *testing.T) (*interactive.SSHConfig, ssh.Signer) {
+	dir := t.TempDir()
+	signer, keyPEM := newTestSSHKey(t)
+	keyFile := filepath.Join(dir, "id_ecdsa")
+	assert.Nil(t, os.WriteFile(keyFile, keyPEM, 0600))
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	assert.Nil(t, os.WriteFile(knownHostsFile, nil, 0600))
+	return &interactive.SSHConfig{
+		KeyFiles:        []string{keyFile},
+		KnownHostsFiles: []string{knownHostsFile},
+	}, signer
+}
+
+func addKnownHost(t *testing.T, config *interactive.SSHConfig, server *testSSHServer) {
+	file, err := os.OpenFile(config.KnownHostsFiles[0], os.O_APPEND|os.O_WRONLY, 0600)
+	assert.Nil(t, err)
+	defer file.Close()
+	_, err = fmt.Fprintln(file, knownhosts.Line([]string{knownhosts.Normalize(server.address)}, server.hostKey.PublicKey()))
+	assert.Nil(t, err)
+}
+
+// startSSHSession starts the shell of the tnf user on server, and sends line to it.
+func startSSHSession(t *testing.T, config *interactive.SSHConfig, server *testSSHServer) (interactive.SpawnFunc, error) {
+	sFunc, err := interactive.NewSSHSpawnFunc(config)
+	assert.Nil(t, err)
+	session := *sFunc.Command("ssh", "tnf@"+server.address)
+	stdout, err := session.StdoutPipe()
+	assert.Nil(t, err)
+	go func() {
+		_, _ = io.Copy(io.Discard, stdout)
+	}()
+	stderr, err := session.StderrPipe()
+	assert.Nil(t, err)
+	go func() {
+		_, _ = io.Copy(io.Discard, stderr)
+	}()
+	return session, session.Start()
+}
+
+func TestSSHSpawnFunc(t *testing.T) {
+	config, signer := newTestSSHConfig(t)
+	server := newTestSSHServer(t, signer.PublicKey())
+	addKnownHost(t, config, server)
+	sFunc, err := interactive.NewSSHSpawnFunc(config)
+	assert.Nil(t, err)
+
+	interactive.SetSSHSpawnFunc(sFunc)
+	defer interactive.SetSSHSpawnFunc(nil)
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnSSH(&spawner, "tnf", server.address, sshTestTimeout)
+	assert.Nil(t, err)
+	expecter := *context.GetExpecter()
+	defer expecter.Close()
+
+	assert.Nil(t, expecter.Send("hello\n"))
+	_, _, err = expecter.Expect(regexp.MustCompile(`out: hello`), sshTestTimeout)
+	assert.Nil(t, err)
+}
+
+func TestSSHSpawnFunc_Exit(t *testing.T) {
+	config, signer := newTestSSHConfig(t)
+	server := newTestSSHServer(t, signer.PublicKey())
+	addKnownHost(t, config, server)
+
+	session, err := startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	assert.True(t, session.IsRunning())
+	stdin, err := session.StdinPipe()
+	assert.Nil(t, err)
+	_, err = stdin.Write([]byte("exit\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, session.Wait())
+	assert.False(t, session.IsRunning())
+	assert.Equal(t, []string{"ssh", "tnf@" + server.address}, session.Args())
+}
+
+func TestSSHSpawnFunc_HostKeyPolicy(t *testing.T) {
+	config, signer := newTestSSHConfig(t)
+	server := newTestSSHServer(t, signer.PublicKey())
+
+	// Unknown host.
+	_, err := startSSHSession(t, config, server)
+	assert.NotNil(t, err)
+
+	config.HostKeyPolicy = interactive.HostKeyPolicyInsecure
+	session, err := startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	assert.Nil(t, session.Close())
+
+	// The key is added to the known_hosts file, and trusted afterwards.
+	config.HostKeyPolicy = interactive.HostKeyPolicyAcceptNew
+	session, err = startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	assert.Nil(t, session.Close())
+	knownHosts, err := os.ReadFile(config.KnownHostsFiles[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(knownHosts), knownhosts.Normalize(server.address))
+	config.HostKeyPolicy = interactive.HostKeyPolicyStrict
+	session, err = startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	assert.Nil(t, session.Close())
+
+	// Changed host key.
+	otherServer := newTestSSHServer(t, signer.PublicKey())
+	otherServer.address = server.address
+	assert.Nil(t, os.WriteFile(config.KnownHostsFiles[0], nil, 0600))
+	addKnownHost(t, config, otherServer)
+	config.HostKeyPolicy = interactive.HostKeyPolicyAcceptNew
+	_, err = startSSHSession(t, config, server)
+	assert.NotNil(t, err)
+}
+
+func TestSSHSpawnFunc_UnknownKey(t *testing.T) {
+	config, _ := newTestSSHConfig(t)
+	otherKey, _ := newTestSSHKey(t)
+	server := newTestSSHServer(t, otherKey.PublicKey())
+	addKnownHost(t, config, server)
+
+	_, err := startSSHSession(t, config, server)
+	assert.NotNil(t, err)
+}
+
+func TestSSHSpawnFunc_Agent(t *testing.T) {
+	config, signer := newTestSSHConfig(t)
+	server := newTestSSHServer(t, signer.PublicKey())
+	addKnownHost(t, config, server)
+
+	// The agent holds the key instead of the key file.
+	keyPEM, err := os.ReadFile(config.KeyFiles[0])
+	assert.Nil(t, err)
+	block, _ := pem.Decode(keyPEM)
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	assert.Nil(t, err)
+	keyring := agent.NewKeyring()
+	assert.Nil(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	assert.Nil(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+	config.KeyFiles = nil
+	config.UseAgent = true
+
+	session, err := startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	assert.Nil(t, session.Close())
+}
+
+func TestSSHSpawnFunc_JumpHost(t *testing.T) {
+	config, signer := newTestSSHConfig(t)
+	jump := newTestSSHServer(t, signer.PublicKey())
+	server := newTestSSHServer(t, signer.PublicKey())
+	addKnownHost(t, config, jump)
+	addKnownHost(t, config, server)
+	config.JumpHosts = []string{"jump@" + jump.address}
+
+	session, err := startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	assert.Nil(t, session.Close())
+
+	config.JumpHosts = []string{"jump@127.0.0.1:1"}
+	_, err = startSSHSession(t, config, server)
+	assert.NotNil(t, err)
+}
+
+func TestSSHSpawnFunc_KeepAlive(t *testing.T) {
+	config, signer := newTestSSHConfig(t)
+	server := newTestSSHServer(t, signer.PublicKey())
+	addKnownHost(t, config, server)
+	config.KeepAlive = 10 * time.Millisecond
+
+	session, err := startSSHSession(t, config, server)
+	assert.Nil(t, err)
+	defer session.Close()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&server.keepAlives) > 1
+	}, sshTestTimeout, time.Millisecond)
+}
+
+func TestSSHSpawnFunc_UnsupportedArgs(t *testing.T) {
+	config, _ := newTestSSHConfig(t)
+	sFunc, err := interactive.NewSSHSpawnFunc(config)
+	assert.Nil(t, err)
+
+	for _, args := range [][]string{
+		{},
+		{"-p", "22", "tnf@host"},
+		{"@host"},
+		{"tnf@"},
+	} {
+		session := *sFunc.Command("ssh", args...)
+		assert.NotNil(t, session.Start(), args)
+	}
+}
+
+func TestSpawn_SSHDestinationOnly(t *testing.T) {
+	config, _ := newTestSSHConfig(t)
+	sFunc, err := interactive.NewSSHSpawnFunc(config)
+	assert.Nil(t, err)
+	interactive.SetSSHSpawnFunc(sFunc)
+	defer interactive.SetSSHSpawnFunc(nil)
+
+	// The ssh commands with options are subprocesses, which the native client does not support.
+	assert.Nil(t, spawnWithShell(t, "ssh", "-V"))
+	assert.NotNil(t, spawnWithShell(t, "ssh", "tnf@"))
+}
+
+func TestNewSSHSpawnFunc(t *testing.T) {
+	config, _ := newTestSSHConfig(t)
+	config.HostKeyPolicy = "unknown"
+	_, err := interactive.NewSSHSpawnFunc(config)
+	assert.NotNil(t, err)
+
+	config, _ = newTestSSHConfig(t)
+	config.KeyFiles = nil
+	_, err = interactive.NewSSHSpawnFunc(config)
+	assert.NotNil(t, err)
+
+	config, _ = newTestSSHConfig(t)
+	assert.Nil(t, os.WriteFile(config.KeyFiles[0], []byte("not a key"), 0600))
+	_, err = interactive.NewSSHSpawnFunc(config)
+	assert.NotNil(t, err)
+}
+
+func TestSSHConfigFromEnvironment(t *testing.T) {
+	home := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(home, ".ssh"), 0700))
+	assert.Nil(t, os.WriteFile(filepath.Join(home, ".ssh", "id_ed25519"), nil, 0600))
+	t.Setenv("HOME", home)
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	config, err := interactive.SSHConfigFromEnvironment()
+	assert.Nil(t, err)
+	assert.Equal(t, &interactive.SSHConfig{
+		KeyFiles:        []string{filepath.Join(home, ".ssh", "id_ed25519")},
+		KnownHostsFiles: []string{filepath.Join(home, ".ssh", "known_hosts")},
+		KeepAlive:       30 * time.Second,
+		ConnectTimeout:  30 * time.Second,
+	}, config)
+
+	t.Setenv("TNF_SSH_KEY_FILES", "/keys/a, /keys/b")
+	t.Setenv("TNF_SSH_KNOWN_HOSTS", "/keys/known_hosts")
+	t.Setenv("TNF_SSH_HOST_KEY_POLICY", "Accept-New")
+	t.Setenv("TNF_SSH_JUMP_HOSTS", "core@bastion,core@jump:2222")
+	t.Setenv("TNF_SSH_KEEPALIVE", "5s")
+	t.Setenv("SSH_AUTH_SOCK", "/run/agent.sock")
+	config, err = interactive.SSHConfigFromEnvironment()
+	assert.Nil(t, err)
+	assert.Equal(t, &interactive.SSHConfig{
+		KeyFiles:        []string{"/keys/a", "/keys/b"},
+		UseAgent:        true,
+		KnownHostsFiles: []string{"/keys/known_hosts"},
+		HostKeyPolicy:   interactive.HostKeyPolicyAcceptNew,
+		JumpHosts:       []string{"core@bastion", "core@jump:2222"},
+		KeepAlive:       5 * time.Second,
+		ConnectTimeout:  30 * time.Second,
+	}, config)
+
+	t.Setenv("TNF_SSH_KEEPALIVE", "often")
+	_, err = interactive.SSHConfigFromEnvironment()
+	assert.NotNil(t, err)
+}
+
+func TestGetSSHBackend(t *testing.T) {
+	testCases := map[string]string{
+		"":        interactive.SSHBackendOpenSSH,
+		"openssh": interactive.SSHBackendOpenSSH,
+		"Native":  interactive.SSHBackendNative,
+		"unknown": interactive.SSHBackendOpenSSH,
+	}
+	for envValue, expected := range testCases {
+		t.Setenv("TNF_SSH_BACKEND", envValue)
+		assert.Equal(t, expected, interactive.GetSSHBackend())
+	}
+}
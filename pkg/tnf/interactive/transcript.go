@@ -0,0 +1,495 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	expect "github.com/google/goexpect"
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	// TranscriptSpawn is the event of a session being spawned.  Its Args hold the command line.
+	TranscriptSpawn = "spawn"
+	// TranscriptExecute is the event of a reel.Step being executed.  Its Data holds Step.Execute, before it is wrapped.
+	TranscriptExecute = "execute"
+	// TranscriptSend is the event of bytes being written to the session standard input.
+	TranscriptSend = "send"
+	// TranscriptReceive is the event of a chunk being read from the session standard output.
+	TranscriptReceive = "receive"
+
+	// TranscriptRecordEnvironmentVariableKey is the file the sessions of a test run are recorded into, when set.
+	TranscriptRecordEnvironmentVariableKey = "TNF_TRANSCRIPT_RECORD"
+	// TranscriptReplayEnvironmentVariableKey is the transcript the sessions of a test run are replayed from, when set.
+	TranscriptReplayEnvironmentVariableKey = "TNF_TRANSCRIPT_REPLAY"
+
+	transcriptFilePermissions = 0644
+	transcriptReadBufferSize  = 4096
+	// transcriptMaxLineSize bounds the size of a single transcript event when reading a transcript.
+	transcriptMaxLineSize = 64 * 1024 * 1024
+)
+
+// TranscriptEvent is a single entry of a transcript.  Transcripts are stored as JSON lines, one event per line, so that
+// a transcript interrupted by a crash is still readable.
+type TranscriptEvent struct {
+	// Time is the time the event happened at.
+	Time time.Time `json:"time"`
+	// Session identifies the session the event belongs to, in spawn order, starting at 1.
+	Session int `json:"session"`
+	// Kind is one of TranscriptSpawn, TranscriptExecute, TranscriptSend or TranscriptReceive.
+	Kind string `json:"kind"`
+	// Args is the command line of a TranscriptSpawn event.
+	Args []string `json:"args,omitempty"`
+	// Data is the text sent or received.
+	Data string `json:"data,omitempty"`
+}
+
+// TranscriptRecorder writes the events of the sessions it is attached to into a transcript.  It is safe for
+// concurrent use.
+type TranscriptRecorder struct {
+	mutex    sync.Mutex
+	writer   io.Writer
+	closer   io.Closer
+	sessions int
+	// expecters maps the expecters of the open sessions to their identifier, for the events recorded by reel.
+	expecters map[expect.Expecter]int
+}
+
+// NewTranscriptRecorder creates a TranscriptRecorder writing to w.
+func NewTranscriptRecorder(w io.Writer) *TranscriptRecorder {
+	return &TranscriptRecorder{writer: w, expecters: make(map[expect.Expecter]int)}
+}
+
+// CreateTranscriptFile creates a TranscriptRecorder writing to the file at path, which is truncated.
+func CreateTranscriptFile(path string) (*TranscriptRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, transcriptFilePermissions)
+	if err != nil {
+		return nil, err
+	}
+	return &TranscriptRecorder{writer: file, closer: file, expecters: make(map[expect.Expecter]int)}, nil
+}
+
+// Close closes the transcript file, if the recorder was created by CreateTranscriptFile.
+func (t *TranscriptRecorder) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+// newSession records the spawn of a session and returns its identifier.
+func (t *TranscriptRecorder) newSession(args []string) int {
+	t.mutex.Lock()
+	t.sessions++
+	session := t.sessions
+	t.mutex.Unlock()
+	t.record(TranscriptEvent{Session: session, Kind: TranscriptSpawn, Args: args})
+	return session
+}
+
+func (t *TranscriptRecorder) record(event TranscriptEvent) {
+	event.Time = time.Now()
+	line, err := json.Marshal(&event)
+	if err != nil {
+		log.Errorf("unable to marshal transcript event: %s", err)
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, err := t.writer.Write(append(line, '\n')); err != nil {
+		log.Errorf("unable to write transcript event: %s", err)
+	}
+}
+
+// bind attributes the events recorded for expecter to session.
+func (t *TranscriptRecorder) bind(expecter expect.Expecter, session int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.expecters[expecter] = session
+}
+
+// unbind forgets expecter, once its session is closed.
+func (t *TranscriptRecorder) unbind(expecter expect.Expecter) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.expecters, expecter)
+}
+
+// RecordExecute records the execution of a reel.Step in the session of expecter.  The steps run on a pooled session
+// are attributed to its current underlying session.  It implements reel.Recorder.
+func (t *TranscriptRecorder) RecordExecute(expecter expect.Expecter, execute string) {
+	if pooled, ok := expecter.(*pooledSession); ok {
+		expecter = pooled.underlying()
+	}
+	t.mutex.Lock()
+	session, ok := t.expecters[expecter]
+	t.mutex.Unlock()
+	if !ok {
+		log.Debugf("recording %q executed in a session which is not recorded", execute)
+	}
+	t.record(TranscriptEvent{Session: session, Kind: TranscriptExecute, Data: execute})
+}
+
+var (
+	// defaultTranscriptRecorder records the sessions spawned by the GoExpectSpawners which are not given a recorder.
+	defaultTranscriptRecorder *TranscriptRecorder
+	// transcriptReplaying is true when the sessions are replayed by StartTranscriptFromEnvironment.
+	transcriptReplaying bool
+)
+
+// StartTranscriptFromEnvironment records every session spawned from now on into the file named by
+// TNF_TRANSCRIPT_RECORD, or replays every session from the transcript named by TNF_TRANSCRIPT_REPLAY.  It does nothing
+// when neither is set.  StopTranscript ends the recording or the replay.
+func StartTranscriptFromEnvironment() error {
+	recordPath := os.Getenv(TranscriptRecordEnvironmentVariableKey)
+	replayPath := os.Getenv(TranscriptReplayEnvironmentVariableKey)
+	switch {
+	case recordPath != "" && replayPath != "":
+		return fmt.Errorf("%s and %s cannot be both set", TranscriptRecordEnvironmentVariableKey, TranscriptReplayEnvironmentVariableKey)
+	case recordPath != "":
+		recorder, err := CreateTranscriptFile(recordPath)
+		if err != nil {
+			return err
+		}
+		defaultTranscriptRecorder = recorder
+		reel.SetDefaultRecorder(recorder)
+		log.Infof("transcript: recording sessions into %s", recordPath)
+	case replayPath != "":
+		if spawnFuncWrapper != nil {
+			return fmt.Errorf("%s cannot be used with a snapshot", TranscriptReplayEnvironmentVariableKey)
+		}
+		replay, err := LoadTranscriptSpawnFunc(replayPath)
+		if err != nil {
+			return err
+		}
+		SetSpawnFuncWrapper(replay.Wrap)
+		transcriptReplaying = true
+		log.Infof("transcript: replaying %d sessions from %s", len(replay.sessions), replayPath)
+	}
+	return nil
+}
+
+// StopTranscript ends the recording or the replay started by StartTranscriptFromEnvironment.
+func StopTranscript() {
+	if defaultTranscriptRecorder != nil {
+		reel.SetDefaultRecorder(nil)
+		if err := defaultTranscriptRecorder.Close(); err != nil {
+			log.Errorf("unable to close the transcript: %s", err)
+		}
+		defaultTranscriptRecorder = nil
+	}
+	if transcriptReplaying {
+		SetSpawnFuncWrapper(nil)
+		transcriptReplaying = false
+	}
+}
+
+// RecordTranscript records the sessions spawned by the GoExpectSpawner into recorder.
+func RecordTranscript(recorder *TranscriptRecorder) Option {
+	return func(g *GoExpectSpawner) Option {
+		prev := g.transcriptRecorder
+		g.transcriptRecorder = recorder
+		return RecordTranscript(prev)
+	}
+}
+
+// transcriptSpawnFunc records the sessions created by the wrapped SpawnFunc.
+type transcriptSpawnFunc struct {
+	SpawnFunc
+	recorder *TranscriptRecorder
+	session  int
+	// expecter is the expecter of the session, once it is spawned.
+	expecter expect.Expecter
+}
+
+// bind attributes the steps run with expecter to the session.
+func (t *transcriptSpawnFunc) bind(expecter expect.Expecter) {
+	t.expecter = expecter
+	t.recorder.bind(expecter, t.session)
+}
+
+// Close ends the session, and stops attributing the steps run with its expecter to it.
+func (t *transcriptSpawnFunc) Close() error {
+	if t.expecter != nil {
+		t.recorder.unbind(t.expecter)
+	}
+	return t.SpawnFunc.Close()
+}
+
+func (t *transcriptSpawnFunc) Command(name string, arg ...string) *SpawnFunc {
+	inner := t.SpawnFunc.Command(name, arg...)
+	var sFunc SpawnFunc = &transcriptSpawnFunc{
+		SpawnFunc: *inner,
+		recorder:  t.recorder,
+		session:   t.recorder.newSession(append([]string{name}, arg...)),
+	}
+	return &sFunc
+}
+
+type transcriptWriter struct {
+	io.WriteCloser
+	recorder *TranscriptRecorder
+	session  int
+}
+
+func (w *transcriptWriter) Write(p []byte) (int, error) {
+	w.recorder.record(TranscriptEvent{Session: w.session, Kind: TranscriptSend, Data: string(p)})
+	return w.WriteCloser.Write(p)
+}
+
+type transcriptReader struct {
+	io.Reader
+	recorder *TranscriptRecorder
+	session  int
+}
+
+func (r *transcriptReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.recorder.record(TranscriptEvent{Session: r.session, Kind: TranscriptReceive, Data: string(p[:n])})
+	}
+	return n, err
+}
+
+func (t *transcriptSpawnFunc) StdinPipe() (io.WriteCloser, error) {
+	stdin, err := t.SpawnFunc.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	return &transcriptWriter{WriteCloser: stdin, recorder: t.recorder, session: t.session}, nil
+}
+
+func (t *transcriptSpawnFunc) StdoutPipe() (io.Reader, error) {
+	stdout, err := t.SpawnFunc.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	return &transcriptReader{Reader: stdout, recorder: t.recorder, session: t.session}, nil
+}
+
+// ReadTranscript reads the events of the transcript at path.
+func ReadTranscript(path string) ([]TranscriptEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var events []TranscriptEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, transcriptMaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var event TranscriptEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("invalid transcript event at %s:%d: %s", path, line, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// TranscriptSpawnFunc is a SpawnFunc feeding recorded transcripts back instead of spawning processes.  Each session
+// created by Command replays the next recorded session, preferably one spawned with the same command line.  The output
+// recorded after a send is written once the same bytes are sent to the replayed session.  When the replayed session is
+// sent something else, its output is closed, which the expecter sees as the end of the session.
+type TranscriptSpawnFunc struct {
+	mutex    sync.Mutex
+	sessions [][]TranscriptEvent
+	used     []bool
+
+	// the fields below are set on the replayed sessions only.
+	events       []TranscriptEvent
+	args         []string
+	stdinReader  *io.PipeReader
+	stdinWriter  *io.PipeWriter
+	stdoutReader *io.PipeReader
+	stdoutWriter *io.PipeWriter
+	stderrReader *io.PipeReader
+	stderrWriter *io.PipeWriter
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewTranscriptSpawnFunc creates a TranscriptSpawnFunc replaying the sessions found in events.
+func NewTranscriptSpawnFunc(events []TranscriptEvent) *TranscriptSpawnFunc {
+	t := &TranscriptSpawnFunc{}
+	index := map[int]int{}
+	for _, event := range events {
+		if event.Kind == TranscriptSpawn {
+			index[event.Session] = len(t.sessions)
+			t.sessions = append(t.sessions, []TranscriptEvent{event})
+			continue
+		}
+		if i, ok := index[event.Session]; ok {
+			t.sessions[i] = append(t.sessions[i], event)
+		}
+	}
+	t.used = make([]bool, len(t.sessions))
+	return t
+}
+
+// LoadTranscriptSpawnFunc creates a TranscriptSpawnFunc replaying the transcript at path.
+func LoadTranscriptSpawnFunc(path string) (*TranscriptSpawnFunc, error) {
+	events, err := ReadTranscript(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewTranscriptSpawnFunc(events), nil
+}
+
+// Wrap returns t whatever the wrapped SpawnFunc.  It can be given to SetSpawnFuncWrapper to replay the transcript in
+// every session spawned by a GoExpectSpawner.
+func (t *TranscriptSpawnFunc) Wrap(SpawnFunc) SpawnFunc {
+	return t
+}
+
+// nextSession returns the events of the next session to replay for the command line args.
+func (t *TranscriptSpawnFunc) nextSession(args []string) []TranscriptEvent {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	commandLine := strings.Join(args, " ")
+	candidate := -1
+	for i, session := range t.sessions {
+		if t.used[i] {
+			continue
+		}
+		if strings.Join(session[0].Args, " ") == commandLine {
+			candidate = i
+			break
+		}
+		if candidate < 0 {
+			candidate = i
+		}
+	}
+	if candidate < 0 {
+		log.Warnf("no recorded session left to replay %q", commandLine)
+		return nil
+	}
+	t.used[candidate] = true
+	return t.sessions[candidate][1:]
+}
+
+// Command creates a session replaying the next recorded session.
+func (t *TranscriptSpawnFunc) Command(name string, arg ...string) *SpawnFunc {
+	args := append([]string{name}, arg...)
+	session := &TranscriptSpawnFunc{events: t.nextSession(args), args: args, done: make(chan struct{})}
+	session.stdinReader, session.stdinWriter = io.Pipe()
+	session.stdoutReader, session.stdoutWriter = io.Pipe()
+	session.stderrReader, session.stderrWriter = io.Pipe()
+	var sFunc SpawnFunc = session
+	return &sFunc
+}
+
+// Start starts feeding the recorded output.
+func (t *TranscriptSpawnFunc) Start() error {
+	go t.replay()
+	return nil
+}
+
+// replay writes the received chunks in order, waiting for the recorded sends before writing the chunks following them.
+// Once the transcript is exhausted, the session stays open and what is sent to it is discarded.
+func (t *TranscriptSpawnFunc) replay() {
+	if t.events == nil {
+		t.stdoutWriter.Close()
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, t.stdinReader)
+	}()
+	var pending []byte
+	buffer := make([]byte, transcriptReadBufferSize)
+	for _, event := range t.events {
+		switch event.Kind {
+		case TranscriptReceive:
+			if _, err := t.stdoutWriter.Write([]byte(event.Data)); err != nil {
+				return
+			}
+		case TranscriptSend:
+			for len(pending) < len(event.Data) {
+				n, err := t.stdinReader.Read(buffer)
+				pending = append(pending, buffer[:n]...)
+				if err != nil {
+					return
+				}
+			}
+			if !bytes.HasPrefix(pending, []byte(event.Data)) {
+				log.Warnf("transcript replay of %q diverged: expected %q, got %q", strings.Join(t.args, " "), event.Data, pending)
+				t.stdoutWriter.Close()
+				return
+			}
+			pending = pending[len(event.Data):]
+		}
+	}
+}
+
+// StdinPipe returns the end the commands are sent to.
+func (t *TranscriptSpawnFunc) StdinPipe() (io.WriteCloser, error) {
+	return t.stdinWriter, nil
+}
+
+// StdoutPipe returns the end the recorded output is read from.
+func (t *TranscriptSpawnFunc) StdoutPipe() (io.Reader, error) {
+	return t.stdoutReader, nil
+}
+
+// StderrPipe returns a reader that stays empty until the session is closed.
+func (t *TranscriptSpawnFunc) StderrPipe() (io.Reader, error) {
+	return t.stderrReader, nil
+}
+
+// Wait blocks until the session is closed.
+func (t *TranscriptSpawnFunc) Wait() error {
+	<-t.done
+	return nil
+}
+
+// Close ends the session.
+func (t *TranscriptSpawnFunc) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		t.stdinReader.Close()
+		t.stdoutWriter.Close()
+		t.stderrWriter.Close()
+	})
+	return nil
+}
+
+// IsRunning returns true until the session is closed.
+func (t *TranscriptSpawnFunc) IsRunning() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Args returns the command line the session was spawned with.
+func (t *TranscriptSpawnFunc) Args() []string {
+	return t.args
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package interactive_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+// echoHandler runs a single command and keeps what it matched.
+type echoHandler struct {
+	command string
+	matched string
+	timeout bool
+}
+
+func (h *echoHandler) ReelFirst() *reel.Step {
+	return &reel.Step{Execute: h.command, Expect: []string{`(?m)^[a-z]+$`}, Timeout: testTimeoutDuration}
+}
+
+func (h *echoHandler) ReelMatch(_, _, match string) *reel.Step {
+	h.matched = match
+	return nil
+}
+
+func (h *echoHandler) ReelTimeout() *reel.Step {
+	h.timeout = true
+	return nil
+}
+
+func (h *echoHandler) ReelEOF() {}
+
+// runEchoHandler spawns /bin/sh with spawner and runs an echoHandler sending command.
+func runEchoHandler(t *testing.T, spawner *interactive.GoExpectSpawner, command string, opts ...reel.Option) *echoHandler {
+	context, err := spawner.Spawn("/bin/sh", []string{}, testTimeoutDuration)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+	r, err := reel.NewReel(context.GetExpecter(), nil, context.GetErrorChannel(), opts...)
+	assert.Nil(t, err)
+	handler := &echoHandler{command: command}
+	_ = r.Run(handler)
+	return handler
+}
+
+func TestTranscriptRecordAndReplay(t *testing.T) {
+	defer interactive.SetSpawnFunc(nil)
+	var execSpawnFunc interactive.SpawnFunc = &interactive.ExecSpawnFunc{}
+	interactive.SetSpawnFunc(&execSpawnFunc)
+
+	transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+	recorder, err := interactive.CreateTranscriptFile(transcriptPath)
+	assert.Nil(t, err)
+	spawner := interactive.NewGoExpectSpawner()
+	interactive.RecordTranscript(recorder)(spawner)
+	recorded := runEchoHandler(t, spawner, "echo hello", reel.RecordSteps(recorder))
+	assert.Nil(t, recorder.Close())
+	assert.Equal(t, "hello", recorded.matched)
+
+	events, err := interactive.ReadTranscript(transcriptPath)
+	assert.Nil(t, err)
+	kinds := map[string]int{}
+	for _, event := range events {
+		assert.Equal(t, 1, event.Session)
+		assert.False(t, event.Time.IsZero())
+		kinds[event.Kind]++
+	}
+	assert.Equal(t, interactive.TranscriptSpawn, events[0].Kind)
+	assert.Equal(t, []string{"/bin/sh"}, events[0].Args)
+	assert.Equal(t, interactive.TranscriptExecute, events[1].Kind)
+	assert.Equal(t, "echo hello", events[1].Data)
+	assert.Equal(t, 1, kinds[interactive.TranscriptSend])
+	assert.NotZero(t, kinds[interactive.TranscriptReceive])
+
+	// The same handler gets the same match from the transcript, without any process.
+	replay, err := interactive.LoadTranscriptSpawnFunc(transcriptPath)
+	assert.Nil(t, err)
+	var replaySpawnFunc interactive.SpawnFunc = replay
+	interactive.SetSpawnFunc(&replaySpawnFunc)
+	replayed := runEchoHandler(t, interactive.NewGoExpectSpawner(), "echo hello")
+	assert.Equal(t, recorded.matched, replayed.matched)
+
+	// A handler sending something else does not get the recorded output.
+	replay, err = interactive.LoadTranscriptSpawnFunc(transcriptPath)
+	assert.Nil(t, err)
+	replaySpawnFunc = replay
+	interactive.SetSpawnFunc(&replaySpawnFunc)
+	diverged := runEchoHandler(t, interactive.NewGoExpectSpawner(), "echo world")
+	assert.Equal(t, "", diverged.matched)
+}
+
+func TestTranscriptSpawnFuncSessions(t *testing.T) {
+	replay := interactive.NewTranscriptSpawnFunc([]interactive.TranscriptEvent{
+		{Session: 1, Kind: interactive.TranscriptSpawn, Args: []string{"oc", "rsh", "pod-a"}},
+		{Session: 2, Kind: interactive.TranscriptSpawn, Args: []string{"oc", "rsh", "pod-b"}},
+		{Session: 1, Kind: interactive.TranscriptReceive, Data: "a"},
+		{Session: 2, Kind: interactive.TranscriptReceive, Data: "b"},
+	})
+
+	// Sessions are matched by command line first, then replayed in order.
+	for _, expected := range []struct{ pod, output string }{{"pod-b", "b"}, {"pod-c", "a"}} {
+		session := *replay.Command("oc", "rsh", expected.pod)
+		assert.Equal(t, []string{"oc", "rsh", expected.pod}, session.Args())
+		stdout, err := session.StdoutPipe()
+		assert.Nil(t, err)
+		assert.Nil(t, session.Start())
+		buffer := make([]byte, 1)
+		_, err = stdout.Read(buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, expected.output, string(buffer))
+		assert.True(t, session.IsRunning())
+		assert.Nil(t, session.Close())
+		assert.False(t, session.IsRunning())
+		assert.Nil(t, session.Wait())
+	}
+}
+
+func TestTranscriptRecordExecuteSessions(t *testing.T) {
+	defer interactive.SetSpawnFunc(nil)
+	var execSpawnFunc interactive.SpawnFunc = &interactive.ExecSpawnFunc{}
+	interactive.SetSpawnFunc(&execSpawnFunc)
+
+	transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+	recorder, err := interactive.CreateTranscriptFile(transcriptPath)
+	assert.Nil(t, err)
+	spawner := interactive.NewGoExpectSpawner()
+	interactive.RecordTranscript(recorder)(spawner)
+	first, err := spawner.Spawn("/bin/sh", []string{}, testTimeoutDuration)
+	assert.Nil(t, err)
+	defer (*first.GetExpecter()).Close()
+	second, err := spawner.Spawn("/bin/sh", []string{}, testTimeoutDuration)
+	assert.Nil(t, err)
+	defer (*second.GetExpecter()).Close()
+
+	// The steps are attributed to the session they run in, not to the last spawned one.
+	for _, step := range []struct {
+		context *interactive.Context
+		command string
+	}{{first, "echo one"}, {second, "echo two"}, {first, "echo three"}} {
+		r, err := reel.NewReel(step.context.GetExpecter(), nil, step.context.GetErrorChannel(), reel.RecordSteps(recorder))
+		assert.Nil(t, err)
+		assert.Nil(t, r.Run(&echoHandler{command: step.command}))
+	}
+	assert.Nil(t, recorder.Close())
+
+	events, err := interactive.ReadTranscript(transcriptPath)
+	assert.Nil(t, err)
+	executed := map[string]int{}
+	for _, event := range events {
+		if event.Kind == interactive.TranscriptExecute {
+			executed[event.Data] = event.Session
+		}
+	}
+	assert.Equal(t, map[string]int{"echo one": 1, "echo two": 2, "echo three": 1}, executed)
+}
+
+func TestStartTranscriptFromEnvironment(t *testing.T) {
+	defer interactive.SetSpawnFunc(nil)
+	var execSpawnFunc interactive.SpawnFunc = &interactive.ExecSpawnFunc{}
+	interactive.SetSpawnFunc(&execSpawnFunc)
+
+	// Both variables cannot be set.
+	transcriptPath := filepath.Join(t.TempDir(), "transcript.jsonl")
+	t.Setenv(interactive.TranscriptRecordEnvironmentVariableKey, transcriptPath)
+	t.Setenv(interactive.TranscriptReplayEnvironmentVariableKey, transcriptPath)
+	assert.NotNil(t, interactive.StartTranscriptFromEnvironment())
+
+	// The sessions and the steps are recorded without any option.
+	t.Setenv(interactive.TranscriptReplayEnvironmentVariableKey, "")
+	assert.Nil(t, interactive.StartTranscriptFromEnvironment())
+	recorded := runEchoHandler(t, interactive.NewGoExpectSpawner(), "echo hello")
+	interactive.StopTranscript()
+	assert.Equal(t, "hello", recorded.matched)
+	events, err := interactive.ReadTranscript(transcriptPath)
+	assert.Nil(t, err)
+	assert.Equal(t, interactive.TranscriptSpawn, events[0].Kind)
+	assert.Equal(t, interactive.TranscriptExecute, events[1].Kind)
+	assert.Equal(t, 1, events[1].Session)
+
+	// The sessions are replayed without any process.
+	t.Setenv(interactive.TranscriptRecordEnvironmentVariableKey, "")
+	t.Setenv(interactive.TranscriptReplayEnvironmentVariableKey, transcriptPath)
+	assert.Nil(t, interactive.StartTranscriptFromEnvironment())
+	defer interactive.StopTranscript()
+	replayed := runEchoHandler(t, interactive.NewGoExpectSpawner(), "echo hello")
+	assert.Equal(t, recorded.matched, replayed.matched)
+}
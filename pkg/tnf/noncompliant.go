@@ -0,0 +1,76 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package tnf
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// NonCompliantObjectEntryName is the name of the report entries of the non-compliant objects, see Reporter.AddEntry.
+	NonCompliantObjectEntryName = "nonCompliantObject"
+
+	// KindContainer is the kind of a non-compliant container.
+	KindContainer = "Container"
+	// KindPod is the kind of a non-compliant pod.
+	KindPod = "Pod"
+	// KindHelmChart is the kind of a non-compliant helm chart.
+	KindHelmChart = "HelmChart"
+	// KindNode is the kind of a non-compliant node.
+	KindNode = "Node"
+	// KindOperator is the kind of a non-compliant operator.
+	KindOperator = "Operator"
+
+	// ReasonCheckError is the reason code of an object which could not be checked.
+	ReasonCheckError = "CheckError"
+)
+
+// NonCompliantObject is an object which failed a test case, e.g. a pod using the host network.
+type NonCompliantObject struct {
+	// Kind is the kind of the object, e.g. KindPod.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Container is the container of the pod Name, when the object is a container.
+	Container string `json:"container,omitempty"`
+	// Reason is a short code of why the object is non-compliant, e.g. NoLogOutput or ReasonCheckError.
+	Reason string `json:"reason"`
+	// Details is a free-form message about the reason.
+	Details string `json:"details,omitempty"`
+}
+
+// String returns a human readable form of the object, e.g. "Container tnf/test-0/test: NoLogOutput".
+func (o NonCompliantObject) String() string { //nolint:gocritic // Used as a value in report entries
+	parts := []string{}
+	for _, part := range []string{o.Namespace, o.Name, o.Container} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	text := fmt.Sprintf("%s %s: %s", o.Kind, strings.Join(parts, "/"), o.Reason)
+	if o.Details != "" {
+		text += " (" + o.Details + ")"
+	}
+	return text
+}
+
+// ReportNonCompliantObject records that object failed the running test case.  The objects are attached to the claim
+// result of the test case.  It is safe for concurrent use by the goroutines of a test case.
+func ReportNonCompliantObject(object NonCompliantObject) { //nolint:gocritic // Kept by value in the report entry
+	addEntry(NonCompliantObjectEntryName, object)
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package tnf
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reportEntry is an entry attached to the claim result of a test case.
+type reportEntry struct {
+	name  string
+	value interface{}
+}
+
+// recordingReporter records the report entries of a running test case.
+type recordingReporter struct {
+	logReporter
+	entries []reportEntry
+}
+
+func (r *recordingReporter) AddEntry(name string, value interface{}) bool {
+	r.entries = append(r.entries, reportEntry{name: name, value: value})
+	return true
+}
+
+// stubReporter makes a recordingReporter the reporter of the running test case.
+func stubReporter(t *testing.T) *recordingReporter {
+	r := &recordingReporter{}
+	SetReporter(r)
+	t.Cleanup(func() { SetReporter(nil) })
+	return r
+}
+
+func TestReportNonCompliantObject(t *testing.T) {
+	r := stubReporter(t)
+	object := NonCompliantObject{Kind: KindPod, Namespace: "tnf", Name: "test-0", Reason: "HostNetwork"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ReportNonCompliantObject(object)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, r.entries, 10)
+	assert.Equal(t, reportEntry{name: NonCompliantObjectEntryName, value: object}, r.entries[0])
+}
+
+func TestReportRetriedTest(t *testing.T) {
+	r := stubReporter(t)
+	attempts := []Attempt{{Number: 1, Result: ERROR, Backoff: time.Second}, {Number: 2, Result: SUCCESS}}
+
+	// Without retries, nothing is reported.
+	reportRetriedTest("test", attempts[1:])
+	assert.Empty(t, r.entries)
+
+	reportRetriedTest("test", attempts)
+	assert.Equal(t, []reportEntry{{name: RetriedTestEntryName, value: RetriedTest{Name: "test", Attempts: attempts}}}, r.entries)
+}
+
+func TestSetReporter(t *testing.T) {
+	// Outside of a test case, the entries are dropped, and failing or skipping panics.
+	assert.False(t, CurrentReporter().AddEntry(NonCompliantObjectEntryName, NonCompliantObject{}))
+	assert.Panics(t, func() { CurrentReporter().Fail("failed") })
+	assert.Panics(t, func() { CurrentReporter().Skip("skipped") })
+
+	r := stubReporter(t)
+	assert.Equal(t, r, CurrentReporter())
+	SetReporter(nil)
+	assert.Equal(t, logReporter{}, CurrentReporter())
+}
+
+func TestNonCompliantObject_String(t *testing.T) {
+	assert.Equal(t, "Container tnf/test-0/test: NoLogOutput",
+		NonCompliantObject{Kind: KindContainer, Namespace: "tnf", Name: "test-0", Container: "test", Reason: "NoLogOutput"}.String())
+	assert.Equal(t, "HelmChart example: HelmChartNotCertified (version 1.0.0)",
+		NonCompliantObject{Kind: KindHelmChart, Name: "example", Reason: "HelmChartNotCertified", Details: "version 1.0.0"}.String())
+}
@@ -0,0 +1,23 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+/*
+Package parallel runs the per-container and per-node iterations of a test case on a bounded pool of workers.  Each
+worker gets its own interactive sessions, as an expect.Expecter cannot be shared between goroutines.  The claim file
+output of every task is buffered and written in task order, and the failures are reported in task order, so that the
+outcome of a test case does not depend on scheduling.
+*/
+package parallel
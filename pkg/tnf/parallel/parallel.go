@@ -0,0 +1,242 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package parallel
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+)
+
+const (
+	// LimitEnvironmentVariableKey is the OS environment variable name to override DefaultLimit.
+	LimitEnvironmentVariableKey = "TNF_PARALLELISM"
+	// DefaultLimit is the default number of workers of a Run.
+	DefaultLimit = 4
+)
+
+var (
+	// claimFilePrintf writes the output of the tasks.
+	claimFilePrintf = tnf.ClaimFilePrintf
+	// reportNonCompliantObject reports the non-compliant objects of the tasks.
+	reportNonCompliantObject = tnf.ReportNonCompliantObject
+)
+
+// Limit returns the number of workers as sourced from TNF_PARALLELISM.  If TNF_PARALLELISM is not set or is not a
+// positive integer, DefaultLimit is returned.  A limit of 1 runs the tasks serially.
+func Limit() int {
+	if limitFromEnv := os.Getenv(LimitEnvironmentVariableKey); limitFromEnv != "" {
+		limit, err := strconv.Atoi(limitFromEnv)
+		if err == nil && limit > 0 {
+			return limit
+		}
+		log.Warnf("%s must be a positive integer, using the default parallelism %d", LimitEnvironmentVariableKey, DefaultLimit)
+	}
+	return DefaultLimit
+}
+
+// Task is a single iteration of a Run, e.g. the check of one container.
+type Task struct {
+	// Index is the position of the task in the Run.
+	Index int
+	// Name identifies the task in the Report, e.g. a container or node name.
+	Name         string
+	output       []string
+	failures     []string
+	errs         []string
+	nonCompliant []tnf.NonCompliantObject
+}
+
+// Printf buffers a line for the claim file.  The lines of a task are written together once all the previous tasks
+// are done.
+func (t *Task) Printf(format string, args ...interface{}) {
+	t.output = append(t.output, fmt.Sprintf(format, args...))
+}
+
+// Failf marks the task as failed and buffers the reason for the claim file.
+func (t *Task) Failf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	t.failures = append(t.failures, message)
+	t.output = append(t.output, message)
+}
+
+// Errorf marks the task as errored, i.e. the check could not be performed, and buffers the reason for the claim file.
+func (t *Task) Errorf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	t.errs = append(t.errs, message)
+	t.output = append(t.output, message)
+}
+
+// ReportNonCompliant buffers a non-compliant object for the claim result, see tnf.ReportNonCompliantObject.  The
+// objects of a task are reported together with its output.
+func (t *Task) ReportNonCompliant(object tnf.NonCompliantObject) { //nolint:gocritic // Same as tnf.ReportNonCompliantObject
+	t.nonCompliant = append(t.nonCompliant, object)
+}
+
+// Failed returns true when Failf was called.
+func (t *Task) Failed() bool {
+	return len(t.failures) > 0
+}
+
+// Errored returns true when Errorf was called or the task panicked.
+func (t *Task) Errored() bool {
+	return len(t.errs) > 0
+}
+
+// Report is the outcome of a Run, with the tasks in the order they were given.
+type Report struct {
+	Tasks []*Task
+}
+
+// Failed returns the names of the failed tasks, in task order.
+func (r *Report) Failed() []string {
+	var names []string
+	for _, task := range r.Tasks {
+		if task.Failed() {
+			names = append(names, task.Name)
+		}
+	}
+	return names
+}
+
+// Errored returns the names of the errored tasks, in task order.
+func (r *Report) Errored() []string {
+	var names []string
+	for _, task := range r.Tasks {
+		if task.Errored() {
+			names = append(names, task.Name)
+		}
+	}
+	return names
+}
+
+// Runner runs tasks on a bounded number of workers sharing an interactive.SessionPool.
+type Runner struct {
+	limit int
+	pool  *interactive.SessionPool
+	// printf and report replace claimFilePrintf and reportNonCompliantObject, when set.
+	printf func(format string, args ...interface{})
+	report func(object tnf.NonCompliantObject)
+}
+
+// NewRunner creates a Runner with at most limit concurrent workers taking their sessions from pool.
+func NewRunner(limit int, pool *interactive.SessionPool) *Runner {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Runner{limit: limit, pool: pool}
+}
+
+// WithOutput returns a copy of the Runner which writes the output of the tasks with printf and reports their
+// non-compliant objects with report, instead of the claim file and the Ginkgo report, e.g. to run outside a Ginkgo spec.
+func (r *Runner) WithOutput(printf func(format string, args ...interface{}), report func(object tnf.NonCompliantObject)) *Runner {
+	return &Runner{limit: r.limit, pool: r.pool, printf: printf, report: report}
+}
+
+// Run calls fn for each name, with at most the Runner limit calls running concurrently.  Every call gets its own Task
+// and a Worker whose sessions are not used by any other concurrent call.  A panic in fn, e.g. a failed gomega
+// assertion, errors the task instead of ending the run.  The output of the tasks is written to the claim file in task
+// order as soon as the previous tasks are done.
+func (r *Runner) Run(names []string, fn func(w *Worker, t *Task)) *Report {
+	report := &Report{Tasks: make([]*Task, len(names))}
+	for i, name := range names {
+		report.Tasks[i] = &Task{Index: i, Name: name}
+	}
+	if len(names) == 0 {
+		return report
+	}
+
+	workers := r.limit
+	if workers > len(names) {
+		workers = len(names)
+	}
+	printer := newOrderedPrinter(report.Tasks, r.printf, r.report)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for id := 0; id < workers; id++ {
+		wg.Add(1)
+		go func(worker *Worker) {
+			defer wg.Done()
+			defer worker.release()
+			for i := range indexes {
+				runTask(worker, report.Tasks[i], fn)
+				printer.done(i)
+			}
+		}(newWorker(id, r.pool))
+	}
+	for i := range names {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return report
+}
+
+func runTask(worker *Worker, task *Task, fn func(w *Worker, t *Task)) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Errorf("task %s panicked: %v", task.Name, recovered)
+			task.Errorf("ERROR: %s: %v", task.Name, recovered)
+		}
+	}()
+	fn(worker, task)
+}
+
+// orderedPrinter writes the output of the tasks in task order.
+type orderedPrinter struct {
+	mutex    sync.Mutex
+	tasks    []*Task
+	finished []bool
+	next     int
+	printf   func(format string, args ...interface{})
+	report   func(object tnf.NonCompliantObject)
+}
+
+// newOrderedPrinter creates an orderedPrinter writing with printf and report, or with claimFilePrintf and
+// reportNonCompliantObject when they are nil.
+func newOrderedPrinter(tasks []*Task, printf func(format string, args ...interface{}), report func(object tnf.NonCompliantObject)) *orderedPrinter {
+	if printf == nil {
+		printf = claimFilePrintf
+	}
+	if report == nil {
+		report = reportNonCompliantObject
+	}
+	return &orderedPrinter{tasks: tasks, finished: make([]bool, len(tasks)), printf: printf, report: report}
+}
+
+// done marks task i as done and writes the output and reports the non-compliant objects of the tasks that are no longer
+// waiting for a previous one.
+func (p *orderedPrinter) done(i int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.finished[i] = true
+	for p.next < len(p.tasks) && p.finished[p.next] {
+		if output := p.tasks[p.next].output; len(output) > 0 {
+			p.printf("%s", strings.Join(output, "\n"))
+		}
+		for _, object := range p.tasks[p.next].nonCompliant {
+			p.report(object)
+		}
+		p.next++
+	}
+}
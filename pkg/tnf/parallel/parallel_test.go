@@ -0,0 +1,240 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package parallel
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+)
+
+// fakeSpawner creates sessions without any process.
+type fakeSpawner struct{}
+
+func (fakeSpawner) Spawn(command string, args []string, timeout time.Duration, opts ...interactive.Option) (*interactive.Context, error) {
+	return interactive.NewContext(nil, nil), nil
+}
+
+func newFakeOc(t *testing.T, pod string) *interactive.Oc {
+	var spawner interactive.Spawner = fakeSpawner{}
+	oc, _, err := interactive.SpawnOc(&spawner, pod, "test", "tnf", time.Second)
+	assert.Nil(t, err)
+	return oc
+}
+
+// countingSpawnFunc runs a local shell in place of every command, and counts the sessions spawned and closed.
+type countingSpawnFunc struct {
+	interactive.ExecSpawnFunc
+	spawned, closed *int32
+}
+
+func (c *countingSpawnFunc) Command(name string, arg ...string) *interactive.SpawnFunc {
+	atomic.AddInt32(c.spawned, 1)
+	var session interactive.SpawnFunc = &countingSession{SpawnFunc: *c.ExecSpawnFunc.Command("/bin/sh"), closed: c.closed}
+	return &session
+}
+
+type countingSession struct {
+	interactive.SpawnFunc
+	closed *int32
+}
+
+func (c *countingSession) Close() error {
+	atomic.AddInt32(c.closed, 1)
+	return c.SpawnFunc.Close()
+}
+
+// stubSessions replaces the session creation and returns the number of sessions spawned and closed.
+func stubSessions(t *testing.T) (spawned, closed *int32) {
+	spawned, closed = new(int32), new(int32)
+	t.Setenv("SHELL", "/bin/sh")
+	t.Cleanup(func() { interactive.SetSpawnFuncWrapper(nil) })
+	interactive.SetSpawnFuncWrapper(func(interactive.SpawnFunc) interactive.SpawnFunc {
+		return &countingSpawnFunc{spawned: spawned, closed: closed}
+	})
+	return spawned, closed
+}
+
+// stubClaimFile captures the claim file output.
+func stubClaimFile(t *testing.T) *[]string {
+	var lines []string
+	orig := claimFilePrintf
+	t.Cleanup(func() { claimFilePrintf = orig })
+	claimFilePrintf = func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	return &lines
+}
+
+func TestRunOrderAndAggregation(t *testing.T) {
+	stubSessions(t)
+	lines := stubClaimFile(t)
+	names := []string{"c0", "c1", "c2", "c3", "c4", "c5"}
+
+	var running, maxRunning int32
+	var mutex sync.Mutex
+	report := NewRunner(3, interactive.NewSessionPool(time.Second)).Run(names, func(w *Worker, task *Task) {
+		current := atomic.AddInt32(&running, 1)
+		mutex.Lock()
+		if current > maxRunning {
+			maxRunning = current
+		}
+		mutex.Unlock()
+		// The first tasks finish last.
+		time.Sleep(time.Duration(len(names)-task.Index) * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		task.Printf("checking %s", task.Name)
+		switch task.Index {
+		case 1, 4:
+			task.Failf("%s failed", task.Name)
+		case 3:
+			panic("broken session")
+		}
+	})
+
+	assert.LessOrEqual(t, maxRunning, int32(3))
+	assert.Equal(t, []string{"c1", "c4"}, report.Failed())
+	assert.Equal(t, []string{"c3"}, report.Errored())
+	assert.Equal(t, []string{
+		"checking c0",
+		"checking c1\nc1 failed",
+		"checking c2",
+		"checking c3\nERROR: c3: broken session",
+		"checking c4\nc4 failed",
+		"checking c5",
+	}, *lines)
+}
+
+func TestRunWithOutput(t *testing.T) {
+	stubSessions(t)
+	claimFile := stubClaimFile(t)
+	var lines []string
+	var objects []tnf.NonCompliantObject
+	runner := NewRunner(2, interactive.NewSessionPool(time.Second)).WithOutput(func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}, func(object tnf.NonCompliantObject) {
+		objects = append(objects, object)
+	})
+
+	report := runner.Run([]string{"c0", "c1"}, func(w *Worker, task *Task) {
+		task.Printf("checking %s", task.Name)
+		if task.Index == 1 {
+			task.Failf("%s failed", task.Name)
+			task.ReportNonCompliant(tnf.NonCompliantObject{Kind: tnf.KindContainer, Name: task.Name, Reason: "Test"})
+		}
+	})
+
+	assert.Equal(t, []string{"c1"}, report.Failed())
+	assert.Equal(t, []string{"checking c0", "checking c1\nc1 failed"}, lines)
+	assert.Equal(t, []tnf.NonCompliantObject{{Kind: tnf.KindContainer, Name: "c1", Reason: "Test"}}, objects)
+	assert.Empty(t, *claimFile)
+}
+
+func TestRunNonCompliantObjects(t *testing.T) {
+	stubSessions(t)
+	stubClaimFile(t)
+	var objects []tnf.NonCompliantObject
+	orig := reportNonCompliantObject
+	t.Cleanup(func() { reportNonCompliantObject = orig })
+	reportNonCompliantObject = func(object tnf.NonCompliantObject) {
+		objects = append(objects, object)
+	}
+
+	names := []string{"c0", "c1", "c2"}
+	NewRunner(3, interactive.NewSessionPool(time.Second)).Run(names, func(w *Worker, task *Task) {
+		// The first tasks finish last.
+		time.Sleep(time.Duration(len(names)-task.Index) * time.Millisecond)
+		if task.Index != 1 {
+			task.ReportNonCompliant(tnf.NonCompliantObject{Kind: tnf.KindContainer, Name: task.Name, Reason: "Test"})
+		}
+	})
+
+	assert.Equal(t, []tnf.NonCompliantObject{
+		{Kind: tnf.KindContainer, Name: "c0", Reason: "Test"},
+		{Kind: tnf.KindContainer, Name: "c2", Reason: "Test"},
+	}, objects)
+}
+
+func TestRunEmpty(t *testing.T) {
+	report := NewRunner(0, interactive.NewSessionPool(time.Second)).Run(nil, func(w *Worker, task *Task) {
+		t.Fail()
+	})
+	assert.Empty(t, report.Tasks)
+	assert.Nil(t, report.Failed())
+}
+
+func TestWorkerSessions(t *testing.T) {
+	spawned, closed := stubSessions(t)
+	stubClaimFile(t)
+	pool := interactive.NewSessionPool(time.Second)
+	templates := []*interactive.Oc{newFakeOc(t, "node-0"), newFakeOc(t, "node-1")}
+
+	run := func() {
+		NewRunner(2, pool).Run([]string{"a", "b", "c", "d"}, func(w *Worker, task *Task) {
+			template := templates[task.Index%2]
+			oc, err := w.Oc(template)
+			assert.Nil(t, err)
+			assert.NotSame(t, template, oc)
+			assert.Equal(t, template.GetPodName(), oc.GetPodName())
+			again, err := w.Oc(template)
+			assert.Nil(t, err)
+			assert.Same(t, oc, again)
+			shell, err := w.LocalShell()
+			assert.Nil(t, err)
+			assert.NotNil(t, shell)
+		})
+	}
+
+	// Each worker has at most one session per target, the sessions are reused by the next run.
+	run()
+	firstRun := atomic.LoadInt32(spawned)
+	assert.LessOrEqual(t, firstRun, int32(6))
+	run()
+	assert.Equal(t, firstRun, atomic.LoadInt32(spawned))
+
+	// A closed session is replaced.
+	NewRunner(1, pool).Run([]string{"timeout"}, func(w *Worker, task *Task) {
+		oc, err := w.Oc(templates[0])
+		assert.Nil(t, err)
+		w.CloseOc(oc)
+		replacement, err := w.Oc(templates[0])
+		assert.Nil(t, err)
+		assert.NotSame(t, oc, replacement)
+	})
+	assert.Equal(t, int32(1), atomic.LoadInt32(closed))
+
+	pool.Close()
+	assert.Equal(t, atomic.LoadInt32(spawned), atomic.LoadInt32(closed))
+}
+
+func TestLimit(t *testing.T) {
+	t.Setenv(LimitEnvironmentVariableKey, "")
+	assert.Equal(t, DefaultLimit, Limit())
+	t.Setenv(LimitEnvironmentVariableKey, "16")
+	assert.Equal(t, 16, Limit())
+	t.Setenv(LimitEnvironmentVariableKey, "0")
+	assert.Equal(t, DefaultLimit, Limit())
+	t.Setenv(LimitEnvironmentVariableKey, "many")
+	assert.Equal(t, DefaultLimit, Limit())
+}
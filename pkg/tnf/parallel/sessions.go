@@ -0,0 +1,69 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package parallel
+
+import (
+	"fmt"
+
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+)
+
+// Worker holds the sessions used by one goroutine of a Run.  They are the sessions of a slot of the Runner
+// interactive.SessionPool, leased for the Run, so that no other goroutine uses them and the next Runs reuse them as long
+// as the pool keeps them open, see interactive.SessionPool.MaxIdleSessions.  Its methods must only be called by the task
+// it is given to.
+type Worker struct {
+	// ID identifies the worker within its Run, starting at 0.
+	ID   int
+	pool *interactive.SessionPool
+	slot int
+}
+
+func newWorker(id int, pool *interactive.SessionPool) *Worker {
+	return &Worker{ID: id, pool: pool, slot: pool.LeaseSlot()}
+}
+
+// Oc returns the worker session to the container of template, which is typically the shared session of a
+// configsections.Container or of a node debug container.  template itself is never used by the worker.
+func (w *Worker) Oc(template *interactive.Oc) (*interactive.Oc, error) {
+	target := template.Target().WithSlot(w.slot)
+	oc, err := w.pool.GetOc(target)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a session to %s: %s", target, err)
+	}
+	return oc, nil
+}
+
+// CloseOc ends the worker session to the container of oc, e.g. after a timeout left it in an unknown state.  The next
+// call to Oc creates a new session.
+func (w *Worker) CloseOc(oc *interactive.Oc) {
+	w.pool.Remove(oc.Target().WithSlot(w.slot))
+}
+
+// LocalShell returns the worker local shell session.
+func (w *Worker) LocalShell() (*interactive.Context, error) {
+	context, err := w.pool.Get(interactive.LocalTarget().WithSlot(w.slot))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a local shell session: %s", err)
+	}
+	return context, nil
+}
+
+// release gives the worker sessions back to the pool.
+func (w *Worker) release() {
+	w.pool.ReleaseSlot(w.slot)
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package reel
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// StderrSentinel separates the output of a command from its standard error, when it is captured.
+	StderrSentinel = `START_OF_STDERR_SENTINEL`
+
+	// stderrFileVariable and exitCodeVariable are the shell variables holding the file capturing the standard error of
+	// a command, and its exit code.
+	stderrFileVariable = "TNF_STDERR_FILE"
+	exitCodeVariable   = "TNF_EXIT_CODE"
+)
+
+// A MatchEvent is the outcome of the command of a Step with expectations.
+type MatchEvent struct {
+	// Pattern is the first expectation matched by the command output, or an empty string when none matched.
+	Pattern string
+	// ExitCode is the exit code of the command, or -1 when it is unknown, see Output.ExitCode.
+	ExitCode int
+	// Stderr is the standard error of the command, when the Step captured it.
+	Stderr string
+	// Duration is the time from sending the command to the end of its output.
+	Duration time.Duration
+	// Output is the command output.  It is only valid during the call to EventHandler.ReelMatchEvent.
+	Output *Output
+}
+
+// Stdout returns the output of the command.
+func (e *MatchEvent) Stdout() string {
+	return e.Output.String()
+}
+
+// An EventHandler is a Handler which is informed of the outcome of the command of every Step with expectations,
+// including the commands which fail or whose output matches none of the expectations.  A command exiting with a
+// non-zero code is then not an error of the Reel, it is up to the handler.
+type EventHandler interface {
+	Handler
+
+	// ReelMatchEvent informs of the outcome of a command, returning the next step to perform.
+	ReelMatchEvent(event *MatchEvent) *Step
+}
+
+// DispatchMatchEvent informs handler of event.  An EventHandler gets event as it is, any other Handler only gets the
+// match of a successful command, see MatchOutput.
+func DispatchMatchEvent(handler Handler, event *MatchEvent) *Step {
+	if eventHandler, ok := handler.(EventHandler); ok {
+		return eventHandler.ReelMatchEvent(event)
+	}
+	if event.Pattern == "" || event.ExitCode > 0 {
+		return nil
+	}
+	return MatchOutput(handler, event.Pattern, event.Output)
+}
+
+// WrapTestCommandWithStderr wraps cmd as WrapTestCommand does, and captures its standard error in a temporary file,
+// which follows the output of cmd after StderrSentinel.
+func WrapTestCommandWithStderr(cmd string) string {
+	cmd = strings.TrimRight(cmd, "\n")
+	wrappedCommand := fmt.Sprintf(`%[1]s=$(mktemp 2>/dev/null || echo /tmp/tnf-stderr-$$) ; { %[3]s ; } 2>"$%[1]s" ; `+
+		`%[2]s=$? ; echo %[4]s ; cat "$%[1]s" ; rm -f "$%[1]s" ; echo %[5]s %[6]s$%[2]s`+"\n",
+		stderrFileVariable, exitCodeVariable, cmd, StderrSentinel, EndOfTestSentinel, ExitKeyword)
+	log.Tracef("Command sent: %s", wrappedCommand)
+	return wrappedCommand
+}
+
+// wrapStepCommand wraps the command of step, capturing its standard error when asked to.
+func (r *Reel) wrapStepCommand(step *Step) string {
+	if step.Stderr && !r.disableTerminalPromptEmulation {
+		return WrapTestCommandWithStderr(step.Execute)
+	}
+	return r.wrapTestCommand(step.Execute)
+}
+
+// newOutput creates the Output of step.
+func (r *Reel) newOutput(step *Step) *Output {
+	return &Output{reel: r, spool: newSpool(r.spoolThreshold, r.spoolDir), captureStderr: step.Stderr}
+}
+
+// completeOutput returns the Output of text, the output of step matched by goexpect, when handler is an EventHandler
+// or the standard error of step is captured.  Otherwise, or when the output cannot be parsed, it returns nil.
+func (r *Reel) completeOutput(step *Step, handler Handler, text string) *Output {
+	if _, ok := handler.(EventHandler); (!ok && !step.Stderr) || r.disableTerminalPromptEmulation {
+		return nil
+	}
+	output := r.newOutput(step)
+	complete, err := output.write(newLineNormalizer(r.normalizers, r.sent).normalize([]byte(text)))
+	if err != nil || !complete {
+		output.close()
+		return nil
+	}
+	return output
+}
+
+// completeStep informs handler of the complete output of step, whose command was sent at start, and returns the next
+// step.
+func (r *Reel) completeStep(step *Step, output *Output, handler Handler, start time.Time) *Step {
+	log.Debugf("command status: size=%d, spooled=%t, status=%d", output.Size(), output.Path() != "", output.status)
+	// The echo of the command, if any, precedes the emulated terminal prompt.
+	r.sent = ""
+	eventHandler, events := handler.(EventHandler)
+	if !events && output.status != 0 {
+		r.Err = fmt.Errorf("error executing command exit code:%d", output.status)
+		return nil
+	}
+	var pattern string
+	for _, expectation := range step.Expect {
+		if output.find(expectation) {
+			pattern = expectation
+			break
+		}
+	}
+	if events {
+		return eventHandler.ReelMatchEvent(&MatchEvent{
+			Pattern:  pattern,
+			ExitCode: output.ExitCode(),
+			Stderr:   output.Stderr(),
+			Duration: time.Since(start),
+			Output:   output,
+		})
+	}
+	if pattern == "" {
+		// The command output matches none of the expectations, like the extra case of generateCases().
+		return nil
+	}
+	return MatchOutput(handler, pattern, output)
+}
+
+// splitStderr separates the standard error of the command from its output.
+func (o *Output) splitStderr() error {
+	sentinel := StderrSentinel + "\n"
+	index, err := lastIndex(o.spool.readerAt(), o.prompt, []byte(sentinel))
+	if err != nil || index < 0 {
+		return err
+	}
+	o.stdoutEnd = index
+	o.stderr = strings.TrimRight(o.text(index+int64(len(sentinel)), o.prompt), "\n")
+	return nil
+}
+
+// lastIndex returns the offset of the last occurrence of sep in the first size bytes of reader, or -1 if there is none.
+func lastIndex(reader io.ReaderAt, size int64, sep []byte) (int64, error) {
+	buffer := make([]byte, spoolReadSize+len(sep))
+	for end := size; end > 0; {
+		start := end - spoolReadSize
+		if start < 0 {
+			start = 0
+		}
+		// Overlap the previous block, for sep to be found across blocks.
+		stop := end + int64(len(sep)) - 1
+		if stop > size {
+			stop = size
+		}
+		n, err := reader.ReadAt(buffer[:stop-start], start)
+		if err != nil && err != io.EOF {
+			return -1, err
+		}
+		if i := bytes.LastIndex(buffer[:n], sep); i >= 0 {
+			return start + int64(i), nil
+		}
+		end = start
+	}
+	return -1, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package reel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+// eventRecorder records the match events of a reel.Reel.
+type eventRecorder struct {
+	matchRecorder
+	events []reel.MatchEvent
+	stdout []string
+}
+
+func (e *eventRecorder) ReelMatchEvent(event *reel.MatchEvent) *reel.Step {
+	e.events = append(e.events, *event)
+	if event.Output != nil {
+		e.stdout = append(e.stdout, event.Stdout())
+	}
+	return nil
+}
+
+func TestReel_StepEvent(t *testing.T) {
+	testCases := map[string]struct {
+		step     reel.Step
+		pattern  string
+		exitCode int
+		stdout   string
+		stderr   string
+	}{
+		"success": {
+			step:    reel.Step{Execute: "echo ok", Expect: []string{"missing", "ok"}},
+			pattern: "ok",
+			stdout:  "ok",
+		},
+		"failure": {
+			step:     reel.Step{Execute: "echo failed; (exit 3)", Expect: []string{"failed"}},
+			pattern:  "failed",
+			exitCode: 3,
+			stdout:   "failed",
+		},
+		"no_output": {
+			step:     reel.Step{Execute: "false", Expect: []string{"(?s).+"}},
+			exitCode: 1,
+		},
+		"stderr": {
+			step:     reel.Step{Execute: "echo out; echo err >&2; printf 'more'; (exit 4)", Expect: []string{"(?s).+"}, Stderr: true},
+			pattern:  "(?s).+",
+			exitCode: 4,
+			stdout:   "out\nmore",
+			stderr:   "err",
+		},
+		"stderr_without_newline": {
+			step:    reel.Step{Execute: "printf out; printf err >&2", Expect: []string{"^out"}, Stderr: true},
+			pattern: "^out",
+			stdout:  "out",
+			stderr:  "err",
+		},
+	}
+
+	for name, testCase := range testCases {
+		step := testCase.step
+		step.Timeout = 5 * time.Second
+		for _, opts := range [][]reel.Option{nil, {reel.StreamOutput(false)}, {reel.SpoolOutput(1, t.TempDir())}} {
+			handler := &eventRecorder{}
+			assert.Nil(t, runStep(t, &step, handler, opts...), name)
+			if assert.Len(t, handler.events, 1, name) {
+				event := handler.events[0]
+				assert.Equal(t, testCase.pattern, event.Pattern, name)
+				assert.Equal(t, testCase.exitCode, event.ExitCode, name)
+				assert.Equal(t, testCase.stderr, event.Stderr, name)
+				assert.Equal(t, testCase.stdout, handler.stdout[0], name)
+				assert.Greater(t, int64(event.Duration), int64(0), name)
+			}
+		}
+	}
+}
+
+func TestReel_StepStderr(t *testing.T) {
+	// Handlers which are not EventHandlers get the output without the standard error.
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: "echo out; echo err >&2", Expect: []string{"(?m)^out"}, Stderr: true, Timeout: 5 * time.Second}
+	assert.Nil(t, runStep(t, step, handler))
+	assert.Equal(t, "out", handler.match)
+
+	step = &reel.Step{Execute: "echo err >&2; (exit 2)", Expect: []string{"(?m).*"}, Stderr: true, Timeout: 5 * time.Second}
+	assert.EqualError(t, runStep(t, step, &matchRecorder{}), "error executing command exit code:2")
+}
+
+func TestDispatchMatchEvent(t *testing.T) {
+	eventHandler := &eventRecorder{}
+	event := &reel.MatchEvent{ExitCode: 1}
+	assert.Nil(t, reel.DispatchMatchEvent(eventHandler, event))
+	assert.Len(t, eventHandler.events, 1)
+
+	// Only the successful commands matching an expectation are passed to the other handlers.
+	handler := &matchRecorder{}
+	assert.Nil(t, reel.DispatchMatchEvent(handler, event))
+	assert.Nil(t, reel.DispatchMatchEvent(handler, &reel.MatchEvent{}))
+	assert.Equal(t, matchRecorder{}, *handler)
+}
+
+func TestWrapTestCommandWithStderr(t *testing.T) {
+	wrapped := reel.WrapTestCommandWithStderr("ls\n")
+	assert.Contains(t, wrapped, "{ ls ; } 2>")
+	assert.Contains(t, wrapped, "echo "+reel.StderrSentinel+" ;")
+	assert.Regexp(t, reel.EndOfTestSentinel+" "+reel.ExitKeyword+`\$\w+\n$`, wrapped)
+}
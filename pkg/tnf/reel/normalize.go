@@ -0,0 +1,199 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package reel
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// NormalizerANSI is the name of StripANSI.
+	NormalizerANSI = "ansi"
+	// NormalizerCRLF is the name of TrimCarriageReturns.
+	NormalizerCRLF = "crlf"
+	// NormalizerEcho is the name of StripEcho.
+	NormalizerEcho = "echo"
+	// NormalizerNone disables the normalization.
+	NormalizerNone = "none"
+
+	// normalizersEnvironmentVariableKey is the OS environment variable name to select the normalizers.
+	normalizersEnvironmentVariableKey = "TNF_REEL_NORMALIZERS"
+
+	// maxPendingLineSize bounds the size of an incomplete line held back before it is normalized.
+	maxPendingLineSize = 64 * 1024
+)
+
+var (
+	// ansiEscapeSequence matches the CSI sequences, e.g. colors and bracketed-paste markers, the OSC sequences, e.g.
+	// window titles, and the other escape sequences, e.g. character set selections.
+	ansiEscapeSequence = regexp.MustCompile("\x1b(\\[[0-?]*[ -/]*[@-~]|\\][^\x07\x1b]*(\x07|\x1b\\\\)|[()][0-9A-Za-z]|[@-Z\\\\^_])")
+
+	// namedNormalizers are the normalizers which can be selected by name, in the order they are applied.
+	namedNormalizers = []struct {
+		name       string
+		normalizer Normalizer
+	}{
+		{name: NormalizerANSI, normalizer: StripANSI},
+		{name: NormalizerCRLF, normalizer: TrimCarriageReturns},
+		{name: NormalizerEcho, normalizer: StripEcho},
+	}
+)
+
+// A Normalizer removes terminal noise from a line of the output of command before it is matched.  line has no line
+// ending, and index is its index in the output.  It returns the normalized line, or false to drop it.  command is the
+// command as it was sent, including the emulated terminal prompt.
+type Normalizer func(command string, index int, line string) (string, bool)
+
+// StripANSI removes the ANSI escape sequences, such as colors, window titles and bracketed-paste markers.
+func StripANSI(_ string, _ int, line string) (string, bool) {
+	if !strings.Contains(line, "\x1b") {
+		return line, true
+	}
+	return ansiEscapeSequence.ReplaceAllString(line, ""), true
+}
+
+// TrimCarriageReturns removes the carriage returns ending line, i.e. turns the "\r\n" line endings of a terminal into
+// "\n".
+func TrimCarriageReturns(_ string, _ int, line string) (string, bool) {
+	return strings.TrimRight(line, "\r"), true
+}
+
+// StripEcho drops the lines echoing command, such as a terminal does.  The echo may follow a shell prompt.
+func StripEcho(command string, index int, line string) (string, bool) {
+	command = strings.TrimRight(command, "\n")
+	if command == "" || index > strings.Count(command, "\n") {
+		return line, true
+	}
+	if strings.HasSuffix(strings.TrimRight(line, " "), strings.Split(command, "\n")[index]) {
+		return "", false
+	}
+	return line, true
+}
+
+// NormalizeOutput normalizes the output of the steps with normalizers, in order, before it is matched.  The output is
+// streamed line by line through the normalizers.  The output of the steps which cannot be streamed is matched as the
+// session delivers it, which the sessions spawned by interactive normalize as well, then the normalized `before` and
+// `match` are passed to Handler.ReelMatch.  By default, the normalizers are the
+// ones named by TNF_REEL_NORMALIZERS, as a comma separated list of NormalizerANSI, NormalizerCRLF and NormalizerEcho,
+// or all of them when it is not set.
+func NormalizeOutput(normalizers ...Normalizer) Option {
+	return func(r *Reel) Option {
+		prev := r.normalizers
+		r.normalizers = normalizers
+		return NormalizeOutput(prev...)
+	}
+}
+
+// GetNormalizers returns the normalizers named by names, in the order they are applied.  Unknown names are ignored.
+func GetNormalizers(names []string) []Normalizer {
+	var normalizers []Normalizer
+	for _, named := range namedNormalizers {
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSpace(name), named.name) {
+				normalizers = append(normalizers, named.normalizer)
+				break
+			}
+		}
+	}
+	return normalizers
+}
+
+// DefaultNormalizers returns the normalizers named by TNF_REEL_NORMALIZERS, or all of them when it is not set.
+func DefaultNormalizers() []Normalizer {
+	namesFromEnv, ok := os.LookupEnv(normalizersEnvironmentVariableKey)
+	if !ok {
+		return GetNormalizers([]string{NormalizerANSI, NormalizerCRLF, NormalizerEcho})
+	}
+	log.Debugf("Utilizing the normalizers as sourced from %s: %s", normalizersEnvironmentVariableKey, namesFromEnv)
+	return GetNormalizers(strings.Split(namesFromEnv, ","))
+}
+
+// lineNormalizer applies normalizers to the lines of the output of command, as it is streamed.
+type lineNormalizer struct {
+	normalizers []Normalizer
+	command     string
+	// index is the index of the next line, and pending the incomplete line held back until its line ending.
+	index   int
+	pending []byte
+}
+
+func newLineNormalizer(normalizers []Normalizer, command string) *lineNormalizer {
+	return &lineNormalizer{normalizers: normalizers, command: strings.TrimRight(command, "\n")}
+}
+
+// normalize returns the normalized complete lines of p, holding back its last line if it is incomplete.
+func (n *lineNormalizer) normalize(p []byte) []byte {
+	if len(n.normalizers) == 0 {
+		return p
+	}
+	n.pending = append(n.pending, p...)
+	var normalized bytes.Buffer
+	normalized.Grow(len(n.pending))
+	start := 0
+	for end := bytes.IndexByte(n.pending, '\n'); end >= 0; end = bytes.IndexByte(n.pending[start:], '\n') {
+		n.normalizeLine(&normalized, string(n.pending[start:start+end]), "\n")
+		start += end + 1
+	}
+	n.pending = append(n.pending[:0], n.pending[start:]...)
+	if len(n.pending) > maxPendingLineSize {
+		// Normalize a very long line in parts, the next part being the same line.
+		n.normalizeLine(&normalized, string(n.pending), "")
+		n.index--
+		n.pending = n.pending[:0]
+	}
+	return normalized.Bytes()
+}
+
+// normalizeLine writes line, normalized, followed by ending unless it is dropped.
+func (n *lineNormalizer) normalizeLine(normalized *bytes.Buffer, line, ending string) {
+	index := n.index
+	n.index++
+	for _, normalizer := range n.normalizers {
+		var keep bool
+		if line, keep = normalizer(n.command, index, line); !keep {
+			return
+		}
+	}
+	normalized.WriteString(line)
+	normalized.WriteString(ending)
+}
+
+// normalizeText applies normalizers to the lines of text, which starts at the line index of the output of command.
+func normalizeText(normalizers []Normalizer, command string, index int, text string) string {
+	if len(normalizers) == 0 {
+		return text
+	}
+	n := newLineNormalizer(normalizers, command)
+	n.index = index
+	normalized := n.normalize([]byte(text + "\n"))
+	return strings.TrimSuffix(string(normalized), "\n")
+}
+
+// normalize returns the normalized before and match of a step which was not streamed, the output of command.  match is
+// at matchIndex in output.
+func (r *Reel) normalize(command, output, before, match string, matchIndex int) (normalizedBefore, normalizedMatch string) {
+	index := 0
+	if matchIndex > 0 {
+		index = strings.Count(output[:matchIndex], "\n")
+	}
+	return normalizeText(r.normalizers, command, 0, before), normalizeText(r.normalizers, command, index, match)
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package reel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+// terminalScript runs the commands it reads as a terminal would show them:  echoed after a colored prompt, with "\r\n"
+// line endings.
+const terminalScript = `while IFS= read -r line; do
+	printf '\033[01;32m$\033[00m %s\r\n' "$line"
+	eval "$line" | sed 's/$/\r/'
+done`
+
+func TestStripANSI(t *testing.T) {
+	testCases := map[string]string{
+		"plain":                       "plain",
+		"\x1b[01;32mok\x1b[0m":        "ok",
+		"\x1b[?2004hpaste\x1b[?2004l": "paste",
+		"\x1b]0;title\x07prompt$ ":    "prompt$ ",
+		"\x1b(Bcharset":               "charset",
+	}
+	for line, expected := range testCases {
+		normalized, keep := reel.StripANSI("", 0, line)
+		assert.True(t, keep)
+		assert.Equal(t, expected, normalized)
+	}
+}
+
+func TestTrimCarriageReturns(t *testing.T) {
+	normalized, keep := reel.TrimCarriageReturns("", 0, "line\r")
+	assert.True(t, keep)
+	assert.Equal(t, "line", normalized)
+	normalized, _ = reel.TrimCarriageReturns("", 0, "progress\rline")
+	assert.Equal(t, "progress\rline", normalized)
+}
+
+func TestStripEcho(t *testing.T) {
+	command := "cat <<EOF\nline\nEOF ; echo END_OF_TEST_SENTINEL exit=$?\n"
+	testCases := []struct {
+		index int
+		line  string
+		keep  bool
+	}{
+		{index: 0, line: "sh-4.4$ cat <<EOF", keep: false},
+		{index: 1, line: "> line", keep: false},
+		{index: 2, line: "> EOF ; echo END_OF_TEST_SENTINEL exit=$?", keep: false},
+		{index: 3, line: "line", keep: true},
+		{index: 0, line: "cat", keep: true},
+	}
+	for _, testCase := range testCases {
+		normalized, keep := reel.StripEcho(command, testCase.index, testCase.line)
+		assert.Equal(t, testCase.keep, keep, testCase.line)
+		if keep {
+			assert.Equal(t, testCase.line, normalized)
+		}
+	}
+}
+
+func TestGetNormalizers(t *testing.T) {
+	assert.Len(t, reel.GetNormalizers([]string{"echo", " ANSI", "unknown"}), 2)
+	assert.Len(t, reel.GetNormalizers([]string{reel.NormalizerNone}), 0)
+}
+
+func TestReel_StepNormalized(t *testing.T) {
+	expecter, errorChannel := spawnCommand(t, "sh", "-c", terminalScript)
+	r, err := reel.NewReel(expecter, nil, errorChannel)
+	assert.Nil(t, err)
+
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: `printf '\033[1mfirst\033[0m\nsecond\n'`, Expect: []string{`(?m)^first$`}, Timeout: 5 * time.Second}
+	assert.Nil(t, r.Step(step, handler))
+	assert.Equal(t, "first\nsecond", handler.match)
+	assert.Equal(t, "", handler.before)
+
+	// Without the echo of the emulated terminal prompt, the exit code is known.
+	step = &reel.Step{Execute: "echo failed; (exit 3)", Expect: []string{"failed"}, Timeout: 5 * time.Second}
+	assert.EqualError(t, r.Step(step, &matchRecorder{}), "error executing command exit code:3")
+}
+
+func TestReel_StepNotNormalized(t *testing.T) {
+	expecter, errorChannel := spawnCommand(t, "sh", "-c", terminalScript)
+	r, err := reel.NewReel(expecter, nil, errorChannel, reel.NormalizeOutput())
+	assert.Nil(t, err)
+
+	// Without normalization, the emulated terminal prompt ending with "\r\n" is not found.
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: "echo ready", Expect: []string{"ready"}, Timeout: 200 * time.Millisecond}
+	assert.True(t, reel.IsTimeout(r.Step(step, handler)))
+	assert.True(t, handler.timeout)
+}
@@ -17,6 +17,8 @@
 package reel
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -47,6 +49,9 @@ var (
 	// output, the shell might also return a prompt which is not desired. Note: this is currently the same as the string above
 	// but was splitted for clarity
 	EndOfTestRegexPostfix = matchSentinel
+
+	// ErrCancelled is wrapped by the error of a Reel whose context was cancelled or exceeded its deadline.
+	ErrCancelled = errors.New("reel cancelled")
 )
 
 // Step is an instruction for a single REEL pass.
@@ -62,6 +67,9 @@ type Step struct {
 
 	// Timeout is the timeout for the Step.  A positive Timeout prevents blocking forever.
 	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Stderr captures the standard error of Execute apart from its output, see WrapTestCommandWithStderr.
+	Stderr bool `json:"stderr,omitempty" yaml:"stderr,omitempty"`
 }
 
 // A utility method to return the important aspects of the Step container as a tuple.
@@ -98,6 +106,28 @@ type StepFunc func(Handler) *Step
 // Option is a function pointer to enable lightweight optionals for Reel.
 type Option func(reel *Reel) Option
 
+// Recorder receives the commands executed by a Reel, e.g. to write them to a transcript.
+type Recorder interface {
+	// RecordExecute is called with every Step.Execute (and the initial command line) before it is sent to expecter.
+	RecordExecute(expecter expect.Expecter, execute string)
+}
+
+// Interrupter is implemented by the expect.Expecter which can abort the expectation in progress and stay usable, e.g.
+// by replacing its underlying session.  The other expecters are closed when a reel is cancelled during a step.
+type Interrupter interface {
+	// Interrupt aborts the expectation in progress, if any.
+	Interrupt()
+}
+
+// defaultRecorder receives the commands executed by the reels which are not given a Recorder.
+var defaultRecorder Recorder
+
+// SetDefaultRecorder sets the Recorder of the reels created without RecordSteps.  A nil recorder disables the
+// recording.
+func SetDefaultRecorder(recorder Recorder) {
+	defaultRecorder = recorder
+}
+
 // A Reel instance allows interaction with a target subprocess.
 type Reel struct {
 	// A pointer to the underlying subprocess
@@ -105,6 +135,37 @@ type Reel struct {
 	Err      error
 	// disableTerminalPromptEmulation determines whether terminal prompt emulation should be disabled.
 	disableTerminalPromptEmulation bool
+	// recorder receives the executed commands, when set.
+	recorder Recorder
+	// ctx cancels the steps, when set.
+	ctx context.Context
+	// disableStreaming determines whether the output of the steps is matched by goexpect instead of being streamed.
+	disableStreaming bool
+	// spoolThreshold is the size above which the output of a streamed step is spooled to a file in spoolDir.
+	spoolThreshold int64
+	spoolDir       string
+	// normalizers remove terminal noise from the output, and sent is the last command sent, which may be echoed.
+	normalizers []Normalizer
+	sent        string
+}
+
+// WithContext stops the reel.Reel when ctx is cancelled or exceeds its deadline.  The handler is then informed with
+// ReelEOF, and the reel returns an error wrapping ErrCancelled.  A deadline also bounds the timeout of every Step.
+func WithContext(ctx context.Context) Option {
+	return func(r *Reel) Option {
+		prev := r.ctx
+		r.ctx = ctx
+		return WithContext(prev)
+	}
+}
+
+// RecordSteps sends the commands executed by the reel.Reel to recorder.
+func RecordSteps(recorder Recorder) Option {
+	return func(r *Reel) Option {
+		prev := r.recorder
+		r.recorder = recorder
+		return RecordSteps(prev)
+	}
 }
 
 // DisableTerminalPromptEmulation disables terminal prompt emulation for the reel.Reel.
@@ -164,10 +225,12 @@ func (r *Reel) generateCase(expectation string, firstMatch *string) *expect.Case
 // Each Step can have exactly one execution string (Step.Execute). This method follows the Adapter design pattern;  a
 // single raw execution string is converted into a corresponding expect.Batcher.  The function returns an array of
 // expect.Batcher, as it is expected that there are likely expectations to follow.
-func (r *Reel) generateBatcher(execute string) []expect.Batcher {
+func (r *Reel) generateBatcher(step *Step) []expect.Batcher {
 	var batcher []expect.Batcher
-	if execute != "" {
-		execute = r.wrapTestCommand(execute)
+	if execute := step.Execute; execute != "" {
+		r.recordExecute(execute)
+		execute = r.wrapStepCommand(step)
+		r.sent = execute
 		batcher = append(batcher, &expect.BSnd{S: execute})
 	}
 	return batcher
@@ -179,6 +242,96 @@ func IsTimeout(err error) bool {
 	return ok
 }
 
+// IsCancelled determines if an error comes from a Reel whose context was cancelled.
+func IsCancelled(err error) bool {
+	return errors.Is(err, ErrCancelled)
+}
+
+// cancelledError is the error of a cancelled Reel.  It is ErrCancelled, and wraps the error of the context.
+type cancelledError struct {
+	cause error
+}
+
+func (e *cancelledError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCancelled, e.cause)
+}
+
+func (e *cancelledError) Is(target error) bool {
+	return target == ErrCancelled
+}
+
+func (e *cancelledError) Unwrap() error {
+	return e.cause
+}
+
+// cancelled returns the cancellation error of the reel, or nil when its context is not done.
+func (r *Reel) cancelled() error {
+	if r.ctx == nil || r.ctx.Err() == nil {
+		return nil
+	}
+	return &cancelledError{cause: r.ctx.Err()}
+}
+
+// stepTimeout returns timeout bounded by the deadline of the context, if any, and whether it was bounded.
+func (r *Reel) stepTimeout(timeout time.Duration) (time.Duration, bool) {
+	deadline, ok := r.ctx.Deadline()
+	if !ok {
+		return timeout, false
+	}
+	// A zero timeout is the expect.DefaultTimeout, keep it positive.
+	remaining := time.Until(deadline)
+	if remaining >= timeout && timeout > 0 {
+		return timeout, false
+	}
+	if remaining <= 0 {
+		remaining = time.Nanosecond
+	}
+	return remaining, true
+}
+
+// expectBatch runs the batchers, returning early when the context of the reel is cancelled.  The batch itself keeps
+// waiting in the background until it matches or times out.
+func (r *Reel) expectBatch(batchers []expect.Batcher, timeout time.Duration) ([]expect.BatchRes, error) {
+	if r.ctx == nil || r.ctx.Done() == nil {
+		return (*r.expecter).ExpectBatch(batchers, timeout)
+	}
+	type batchResult struct {
+		results []expect.BatchRes
+		err     error
+	}
+	timeout, bounded := r.stepTimeout(timeout)
+	done := make(chan batchResult, 1)
+	go func() {
+		results, err := (*r.expecter).ExpectBatch(batchers, timeout)
+		done <- batchResult{results: results, err: err}
+	}()
+	select {
+	case result := <-done:
+		if bounded && IsTimeout(result.err) {
+			// The batch timed out at the deadline, which the context may not have noticed yet.
+			<-r.ctx.Done()
+			return nil, r.cancelled()
+		}
+		return result.results, result.err
+	case <-r.ctx.Done():
+		// Stop the batch, so that it does not consume the output of the commands sent next to the session.
+		r.interrupt()
+		<-done
+		return nil, r.cancelled()
+	}
+}
+
+// interrupt aborts the expectation in progress on the expecter, closing it if it is not an Interrupter.
+func (r *Reel) interrupt() {
+	if interrupter, ok := (*r.expecter).(Interrupter); ok {
+		interrupter.Interrupt()
+		return
+	}
+	if err := (*r.expecter).Close(); err != nil {
+		log.Debugf("Closing the session of the cancelled reel: %v", err)
+	}
+}
+
 // Step performs `step`, then, in response to events, consequent steps fed by `handler`.
 // Return on first error, or when there is no next step to perform.
 func (r *Reel) Step(step *Step, handler Handler) error {
@@ -186,13 +339,29 @@ func (r *Reel) Step(step *Step, handler Handler) error {
 		if r.Err != nil {
 			return r.Err
 		}
-		exec, exp, timeout := step.unpack()
+		if err := r.cancelled(); err != nil {
+			r.Err = err
+			handler.ReelEOF()
+			return r.Err
+		}
+		if reader, ok := r.streamReader(step); ok {
+			step = r.streamStep(step, reader, handler)
+			continue
+		}
+		_, exp, timeout := step.unpack()
+		start := time.Now()
 		var batchers []expect.Batcher
-		batchers = r.generateBatcher(exec)
+		batchers = r.generateBatcher(step)
 		// firstMatchRe is the first regular expression (expectation) that has matched results
 		var firstMatchRe string
 		batchers = r.batchExpectations(exp, batchers, &firstMatchRe)
-		results, err := (*r.expecter).ExpectBatch(batchers, timeout)
+		results, err := r.expectBatch(batchers, timeout)
+		if cancelErr := r.cancelled(); cancelErr != nil {
+			// A match or a timeout at the deadline of the context is a cancellation as well.
+			r.Err = cancelErr
+			handler.ReelEOF()
+			return r.Err
+		}
 		if !step.hasExpectations() {
 			return nil
 		}
@@ -206,6 +375,14 @@ func (r *Reel) Step(step *Step, handler Handler) error {
 			}
 		} else if len(results) > 0 {
 			result := results[0]
+			if output := r.completeOutput(step, handler, result.Output); output != nil {
+				step = r.completeStep(step, output, handler, start)
+				output.close()
+				continue
+			}
+			sent := r.sent
+			// The echo of the command, if any, precedes the emulated terminal prompt.
+			r.sent = ""
 			output, outputStatus := r.stripEmulatedPromptFromOutput(result.Output)
 			if outputStatus != 0 {
 				r.Err = fmt.Errorf("error executing command exit code:%d", outputStatus)
@@ -225,6 +402,7 @@ func (r *Reel) Step(step *Step, handler Handler) error {
 					before = ""
 				}
 				strippedFirstMatchRe := r.stripEmulatedRegularExpression(firstMatchRe)
+				before, match = r.normalize(sent, output, before, match, matchIndex)
 				step = handler.ReelMatch(strippedFirstMatchRe, before, match)
 			} else {
 				step = nil
@@ -249,18 +427,26 @@ func (r *Reel) createExecutableCommand(command string) string {
 // NewReel create a new `Reel` instance for interacting with a target subprocess.  The command line for the target is
 // specified by the args parameter.
 func NewReel(expecter *expect.Expecter, args []string, errorChannel <-chan error, opts ...Option) (*Reel, error) {
-	r := &Reel{}
+	r := &Reel{
+		disableStreaming: isStreamingDisabled(),
+		spoolThreshold:   getDefaultSpoolThreshold(),
+		normalizers:      DefaultNormalizers(),
+		recorder:         defaultRecorder,
+		expecter:         expecter,
+	}
 	for _, o := range opts {
 		o(r)
 	}
-	if len(args) > 0 {
+	// Do not start the command of a cancelled reel, Run reports the cancellation.
+	if len(args) > 0 && r.cancelled() == nil {
+		r.recordExecute(strings.Join(args, " "))
 		command := r.createExecutableCommand(strings.Join(args, " "))
+		r.sent = command
 		err := (*expecter).Send(command)
 		if err != nil {
 			return nil, err
 		}
 	}
-	r.expecter = expecter
 
 	go func() {
 		r.Err = <-errorChannel
@@ -268,6 +454,13 @@ func NewReel(expecter *expect.Expecter, args []string, errorChannel <-chan error
 	return r, nil
 }
 
+// recordExecute sends execute to the recorder, if any.
+func (r *Reel) recordExecute(execute string) {
+	if r.recorder != nil {
+		r.recorder.RecordExecute(*r.expecter, execute)
+	}
+}
+
 // wrapTestCommand will wrap a test command in syntax to postfix a terminal emulation prompt.
 func (r *Reel) wrapTestCommand(cmd string) string {
 	if !r.disableTerminalPromptEmulation {
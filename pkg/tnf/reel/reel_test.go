@@ -17,6 +17,7 @@
 package reel_test
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -261,3 +262,151 @@ func TestReel_Step(t *testing.T) {
 		assert.Equal(t, testCase.stepReturnErr, err)
 	}
 }
+
+type executeRecorder struct {
+	expecters []expect.Expecter
+	executed  []string
+}
+
+func (e *executeRecorder) RecordExecute(expecter expect.Expecter, execute string) {
+	e.expecters = append(e.expecters, expecter)
+	e.executed = append(e.executed, execute)
+}
+
+func TestRecordSteps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExpecter := mock_interactive.NewMockExpecter(ctrl)
+	mockExpecter.EXPECT().Send(reel.WrapTestCommand("ls")).Return(nil)
+	mockExpecter.EXPECT().ExpectBatch(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	recorder := &executeRecorder{}
+	r, err := reel.NewReel(&expecter, defaultCommand, errorChannel, reel.RecordSteps(recorder))
+	assert.Nil(t, err)
+	assert.Nil(t, r.Step(&reel.Step{Execute: "pwd"}, mock_reel.NewMockHandler(ctrl)))
+	assert.Equal(t, []string{"ls", "pwd"}, recorder.executed)
+	assert.Equal(t, []expect.Expecter{expecter, expecter}, recorder.expecters)
+}
+
+func TestWithContext_Cancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The command of a cancelled reel is not sent.
+	mockExpecter := mock_interactive.NewMockExpecter(ctrl)
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err := reel.NewReel(&expecter, defaultCommand, errorChannel, reel.WithContext(ctx))
+	assert.Nil(t, err)
+
+	handler := mock_reel.NewMockHandler(ctrl)
+	handler.EXPECT().ReelEOF()
+	err = r.Step(&reel.Step{Expect: []string{"x"}, Timeout: time.Second}, handler)
+	assert.True(t, reel.IsCancelled(err))
+	assert.True(t, errors.Is(err, reel.ErrCancelled))
+	assert.False(t, reel.IsCancelled(errReel))
+}
+
+func TestWithContext_CancelDuringStep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unblock := make(chan struct{})
+	mockExpecter := mock_interactive.NewMockExpecter(ctrl)
+	mockExpecter.EXPECT().Send(reel.WrapTestCommand("ls")).Return(nil)
+	mockExpecter.EXPECT().ExpectBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func([]expect.Batcher, time.Duration) ([]expect.BatchRes, error) {
+			<-unblock
+			return nil, errTimeout
+		})
+	// The session of the cancelled step is closed, which ends the batch.
+	mockExpecter.EXPECT().Close().DoAndReturn(func() error {
+		close(unblock)
+		return nil
+	})
+
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := reel.NewReel(&expecter, defaultCommand, errorChannel, reel.WithContext(ctx))
+	assert.Nil(t, err)
+
+	handler := mock_reel.NewMockHandler(ctrl)
+	handler.EXPECT().ReelEOF()
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err = r.Step(&reel.Step{Expect: []string{"x"}, Timeout: time.Hour}, handler)
+	assert.True(t, reel.IsCancelled(err))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+// interruptibleExpecter is an expect.Expecter implementing reel.Interrupter.
+type interruptibleExpecter struct {
+	*mock_interactive.MockExpecter
+	interrupt chan struct{}
+}
+
+func (e *interruptibleExpecter) Interrupt() {
+	close(e.interrupt)
+}
+
+func TestWithContext_CancelInterrupts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExpecter := &interruptibleExpecter{MockExpecter: mock_interactive.NewMockExpecter(ctrl), interrupt: make(chan struct{})}
+	mockExpecter.EXPECT().Send(reel.WrapTestCommand("ls")).Return(nil)
+	// An Interrupter is interrupted rather than closed, and the step waits for the end of the batch.
+	batchDone := false
+	mockExpecter.EXPECT().ExpectBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func([]expect.Batcher, time.Duration) ([]expect.BatchRes, error) {
+			<-mockExpecter.interrupt
+			batchDone = true
+			return nil, errTimeout
+		})
+
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := reel.NewReel(&expecter, defaultCommand, errorChannel, reel.WithContext(ctx))
+	assert.Nil(t, err)
+
+	handler := mock_reel.NewMockHandler(ctrl)
+	handler.EXPECT().ReelEOF()
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err = r.Step(&reel.Step{Expect: []string{"x"}, Timeout: time.Hour}, handler)
+	assert.True(t, reel.IsCancelled(err))
+	assert.True(t, batchDone)
+}
+
+func TestWithContext_Deadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExpecter := mock_interactive.NewMockExpecter(ctrl)
+	mockExpecter.EXPECT().Send(reel.WrapTestCommand("ls")).Return(nil)
+	mockExpecter.EXPECT().ExpectBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ []expect.Batcher, timeout time.Duration) ([]expect.BatchRes, error) {
+			// The step timeout is bounded by the deadline.
+			assert.LessOrEqual(t, timeout, 50*time.Millisecond)
+			time.Sleep(timeout)
+			return nil, expect.TimeoutError(timeout)
+		})
+
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r, err := reel.NewReel(&expecter, defaultCommand, errorChannel, reel.WithContext(ctx))
+	assert.Nil(t, err)
+
+	handler := mock_reel.NewMockHandler(ctrl)
+	handler.EXPECT().ReelEOF()
+	err = r.Step(&reel.Step{Expect: []string{"x"}, Timeout: time.Hour}, handler)
+	assert.True(t, reel.IsCancelled(err))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,545 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package reel
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+
+	expect "github.com/google/goexpect"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultSpoolThreshold is the size in bytes above which the output of a streamed Step is spooled to a file.
+	defaultSpoolThreshold = 8 * 1024 * 1024
+	// spoolThresholdEnvironmentVariableKey is the OS environment variable name to override defaultSpoolThreshold.
+	spoolThresholdEnvironmentVariableKey = "TNF_REEL_SPOOL_THRESHOLD"
+	// streamingEnvironmentVariableKey is the OS environment variable name to disable the streaming of Step output.
+	streamingEnvironmentVariableKey = "TNF_REEL_STREAMING"
+
+	// streamChunkSize is the size of the reads from the subprocess.
+	streamChunkSize = 32 * 1024
+	// streamTailSize is the output kept from the previous chunk to find a sentinel split across chunks.
+	streamTailSize = 64
+	// minPollInterval and maxPollInterval bound the wait for new output.
+	minPollInterval = time.Millisecond
+	maxPollInterval = 20 * time.Millisecond
+	// spoolReadSize is the size of the buffered reads of a spooled output.
+	spoolReadSize = 64 * 1024
+)
+
+// sentinelLine matches the emulated terminal prompt and captures the exit code.
+var sentinelLine = regexp.MustCompile(fmt.Sprintf("%s %s([0-9]+)\n", EndOfTestSentinel, ExitKeyword))
+
+// An OutputHandler is a Handler which is informed of the match events of streamed Steps with the command output,
+// instead of the `before` and `match` strings.  It avoids copying a large output into strings.
+type OutputHandler interface {
+	Handler
+
+	// ReelMatchOutput informs of a match event, returning the next step to perform.  `pattern` represents the regular
+	// expression pattern which was matched in `output`.
+	ReelMatchOutput(pattern string, output *Output) *Step
+}
+
+// MatchOutput informs handler of the match of pattern in output.  An OutputHandler gets output as it is, any other
+// Handler gets the `before` and `match` strings of output.
+func MatchOutput(handler Handler, pattern string, output *Output) *Step {
+	if outputHandler, ok := handler.(OutputHandler); ok {
+		return outputHandler.ReelMatchOutput(pattern, output)
+	}
+	return handler.ReelMatch(pattern, output.Before(), output.Match())
+}
+
+// StreamOutput enables or disables the streaming of the output of the steps.  When enabled, which is the default
+// unless TNF_REEL_STREAMING is false, the output of a subprocess which can be read is matched incrementally as it
+// arrives, and spooled to a file once it is large.  Otherwise, the expectations are matched by goexpect.
+func StreamOutput(enabled bool) Option {
+	return func(r *Reel) Option {
+		prev := !r.disableStreaming
+		r.disableStreaming = !enabled
+		return StreamOutput(prev)
+	}
+}
+
+// SpoolOutput spools the output of the streamed steps to a file in dir once it exceeds threshold bytes.  An empty dir
+// is the default directory for temporary files, and a threshold which is not positive keeps the output in memory.
+func SpoolOutput(threshold int64, dir string) Option {
+	return func(r *Reel) Option {
+		prevThreshold, prevDir := r.spoolThreshold, r.spoolDir
+		r.spoolThreshold, r.spoolDir = threshold, dir
+		return SpoolOutput(prevThreshold, prevDir)
+	}
+}
+
+// getDefaultSpoolThreshold returns the spool threshold as sourced from TNF_REEL_SPOOL_THRESHOLD.  If
+// TNF_REEL_SPOOL_THRESHOLD is not set or cannot be parsed as an integer, defaultSpoolThreshold is returned.
+func getDefaultSpoolThreshold() int64 {
+	thresholdFromEnv := os.Getenv(spoolThresholdEnvironmentVariableKey)
+	if thresholdFromEnv != "" {
+		if threshold, err := strconv.ParseInt(thresholdFromEnv, 10, 64); err == nil {
+			log.Debugf("Utilizing spool threshold as sourced from %s: %dB", spoolThresholdEnvironmentVariableKey, threshold)
+			return threshold
+		}
+	}
+	return defaultSpoolThreshold
+}
+
+// isStreamingDisabled returns whether TNF_REEL_STREAMING disables the streaming of the output of the steps.
+func isStreamingDisabled() bool {
+	streaming, err := strconv.ParseBool(os.Getenv(streamingEnvironmentVariableKey))
+	return err == nil && !streaming
+}
+
+// streamReader returns the reader of the output of the subprocess when step is streamed.  Streaming needs the
+// emulated terminal prompt to find the end of the output, and an expecter which can be read, such as expect.GExpect.
+func (r *Reel) streamReader(step *Step) (io.Reader, bool) {
+	if r.disableStreaming || r.disableTerminalPromptEmulation || !step.hasExpectations() {
+		return nil, false
+	}
+	reader, ok := (*r.expecter).(io.Reader)
+	return reader, ok
+}
+
+// streamStep performs step by reading the output of the subprocess from reader, and returns the next step.
+func (r *Reel) streamStep(step *Step, reader io.Reader, handler Handler) *Step {
+	start := time.Now()
+	if step.Execute != "" {
+		r.recordExecute(step.Execute)
+		r.sent = r.wrapStepCommand(step)
+		if err := (*r.expecter).Send(r.sent); err != nil {
+			r.Err = err
+			return nil
+		}
+	}
+	output := r.newOutput(step)
+	defer output.close()
+	err := r.readOutput(reader, step.Timeout, newLineNormalizer(r.normalizers, r.sent), output)
+	if cancelErr := r.cancelled(); cancelErr != nil {
+		// Output ending at the deadline of the context is a cancellation as well.
+		r.Err = cancelErr
+		handler.ReelEOF()
+		return nil
+	}
+	if err != nil {
+		r.Err = err
+		if IsTimeout(err) {
+			return handler.ReelTimeout()
+		}
+		return nil
+	}
+	return r.completeStep(step, output, handler, start)
+}
+
+// readOutput reads the output of the subprocess, normalized by normalizer, into output until the emulated terminal
+// prompt.  As with goexpect, timeout is the time without any new output, and the default timeout when it is negative.
+func (r *Reel) readOutput(reader io.Reader, timeout time.Duration, normalizer *lineNormalizer, output *Output) error {
+	if timeout < 0 {
+		timeout = expect.DefaultTimeout
+	}
+	var done <-chan struct{}
+	if r.ctx != nil {
+		done = r.ctx.Done()
+	}
+	chunk := make([]byte, streamChunkSize)
+	lastOutput := time.Now()
+	interval := minPollInterval
+	for {
+		n, err := reader.Read(chunk)
+		if n > 0 {
+			complete, writeErr := output.write(normalizer.normalize(chunk[:n]))
+			if writeErr != nil || complete {
+				return writeErr
+			}
+			lastOutput = time.Now()
+			interval = minPollInterval
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		remaining := timeout - time.Since(lastOutput)
+		if remaining <= 0 {
+			return expect.TimeoutError(timeout)
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-done:
+			timer.Stop()
+			return r.cancelled()
+		case <-timer.C:
+		}
+		if interval *= 2; interval > maxPollInterval {
+			interval = maxPollInterval
+		}
+	}
+}
+
+// Output is the output of a streamed Step, which is kept in memory or spooled to a file.  It is only valid during the
+// call to OutputHandler.ReelMatchOutput, as the spooled file is removed afterwards.
+type Output struct {
+	reel  *Reel
+	spool *spool
+	// tail is the end of the output read so far, to find a sentinel split across reads.
+	tail []byte
+	// sentinels counts the occurrences of EndOfTestSentinel, including those echoed with the command.
+	sentinels int
+	// prompt and end are the start and the end of the emulated terminal prompt in the output, and status the exit
+	// code it reports.  echoed tells whether the emulated terminal prompt was echoed with the command, hiding the exit
+	// code.
+	prompt, end int64
+	status      int
+	echoed      bool
+	// stdoutEnd is the end of the standard output of the command, which is followed by StderrSentinel and its
+	// standard error when captureStderr.
+	stdoutEnd     int64
+	captureStderr bool
+	stderr        string
+	// size is the size of the command output.
+	size int64
+	// index holds the index pairs of the match and its submatches in the output.
+	index []int
+	// before and match are the legacy strings passed to Handler.ReelMatch.
+	before, match *string
+}
+
+// Size returns the size of the command output in bytes.
+func (o *Output) Size() int64 {
+	return o.size
+}
+
+// ExitCode returns the exit code of the command, or -1 when it is unknown because the terminal echoed the emulated
+// terminal prompt.
+func (o *Output) ExitCode() int {
+	if o.echoed {
+		return -1
+	}
+	return o.status
+}
+
+// Stderr returns the standard error of the command, when the Step captured it.
+func (o *Output) Stderr() string {
+	return o.stderr
+}
+
+// String returns the command output.
+func (o *Output) String() string {
+	return o.text(0, o.size)
+}
+
+// Reader returns a reader of the command output, without the emulated terminal prompt.
+func (o *Output) Reader() io.Reader {
+	return io.NewSectionReader(o.spool.readerAt(), 0, o.size)
+}
+
+// Path returns the file the output is spooled to, or an empty string when the output is kept in memory.
+func (o *Output) Path() string {
+	if o.spool.file == nil {
+		return ""
+	}
+	return o.spool.file.Name()
+}
+
+// Index returns the pairs of offsets of the match and its submatches in the output, as regexp.Regexp.FindSubmatchIndex.
+func (o *Output) Index() []int {
+	index := make([]int, len(o.index))
+	copy(index, o.index)
+	return index
+}
+
+// Submatch returns the text of the i-th submatch, the whole match being 0, or an empty string when it did not match.
+func (o *Output) Submatch(i int) string {
+	if 2*i+1 >= len(o.index) || o.index[2*i] < 0 {
+		return ""
+	}
+	return o.text(int64(o.index[2*i]), int64(o.index[2*i+1]))
+}
+
+// Before returns the output preceding Match, as Handler.ReelMatch `before`.
+func (o *Output) Before() string {
+	o.legacy()
+	return *o.before
+}
+
+// Match returns the output from the start of the match, as Handler.ReelMatch `match`.
+func (o *Output) Match() string {
+	o.legacy()
+	return *o.match
+}
+
+// legacy computes the strings goexpect would have matched, with the emulated terminal prompt stripped.  Unless the
+// command was echoed, they are read from the offsets of the match rather than from a copy of the whole output.
+func (o *Output) legacy() {
+	if o.match != nil {
+		return
+	}
+	var before, match string
+	o.before, o.match = &before, &match
+	if o.index == nil {
+		return
+	}
+	if o.echoed {
+		o.legacyEchoed()
+		return
+	}
+	start := int64(o.index[0])
+	if start >= o.size {
+		return
+	}
+	match = o.text(start, o.size)
+	if start > 0 {
+		before = o.text(0, start-1)
+	}
+}
+
+// legacyEchoed computes the legacy strings of the output of an echoed command, which holds several sentinels.
+func (o *Output) legacyEchoed() {
+	var builder strings.Builder
+	if _, err := io.Copy(&builder, o.reader(true)); err != nil {
+		log.Errorf("Cannot read the command output. Error: %s", err)
+	}
+	whole := builder.String()
+	output, _ := o.reel.stripEmulatedPromptFromOutput(whole)
+	*o.match, _ = o.reel.stripEmulatedPromptFromOutput(whole[o.index[0]:])
+	// special case:  the match regex may be nothing at all.
+	if matchIndex := strings.Index(output, *o.match); matchIndex > 0 {
+		*o.before = output[0 : matchIndex-1]
+	}
+}
+
+// text returns the output from start to end.
+func (o *Output) text(start, end int64) string {
+	var builder strings.Builder
+	builder.Grow(int(end - start))
+	if _, err := io.Copy(&builder, io.NewSectionReader(o.spool.readerAt(), start, end-start)); err != nil {
+		log.Errorf("Cannot read the command output. Error: %s", err)
+	}
+	return builder.String()
+}
+
+// write appends p to the output, and returns whether the output is complete, i.e. it ends with the emulated terminal
+// prompt.
+func (o *Output) write(p []byte) (bool, error) {
+	offset := o.spool.size - int64(len(o.tail))
+	if _, err := o.spool.Write(p); err != nil {
+		return false, err
+	}
+	window := append(o.tail, p...)
+	index := sentinelLine.FindSubmatchIndex(window)
+	searchEnd := len(window)
+	if index != nil {
+		searchEnd = index[1]
+	}
+	// Count the sentinels which were not entirely in the tail, and thus already counted.
+	for start := 0; ; {
+		i := bytes.Index(window[start:searchEnd], []byte(EndOfTestSentinel))
+		if i < 0 {
+			break
+		}
+		start += i + len(EndOfTestSentinel)
+		if start > len(o.tail) {
+			o.sentinels++
+		}
+	}
+	if index == nil {
+		if len(window) > streamTailSize {
+			window = window[len(window)-streamTailSize:]
+		}
+		o.tail = append(o.tail[:0], window...)
+		return false, nil
+	}
+	o.prompt, o.end = offset+int64(index[0]), offset+int64(index[1])
+	o.stdoutEnd = o.prompt
+	if o.sentinels > 1 {
+		// The command was echoed, with its sentinel:  as goexpect, report the whole output, and no exit code.
+		o.echoed, o.size = true, o.end
+		return true, nil
+	}
+	status, err := strconv.Atoi(string(window[index[2]:index[3]]))
+	if err != nil {
+		return false, err
+	}
+	o.status = status
+	if o.captureStderr {
+		if err := o.splitStderr(); err != nil {
+			return false, err
+		}
+	}
+	size, err := trimNewlines(o.spool.readerAt(), o.stdoutEnd)
+	o.size = size
+	return true, err
+}
+
+// find matches re against the output, and returns whether it matched.  The match is the one of goexpect, i.e. of re
+// followed by EndOfTestRegexPostfix.  Unless re asserts the end of a line, of the text or a word boundary, it is also
+// the match of re in the output preceding the emulated terminal prompt, which is much faster to find.
+func (o *Output) find(expectation string) bool {
+	re := regexp.MustCompile(expectation)
+	o.before, o.match, o.index = nil, nil, nil
+	if !assertsEnd(expectation) {
+		o.index = o.findIndex(re, false)
+		return o.index != nil
+	}
+	index := o.findIndex(regexp.MustCompile(o.reel.addEmulatedRegularExpression(expectation)), true)
+	if index == nil {
+		return false
+	}
+	// Keep the submatches of re, the match of re ending where the one of EndOfTestRegexPostfix starts.
+	postfix := 2 * (re.NumSubexp() + 1)
+	o.index = append([]int{index[0], index[postfix]}, index[2:postfix]...)
+	return true
+}
+
+// findIndex returns the submatch index of re in the standard output of the command, followed by the emulated terminal
+// prompt when withPrompt.
+func (o *Output) findIndex(re *regexp.Regexp, withPrompt bool) []int {
+	if o.spool.file == nil && !withPrompt {
+		return re.FindSubmatchIndex(o.spool.memory.Bytes()[:o.stdoutEnd])
+	}
+	if o.spool.file == nil && o.stdoutEnd == o.prompt {
+		return re.FindSubmatchIndex(o.spool.memory.Bytes()[:o.end])
+	}
+	return re.FindReaderSubmatchIndex(bufio.NewReaderSize(o.reader(withPrompt), spoolReadSize))
+}
+
+// reader returns a reader of the standard output of the command, followed by the emulated terminal prompt when
+// withPrompt.
+func (o *Output) reader(withPrompt bool) io.Reader {
+	readerAt := o.spool.readerAt()
+	stdout := io.NewSectionReader(readerAt, 0, o.stdoutEnd)
+	if !withPrompt {
+		return stdout
+	}
+	return io.MultiReader(stdout, io.NewSectionReader(readerAt, o.prompt, o.end-o.prompt))
+}
+
+// assertsEnd returns whether the regular expression asserts the end of a line, of the text or a word boundary, which
+// may hold at the end of the output but not before the emulated terminal prompt.
+func assertsEnd(expectation string) bool {
+	re, err := syntax.Parse(expectation, syntax.Perl)
+	if err != nil {
+		return true
+	}
+	return hasEndAssertion(re)
+}
+
+func hasEndAssertion(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEndLine, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasEndAssertion(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// close releases the output.
+func (o *Output) close() {
+	if err := o.spool.Close(); err != nil {
+		log.Errorf("Cannot remove the spooled command output. Error: %s", err)
+	}
+}
+
+// trimNewlines returns the size of the first size bytes of reader without their trailing newlines.
+func trimNewlines(reader io.ReaderAt, size int64) (int64, error) {
+	buffer := make([]byte, streamTailSize)
+	for size > 0 {
+		n := int64(len(buffer))
+		if n > size {
+			n = size
+		}
+		if _, err := reader.ReadAt(buffer[:n], size-n); err != nil {
+			return 0, err
+		}
+		trimmed := bytes.TrimRight(buffer[:n], "\n")
+		size -= n - int64(len(trimmed))
+		if len(trimmed) > 0 {
+			break
+		}
+	}
+	return size, nil
+}
+
+// spool stores output in memory, then in a temporary file once it exceeds threshold bytes.
+type spool struct {
+	threshold int64
+	dir       string
+	memory    bytes.Buffer
+	file      *os.File
+	size      int64
+}
+
+func newSpool(threshold int64, dir string) *spool {
+	return &spool{threshold: threshold, dir: dir}
+}
+
+// Write appends p to the spool, moving it to a temporary file when it exceeds the threshold.
+func (s *spool) Write(p []byte) (int, error) {
+	if s.file == nil && s.threshold > 0 && s.size+int64(len(p)) > s.threshold {
+		file, err := os.CreateTemp(s.dir, "tnf-reel-*.out")
+		if err != nil {
+			return 0, err
+		}
+		s.file = file
+		if _, err := s.memory.WriteTo(file); err != nil {
+			return 0, err
+		}
+	}
+	var n int
+	var err error
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.memory.Write(p)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+// readerAt returns a reader of the spooled output.
+func (s *spool) readerAt() io.ReaderAt {
+	if s.file != nil {
+		return s.file
+	}
+	return bytes.NewReader(s.memory.Bytes())
+}
+
+// Close removes the temporary file, if any.
+func (s *spool) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
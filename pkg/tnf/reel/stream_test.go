@@ -0,0 +1,251 @@
+// Copyright (C) 2020-2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package reel_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	expect "github.com/google/goexpect"
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+// spawnShell starts a shell subprocess through goexpect, as interactive.SpawnShell does.
+func spawnShell(tb testing.TB) (*expect.Expecter, <-chan error) {
+	return spawnCommand(tb, "sh")
+}
+
+// spawnCommand starts a subprocess through goexpect.
+func spawnCommand(tb testing.TB, name string, args ...string) (*expect.Expecter, <-chan error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	assert.Nil(tb, err)
+	stdout, err := cmd.StdoutPipe()
+	assert.Nil(tb, err)
+	assert.Nil(tb, cmd.Start())
+	gexpect, errorChannel, err := expect.SpawnGeneric(&expect.GenOptions{
+		In:    stdin,
+		Out:   stdout,
+		Wait:  cmd.Wait,
+		Close: cmd.Process.Kill,
+		Check: func() bool { return true },
+	}, time.Minute)
+	assert.Nil(tb, err)
+	var expecter expect.Expecter = gexpect
+	tb.Cleanup(func() {
+		_ = expecter.Close()
+	})
+	return &expecter, errorChannel
+}
+
+// matchRecorder records the match events of a reel.Reel.
+type matchRecorder struct {
+	pattern, before, match string
+	timeout, eof           bool
+}
+
+func (m *matchRecorder) ReelFirst() *reel.Step {
+	return nil
+}
+
+func (m *matchRecorder) ReelMatch(pattern, before, match string) *reel.Step {
+	m.pattern, m.before, m.match = pattern, before, match
+	return nil
+}
+
+func (m *matchRecorder) ReelTimeout() *reel.Step {
+	m.timeout = true
+	return nil
+}
+
+func (m *matchRecorder) ReelEOF() {
+	m.eof = true
+}
+
+// outputRecorder records the output of the match events of a reel.Reel.
+type outputRecorder struct {
+	matchRecorder
+	output  string
+	path    string
+	spooled bool
+	index   []int
+}
+
+func (o *outputRecorder) ReelMatchOutput(pattern string, output *reel.Output) *reel.Step {
+	o.pattern, o.path, o.index = pattern, output.Path(), output.Index()
+	if o.path != "" {
+		_, err := os.Stat(o.path)
+		o.spooled = err == nil
+	}
+	data, err := io.ReadAll(output.Reader())
+	if err == nil && int64(len(data)) == output.Size() {
+		o.output = string(data)
+	}
+	o.match = output.Submatch(1)
+	return nil
+}
+
+func runStep(t *testing.T, step *reel.Step, handler reel.Handler, opts ...reel.Option) error {
+	expecter, errorChannel := spawnShell(t)
+	r, err := reel.NewReel(expecter, nil, errorChannel, opts...)
+	assert.Nil(t, err)
+	return r.Step(step, handler)
+}
+
+func TestReel_StepStreamed(t *testing.T) {
+	testCases := map[string]struct {
+		execute string
+		expect  []string
+	}{
+		"whole_output": {
+			execute: `printf 'a\nb\n\n'`,
+			expect:  []string{`(?m).*`},
+		},
+		"second_expectation": {
+			execute: `printf 'first line\nsecond line\nthird line\n'`,
+			expect:  []string{`missing`, `second (\w+)`},
+		},
+		"submatch_at_end": {
+			execute: `printf 'key=value'`,
+			expect:  []string{`=(\w+)$`},
+		},
+		"line_end": {
+			execute: `printf 'a=1\nb=2\n\n'`,
+			expect:  []string{`(?m)^b=(\d)$`},
+		},
+		"word_boundary": {
+			execute: `printf 'one two'`,
+			expect:  []string{`\btwo\b`, `two`},
+		},
+		"no_output": {
+			execute: `true`,
+			expect:  []string{`(?m).*`},
+		},
+	}
+
+	for name, testCase := range testCases {
+		step := &reel.Step{Execute: testCase.execute, Expect: testCase.expect, Timeout: 5 * time.Second}
+		streamed := &matchRecorder{}
+		assert.Nil(t, runStep(t, step, streamed), name)
+		legacy := &matchRecorder{}
+		assert.Nil(t, runStep(t, step, legacy, reel.StreamOutput(false)), name)
+		assert.Equal(t, *legacy, *streamed, name)
+	}
+}
+
+func TestReel_StepStreamedNoMatch(t *testing.T) {
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: "echo hello", Expect: []string{"goodbye"}, Timeout: 5 * time.Second}
+	assert.Nil(t, runStep(t, step, handler))
+	assert.Equal(t, matchRecorder{}, *handler)
+}
+
+func TestReel_StepStreamedExitCode(t *testing.T) {
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: "echo failed; (exit 3)", Expect: []string{"failed"}, Timeout: 5 * time.Second}
+	err := runStep(t, step, handler)
+	assert.EqualError(t, err, "error executing command exit code:3")
+	assert.Equal(t, matchRecorder{}, *handler)
+}
+
+func TestReel_StepStreamedTimeout(t *testing.T) {
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: "echo started; sleep 5", Expect: []string{"started"}, Timeout: 100 * time.Millisecond}
+	err := runStep(t, step, handler)
+	assert.True(t, reel.IsTimeout(err))
+	assert.True(t, handler.timeout)
+}
+
+func TestReel_StepStreamedCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	handler := &matchRecorder{}
+	step := &reel.Step{Execute: "sleep 5", Expect: []string{"(?m).*"}, Timeout: 10 * time.Second}
+	start := time.Now()
+	err := runStep(t, step, handler, reel.WithContext(ctx))
+	assert.True(t, reel.IsCancelled(err))
+	assert.True(t, handler.eof)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestReel_StepStreamedSpooled(t *testing.T) {
+	dir := t.TempDir()
+	step := &reel.Step{Execute: "seq 1 20000", Expect: []string{`(?m)^(19999)$`}, Timeout: 5 * time.Second}
+	expected, err := exec.Command("seq", "1", "20000").Output()
+	assert.Nil(t, err)
+
+	handler := &outputRecorder{}
+	assert.Nil(t, runStep(t, step, handler, reel.SpoolOutput(1024, dir)))
+	assert.Equal(t, step.Expect[0], handler.pattern)
+	assert.Equal(t, "19999", handler.match)
+	assert.Equal(t, string(expected[:len(expected)-1]), handler.output)
+	assert.Equal(t, dir, handler.path[:len(dir)])
+	assert.True(t, handler.spooled)
+	// The spooled output is removed after the match event.
+	_, err = os.Stat(handler.path)
+	assert.True(t, os.IsNotExist(err))
+
+	inMemory := &outputRecorder{}
+	assert.Nil(t, runStep(t, step, inMemory, reel.SpoolOutput(0, dir)))
+	assert.Equal(t, "", inMemory.path)
+	assert.Equal(t, handler.output, inMemory.output)
+	assert.Equal(t, handler.index, inMemory.index)
+}
+
+// benchmarkStep matches the output of a command printing size bytes of JSON, as autodiscovery does.
+func benchmarkStep(b *testing.B, size int, opts ...reel.Option) {
+	expecter, errorChannel := spawnShell(b)
+	r, err := reel.NewReel(expecter, nil, errorChannel, opts...)
+	assert.Nil(b, err)
+	line := `{"kind":"Pod","metadata":{"name":"pod","namespace":"tnf"}},`
+	execute := fmt.Sprintf(`printf '{"items":['; yes '%s' | head -n %d; printf '{}]}\n'`, line, size/(len(line)+1))
+	step := &reel.Step{Execute: execute, Expect: []string{`(?m).*`}, Timeout: time.Minute}
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler := &outputRecorder{}
+		if err := r.Step(step, handler); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReel_StepStreamed1MB(b *testing.B) {
+	benchmarkStep(b, 1<<20)
+}
+
+func BenchmarkReel_StepStreamed16MB(b *testing.B) {
+	benchmarkStep(b, 16<<20)
+}
+
+func BenchmarkReel_StepStreamedSpooled16MB(b *testing.B) {
+	benchmarkStep(b, 16<<20, reel.SpoolOutput(1<<20, b.TempDir()))
+}
+
+func BenchmarkReel_StepLegacy1MB(b *testing.B) {
+	benchmarkStep(b, 1<<20, reel.StreamOutput(false))
+}
+
+func BenchmarkReel_StepLegacy4MB(b *testing.B) {
+	benchmarkStep(b, 4<<20, reel.StreamOutput(false))
+}
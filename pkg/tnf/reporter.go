@@ -0,0 +1,95 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package tnf
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// A Reporter attaches the output, the data and the outcome of the running test case to its claim result.  The default
+// Reporter only logs the output, as no test case is running;  the Ginkgo suites report to the running spec, see
+// package ginkgoadapter.
+type Reporter interface {
+	// Printf writes a line to the output of the running test case, i.e. to its claim file output.
+	Printf(format string, args ...interface{})
+	// AddEntry attaches value to the claim result of the running test case under name, and returns false when no test
+	// case is running.  It is not called concurrently.
+	AddEntry(name string, value interface{}) bool
+	// Fail ends the running test case as failed with message.  It does not return.
+	Fail(message string)
+	// Skip ends the running test case as skipped with message.  It does not return.
+	Skip(message string)
+}
+
+var (
+	// reporter is the Reporter of the running test case, see SetReporter.
+	reporter Reporter = logReporter{}
+	// addEntryMutex serializes the calls to Reporter.AddEntry.
+	addEntryMutex sync.Mutex
+)
+
+// SetReporter sets the Reporter of the running test case.  A nil r restores the default Reporter, which logs the output
+// and panics on Fail and Skip.
+func SetReporter(r Reporter) {
+	if r == nil {
+		r = logReporter{}
+	}
+	reporter = r
+}
+
+// CurrentReporter returns the Reporter of the running test case, for the helpers which end the test case when they
+// fail, e.g. Test.RunAndValidate.
+func CurrentReporter() Reporter {
+	return reporter
+}
+
+// FailOnError ends the running test case as failed when err is not nil, see Reporter.Fail.
+func FailOnError(err error) {
+	if err != nil {
+		reporter.Fail(fmt.Sprintf("Unexpected error: %v", err))
+	}
+}
+
+// addEntry attaches value to the claim result of the running test case under name.  It is safe for concurrent use.
+func addEntry(name string, value interface{}) bool {
+	addEntryMutex.Lock()
+	defer addEntryMutex.Unlock()
+	return reporter.AddEntry(name, value)
+}
+
+// logReporter is the Reporter used outside of a test case.
+type logReporter struct{}
+
+func (logReporter) Printf(format string, args ...interface{}) {
+	logrus.Infof(format, args...)
+}
+
+func (logReporter) AddEntry(name string, value interface{}) bool {
+	logrus.Debugf("No test case to attach the %s entry to: %+v", name, value)
+	return false
+}
+
+func (logReporter) Fail(message string) {
+	panic(fmt.Sprintf("no running test case to fail: %s", message))
+}
+
+func (logReporter) Skip(message string) {
+	panic(fmt.Sprintf("no running test case to skip: %s", message))
+}
@@ -0,0 +1,340 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package tnf
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	defaultRetryAttempts       = 3
+	defaultRetryInitialBackoff = time.Second
+	defaultRetryMaxBackoff     = 30 * time.Second
+	defaultRetryMultiplier     = 2
+
+	// RetriedTestEntryName is the name of the report entries of the retried tests, see Reporter.AddEntry.
+	RetriedTestEntryName = "retriedTest"
+)
+
+// RetryPolicy tells which failed attempts of a Test are retried, and how long to wait before the next attempt.  Only the
+// ERROR results are retried, never the FAILURE, SUCCESS or CANCELLED ones.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first one.  Less than 2 disables the retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts.  0 does not cap it.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the wait after every attempt.  Less than 1 keeps it constant.
+	Multiplier float64
+	// RetryOnTimeout retries the attempts which ended with a timeout.
+	RetryOnTimeout bool
+	// RetryPatterns restricts the retries of the attempts which ended after a match to the ones whose last matched
+	// pattern is listed, e.g. the pattern of a transient API error.  nil retries them all.
+	RetryPatterns []string
+}
+
+// DefaultRetryPolicy returns a policy making up to 3 attempts, 1s then 2s apart, retrying the errors and timeouts.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    defaultRetryAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		RetryOnTimeout: true,
+	}
+}
+
+// Backoff returns the wait after the attempt number attempt, starting at 1.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && p.Multiplier > 1 && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+// retryable returns true when attempt is to be retried.
+func (p *RetryPolicy) retryable(attempt *Attempt) bool {
+	if attempt.Result != ERROR || attempt.Number >= p.MaxAttempts {
+		return false
+	}
+	if attempt.Timeout {
+		return p.RetryOnTimeout
+	}
+	if attempt.Pattern == "" || p.RetryPatterns == nil {
+		return true
+	}
+	for _, pattern := range p.RetryPatterns {
+		if pattern == attempt.Pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// Attempt records one run of a Test decorated by a Retrier.
+type Attempt struct {
+	// Number is the attempt number, starting at 1.
+	Number int `json:"number"`
+	// Result is the result of the attempt, e.g. ERROR.
+	Result int `json:"result"`
+	// Pattern is the last pattern matched during the attempt, if any.
+	Pattern string `json:"pattern,omitempty"`
+	// Timeout is set when the attempt ended with a timeout.
+	Timeout bool `json:"timeout,omitempty"`
+	// Error is the error of the attempt, if any.
+	Error string `json:"error,omitempty"`
+	// Duration is how long the attempt took.
+	Duration time.Duration `json:"duration"`
+	// Backoff is the wait before the next attempt, when the attempt was retried.
+	Backoff time.Duration `json:"backoff,omitempty"`
+}
+
+// RetriedTest records the attempts of a test which was retried.  It is attached to the claim result of the test case
+// running the test.
+type RetriedTest struct {
+	// Name identifies the test, e.g. the identifier URL of its Tester.
+	Name string `json:"name"`
+	// Attempts are all the attempts of the test, the last one giving its result.
+	Attempts []Attempt `json:"attempts"`
+}
+
+// reportRetriedTest attaches the attempts of the test name to the claim result of the running test case, when the test
+// was retried.  The tests run outside of a test case, e.g. by the autodiscovery, are only logged.
+func reportRetriedTest(name string, attempts []Attempt) {
+	if len(attempts) < 2 {
+		return
+	}
+	addEntry(RetriedTestEntryName, RetriedTest{Name: name, Attempts: attempts})
+}
+
+// Retry calls attempt until it succeeds, or until policy does not retry its error, and returns the error of the last
+// attempt.  It retries the calls which are not run by a Test, e.g. the requests to an API, which are named name in the
+// logs and in the claim.  The wait before the next attempt is cut short when ctx is done.
+func Retry(ctx context.Context, name string, policy RetryPolicy, attempt func() error) error { //nolint:gocritic // The policy is copied on purpose
+	var attempts []Attempt
+	for {
+		start := time.Now()
+		err := attempt()
+		current := Attempt{Number: len(attempts) + 1, Result: SUCCESS, Duration: time.Since(start)}
+		if err != nil {
+			current.Result = ERROR
+			current.Error = err.Error()
+		}
+		retry := policy.retryable(&current)
+		if retry {
+			current.Backoff = policy.Backoff(current.Number)
+			logRetry(name, &policy, &current)
+		}
+		attempts = append(attempts, current)
+		if !retry {
+			reportRetriedTest(name, attempts)
+			return err
+		}
+		if err := waitBackoff(ctx, current.Backoff); err != nil {
+			reportRetriedTest(name, attempts)
+			return err
+		}
+	}
+}
+
+// waitBackoff waits for backoff, or until ctx is done.
+func waitBackoff(ctx context.Context, backoff time.Duration) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %s", reel.ErrCancelled, ctx.Err())
+	}
+}
+
+// logRetry logs that attempt of name is retried, in the claim file as well.
+func logRetry(name string, policy *RetryPolicy, attempt *Attempt) {
+	message := fmt.Sprintf("Attempt %d/%d of %s failed (%s), retrying in %s", attempt.Number, policy.MaxAttempts, name,
+		attempt.cause(), attempt.Backoff)
+	logrus.Warn(message)
+	ClaimFilePrintf("%s", message)
+}
+
+// Retrier decorates a Tester, and the reel.Handler it usually is as well, so that a Test running it retries the
+// failed attempts according to a RetryPolicy.  The Retrier is given to NewTest both as the Tester and in the chain of
+// Handlers, in place of the decorated ones:
+//  tester := tnf.WithRetry(ds.NewDaemonSet(timeout, name, namespace), tnf.DefaultRetryPolicy())
+//  test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
+// When the decorated Tester is not a reel.Handler, the Retrier only observes the events before passing them to the
+// next Handlers of the chain.  Every attempt runs the Tester command again, and feeds the same Handlers.
+type Retrier struct {
+	tester   Tester
+	handler  reel.Handler
+	policy   RetryPolicy
+	attempts []Attempt
+
+	// The state of the current attempt.
+	start   time.Time
+	pattern string
+	timeout bool
+}
+
+// WithRetry decorates tester with policy.
+func WithRetry(tester Tester, policy RetryPolicy) *Retrier { //nolint:gocritic // The policy is copied on purpose
+	handler, _ := tester.(reel.Handler)
+	return &Retrier{tester: tester, handler: handler, policy: policy}
+}
+
+// Args returns the command of the decorated Tester.
+func (r *Retrier) Args() []string {
+	return r.tester.Args()
+}
+
+// GetIdentifier returns the identifier of the decorated Tester.
+func (r *Retrier) GetIdentifier() identifier.Identifier {
+	return r.tester.GetIdentifier()
+}
+
+// Result returns the result of the last attempt.
+func (r *Retrier) Result() int {
+	return r.tester.Result()
+}
+
+// Timeout returns the timeout of every attempt.
+func (r *Retrier) Timeout() time.Duration {
+	return r.tester.Timeout()
+}
+
+// Attempts returns the attempts made so far.
+func (r *Retrier) Attempts() []Attempt {
+	attempts := make([]Attempt, len(r.attempts))
+	copy(attempts, r.attempts)
+	return attempts
+}
+
+// Policy returns the retry policy.
+func (r *Retrier) Policy() RetryPolicy {
+	return r.policy
+}
+
+// ReelFirst consult reel.Handler.ReelFirst.
+func (r *Retrier) ReelFirst() *reel.Step {
+	if r.handler == nil {
+		return nil
+	}
+	return r.handler.ReelFirst()
+}
+
+// ReelMatch records the matched pattern.
+func (r *Retrier) ReelMatch(pattern, before, match string) *reel.Step {
+	r.pattern = pattern
+	if r.handler == nil {
+		return nil
+	}
+	return r.handler.ReelMatch(pattern, before, match)
+}
+
+// ReelMatchOutput records the matched pattern of a streamed step.
+func (r *Retrier) ReelMatchOutput(pattern string, output *reel.Output) *reel.Step {
+	r.pattern = pattern
+	if r.handler == nil {
+		return nil
+	}
+	return reel.MatchOutput(r.handler, pattern, output)
+}
+
+// ReelMatchEvent records the matched pattern, and informs the decorated Handler of event, see reel.DispatchMatchEvent.
+// A Test only runs its reel with events when the decorated Handler is a reel.EventHandler.
+func (r *Retrier) ReelMatchEvent(event *reel.MatchEvent) *reel.Step {
+	r.pattern = event.Pattern
+	if r.handler == nil {
+		return nil
+	}
+	return reel.DispatchMatchEvent(r.handler, event)
+}
+
+// ReelTimeout records the timeout.
+func (r *Retrier) ReelTimeout() *reel.Step {
+	r.timeout = true
+	if r.handler == nil {
+		return nil
+	}
+	return r.handler.ReelTimeout()
+}
+
+// ReelEOF consult reel.Handler.ReelEOF.
+func (r *Retrier) ReelEOF() {
+	if r.handler != nil {
+		r.handler.ReelEOF()
+	}
+}
+
+// startAttempt resets the state of the current attempt.
+func (r *Retrier) startAttempt() {
+	r.start = time.Now()
+	r.pattern = ""
+	r.timeout = false
+}
+
+// endAttempt records the attempt which ended with result and err, and returns the wait before the next attempt and
+// whether there is one.
+func (r *Retrier) endAttempt(result int, err error) (time.Duration, bool) {
+	attempt := Attempt{
+		Number:   len(r.attempts) + 1,
+		Result:   result,
+		Pattern:  r.pattern,
+		Timeout:  r.timeout || reel.IsTimeout(err),
+		Duration: time.Since(r.start),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	retry := r.policy.retryable(&attempt)
+	if retry {
+		attempt.Backoff = r.policy.Backoff(attempt.Number)
+		logRetry(r.tester.GetIdentifier().URL, &r.policy, &attempt)
+	}
+	r.attempts = append(r.attempts, attempt)
+	return attempt.Backoff, retry
+}
+
+// report attaches the attempts to the claim result of the running test case, when there were retries.
+func (r *Retrier) report() {
+	reportRetriedTest(r.tester.GetIdentifier().URL, r.Attempts())
+}
+
+// cause describes why the attempt failed.
+func (a *Attempt) cause() string {
+	switch {
+	case a.Timeout:
+		return "timeout"
+	case a.Error != "":
+		return a.Error
+	case a.Pattern != "":
+		return fmt.Sprintf("matched %q", a.Pattern)
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (C) 2022 Red Hat, Inc.
+//
+// This program is free software; you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation; either version 2 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program; if not, write to the Free Software Foundation, Inc.,
+// 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
+
+package tnf_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	expect "github.com/google/goexpect"
+	"github.com/stretchr/testify/assert"
+	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
+	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+)
+
+const (
+	transientPattern = `transient error \d+`
+	donePattern      = `done \d+`
+	failedPattern    = `failed \d+`
+)
+
+// flakyHandler runs script, which gets the number of the attempt as $n.
+type flakyHandler struct {
+	args    []string
+	timeout time.Duration
+	result  int
+}
+
+func newFlakyHandler(t *testing.T, script string) *flakyHandler {
+	counter := filepath.Join(t.TempDir(), "attempts")
+	command := fmt.Sprintf(`n=$(cat %s 2>/dev/null || echo 0); n=$((n + 1)); echo $n > %s; %s`, counter, counter, script)
+	return &flakyHandler{args: []string{command}, timeout: testTimeoutDuration}
+}
+
+func (h *flakyHandler) Args() []string                       { return h.args }
+func (h *flakyHandler) GetIdentifier() identifier.Identifier { return identifier.CommandIdentifier }
+func (h *flakyHandler) Result() int                          { return h.result }
+func (h *flakyHandler) Timeout() time.Duration               { return h.timeout }
+func (h *flakyHandler) ReelTimeout() *reel.Step              { return nil }
+func (h *flakyHandler) ReelEOF()                             {}
+func (h *flakyHandler) ReelFirst() *reel.Step {
+	h.result = tnf.ERROR
+	return &reel.Step{Expect: []string{transientPattern, donePattern, failedPattern}, Timeout: h.timeout}
+}
+
+func (h *flakyHandler) ReelMatch(pattern, _, _ string) *reel.Step {
+	switch pattern {
+	case donePattern:
+		h.result = tnf.SUCCESS
+	case failedPattern:
+		h.result = tnf.FAILURE
+	}
+	return nil
+}
+
+func runWithRetry(t *testing.T, tester tnf.Tester, policy tnf.RetryPolicy) (*tnf.Retrier, int, error) { //nolint:gocritic
+	t.Setenv("SHELL", "/bin/sh")
+	var spawner interactive.Spawner = interactive.NewGoExpectSpawner()
+	context, err := interactive.SpawnShell(&spawner, testTimeoutDuration)
+	assert.Nil(t, err)
+	defer (*context.GetExpecter()).Close()
+
+	retrier := tnf.WithRetry(tester, policy)
+	test, err := tnf.NewTest(context.GetExpecter(), retrier, []reel.Handler{retrier}, context.GetErrorChannel())
+	assert.Nil(t, err)
+	result, err := test.Run()
+	return retrier, result, err
+}
+
+func testRetryPolicy(maxAttempts int) tnf.RetryPolicy {
+	policy := tnf.DefaultRetryPolicy()
+	policy.MaxAttempts = maxAttempts
+	policy.InitialBackoff = time.Millisecond
+	return policy
+}
+
+func TestRetrier_RetriesErrors(t *testing.T) {
+	handler := newFlakyHandler(t, `if [ $n -lt 3 ]; then echo "transient error $n"; else echo "done $n"; fi`)
+	retrier, result, err := runWithRetry(t, handler, testRetryPolicy(3))
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.SUCCESS, result)
+
+	attempts := retrier.Attempts()
+	assert.Len(t, attempts, 3)
+	for i, attempt := range attempts[:2] {
+		assert.Equal(t, i+1, attempt.Number)
+		assert.Equal(t, tnf.ERROR, attempt.Result)
+		assert.Equal(t, transientPattern, attempt.Pattern)
+		assert.Equal(t, time.Duration(1<<i)*time.Millisecond, attempt.Backoff)
+	}
+	assert.Equal(t, tnf.SUCCESS, attempts[2].Result)
+	assert.Equal(t, donePattern, attempts[2].Pattern)
+	assert.Zero(t, attempts[2].Backoff)
+}
+
+func TestRetrier_RetriesExitCodes(t *testing.T) {
+	handler := newFlakyHandler(t, `if [ $n -lt 2 ]; then false; else echo "done $n"; fi`)
+	retrier, result, err := runWithRetry(t, handler, testRetryPolicy(3))
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.SUCCESS, result)
+	attempts := retrier.Attempts()
+	assert.Len(t, attempts, 2)
+	assert.Contains(t, attempts[0].Error, "exit code:1")
+}
+
+func TestRetrier_GivesUp(t *testing.T) {
+	handler := newFlakyHandler(t, `echo "transient error $n"`)
+	retrier, result, err := runWithRetry(t, handler, testRetryPolicy(2))
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.ERROR, result)
+	assert.Len(t, retrier.Attempts(), 2)
+}
+
+func TestRetrier_DoesNotRetry(t *testing.T) {
+	testCases := map[string]struct {
+		script string
+		policy tnf.RetryPolicy
+		result int
+	}{
+		"failure": {
+			script: `echo "failed $n"`,
+			policy: testRetryPolicy(3),
+			result: tnf.FAILURE,
+		},
+		"unlisted_pattern": {
+			script: `echo "transient error $n"`,
+			policy: func() tnf.RetryPolicy {
+				policy := testRetryPolicy(3)
+				policy.RetryPatterns = []string{failedPattern}
+				return policy
+			}(),
+			result: tnf.ERROR,
+		},
+		"single_attempt": {
+			script: `echo "transient error $n"`,
+			policy: testRetryPolicy(1),
+			result: tnf.ERROR,
+		},
+	}
+	for name, tc := range testCases {
+		handler := newFlakyHandler(t, tc.script)
+		retrier, result, err := runWithRetry(t, handler, tc.policy)
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.result, result, name)
+		assert.Len(t, retrier.Attempts(), 1, name)
+	}
+}
+
+// timeoutExpecter never answers.
+type timeoutExpecter struct {
+	sent []string
+}
+
+func (e *timeoutExpecter) Expect(*regexp.Regexp, time.Duration) (string, []string, error) {
+	return "", nil, expect.TimeoutError(0)
+}
+
+func (e *timeoutExpecter) ExpectBatch([]expect.Batcher, time.Duration) ([]expect.BatchRes, error) {
+	return nil, expect.TimeoutError(0)
+}
+
+func (e *timeoutExpecter) ExpectSwitchCase([]expect.Caser, time.Duration) (string, []string, int, error) {
+	return "", nil, -1, expect.TimeoutError(0)
+}
+
+func (e *timeoutExpecter) Send(in string) error {
+	e.sent = append(e.sent, in)
+	return nil
+}
+
+func (e *timeoutExpecter) Close() error {
+	return nil
+}
+
+func TestRetrier_Timeouts(t *testing.T) {
+	for _, retryOnTimeout := range []bool{true, false} {
+		fake := &timeoutExpecter{}
+		var expecter expect.Expecter = fake
+		policy := testRetryPolicy(3)
+		policy.RetryOnTimeout = retryOnTimeout
+		retrier := tnf.WithRetry(&flakyHandler{args: []string{"ls"}, timeout: time.Millisecond}, policy)
+		test, err := tnf.NewTest(&expecter, retrier, []reel.Handler{retrier}, make(chan error))
+		assert.Nil(t, err)
+		result, err := test.Run()
+		assert.True(t, reel.IsTimeout(err))
+		assert.Equal(t, tnf.ERROR, result)
+
+		expectedAttempts := 1
+		if retryOnTimeout {
+			expectedAttempts = 3
+		}
+		assert.Len(t, fake.sent, expectedAttempts)
+		assert.Len(t, retrier.Attempts(), expectedAttempts)
+		for _, attempt := range retrier.Attempts() {
+			assert.True(t, attempt.Timeout)
+		}
+	}
+}
+
+func TestRetrier_CancelledBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := &timeoutExpecter{}
+	var expecter expect.Expecter = fake
+	policy := testRetryPolicy(3)
+	policy.InitialBackoff = time.Hour
+	retrier := tnf.WithRetry(&flakyHandler{args: []string{"ls"}, timeout: time.Millisecond}, policy)
+	test, err := tnf.NewTestWithContext(ctx, &expecter, retrier, []reel.Handler{retrier}, make(chan error))
+	assert.Nil(t, err)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	result, err := test.Run()
+	assert.Equal(t, tnf.CANCELLED, result)
+	assert.True(t, reel.IsCancelled(err))
+	assert.Len(t, fake.sent, 1)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := tnf.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, backoff := range expected {
+		assert.Equal(t, backoff, policy.Backoff(i+1))
+	}
+	policy.Multiplier = 0
+	assert.Equal(t, time.Second, policy.Backoff(4))
+	assert.Equal(t, tnf.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second, Multiplier: 2,
+		RetryOnTimeout: true}, tnf.DefaultRetryPolicy())
+}
+
+// flakyEventHandler is a flakyHandler informed of the exit code of its command.
+type flakyEventHandler struct {
+	*flakyHandler
+	exitCodes []int
+}
+
+func (h *flakyEventHandler) ReelMatchEvent(event *reel.MatchEvent) *reel.Step {
+	h.exitCodes = append(h.exitCodes, event.ExitCode)
+	if event.ExitCode == 0 {
+		return h.ReelMatch(event.Pattern, "", event.Stdout())
+	}
+	return nil
+}
+
+func TestRetrier_EventHandler(t *testing.T) {
+	handler := &flakyEventHandler{flakyHandler: newFlakyHandler(t, `if [ $n -lt 2 ]; then echo "transient error $n"; false; else echo "done $n"; fi`)}
+	retrier, result, err := runWithRetry(t, handler, testRetryPolicy(3))
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.SUCCESS, result)
+	// The decorated reel.EventHandler is informed of the failed command.
+	assert.Equal(t, []int{1, 0}, handler.exitCodes)
+	attempts := retrier.Attempts()
+	assert.Len(t, attempts, 2)
+	assert.Equal(t, transientPattern, attempts[0].Pattern)
+	assert.Empty(t, attempts[0].Error)
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := tnf.Retry(context.Background(), "request", testRetryPolicy(3), func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("transient error %d", calls)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = tnf.Retry(context.Background(), "request", testRetryPolicy(2), func() error {
+		calls++
+		return fmt.Errorf("transient error %d", calls)
+	})
+	assert.EqualError(t, err, "transient error 2")
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	err = tnf.Retry(ctx, "request", testRetryPolicy(3), func() error {
+		calls++
+		return errors.New("transient error")
+	})
+	assert.True(t, reel.IsCancelled(err))
+	assert.Equal(t, 1, calls)
+}
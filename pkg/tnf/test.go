@@ -17,24 +17,18 @@
 package tnf
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	expect "github.com/google/goexpect"
-	"github.com/onsi/ginkgo/v2"
-	"github.com/onsi/gomega"
-	"github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/tnf/identifier"
 	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
 )
 
-// ClaimFilePrintf prints to claim and junit report files.
+// ClaimFilePrintf prints to claim and junit report files, see Reporter.Printf.
 func ClaimFilePrintf(format string, args ...interface{}) {
-	message := fmt.Sprintf(format+"\n", args...)
-	_, err := ginkgo.GinkgoWriter.Write([]byte(message))
-	if err != nil {
-		logrus.Errorf("Ginkgo writer could not write msg '%s' because: %s", message, err)
-	}
+	reporter.Printf(format, args...)
 }
 
 const (
@@ -44,6 +38,8 @@ const (
 	SUCCESS
 	// FAILURE represents a failed test.
 	FAILURE
+	// CANCELLED represents a test stopped by the cancellation or the deadline of its context.
+	CANCELLED
 )
 
 // TestsExtraInfo a collection of messages per test that is added to the claim file
@@ -52,9 +48,28 @@ var TestsExtraInfo = []map[string][]string{}
 
 // ExitCodeMap maps a test result value to a more appropriate Unix return code.
 var ExitCodeMap = map[int]int{
-	SUCCESS: 0,
-	FAILURE: 1,
-	ERROR:   2,
+	SUCCESS:   0,
+	FAILURE:   1,
+	ERROR:     2,
+	CANCELLED: 3,
+}
+
+// contextFunc returns the context of the tests created by NewTest, see SetContextFunc.
+var contextFunc = context.Background
+
+// SetContextFunc sets the function returning the context of the tests created by NewTest, which is called for every
+// test, e.g. to return the context of the running test suite and enforce its time budget.  A nil f restores
+// context.Background.
+func SetContextFunc(f func() context.Context) {
+	if f == nil {
+		f = context.Background
+	}
+	contextFunc = f
+}
+
+// CurrentContext returns the context a test created now by NewTest would get, for the commands run without a Test.
+func CurrentContext() context.Context {
+	return contextFunc()
 }
 
 // Tester provides the interface for a Test.
@@ -77,12 +92,46 @@ type Test struct {
 	runner *reel.Reel
 	tester Tester
 	chain  []reel.Handler
+
+	// The arguments of the reel, to create a new one for every retried attempt.
+	ctx          context.Context
+	expecter     *expect.Expecter
+	errorChannel <-chan error
+	opts         []reel.Option
 }
 
-// Run performs a test, returning the result and any encountered errors.
+// Run performs a test, returning the result and any encountered errors.  The result is CANCELLED when the context of
+// the test is done before the test completes.  When the Tester is a Retrier, the failed attempts are run again
+// according to its RetryPolicy, the result is the one of the last attempt, and the attempts are attached to the claim
+// result of the running test case.
 func (t *Test) Run() (int, error) {
-	err := t.runner.Run(t)
-	return t.tester.Result(), err
+	retrier, _ := t.tester.(*Retrier)
+	for {
+		if retrier != nil {
+			retrier.startAttempt()
+		}
+		err := t.runner.Run(t.reelHandler())
+		result := CANCELLED
+		if !reel.IsCancelled(err) {
+			result = t.tester.Result()
+		}
+		if retrier == nil {
+			return result, err
+		}
+		backoff, retry := retrier.endAttempt(result, err)
+		if !retry {
+			retrier.report()
+			return result, err
+		}
+		if err := waitBackoff(t.ctx, backoff); err != nil {
+			retrier.report()
+			return CANCELLED, err
+		}
+		t.runner, err = reel.NewReel(t.expecter, t.tester.Args(), t.errorChannel, t.opts...)
+		if err != nil {
+			return ERROR, err
+		}
+	}
 }
 
 func (t *Test) dispatch(fp reel.StepFunc) *reel.Step {
@@ -111,6 +160,42 @@ func (t *Test) ReelMatch(pattern, before, match string) *reel.Step {
 	return t.dispatch(fp)
 }
 
+// ReelMatchOutput informs the current Handler of a match event in the output of a streamed step, see reel.MatchOutput.
+func (t *Test) ReelMatchOutput(pattern string, output *reel.Output) *reel.Step {
+	fp := func(handler reel.Handler) *reel.Step {
+		return reel.MatchOutput(handler, pattern, output)
+	}
+	return t.dispatch(fp)
+}
+
+// reelHandler returns the Handler the Test gives to its reel.Reel:  the Test itself, or the Test as a reel.EventHandler
+// when a Handler of the chain is one.
+func (t *Test) reelHandler() reel.Handler {
+	for _, handler := range t.chain {
+		// A Retrier is a reel.EventHandler when the Handler it decorates is one.
+		if retrier, ok := handler.(*Retrier); ok {
+			handler = retrier.handler
+		}
+		if _, ok := handler.(reel.EventHandler); ok {
+			return &eventTest{Test: t}
+		}
+	}
+	return t
+}
+
+// eventTest is a Test informed of the outcome of every command, for the reel.EventHandler(s) of its chain.
+type eventTest struct {
+	*Test
+}
+
+// ReelMatchEvent informs the current Handler of the outcome of a command, see reel.DispatchMatchEvent.
+func (t *eventTest) ReelMatchEvent(event *reel.MatchEvent) *reel.Step {
+	fp := func(handler reel.Handler) *reel.Step {
+		return reel.DispatchMatchEvent(handler, event)
+	}
+	return t.dispatch(fp)
+}
+
 // ReelTimeout calls the current Handler's ReelTimeout function.
 func (t *Test) ReelTimeout() *reel.Step {
 	fp := func(handler reel.Handler) *reel.Step {
@@ -131,18 +216,22 @@ func (t *Test) RunAndValidate() {
 	t.RunAndValidateWithFailureCallback(nil)
 }
 
-// RunAndValidateWithFailureCallback runs the test, checks the result/error and invokes the cb on failure
+// RunAndValidateWithFailureCallback runs the test, checks the result/error and invokes the cb on failure.  A failed
+// check ends the running test case, see Reporter.Fail.
 func (t *Test) RunAndValidateWithFailureCallback(cb func()) {
 	testResult, err := t.Run()
 	if testResult == FAILURE && cb != nil {
 		cb()
 	}
-	gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
-	gomega.Expect(testResult).To(gomega.Equal(SUCCESS))
+	FailOnError(err)
+	if testResult != SUCCESS {
+		reporter.Fail(fmt.Sprintf("Expected the test result to be SUCCESS (%d), got %d", SUCCESS, testResult))
+	}
 }
 
 // RunWithCallbacks runs the test, invokes the cb on failure/error/success
 // This is useful when the testcase needs to continue whether this test result is success or not
+// A cancelled test is reported to errorCb, with an error for which reel.IsCancelled is true.
 func (t *Test) RunWithCallbacks(successCb, failureCb func(), errorCb func(error)) {
 	testResult, err := t.Run()
 	switch testResult {
@@ -154,19 +243,27 @@ func (t *Test) RunWithCallbacks(successCb, failureCb func(), errorCb func(error)
 		if failureCb != nil {
 			failureCb()
 		}
-	case ERROR:
+	case ERROR, CANCELLED:
 		if errorCb != nil {
 			errorCb(err)
 		}
 	}
 }
 
-// NewTest creates a new Test given a chain of Handlers.
+// NewTest creates a new Test given a chain of Handlers.  The test is stopped when its context is done, see
+// SetContextFunc.
 func NewTest(expecter *expect.Expecter, tester Tester, chain []reel.Handler, errorChannel <-chan error, opts ...reel.Option) (*Test, error) {
+	return NewTestWithContext(CurrentContext(), expecter, tester, chain, errorChannel, opts...)
+}
+
+// NewTestWithContext creates a new Test given a chain of Handlers, which is stopped when ctx is done.
+func NewTestWithContext(ctx context.Context, expecter *expect.Expecter, tester Tester, chain []reel.Handler, errorChannel <-chan error,
+	opts ...reel.Option) (*Test, error) {
 	args := tester.Args()
+	opts = append([]reel.Option{reel.WithContext(ctx)}, opts...)
 	runner, err := reel.NewReel(expecter, args, errorChannel, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return &Test{runner: runner, tester: tester, chain: chain}, nil
+	return &Test{runner: runner, tester: tester, chain: chain, ctx: ctx, expecter: expecter, errorChannel: errorChannel, opts: opts}, nil
 }
@@ -17,6 +17,7 @@
 package tnf_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -293,3 +294,99 @@ func TestTest_ReelEof(t *testing.T) {
 	// just ensure there are no panics
 	test.ReelEOF()
 }
+
+func TestTest_RunCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExpecter := mock_interactive.NewMockExpecter(ctrl)
+	mockTester := mock_tnf.NewMockTester(ctrl)
+	mockTester.EXPECT().Args().Return(defaultTestCommand)
+	mockHandler := mock_reel.NewMockHandler(ctrl)
+	mockHandler.EXPECT().ReelFirst().Return(&reel.Step{Expect: []string{"x"}, Timeout: testTimeoutDuration})
+	mockHandler.EXPECT().ReelEOF()
+
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	test, err := tnf.NewTestWithContext(ctx, &expecter, mockTester, []reel.Handler{mockHandler}, errorChannel)
+	assert.Nil(t, err)
+
+	var callbackErr error
+	test.RunWithCallbacks(func() { t.Fail() }, func() { t.Fail() }, func(err error) { callbackErr = err })
+	assert.True(t, reel.IsCancelled(callbackErr))
+	assert.Equal(t, 3, tnf.ExitCodeMap[tnf.CANCELLED])
+}
+
+func TestSetContextFunc(t *testing.T) {
+	defer tnf.SetContextFunc(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	tnf.SetContextFunc(func() context.Context {
+		calls++
+		return ctx
+	})
+	assert.Equal(t, ctx, tnf.CurrentContext())
+	assert.Equal(t, 1, calls)
+	tnf.SetContextFunc(nil)
+	assert.Equal(t, context.Background(), tnf.CurrentContext())
+}
+
+// eventRecorder is a reel.EventHandler recording the last reel.MatchEvent.
+type eventRecorder struct {
+	step     *reel.Step
+	pattern  string
+	exitCode int
+	stdout   string
+}
+
+func (e *eventRecorder) ReelFirst() *reel.Step {
+	return e.step
+}
+
+func (e *eventRecorder) ReelMatch(_, _, _ string) *reel.Step {
+	return nil
+}
+
+func (e *eventRecorder) ReelMatchEvent(event *reel.MatchEvent) *reel.Step {
+	e.pattern, e.exitCode, e.stdout = event.Pattern, event.ExitCode, event.Stdout()
+	return nil
+}
+
+func (e *eventRecorder) ReelTimeout() *reel.Step {
+	return nil
+}
+
+func (e *eventRecorder) ReelEOF() {
+}
+
+func TestTest_RunEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockExpecter := mock_interactive.NewMockExpecter(ctrl)
+	mockExpecter.EXPECT().Send(gomock.Any()).AnyTimes()
+	output := fmt.Sprintf("failed\n%s %s2\n", reel.EndOfTestSentinel, reel.ExitKeyword)
+	mockExpecter.EXPECT().ExpectBatch(gomock.Any(), gomock.Any()).Return([]expect.BatchRes{{Output: output, Match: []string{output}}}, nil)
+
+	mockTester := mock_tnf.NewMockTester(ctrl)
+	mockTester.EXPECT().Args().Return(defaultTestCommand)
+	mockTester.EXPECT().Result().Return(tnf.FAILURE)
+
+	// The other Handlers of the chain are not informed of the failed command.
+	recorder := &eventRecorder{step: &reel.Step{Expect: []string{"failed"}, Timeout: testTimeoutDuration}}
+	mockHandler := mock_reel.NewMockHandler(ctrl)
+
+	var expecter expect.Expecter = mockExpecter
+	var errorChannel <-chan error
+	test, err := tnf.NewTest(&expecter, mockTester, []reel.Handler{recorder, mockHandler}, errorChannel)
+	assert.Nil(t, err)
+	result, err := test.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, tnf.FAILURE, result)
+	assert.Equal(t, "failed", recorder.pattern)
+	assert.Equal(t, 2, recorder.exitCode)
+	assert.Equal(t, "failed", recorder.stdout)
+}
@@ -26,10 +26,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/onsi/ginkgo/v2"
-	"github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/execute"
 	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/generic"
 	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/nodedebug"
 	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
@@ -47,6 +47,13 @@ var (
 
 const (
 	timeoutPid = 5 * time.Second
+
+	// localCommandBackendEnvVar selects how ExecuteLocalCommand runs the commands.
+	localCommandBackendEnvVar = "TNF_LOCAL_COMMAND_BACKEND"
+	// LocalCommandBackendExec runs the local commands directly with os/exec.
+	LocalCommandBackendExec = "exec"
+	// LocalCommandBackendShell runs the local commands in an interactive shell with the generic command handler.
+	LocalCommandBackendShell = "shell"
 )
 
 // ArgListToMap takes a list of strings of the form "key=value" and translate it into a map
@@ -101,10 +108,13 @@ func escapeToJSONstringFormat(line string) (string, error) {
 // ExecuteCommand uses the generic command handler to execute an arbitrary interactive command, returning
 // its output wihout any filtering/matching if the command is successfully executed
 var ExecuteCommand = func(command string, timeout time.Duration, context *interactive.Context) (string, error) {
-	tester, test := newGenericCommandTester(command, timeout, context)
+	tester, test, err := newGenericCommandTester(command, timeout, context)
+	if err != nil {
+		return "", err
+	}
 	result, err := test.Run()
+	genericTest := (*tester).(*generic.Generic)
 	if result == tnf.SUCCESS && err == nil {
-		genericTest := (*tester).(*generic.Generic)
 		if genericTest != nil {
 			matches := genericTest.Matches
 			if len(matches) == 1 {
@@ -112,52 +122,138 @@ var ExecuteCommand = func(command string, timeout time.Duration, context *intera
 			}
 		}
 	}
+	// The generic handler reports a command exiting with a non-zero code as its failure reason.
+	if err == nil && genericTest != nil && genericTest.FailureReason != "" {
+		err = errors.New(genericTest.FailureReason)
+	}
 	return "", err
 }
 
 // ExecuteCommandAndValidate uses the generic command handler to execute an arbitrary interactive command, returning
 // its output wihout any filtering/matching
 var ExecuteCommandAndValidate = func(command string, timeout time.Duration, context *interactive.Context, failureCallbackFun func()) string {
-	tester, test := newGenericCommandTester(command, timeout, context)
+	tester, test, err := newGenericCommandTester(command, timeout, context)
+	tnf.FailOnError(err)
 	test.RunAndValidateWithFailureCallback(failureCallbackFun)
 	genericTest := (*tester).(*generic.Generic)
-	gomega.Expect(genericTest).ToNot(gomega.BeNil())
 
 	matches := genericTest.Matches
-	gomega.Expect(len(matches)).To(gomega.Equal(1))
+	if len(matches) != 1 {
+		tnf.CurrentReporter().Fail(fmt.Sprintf("Expected 1 match of the command %q, got %d", command, len(matches)))
+	}
 	match := genericTest.GetMatches()[0]
 	return match.Match
 }
 
-func newGenericCommandTester(command string, timeout time.Duration, context *interactive.Context) (*tnf.Tester, *tnf.Test) {
+// GetLocalCommandBackend returns the backend used by ExecuteLocalCommand, from the TNF_LOCAL_COMMAND_BACKEND
+// environment variable, defaulting to the exec backend.  The shell backend is always used with snapshots, since they
+// only hold the commands run in the interactive sessions.
+func GetLocalCommandBackend() string {
+	backend := strings.ToLower(os.Getenv(localCommandBackendEnvVar))
+	switch {
+	case snapshot.IsActive():
+		if backend != "" && backend != LocalCommandBackendShell {
+			log.Warnf("%s local command backend is not supported with snapshots, using %s", backend, LocalCommandBackendShell)
+		}
+		return LocalCommandBackendShell
+	case backend == "" || backend == LocalCommandBackendExec:
+		return LocalCommandBackendExec
+	case backend == LocalCommandBackendShell:
+		return LocalCommandBackendShell
+	default:
+		log.Warnf("Unknown %s value %q, using %s", localCommandBackendEnvVar, backend, LocalCommandBackendExec)
+		return LocalCommandBackendExec
+	}
+}
+
+// ExecuteLocalCommand runs a non-interactive command on the machine running the tests, e.g. oc or helm, and returns
+// its standard output without the trailing new lines.  A command exiting with a non-zero code returns an error with
+// its standard error.
+var ExecuteLocalCommand = func(command string, timeout time.Duration) (string, error) {
+	if GetLocalCommandBackend() == LocalCommandBackendShell {
+		context, err := interactive.NewShellContext(false)
+		if err != nil {
+			return "", err
+		}
+		defer closeShellContext(context)
+		return ExecuteCommand(command, timeout, context)
+	}
+	result, err := execute.Run(tnf.CurrentContext(), command, timeout)
+	if err != nil {
+		return "", err
+	}
+	if err := result.Err(); err != nil {
+		return "", err
+	}
+	if result.Stderr != "" {
+		log.Debugf("Command %q standard error: %s", command, result.Stderr)
+	}
+	return strings.TrimRight(result.Stdout, "\r\n"), nil
+}
+
+// closeShellContext ends the shell session spawned for a single local command.
+var closeShellContext = func(context *interactive.Context) {
+	if err := (*context.GetExpecter()).Close(); err != nil {
+		log.Debugf("Closing the local shell session: %v", err)
+	}
+}
+
+// ExecuteLocalCommandAndValidate runs a command like ExecuteLocalCommand, and fails the test if it does not succeed.
+var ExecuteLocalCommandAndValidate = func(command string, timeout time.Duration, failureCallbackFun func()) string {
+	output, err := ExecuteLocalCommand(command, timeout)
+	if err != nil {
+		log.Errorf("Command %q failed: %v", command, err)
+		failureCallbackFun()
+	}
+	tnf.FailOnError(err)
+	return output
+}
+
+func newGenericCommandTester(command string, timeout time.Duration, context *interactive.Context) (*tnf.Tester, *tnf.Test, error) {
 	log.Debugf("Executing command: %s", command)
 
 	values := make(map[string]interface{})
 	// Escapes the double quote and new line chars to make a valid json string for the command to be executed by the handler.
 	var err error
 	values["COMMAND"], err = escapeToJSONstringFormat(command)
-	gomega.Expect(err).To(gomega.BeNil())
+	if err != nil {
+		return nil, nil, err
+	}
 	values["TIMEOUT"] = timeout.Nanoseconds()
 
 	log.Debugf("Command handler's COMMAND string value: %s", values["COMMAND"])
 
-	tester, handlers := NewGenericTesterAndValidate(commandHandlerFilePath, handlerJSONSchemaFilePath, values)
+	tester, handlers, err := NewGenericTester(commandHandlerFilePath, handlerJSONSchemaFilePath, values)
+	if err != nil {
+		return nil, nil, err
+	}
 	test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(tester).ToNot(gomega.BeNil())
-	return tester, test
+	if err != nil {
+		return nil, nil, err
+	}
+	return tester, test, nil
+}
+
+// NewGenericTester creates a generic handler from the json template with the var map, or returns an error if the
+// template is not valid.
+func NewGenericTester(templateFile, schemaPath string, values map[string]interface{}) (*tnf.Tester, []reel.Handler, error) {
+	tester, handlers, result, err := generic.NewGenericFromMap(templateFile, schemaPath, values)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result == nil || !result.Valid() {
+		return nil, nil, fmt.Errorf("the generic handler %s does not match the schema %s", templateFile, schemaPath)
+	}
+	if tester == nil || handlers == nil {
+		return nil, nil, fmt.Errorf("no generic handler created from %s", templateFile)
+	}
+	return tester, handlers, nil
 }
 
 // NewGenericTesterAndValidate creates a generic handler from the json template with the var map and validate the outcome
 func NewGenericTesterAndValidate(templateFile, schemaPath string, values map[string]interface{}) (*tnf.Tester, []reel.Handler) {
-	tester, handlers, result, err := generic.NewGenericFromMap(templateFile, handlerJSONSchemaFilePath, values)
-
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(result).ToNot(gomega.BeNil())
-	gomega.Expect(result.Valid()).To(gomega.BeTrue())
-	gomega.Expect(handlers).ToNot(gomega.BeNil())
-	gomega.Expect(tester).ToNot(gomega.BeNil())
-
+	tester, handlers, err := NewGenericTester(templateFile, schemaPath, values)
+	tnf.FailOnError(err)
 	return tester, handlers
 }
 
@@ -172,19 +268,19 @@ func GetContainerPID(nodeName string, nodeOc *interactive.Oc, containerID, runti
 	case "cri-o", "containerd": //nolint:goconst // used only once
 		command = "chroot /host crictl inspect --output go-template --template '{{.info.pid}}' " + containerID + " 2>/dev/null"
 	default:
-		ginkgo.Skip(fmt.Sprintf("Container runtime %s not supported yet for this test, skipping", runtime))
+		tnf.CurrentReporter().Skip(fmt.Sprintf("Container runtime %s not supported yet for this test, skipping", runtime))
 	}
 	return RunCommandInNode(nodeName, nodeOc, command, timeoutPid)
 }
 
 func GetModulesFromNode(nodeName string, nodeOc *interactive.Oc) []string {
 	// Get the 1st column list of the modules running on the node.
-	// Split on the return/newline and get the list of the modules back.
+	// Split on the newline and get the list of the modules back.
 	//nolint:goconst // used only once
 	command := `chroot /host lsmod | awk '{ print $1 }' | grep -v Module`
 	output := RunCommandInNode(nodeName, nodeOc, command, timeoutPid)
 	output = strings.ReplaceAll(output, "\t", "")
-	return strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	return strings.Split(output, "\n")
 }
 
 // ModuleInTree returns true if the module hasn't tainted the kernel with the
@@ -206,7 +302,7 @@ var RunCommandInNode = func(nodeName string, nodeOc *interactive.Oc, command str
 	context := nodeOc
 	tester := nodedebug.NewNodeDebug(timeout, nodeName, command, true, true)
 	test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
+	tnf.FailOnError(err)
 	test.RunAndValidate()
 	return tester.Raw
 }
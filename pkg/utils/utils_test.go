@@ -17,6 +17,7 @@
 package utils
 
 import (
+	"path"
 	"reflect"
 	"strings"
 	"testing"
@@ -235,3 +236,75 @@ func TestStringInSlice(t *testing.T) {
 		assert.Equal(t, tc.expected, StringInSlice(tc.testSlice, tc.testString, tc.containsFeature))
 	}
 }
+
+func TestGetLocalCommandBackend(t *testing.T) {
+	testCases := []struct {
+		envValue string
+		expected string
+	}{
+		{envValue: "", expected: LocalCommandBackendExec},
+		{envValue: "exec", expected: LocalCommandBackendExec},
+		{envValue: "Shell", expected: LocalCommandBackendShell},
+		{envValue: "unknown", expected: LocalCommandBackendExec},
+	}
+
+	for _, tc := range testCases {
+		t.Setenv(localCommandBackendEnvVar, tc.envValue)
+		assert.Equal(t, tc.expected, GetLocalCommandBackend())
+	}
+}
+
+func TestExecuteLocalCommand(t *testing.T) {
+	t.Setenv(localCommandBackendEnvVar, LocalCommandBackendExec)
+
+	output, err := ExecuteLocalCommand(`printf '%s\n\n' '{"a": "b\\n"}'; echo ignored >&2`, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a": "b\\n"}`, output)
+
+	output, err = ExecuteLocalCommand("echo partial; echo not found >&2; exit 1", time.Second)
+	assert.Equal(t, "", output)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = ExecuteLocalCommand("sleep 30", 50*time.Millisecond)
+	assert.NotNil(t, err)
+}
+
+func TestExecuteLocalCommand_Shell(t *testing.T) {
+	t.Setenv(localCommandBackendEnvVar, LocalCommandBackendShell)
+	t.Setenv("SHELL", "/bin/sh")
+	origCommand, origSchema := commandHandlerFilePath, handlerJSONSchemaFilePath
+	defer func() {
+		commandHandlerFilePath, handlerJSONSchemaFilePath = origCommand, origSchema
+	}()
+	commandHandlerFilePath = path.Join("..", "tnf", "handlers", "command", "command.json")
+	handlerJSONSchemaFilePath = path.Join("..", "..", "schemas", "generic-test.schema.json")
+
+	output, err := ExecuteLocalCommand("echo first; echo second", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, "first\nsecond", output)
+
+	// The failure of the command is reported by the generic handler.
+	output, err = ExecuteLocalCommand("(echo partial; exit 3)", time.Second)
+	assert.Equal(t, "", output)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exit code:3")
+}
+
+func TestNewGenericTester(t *testing.T) {
+	templateFile := path.Join("..", "tnf", "handlers", "command", "command.json")
+	schemaPath := path.Join("..", "..", "schemas", "generic-test.schema.json")
+	values := map[string]interface{}{"COMMAND": "ls", "TIMEOUT": time.Second.Nanoseconds()}
+
+	tester, handlers, err := NewGenericTester(templateFile, schemaPath, values)
+	assert.Nil(t, err)
+	assert.NotNil(t, tester)
+	assert.NotEmpty(t, handlers)
+
+	_, _, err = NewGenericTester(path.Join("testdata", "missing.json"), schemaPath, values)
+	assert.NotNil(t, err)
+
+	// The template requires a COMMAND value.
+	_, _, err = NewGenericTester(templateFile, schemaPath, map[string]interface{}{})
+	assert.NotNil(t, err)
+}
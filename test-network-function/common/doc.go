@@ -15,7 +15,7 @@
 // 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
 
 /*
-Package common contains constants, structures and utility function shared
-by multiple test suites.
+Package common contains the Ginkgo hooks shared by multiple test suites,
+e.g. the clean up of the debug pods after the suites.
 */
 package common
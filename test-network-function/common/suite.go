@@ -27,19 +27,24 @@ var env *configpkg.TestEnvironment
 
 func RemoveLabelsFromAllNodes() {
 	for name := range autodiscover.GetNodesList() {
-		autodiscover.DeleteDebugLabel(name)
+		if err := autodiscover.DeleteDebugLabel(name); err != nil {
+			log.Error(err)
+		}
 	}
 }
 
 func RemoveDebugPods() {
 	env = configpkg.GetTestEnvironment()
 	env.LoadAndRefresh()
+	env.CloseSessionPool()
 	for name, node := range env.NodesUnderTest {
 		if !(node.HasDebugPod()) {
 			continue
 		}
 		node.DebugContainer.CloseOc()
-		autodiscover.DeleteDebugLabel(name)
+		if err := autodiscover.DeleteDebugLabel(name); err != nil {
+			log.Error(err)
+		}
 	}
 }
 
@@ -10,7 +10,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function/pkg/config"
-	"github.com/test-network-function/test-network-function/test-network-function/common"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
 
 	"github.com/test-network-function/test-network-function/pkg/tnf"
 	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/clusterversion"
@@ -431,7 +431,7 @@ func getClusterCSIInfo() error {
 	log.Infof("Getting cluster CSI information.")
 
 	context := env.GetLocalShellContext()
-	tester, handlers, jsonParseResult, err := generic.NewGenericFromJSONFile(relativeCsiDriverTestPath, common.RelativeSchemaPath)
+	tester, handlers, jsonParseResult, err := generic.NewGenericFromJSONFile(relativeCsiDriverTestPath, environment.RelativeSchemaPath)
 	if validParseResult := jsonParseResult.Valid(); err != nil || !validParseResult {
 		return fmt.Errorf("failed to create handler to get cluster CSI info (validParseResult: %v, error: %v)", validParseResult, err)
 	}
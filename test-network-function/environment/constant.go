@@ -14,9 +14,9 @@
 // with this program; if not, write to the Free Software Foundation, Inc.,
 // 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
 
-package common
+package environment
 
-// Constants shared by multiple test suite packages
+// Constants shared by multiple test suite packages and their checks
 const (
 	ConfiguredTestFile        = "testconfigure.yml"
 	defaultTimeoutSeconds     = 10
@@ -14,7 +14,7 @@
 // with this program; if not, write to the Free Software Foundation, Inc.,
 // 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA.
 
-package common
+package environment
 
 import (
 	"fmt"
@@ -24,8 +24,8 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/onsi/ginkgo/v2"
 	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
 )
 
 var (
@@ -45,22 +45,18 @@ var DefaultTimeout = time.Duration(defaultTimeoutSeconds) * time.Second
 // LogLevelTraceEnabled is saved to filter some debug trace logs (e.g. expecters Sent/Match)
 var LogLevelTraceEnabled = false
 
-var TcClaimLogPrintf = func(format string, args ...interface{}) {
-	message := fmt.Sprintf(format+"\n", args...)
-	_, err := ginkgo.GinkgoWriter.Write([]byte(message))
-	if err != nil {
-		log.Errorf("Ginkgo writer could not write msg '%s' because: %s", message, err)
-	}
-}
-
 // IsNonOcpCluster returns true when the env var is set, OCP only test would be skipped based on this flag
 func IsNonOcpCluster() bool {
 	b, _ := strconv.ParseBool(os.Getenv("TNF_NON_OCP_CLUSTER"))
 	return b
 }
 
-// Intrusive is for running tests that can impact the CNF or test environment in an intrusive way
+// Intrusive is for running tests that can impact the CNF or test environment in an intrusive way.  Intrusive tests
+// never run against a snapshot, as their outcome depends on the live cluster.
 func Intrusive() bool {
+	if snapshot.IsReplaying() {
+		return false
+	}
 	b, _ := strconv.ParseBool(os.Getenv("TNF_NON_INTRUSIVE_ONLY"))
 	return !b
 }
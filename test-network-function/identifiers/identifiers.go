@@ -21,7 +21,7 @@ import (
 	"strings"
 
 	"github.com/test-network-function/test-network-function-claim/pkg/claim"
-	"github.com/test-network-function/test-network-function/test-network-function/common"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
 )
 
 const (
@@ -63,182 +63,182 @@ var (
 	TestIDBaseDomain = url
 	// TestHostResourceIdentifier tests container best practices.
 	TestHostResourceIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AccessControlTestKey, "host-resource"),
+		Url:     formTestURL(environment.AccessControlTestKey, "host-resource"),
 		Version: versionOne,
 	}
 	// TestContainerIsCertifiedIdentifier tests whether the container has passed Container Certification.
 	TestContainerIsCertifiedIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AffiliatedCertTestKey, "container-is-certified"),
+		Url:     formTestURL(environment.AffiliatedCertTestKey, "container-is-certified"),
 		Version: versionOne,
 	}
 	// TestHugepagesNotManuallyManipulated represents the test identifier testing hugepages have not been manipulated.
 	TestHugepagesNotManuallyManipulated = claim.Identifier{
-		Url:     formTestURL(common.PlatformAlterationTestKey, "hugepages-config"),
+		Url:     formTestURL(environment.PlatformAlterationTestKey, "hugepages-config"),
 		Version: versionOne,
 	}
 	// TestICMPv4ConnectivityIdentifier tests icmpv4 connectivity.
 	TestICMPv4ConnectivityIdentifier = claim.Identifier{
-		Url:     formTestURL(common.NetworkingTestKey, "icmpv4-connectivity"),
+		Url:     formTestURL(environment.NetworkingTestKey, "icmpv4-connectivity"),
 		Version: versionOne,
 	}
 	// TestICMPv6ConnectivityIdentifier tests icmpv6 connectivity.
 	TestICMPv6ConnectivityIdentifier = claim.Identifier{
-		Url:     formTestURL(common.NetworkingTestKey, "icmpv6-connectivity"),
+		Url:     formTestURL(environment.NetworkingTestKey, "icmpv6-connectivity"),
 		Version: versionOne,
 	}
 	// TestICMPv4ConnectivityIdentifier tests icmpv4 Multus connectivity.
 	TestICMPv4ConnectivityMultusIdentifier = claim.Identifier{
-		Url:     formTestURL(common.NetworkingTestKey, "icmpv4-connectivity-multus"),
+		Url:     formTestURL(environment.NetworkingTestKey, "icmpv4-connectivity-multus"),
 		Version: versionOne,
 	}
 	// TestICMPv6ConnectivityIdentifier tests icmpv6 Multus connectivity.
 	TestICMPv6ConnectivityMultusIdentifier = claim.Identifier{
-		Url:     formTestURL(common.NetworkingTestKey, "icmpv6-connectivity-multus"),
+		Url:     formTestURL(environment.NetworkingTestKey, "icmpv6-connectivity-multus"),
 		Version: versionOne,
 	}
 	// TestNamespaceBestPracticesIdentifier ensures the namespace has followed best namespace practices.
 	TestNamespaceBestPracticesIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AccessControlTestKey, "namespace"),
+		Url:     formTestURL(environment.AccessControlTestKey, "namespace"),
 		Version: versionOne,
 	}
 	// TestNonTaintedNodeKernelsIdentifier is the identifier for the test checking tainted nodes.
 	TestNonTaintedNodeKernelsIdentifier = claim.Identifier{
-		Url:     formTestURL(common.PlatformAlterationTestKey, "tainted-node-kernel"),
+		Url:     formTestURL(environment.PlatformAlterationTestKey, "tainted-node-kernel"),
 		Version: versionOne,
 	}
 	// TestOperatorInstallStatusIdentifier tests Operator best practices.
 	TestOperatorInstallStatusIdentifier = claim.Identifier{
-		Url:     formTestURL(common.OperatorTestKey, "install-status"),
+		Url:     formTestURL(environment.OperatorTestKey, "install-status"),
 		Version: versionOne,
 	}
 	// TestOperatorIsCertifiedIdentifier tests that an Operator has passed Operator certification.
 	TestOperatorIsCertifiedIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AffiliatedCertTestKey, "operator-is-certified"),
+		Url:     formTestURL(environment.AffiliatedCertTestKey, "operator-is-certified"),
 		Version: versionOne,
 	}
 	// TestHelmIsCertifiedIdentifier tests that helm chart has passed helm certification.
 	TestHelmIsCertifiedIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AffiliatedCertTestKey, "helmchart-is-certified"),
+		Url:     formTestURL(environment.AffiliatedCertTestKey, "helmchart-is-certified"),
 		Version: versionOne,
 	}
 	// TestOperatorIsInstalledViaOLMIdentifier tests that an Operator is installed via OLM.
 	TestOperatorIsInstalledViaOLMIdentifier = claim.Identifier{
-		Url:     formTestURL(common.OperatorTestKey, "install-source"),
+		Url:     formTestURL(environment.OperatorTestKey, "install-source"),
 		Version: versionOne,
 	}
 	// TestPodNodeSelectorAndAffinityBestPractices is the test ensuring nodeSelector and nodeAffinity are not used by a
 	// Pod.
 	TestPodNodeSelectorAndAffinityBestPractices = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "pod-scheduling"),
+		Url:     formTestURL(environment.LifecycleTestKey, "pod-scheduling"),
 		Version: versionOne,
 	}
 	// TestPodHighAvailabilityBestPractices is the test ensuring podAntiAffinity are used by a
 	// Pod when pod replica # are great than 1
 	TestPodHighAvailabilityBestPractices = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "pod-high-availability"),
+		Url:     formTestURL(environment.LifecycleTestKey, "pod-high-availability"),
 		Version: versionOne,
 	}
 
 	// TestPodClusterRoleBindingsBestPracticesIdentifier ensures Pod crb best practices.
 	TestPodClusterRoleBindingsBestPracticesIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AccessControlTestKey, "cluster-role-bindings"),
+		Url:     formTestURL(environment.AccessControlTestKey, "cluster-role-bindings"),
 		Version: versionOne,
 	}
 	// TestPodDeploymentBestPracticesIdentifier ensures a CNF follows best Deployment practices.
 	TestPodDeploymentBestPracticesIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "pod-owner-type"),
+		Url:     formTestURL(environment.LifecycleTestKey, "pod-owner-type"),
 		Version: versionOne,
 	}
 	// TestImagePullPolicyIdentifier ensures represent image pull policy practices.
 	TestImagePullPolicyIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "image-pull-policy"),
+		Url:     formTestURL(environment.LifecycleTestKey, "image-pull-policy"),
 		Version: versionOne,
 	}
 	// TestPodRecreationIdentifier ensures recreation best practices.
 	TestPodRecreationIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "pod-recreation"),
+		Url:     formTestURL(environment.LifecycleTestKey, "pod-recreation"),
 		Version: versionOne,
 	}
 	// TestPodRoleBindingsBestPracticesIdentifier represents rb best practices.
 	TestPodRoleBindingsBestPracticesIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AccessControlTestKey, "pod-role-bindings"),
+		Url:     formTestURL(environment.AccessControlTestKey, "pod-role-bindings"),
 		Version: versionOne,
 	}
 	// TestPodServiceAccountBestPracticesIdentifier tests Pod SA best practices.
 	TestPodServiceAccountBestPracticesIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AccessControlTestKey, "pod-service-account"),
+		Url:     formTestURL(environment.AccessControlTestKey, "pod-service-account"),
 		Version: versionOne,
 	}
 	//
 	TestPodAutomountServiceAccountIdentifier = claim.Identifier{
-		Url:     formTestURL(common.AccessControlTestKey, "pod-automount-service-account-token"),
+		Url:     formTestURL(environment.AccessControlTestKey, "pod-automount-service-account-token"),
 		Version: versionOne,
 	}
 	// TestServicesDoNotUseNodeportsIdentifier ensures Services don't utilize NodePorts.
 	TestServicesDoNotUseNodeportsIdentifier = claim.Identifier{
-		Url:     formTestURL(common.NetworkingTestKey, "service-type"),
+		Url:     formTestURL(environment.NetworkingTestKey, "service-type"),
 		Version: versionOne,
 	}
 	// TestUnalteredBaseImageIdentifier ensures the base image is not altered.
 	TestUnalteredBaseImageIdentifier = claim.Identifier{
-		Url:     formTestURL(common.PlatformAlterationTestKey, "base-image"),
+		Url:     formTestURL(environment.PlatformAlterationTestKey, "base-image"),
 		Version: versionOne,
 	}
 	// TestUnalteredStartupBootParamsIdentifier ensures startup boot params are not altered.
 	TestUnalteredStartupBootParamsIdentifier = claim.Identifier{
-		Url:     formTestURL(common.PlatformAlterationTestKey, "boot-params"),
+		Url:     formTestURL(environment.PlatformAlterationTestKey, "boot-params"),
 		Version: versionOne,
 	}
 	// TestLoggingIdentifier ensures stderr/stdout are used
 	TestLoggingIdentifier = claim.Identifier{
-		Url:     formTestURL(common.ObservabilityTestKey, "container-logging"),
+		Url:     formTestURL(environment.ObservabilityTestKey, "container-logging"),
 		Version: versionOne,
 	}
 	// TestCrdsStatusSubresourceIdentifier ensures all CRDs have a valid status subresource
 	TestCrdsStatusSubresourceIdentifier = claim.Identifier{
-		Url:     formTestURL(common.ObservabilityTestKey, "crd-status"),
+		Url:     formTestURL(environment.ObservabilityTestKey, "crd-status"),
 		Version: versionOne,
 	}
 	// TestShudtownIdentifier ensures pre-stop lifecycle is defined
 	TestShudtownIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "container-shutdown"),
+		Url:     formTestURL(environment.LifecycleTestKey, "container-shutdown"),
 		Version: versionOne,
 	}
 
 	// TestLivenessIdentifier ensure liveness is defined.
 	TestLivenessIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "liveness"),
+		Url:     formTestURL(environment.LifecycleTestKey, "liveness"),
 		Version: versionOne,
 	}
 
 	// TestReadinessIdentifier ensure readiness is defined.
 	TestReadinessIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "readiness"),
+		Url:     formTestURL(environment.LifecycleTestKey, "readiness"),
 		Version: versionOne,
 	}
 
 	// TestSysctlConfigsIdentifier ensures that the node's sysctl configs are consistent with the MachineConfig CR
 	TestSysctlConfigsIdentifier = claim.Identifier{
-		Url:     formTestURL(common.PlatformAlterationTestKey, "sysctl-config"),
+		Url:     formTestURL(environment.PlatformAlterationTestKey, "sysctl-config"),
 		Version: versionOne,
 	}
 	// TestDeploymentScalingIdentifier ensures deployment scale in/out operations work correctly.
 	TestDeploymentScalingIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "deployment-scaling"),
+		Url:     formTestURL(environment.LifecycleTestKey, "deployment-scaling"),
 		Version: versionOne,
 	}
 	// TestStateFulSetScalingIdentifier ensures statefulset scale in/out operations work correctly.
 	TestStateFulSetScalingIdentifier = claim.Identifier{
-		Url:     formTestURL(common.LifecycleTestKey, "statefulset-scaling"),
+		Url:     formTestURL(environment.LifecycleTestKey, "statefulset-scaling"),
 		Version: versionOne,
 	}
 	// TestIsRedHatReleaseIdentifier ensures platform is defined
 	TestIsRedHatReleaseIdentifier = claim.Identifier{
-		Url:     formTestURL(common.PlatformAlterationTestKey, "isredhat-release"),
+		Url:     formTestURL(environment.PlatformAlterationTestKey, "isredhat-release"),
 		Version: versionOne,
 	}
 	TestUndeclaredContainerPortsUsage = claim.Identifier{
-		Url:     formTestURL(common.NetworkingTestKey, "undeclared-container-ports-usage"),
+		Url:     formTestURL(environment.NetworkingTestKey, "undeclared-container-ports-usage"),
 		Version: versionOne,
 	}
 )
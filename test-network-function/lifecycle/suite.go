@@ -17,99 +17,21 @@
 package lifecycle
 
 import (
-	"fmt"
-	"path"
-	"strings"
-	"time"
-
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
-	log "github.com/sirupsen/logrus"
+	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	_ "github.com/test-network-function/test-network-function/pkg/checks/lifecycle" // Registers the checks of the suite
 	"github.com/test-network-function/test-network-function/pkg/config"
-	"github.com/test-network-function/test-network-function/pkg/config/configsections"
-	"github.com/test-network-function/test-network-function/pkg/tnf"
-	dd "github.com/test-network-function/test-network-function/pkg/tnf/handlers/deploymentsdrain"
-	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/nodeselector"
-	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/owners"
-	ps "github.com/test-network-function/test-network-function/pkg/tnf/handlers/podsets"
-	"github.com/test-network-function/test-network-function/pkg/tnf/handlers/scaling"
-	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
-	"github.com/test-network-function/test-network-function/pkg/tnf/reel"
+	"github.com/test-network-function/test-network-function/pkg/ginkgoadapter"
 	"github.com/test-network-function/test-network-function/pkg/tnf/testcases"
-	"github.com/test-network-function/test-network-function/pkg/utils"
-	"github.com/test-network-function/test-network-function/test-network-function/common"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
 	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
 	"github.com/test-network-function/test-network-function/test-network-function/results"
 )
 
-const (
-	baseNodeDrainTimeout         = 5 * time.Minute
-	maxNodeDrainTimeout          = 30 * time.Minute
-	scalingTimeout               = 1 * time.Minute
-	scalingPollingPeriod         = 1 * time.Second
-	postNodeDrainRecoveryTimeOut = 2 * time.Minute
-)
-
-var (
-	// nodeUncordonTestPath is the file location of the uncordon.json test case relative to the project root.
-	nodeUncordonTestPath = path.Join("pkg", "tnf", "handlers", "nodeuncordon", "uncordon.json")
-
-	// shutdownTestPath is the file location of shutdown.json test case relative to the project root.
-	shutdownTestPath = path.Join("pkg", "tnf", "handlers", "shutdown", "shutdown.json")
-
-	// livenessTestPath is the file location of liveness.json test case relative to the project root.
-	livenessTestPath = path.Join("pkg", "tnf", "handlers", "liveness", "liveness.json")
-
-	// readinessTestPath is the file location of readiness.json test case relative to the project root.
-	readinessTestPath = path.Join("pkg", "tnf", "handlers", "readiness", "readiness.json")
-
-	// shutdownTestDirectoryPath is the directory of the shutdown test
-	shutdownTestDirectoryPath = path.Join("pkg", "tnf", "handlers", "shutdown")
-
-	// livenessTestDirectoryPath is the directory of the liveness test
-	livenessTestDirectoryPath = path.Join("pkg", "tnf", "handlers", "liveness")
-
-	// readinessTestDirectoryPath is the directory of the readiness test
-	readinessTestDirectoryPath = path.Join("pkg", "tnf", "handlers", "readiness")
-
-	// relativeNodesTestPath is the relative path to the nodes.json test case.
-	relativeNodesTestPath = path.Join(common.PathRelativeToRoot, nodeUncordonTestPath)
-
-	// relativeShutdownTestPath is the relative path to the shutdown.json test case.
-	relativeShutdownTestPath = path.Join(common.PathRelativeToRoot, shutdownTestPath)
-
-	// relativeLivenessTestPath is the relative path to the liveness.json test case.
-	relativeLivenessTestPath = path.Join(common.PathRelativeToRoot, livenessTestPath)
-
-	// relativeReadinessTestPath is the relative path to the readiness.json test case.
-	relativeReadinessTestPath = path.Join(common.PathRelativeToRoot, readinessTestPath)
-
-	// relativeShutdownTestDirectoryPath is the directory of the shutdown directory
-	relativeShutdownTestDirectoryPath = path.Join(common.PathRelativeToRoot, shutdownTestDirectoryPath)
-
-	// relativelivenessTestDirectoryPath is the directory of the liveness directory
-	relativeLivenessTestDirectoryPath = path.Join(common.PathRelativeToRoot, livenessTestDirectoryPath)
-
-	// relativereadinessTestDirectoryPath is the directory of the readiness directory
-	relativeReadinessTestDirectoryPath = path.Join(common.PathRelativeToRoot, readinessTestDirectoryPath)
-
-	// podAntiAffinityTestPath is the file location of the podantiaffinity.json test case relative to the project root.
-	podAntiAffinityTestPath = path.Join("pkg", "tnf", "handlers", "podantiaffinity", "podantiaffinity.json")
-
-	// relativePodTestPath is the relative path to the podantiaffinity.json test case.
-	relativePodTestPath = path.Join(common.PathRelativeToRoot, podAntiAffinityTestPath)
-
-	// relativeimagepullpolicyTestPath is the relative path to the imagepullpolicy.json test case.
-	imagepullpolicyTestPath         = path.Join("pkg", "tnf", "handlers", "imagepullpolicy", "imagepullpolicy.json")
-	relativeimagepullpolicyTestPath = path.Join(common.PathRelativeToRoot, imagepullpolicyTestPath)
-)
-
-//
-// All actual test code belongs below here.  Utilities belong above.
-//
-var _ = ginkgo.Describe(common.LifecycleTestKey, func() {
+var _ = ginkgo.Describe(environment.LifecycleTestKey, func() {
 	conf, _ := ginkgo.GinkgoConfiguration()
-	if testcases.IsInFocus(conf.FocusStrings, common.LifecycleTestKey) {
+	if testcases.IsInFocus(conf.FocusStrings, environment.LifecycleTestKey) {
 		env := config.GetTestEnvironment()
 		ginkgo.BeforeEach(func() {
 			env.LoadAndRefresh()
@@ -121,640 +43,35 @@ var _ = ginkgo.Describe(common.LifecycleTestKey, func() {
 		ginkgo.ReportAfterEach(results.RecordResult)
 		ginkgo.AfterEach(env.CloseLocalShellContext)
 
-		testImagePolicy(env)
+		itRunsCheck(env, identifiers.TestImagePullPolicyIdentifier)
 
-		testNodeSelector(env)
+		itRunsCheck(env, identifiers.TestPodNodeSelectorAndAffinityBestPractices)
 
-		testShutdown(env)
+		itRunsCheck(env, identifiers.TestShudtownIdentifier)
 
-		testLiveness(env)
+		itRunsCheck(env, identifiers.TestLivenessIdentifier)
 
-		testReadiness(env)
+		itRunsCheck(env, identifiers.TestReadinessIdentifier)
 
-		testPodAntiAffinity(env)
+		ginkgo.When("CNF is designed in high availability mode ", func() {
+			itRunsCheck(env, identifiers.TestPodHighAvailabilityBestPractices)
+		})
 
-		if common.Intrusive() {
-			testPodsRecreation(env)
+		if environment.Intrusive() {
+			itRunsCheck(env, identifiers.TestPodRecreationIdentifier)
 
-			testScaling(env)
-			testStateFulSetScaling(env)
+			itRunsCheck(env, identifiers.TestDeploymentScalingIdentifier)
+			itRunsCheck(env, identifiers.TestStateFulSetScalingIdentifier)
 		}
 
-		testOwner(env)
+		itRunsCheck(env, identifiers.TestPodDeploymentBestPracticesIdentifier)
 	}
 })
 
-func waitForAllPodSetsReady(namespace string, timeout, pollingPeriod time.Duration, resourceType configsections.PodSetType, context *interactive.Context) int { //nolint:unparam // it is fine to use always the same value for timeout
-	var elapsed time.Duration
-	var notReadyPodSets []string
-
-	for elapsed < timeout {
-		_, notReadyPodSets = GetPodSets(namespace, resourceType, context)
-		log.Debugf("Waiting for %s to get ready, remaining: %d PodSets", string(resourceType), len(notReadyPodSets))
-		if len(notReadyPodSets) == 0 {
-			break
-		}
-		time.Sleep(pollingPeriod)
-		elapsed += pollingPeriod
-	}
-	return len(notReadyPodSets)
-}
-
-// restoreDeployments is the last attempt to restore the original test deployments' replicaCount
-func restoreDeployments(env *config.TestEnvironment) {
-	for i := range env.DeploymentsUnderTest {
-		// For each test deployment in the namespace, refresh the current replicas and compare.
-		refreshReplicas(&env.DeploymentsUnderTest[i], env)
-	}
-}
-
-// restoreStateFulSet is the last attempt to restore the original test PodSets' replicaCount
-func restoreStateFulSet(env *config.TestEnvironment) {
-	for i := range env.StateFulSetUnderTest {
-		// For each test StateFulSet in the namespace, refresh the current replicas and compare.
-		refreshReplicas(&env.StateFulSetUnderTest[i], env)
-	}
-}
-
-func refreshReplicas(podset *configsections.PodSet, env *config.TestEnvironment) {
-	podsets, notReadyPodsets := GetPodSets(podset.Namespace, podset.Type, env.GetLocalShellContext())
-
-	if len(notReadyPodsets) > 0 {
-		// Wait until the deployment/replicaset is ready
-		notReady := waitForAllPodSetsReady(podset.Namespace, scalingTimeout, scalingPollingPeriod, podset.Type, env.GetLocalShellContext())
-		if notReady != 0 {
-			collectNodeAndPendingPodInfo(podset.Namespace, env.GetLocalShellContext())
-			ginkgo.AbortSuite(fmt.Sprintf("Could not restore %s replicaCount for namespace %s.", string(podset.Type), podset.Namespace))
-		}
-	}
-	if podset.Hpa.HpaName != "" { // it have hpa and need to update the max min
-		runHpaScalingTest(podset, env.GetLocalShellContext())
-	}
-	key := podset.Namespace + ":" + podset.Name
-	dep, ok := podsets[key]
-	if ok {
-		if dep.Replicas != podset.Replicas {
-			log.Warn(string(podset.Type), podset.Name, " replicaCount (", podset.Replicas, ") needs to be restored.")
-
-			// Try to scale to the original deployments/statefulsets replicaCount.
-			runScalingTest(podset, env.GetLocalShellContext())
-
-			env.SetNeedsRefresh()
-		}
-	}
-}
-
-func closeOcSessionsByPodset(containers map[configsections.ContainerIdentifier]*configsections.Container, podset *configsections.PodSet) {
-	log.Debug("close session for", string(podset.Type), "=", podset.Name, " start")
-	defer log.Debug("close session for", string(podset.Type), "=", podset.Name, " done")
-	for cid, c := range containers {
-		if cid.Namespace == podset.Namespace && strings.HasPrefix(cid.PodName, podset.Name+"-") {
-			log.Infof("Closing session to %s %s", cid.PodName, cid.ContainerName)
-			c.CloseOc()
-			delete(containers, cid)
-		}
-	}
-}
-
-// runScalingTest Runs a Scaling handler TC and waits for all the deployments/statefulset to be ready.
-func runScalingTest(podset *configsections.PodSet, context *interactive.Context) {
-	handler := scaling.NewScaling(common.DefaultTimeout, podset.Namespace, podset.Name, string(podset.Type), podset.Replicas)
-	test, err := tnf.NewTest(context.GetExpecter(), handler, []reel.Handler{handler}, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	test.RunAndValidate()
-
-	// Wait until the deployment/statefulset is ready
-	notReady := waitForAllPodSetsReady(podset.Namespace, scalingTimeout, scalingPollingPeriod, podset.Type, context)
-	if notReady != 0 {
-		collectNodeAndPendingPodInfo(podset.Namespace, context)
-		ginkgo.Fail(fmt.Sprintf("Failed to scale deployment for namespace %s.", podset.Namespace))
-	}
-}
-
-func runHpaScalingTest(podset *configsections.PodSet, context *interactive.Context) {
-	handler := scaling.NewHpaScaling(common.DefaultTimeout, podset.Namespace, podset.Hpa.HpaName, podset.Hpa.MinReplicas, podset.Hpa.MaxReplicas)
-	test, err := tnf.NewTest(context.GetExpecter(), handler, []reel.Handler{handler}, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	test.RunAndValidate()
-
-	// Wait until the deployment/statefulset is ready
-	notReady := waitForAllPodSetsReady(podset.Namespace, scalingTimeout, scalingPollingPeriod, podset.Type, context)
-	if notReady != 0 {
-		collectNodeAndPendingPodInfo(podset.Namespace, context)
-		ginkgo.Fail(fmt.Sprintf("Failed to auto-scale %s for namespace %s.", string(podset.Type), podset.Namespace))
-	}
-}
-
-func testScaling(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestDeploymentScalingIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		ginkgo.By("Testing deployment scaling")
-		defer restoreDeployments(env)
-		defer env.SetNeedsRefresh()
-
-		if len(env.DeploymentsUnderTest) == 0 {
-			ginkgo.Skip("No test deployments found.")
-		}
-		for i := range env.DeploymentsUnderTest {
-			runScalingfunc(&env.DeploymentsUnderTest[i], env)
-		}
-	})
-}
-func testStateFulSetScaling(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestStateFulSetScalingIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		ginkgo.By("Testing StatefulSet scaling")
-		defer restoreStateFulSet(env)
-		defer env.SetNeedsRefresh()
-
-		if len(env.StateFulSetUnderTest) == 0 {
-			ginkgo.Skip("No test StatefulSet found.")
-		}
-		for i := range env.StateFulSetUnderTest {
-			runScalingfunc(&env.StateFulSetUnderTest[i], env)
-		}
-	})
-}
-
-func runScalingfunc(podset *configsections.PodSet, env *config.TestEnvironment) {
-	ginkgo.By(fmt.Sprintf("Scaling %s=%s, Replicas=%d (ns=%s)", string(podset.Type), podset.Name, podset.Replicas, podset.Namespace))
-
-	closeOcSessionsByPodset(env.ContainersUnderTest, podset)
-	replicaCount := podset.Replicas
-	podsetscale := *podset
-	if podsetscale.Hpa.HpaName != "" {
-		podsetscale.Hpa.MinReplicas = replicaCount - 1
-		podsetscale.Hpa.MaxReplicas = replicaCount - 1
-		runHpaScalingTest(&podsetscale, env.GetLocalShellContext()) // scale in
-		podsetscale.Hpa.MinReplicas = replicaCount
-		podsetscale.Hpa.MaxReplicas = replicaCount
-		runHpaScalingTest(&podsetscale, env.GetLocalShellContext()) // scale out
-	} else {
-		// ScaleIn, removing one pod from the replicaCount
-		podsetscale.Replicas = replicaCount - 1
-		runScalingTest(&podsetscale, env.GetLocalShellContext())
-
-		// Scaleout, restoring the original replicaCount number
-		podsetscale.Replicas = replicaCount
-		runScalingTest(&podsetscale, env.GetLocalShellContext())
-	}
-}
-
-func testNodeSelector(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestPodNodeSelectorAndAffinityBestPractices)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		ginkgo.By("Testing pod nodeSelector")
-		context := env.GetLocalShellContext()
-		badPods := []configsections.Pod{}
-		for _, podUnderTest := range env.PodsUnderTest {
-			ginkgo.By(fmt.Sprintf("Testing pod nodeSelector %s/%s", podUnderTest.Namespace, podUnderTest.Name))
-			tester := nodeselector.NewNodeSelector(common.DefaultTimeout, podUnderTest.Name, podUnderTest.Namespace)
-			test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-			gomega.Expect(err).To(gomega.BeNil())
-
-			test.RunWithCallbacks(nil, func() {
-				tnf.ClaimFilePrintf("FAILURE: Pod %s/%s has nodeSelector/nodeAffinity rule", podUnderTest.Namespace, podUnderTest.Name)
-				badPods = append(badPods, *podUnderTest)
-			}, func(err error) {
-				tnf.ClaimFilePrintf("ERROR: Pod %s/%s, error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
-				badPods = append(badPods, *podUnderTest)
-			})
-		}
-
-		if n := len(badPods); n > 0 {
-			log.Debugf("Pods with nodeSelector/nodeAffinity: %+v", badPods)
-			ginkgo.Fail(fmt.Sprintf("%d pods found with nodeSelector/nodeAffinity rules", n))
-		}
-	})
-}
-
-//nolint:dupl
-func testShutdown(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestShudtownIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		failedPods := []*configsections.Pod{}
-		ginkgo.By("Testing PUTs are configured with pre-stop lifecycle")
-		for _, podUnderTest := range env.PodsUnderTest {
-			ginkgo.By(fmt.Sprintf("should have pre-stop configured %s/%s", podUnderTest.Namespace, podUnderTest.Name))
-			passed := shutdownTest(podUnderTest.Namespace, podUnderTest.Name, env.GetLocalShellContext())
-			if !passed {
-				failedPods = append(failedPods, podUnderTest)
-			}
-		}
-		if n := len(failedPods); n > 0 {
-			log.Debugf("Pods without pre-stop configured: %+v", failedPods)
-			ginkgo.Fail(fmt.Sprintf("%d pods do not have pre-stop configured.", n))
-		}
-	})
-}
-
-func shutdownTest(podNamespace, podName string, context *interactive.Context) bool {
-	passed := true
-	values := make(map[string]interface{})
-	values["POD_NAMESPACE"] = podNamespace
-	values["POD_NAME"] = podName
-	values["GO_TEMPLATE_PATH"] = relativeShutdownTestDirectoryPath
-	tester, handlers := utils.NewGenericTesterAndValidate(relativeShutdownTestPath, common.RelativeSchemaPath, values)
-	test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(test).ToNot(gomega.BeNil())
-
-	test.RunWithCallbacks(nil, func() {
-		tnf.ClaimFilePrintf("FAILURE: Pod %s/%s does not have pre-stop configured", podNamespace, podName)
-		passed = false
-	}, func(err error) {
-		tnf.ClaimFilePrintf("ERROR: Pod %s/%s, error: %v", podNamespace, podName, err)
-		passed = false
-	})
-	return passed
-}
-
-//nolint:dupl
-func testLiveness(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestLivenessIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		failedPods := []*configsections.Pod{}
-		ginkgo.By("Testing PUTs are configured with liveness lifecycle")
-		for _, podUnderTest := range env.PodsUnderTest {
-			ginkgo.By(fmt.Sprintf("should have liveness configured %s/%s", podUnderTest.Namespace, podUnderTest.Name))
-			passed := livenessTest(podUnderTest.Namespace, podUnderTest.Name, env.GetLocalShellContext())
-			if !passed {
-				failedPods = append(failedPods, podUnderTest)
-			}
-		}
-		if n := len(failedPods); n > 0 {
-			log.Debugf("Pods without liveness: %+v", failedPods)
-			ginkgo.Fail(fmt.Sprintf("%d pods do not have liveness configured.", n))
-		}
-	})
-}
-
-func livenessTest(podNamespace, podName string, context *interactive.Context) bool {
-	passed := true
-	values := make(map[string]interface{})
-	values["POD_NAMESPACE"] = podNamespace
-	values["POD_NAME"] = podName
-	values["GO_TEMPLATE_PATH"] = relativeLivenessTestDirectoryPath
-	tester, handlers := utils.NewGenericTesterAndValidate(relativeLivenessTestPath, common.RelativeSchemaPath, values)
-	test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(test).ToNot(gomega.BeNil())
-
-	test.RunWithCallbacks(nil, func() {
-		tnf.ClaimFilePrintf("FAILURE: Pod %s/%s does not have liveness defined", podNamespace, podName)
-		passed = false
-	}, func(err error) {
-		tnf.ClaimFilePrintf("ERROR: Pod %s/%s, error: %v", podNamespace, podName, err)
-		passed = false
-	})
-	return passed
-}
-
-//nolint:dupl
-func testReadiness(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestReadinessIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		failedPods := []*configsections.Pod{}
-		ginkgo.By("Testing PUTs are configured with readiness lifecycle")
-		for _, podUnderTest := range env.PodsUnderTest {
-			ginkgo.By(fmt.Sprintf("should have readiness configured %s/%s", podUnderTest.Namespace, podUnderTest.Name))
-			passed := readinessTest(podUnderTest.Namespace, podUnderTest.Name, env.GetLocalShellContext())
-			if !passed {
-				failedPods = append(failedPods, podUnderTest)
-			}
-		}
-		if n := len(failedPods); n > 0 {
-			log.Debugf("Pods without readiness: %+v", failedPods)
-			ginkgo.Fail(fmt.Sprintf("%d pods do not have readiness configured.", n))
-		}
-	})
-}
-
-func readinessTest(podNamespace, podName string, context *interactive.Context) bool {
-	passed := true
-	values := make(map[string]interface{})
-	values["POD_NAMESPACE"] = podNamespace
-	values["POD_NAME"] = podName
-	values["GO_TEMPLATE_PATH"] = relativeReadinessTestDirectoryPath
-	tester, handlers := utils.NewGenericTesterAndValidate(relativeReadinessTestPath, common.RelativeSchemaPath, values)
-	test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(test).ToNot(gomega.BeNil())
-
-	test.RunWithCallbacks(nil, func() {
-		tnf.ClaimFilePrintf("FAILURE: Pod %s/%s does not have readiness defined", podNamespace, podName)
-		passed = false
-	}, func(err error) {
-		tnf.ClaimFilePrintf("ERROR: Pod %s/%s, error: %v", podNamespace, podName, err)
-		passed = false
-	})
-	return passed
-}
-
-func cleanupNodeDrain(env *config.TestEnvironment, nodeName string) {
-	uncordonNode(nodeName, env.GetLocalShellContext())
-	for _, ns := range env.NameSpacesUnderTest {
-		notReady := waitForAllPodSetsReady(ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.Deployment, env.GetLocalShellContext())
-		if notReady != 0 {
-			collectNodeAndPendingPodInfo(ns, env.GetLocalShellContext())
-			ginkgo.AbortSuite(fmt.Sprintf("Cleanup after node drain for %s failed, stopping tests to ensure cluster integrity", nodeName))
-		}
-		notReadyStateFulSets := waitForAllPodSetsReady(ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.StateFulSet, env.GetLocalShellContext())
-		if notReadyStateFulSets != 0 {
-			collectNodeAndPendingPodInfo(ns, env.GetLocalShellContext())
-			ginkgo.AbortSuite(fmt.Sprintf("Cleanup after node drain for %s failed, stopping tests to ensure cluster integrity", nodeName))
-		}
-	}
-}
-
-func testNodeDrain(env *config.TestEnvironment, nodeName string) {
-	ginkgo.By(fmt.Sprintf("Testing node drain for %s\n", nodeName))
-	// Ensure the node is uncordoned before exiting the function,
-	// and all podsets(deployments/statefulset) are ready
-	defer cleanupNodeDrain(env, nodeName)
-
-	// drain node
-	if err := drainNode(nodeName, env.GetLocalShellContext()); err != nil {
-		ginkgo.Fail(fmt.Sprintf("Draining node %s failed: %s", nodeName, err))
-	}
-
-	for _, ns := range env.NameSpacesUnderTest {
-		notReadyDeployments := waitForAllPodSetsReady(ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.Deployment, env.GetLocalShellContext())
-		if notReadyDeployments != 0 {
-			collectNodeAndPendingPodInfo(ns, env.GetLocalShellContext())
-			ginkgo.Fail(fmt.Sprintf("Failed to recover deployments on namespace %s after draining node %s.", ns, nodeName))
-		}
-		notReadyStateFulSets := waitForAllPodSetsReady(ns, postNodeDrainRecoveryTimeOut, scalingPollingPeriod, configsections.StateFulSet, env.GetLocalShellContext())
-		if notReadyStateFulSets != 0 {
-			collectNodeAndPendingPodInfo(ns, env.GetLocalShellContext())
-			ginkgo.Fail(fmt.Sprintf("Failed to recover statefulsets on namespace %s after draining node %s.", ns, nodeName))
-		}
-	}
-	// If we got this far, all deployments/statefulsets are ready after draining the node
-	tnf.ClaimFilePrintf("Node drain for %s succeeded", nodeName)
-}
-
-func testPodsRecreation(env *config.TestEnvironment) {
-	deployments := make(ps.PodSetMap)
-	var notReadyDeployments []string
-	statefulsets := make(ps.PodSetMap)
-	var notReadyStatefulsets []string
-
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestPodRecreationIdentifier)
+// itRunsCheck adds the spec running the check with the identifier id.
+func itRunsCheck(env *config.TestEnvironment, id claim.Identifier) {
+	testID := identifiers.XformToGinkgoItIdentifier(id)
 	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		ginkgo.By("Testing node draining effect of deployment")
-		ginkgo.By(fmt.Sprintf("test deployment in namespace %s", env.NameSpacesUnderTest))
-		for _, ns := range env.NameSpacesUnderTest {
-			var dps ps.PodSetMap
-			var sfs ps.PodSetMap
-			dps, notReadyDeployments = GetPodSets(ns, configsections.Deployment, env.GetLocalShellContext())
-			for dpKey, dp := range dps {
-				deployments[dpKey] = dp
-			}
-			sfs, notReadyStatefulsets = GetPodSets(ns, configsections.StateFulSet, env.GetLocalShellContext())
-			for sfKey, sf := range sfs {
-				statefulsets[sfKey] = sf
-			}
-			// We require that all deployments/statefulset have the desired number of replicas and are all up to date
-			if len(notReadyDeployments) != 0 && len(notReadyStatefulsets) != 0 {
-				ginkgo.Skip("Can not test when podsets are not ready")
-			}
-		}
-		if len(deployments) == 0 && len(statefulsets) == 0 {
-			ginkgo.Skip("no valid deployment or statefulset")
-		}
-		defer env.SetNeedsRefresh()
-		ginkgo.By("should create new replicas when node is drained")
-		// We need to delete all Oc sessions because the drain operation is often deleting oauth-openshift pod
-		// This results in lost connectivity for oc sessions
-		env.ResetOc()
-		for _, n := range env.NodesUnderTest {
-			if !n.HasPodset() {
-				log.Debug("node ", n.Name, " has no podset, skip draining")
-				continue
-			}
-			testNodeDrain(env, n.Name)
-		}
-	})
-}
-
-// GetPodSets returns map of podsets(deployments/statefulset) and names of not-ready podsets
-func GetPodSets(namespace string, resourceType configsections.PodSetType, context *interactive.Context) (podsets ps.PodSetMap, notReadypodsets []string) {
-	tester := ps.NewPodSets(common.DefaultTimeout, namespace, string(resourceType))
-	test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	test.RunAndValidate()
-
-	podsets = tester.GetPodSets()
-	for name, d := range podsets {
-		if d.Unavailable != 0 || d.Ready != d.Replicas || (d.Available != d.Replicas && d.Current != d.Replicas) || d.UpToDate != d.Replicas {
-			notReadypodsets = append(notReadypodsets, name)
-			log.Tracef("%s %s: not ready", string(resourceType), name)
-		} else {
-			log.Tracef("%s %s: ready", string(resourceType), name)
-		}
-	}
-
-	return podsets, notReadypodsets
-}
-
-func collectNodeAndPendingPodInfo(ns string, context *interactive.Context) {
-	nodeStatus, _ := utils.ExecuteCommand("oc get nodes -o json | jq '.items[]|{name:.metadata.name, taints:.spec.taints}'", common.DefaultTimeout, context)
-	common.TcClaimLogPrintf("Namespace: %s\nNode status:\n%s", ns, nodeStatus)
-
-	cmd := fmt.Sprintf("oc get pods -n %s --field-selector=status.phase!=Running,status.phase!=Succeeded -o json | jq '.items[]|{name:.metadata.name, status:.status}'", ns)
-	podStatus, _ := utils.ExecuteCommand(cmd, common.DefaultTimeout, context)
-	common.TcClaimLogPrintf("Pending Pods:\n%s", podStatus)
-
-	cmd = fmt.Sprintf("oc get events -n %s --field-selector type!=Normal -o json --sort-by='.lastTimestamp' | jq '.items[]|{object:.involvedObject, reason:.reason, type:.type, message:.message, lastSeen:.lastTimestamp}'", ns)
-	events, _ := utils.ExecuteCommand(cmd, common.DefaultTimeout, context)
-	common.TcClaimLogPrintf("Events:\n%s", events)
-}
-
-// getNumPodsDeployedOnNode is a helper function that returns the number of all pods
-// deployed in a given node.
-func getNumPodsDeployedOnNode(nodeName string, context *interactive.Context) (int, error) {
-	const cmdFmt = "oc get pods --all-namespaces -o wide --field-selector spec.nodeName=%s -l pod-template-hash"
-	cmd := fmt.Sprintf(cmdFmt, nodeName)
-	out, err := utils.ExecuteCommand(cmd, common.DefaultTimeout, context)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get a pod list of pods deployed on the node: %s", err)
-	}
-
-	// The ouptut should be a table, should we expect at list one line for the columns description.
-	numPodsDeployed := len(strings.Split(out, "\n"))
-	if numPodsDeployed == 0 {
-		return 0, fmt.Errorf("empty output from cmd: %q", cmd)
-	}
-
-	return numPodsDeployed - 1, nil
-}
-
-func drainNode(node string, context *interactive.Context) error {
-	// Before draining, we'll get the number of pods currently deployed on it.
-	numPodsDeployed, err := getNumPodsDeployedOnNode(node, context)
-	if err != nil {
-		return fmt.Errorf("failed to get number of pods deployed in the node: %s", err)
-	}
-
-	// We'll add one minute per pod to the base timeout for the node drain operation.
-	nodeDrainTimeout := baseNodeDrainTimeout + (time.Duration(numPodsDeployed) * time.Minute)
-
-	// Make sure the calculated timeout won't exceed the allowed maximum.
-	if nodeDrainTimeout > maxNodeDrainTimeout {
-		nodeDrainTimeout = maxNodeDrainTimeout
-	}
-
-	log.Infof("Dynamic timeout for draining node %s: %s (pods deployed: %d)", node, nodeDrainTimeout, numPodsDeployed)
-
-	tester := dd.NewDeploymentsDrain(nodeDrainTimeout, node)
-	test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-
-	startTime := time.Now()
-	result, err := test.Run()
-	if err != nil {
-		return err
-	}
-
-	elapsedTime := time.Since(startTime)
-	log.Infof("Draining node %s took %s.", node, elapsedTime)
-
-	if result != tnf.SUCCESS {
-		return fmt.Errorf("tester returned result code %d", result)
-	}
-
-	return nil
-}
-
-func uncordonNode(node string, context *interactive.Context) {
-	values := make(map[string]interface{})
-	values["NODE"] = node
-	tester, handlers := utils.NewGenericTesterAndValidate(relativeNodesTestPath, common.RelativeSchemaPath, values)
-	test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(test).ToNot(gomega.BeNil())
-
-	test.RunWithCallbacks(nil, func() {
-		tnf.ClaimFilePrintf("FAILURE: unable to uncordon node %s", node)
-		ginkgo.AbortSuite(fmt.Sprintf("Failed to uncordon node %s, stopping tests to ensure cluster integrity", node))
-	}, func(err error) {
-		tnf.ClaimFilePrintf("ERROR: unable to uncordon node %s: %s", node, err)
-		ginkgo.AbortSuite(fmt.Sprintf("Failed to uncordon node %s, stopping tests to ensure cluster integrity", node))
-	})
-}
-
-// Pod antiaffinity test for all deployments
-func testPodAntiAffinity(env *config.TestEnvironment) {
-	ginkgo.When("CNF is designed in high availability mode ", func() {
-		testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestPodHighAvailabilityBestPractices)
-		ginkgo.It(testID, ginkgo.Label(testID), func() {
-			ginkgo.By("Should set pod replica number greater than 1 and corresponding pod anti-affinity rules in deployment")
-			if len(env.DeploymentsUnderTest) == 0 {
-				ginkgo.Skip("No test deployments found.")
-			}
-
-			badDeployments := []configsections.PodSet{}
-			for _, deployment := range env.DeploymentsUnderTest {
-				ginkgo.By(fmt.Sprintf("Testing Pod AntiAffinity on Deployment=%s, Replicas=%d (ns=%s)",
-					deployment.Name, deployment.Replicas, deployment.Namespace))
-				if !podAntiAffinity(deployment.Name, deployment.Namespace, deployment.Replicas, env.GetLocalShellContext()) {
-					badDeployments = append(badDeployments, deployment)
-				}
-			}
-
-			if n := len(badDeployments); n > 0 {
-				log.Debugf("Deployments without a valid podAntiAffinity rule: %+v", badDeployments)
-				ginkgo.Fail(fmt.Sprintf("%d deployments failed the test for replicaCount > 1 and podAntiAffinity rule.", n))
-			}
-		})
-	})
-}
-
-// check pod antiaffinity definition for a deployment
-func podAntiAffinity(deployment, podNamespace string, replica int, context *interactive.Context) bool {
-	values := make(map[string]interface{})
-	values["DEPLOYMENT_NAME"] = deployment
-	values["DEPLOYMENT_NAMESPACE"] = podNamespace
-	tester, handlers := utils.NewGenericTesterAndValidate(relativePodTestPath, common.RelativeSchemaPath, values)
-	test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-	gomega.Expect(err).To(gomega.BeNil())
-	gomega.Expect(test).ToNot(gomega.BeNil())
-
-	result := true
-	test.RunWithCallbacks(nil, func() {
-		result = false
-		if replica > 1 {
-			tnf.ClaimFilePrintf("FAILURE: The deployment replica count is %d, but a podAntiAffinity rule is not defined, "+
-				"you might want to change it in deployment %s in namespace %s", replica, deployment, podNamespace)
-		} else {
-			tnf.ClaimFilePrintf("FAILURE: The deployment replica count is %d. Pod replica should be > 1 with an "+
-				"podAntiAffinity rule defined . You might want to change it in deployment %s in namespace %s",
-				replica, deployment, podNamespace)
-		}
-	}, func(err error) {
-		result = false
-		tnf.ClaimFilePrintf("ERROR: Failed to get replica count and podAntiAffinity for deployment %s (ns %s). Error: %v", err)
-	})
-
-	return result
-}
-
-func testOwner(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestPodDeploymentBestPracticesIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		ginkgo.By("Testing owners of CNF pod, should be replicas Set")
-		context := env.GetLocalShellContext()
-		failedPods := []*configsections.Pod{}
-		for _, podUnderTest := range env.PodsUnderTest {
-			ginkgo.By(fmt.Sprintf("Should be ReplicaSet %s %s", podUnderTest.Namespace, podUnderTest.Name))
-			tester := owners.NewOwners(common.DefaultTimeout, podUnderTest.Namespace, podUnderTest.Name)
-			test, err := tnf.NewTest(context.GetExpecter(), tester, []reel.Handler{tester}, context.GetErrorChannel())
-			gomega.Expect(err).To(gomega.BeNil())
-
-			test.RunWithCallbacks(nil, func() {
-				tnf.ClaimFilePrintf("FAILURE: Pod %s/%s is not owned by a replica set", podUnderTest.Namespace, podUnderTest.Name)
-				failedPods = append(failedPods, podUnderTest)
-			}, func(err error) {
-				tnf.ClaimFilePrintf("ERROR: Pod %s/%s, error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
-				failedPods = append(failedPods, podUnderTest)
-			})
-		}
-		if n := len(failedPods); n > 0 {
-			log.Debugf("Pods not owned by a replica set: %+v", failedPods)
-			ginkgo.Fail(fmt.Sprintf("%d pods are not owned by a replica set.", n))
-		}
-	})
-}
-
-func testImagePolicy(env *config.TestEnvironment) {
-	testID := identifiers.XformToGinkgoItIdentifier(identifiers.TestImagePullPolicyIdentifier)
-	ginkgo.It(testID, ginkgo.Label(testID), func() {
-		context := env.GetLocalShellContext()
-		failedPods := []*configsections.Pod{}
-		for _, podUnderTest := range env.PodsUnderTest {
-			values := make(map[string]interface{})
-			values["POD_NAMESPACE"] = podUnderTest.Namespace
-			values["POD_NAME"] = podUnderTest.Name
-			for i := 0; i < podUnderTest.ContainerCount; i++ {
-				values["CONTAINER_NUM"] = i
-				tester, handlers := utils.NewGenericTesterAndValidate(relativeimagepullpolicyTestPath, common.RelativeSchemaPath, values)
-				test, err := tnf.NewTest(context.GetExpecter(), *tester, handlers, context.GetErrorChannel())
-				gomega.Expect(err).To(gomega.BeNil())
-				gomega.Expect(test).ToNot(gomega.BeNil())
-
-				test.RunWithCallbacks(nil, func() {
-					tnf.ClaimFilePrintf("FAILURE: Pod %s/%s does not set imagePullPolicy to IfNotPresent", podUnderTest.Namespace, podUnderTest.Name)
-					failedPods = append(failedPods, podUnderTest)
-				}, func(err error) {
-					tnf.ClaimFilePrintf("ERROR: Pod %s/%s, error: %v", podUnderTest.Namespace, podUnderTest.Name, err)
-					failedPods = append(failedPods, podUnderTest)
-				})
-			}
-		}
-		if n := len(failedPods); n > 0 {
-			log.Debugf("Pods with incorrect image pull policy: %+v", failedPods)
-			ginkgo.Fail(fmt.Sprintf("%d pods have incorrect image pull policy.", n))
-		}
+		ginkgoadapter.RunSpec(env, id)
 	})
 }
@@ -22,14 +22,17 @@ import (
 
 	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
 	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
+	"github.com/test-network-function/test-network-function/pkg/ginkgoadapter"
 	"github.com/test-network-function/test-network-function/test-network-function/identifiers"
 )
 
 // results is the results map
-var results = map[string][]claim.Result{}
+var results = map[string][]claimutil.Result{}
 
 // RecordResult is a hook provided to save aspects of the ginkgo.GinkgoTestDescription for a given claim.Identifier.
-// Multiple results for a given identifier are aggregated as an array under the same key.
+// Multiple results for a given identifier are aggregated as an array under the same key.  The objects reported with
+// tnf.ReportNonCompliantObject and the tests retried during the test are attached to the result.
 func RecordResult(report ginkgoTypes.SpecReport) { //nolint:gocritic // From Ginkgo
 	if claimID, ok := identifiers.TestIDToClaimID[report.LeafNodeText]; ok {
 		var key string
@@ -39,7 +42,7 @@ func RecordResult(report ginkgoTypes.SpecReport) { //nolint:gocritic // From Gin
 		}
 		key = strings.TrimLeft(key, "-") + "-" + report.LeafNodeText
 		testText := identifiers.Catalog[claimID].Description
-		results[key] = append(results[key], claim.Result{
+		result := claimutil.Result{Result: claim.Result{
 			Duration:           int(report.RunTime.Nanoseconds()),
 			FailureLocation:    report.FailureLocation().String(),
 			FailureLineContent: report.FailureLocation().ContentsOfLine(),
@@ -50,26 +53,25 @@ func RecordResult(report ginkgoTypes.SpecReport) { //nolint:gocritic // From Gin
 			EndTime:            report.EndTime.String(),
 			CapturedTestOutput: report.CapturedGinkgoWriterOutput,
 			TestID:             &claimID,
-		})
+		}}
+		if objects := ginkgoadapter.GetNonCompliantObjects(report); len(objects) > 0 {
+			result.NonCompliantObjects = objects
+		}
+		if tests := ginkgoadapter.GetRetriedTests(report); len(tests) > 0 {
+			result.RetriedTests = tests
+		}
+		results[key] = append(results[key], result)
 	} else {
 		panic(fmt.Sprintf("TestID %s has no corresponding Claim ID", report.LeafNodeText))
 	}
 }
 
-// GetReconciledResults is a function added to aggregate a Claim's results.  Due to the limitations of
-// test-network-function-claim's Go Client, results are generalized to map[string]interface{}.  This method is needed
-// to take the results gleaned from JUnit output, and to combine them with the contexts built up by subsequent calls to
-// RecordResult.  The combination of the two forms a Claim's results.
-func GetReconciledResults() map[string]interface{} {
-	resultMap := make(map[string]interface{})
+// GetReconciledResults is a function added to aggregate a Claim's results.  The results are stored into the claim
+// with claimutil.SetResults, which keeps the details of the results the claim.Result does not have.
+func GetReconciledResults() map[string][]claimutil.Result {
+	resultMap := make(map[string][]claimutil.Result, len(results))
 	for key, vals := range results {
-		// initializes the result map, if necessary
-		if _, ok := resultMap[key]; !ok {
-			resultMap[key] = make([]claim.Result, 0)
-		}
-		for _, val := range vals { //nolint:gocritic // Only done once at the end
-			resultMap[key] = append(resultMap[key].([]claim.Result), val)
-		}
+		resultMap[key] = append([]claimutil.Result{}, vals...)
 	}
 	return resultMap
 }
@@ -17,10 +17,14 @@
 package suite
 
 import (
+	"context"
 	j "encoding/json"
 	"flag"
+	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
@@ -31,14 +35,20 @@ import (
 	"github.com/onsi/gomega"
 	log "github.com/sirupsen/logrus"
 	"github.com/test-network-function/test-network-function-claim/pkg/claim"
+	"github.com/test-network-function/test-network-function/pkg/claimutil"
 	"github.com/test-network-function/test-network-function/pkg/config"
+	"github.com/test-network-function/test-network-function/pkg/ginkgoadapter"
 	"github.com/test-network-function/test-network-function/pkg/junit"
+	"github.com/test-network-function/test-network-function/pkg/snapshot"
 	"github.com/test-network-function/test-network-function/pkg/tnf"
+	"github.com/test-network-function/test-network-function/pkg/tnf/budget"
+	"github.com/test-network-function/test-network-function/pkg/tnf/interactive"
 
 	utils "github.com/test-network-function/test-network-function/pkg/utils"
 	_ "github.com/test-network-function/test-network-function/test-network-function/accesscontrol"
 	_ "github.com/test-network-function/test-network-function/test-network-function/certification"
 	"github.com/test-network-function/test-network-function/test-network-function/common"
+	"github.com/test-network-function/test-network-function/test-network-function/environment"
 	_ "github.com/test-network-function/test-network-function/test-network-function/generic"
 	_ "github.com/test-network-function/test-network-function/test-network-function/lifecycle"
 	_ "github.com/test-network-function/test-network-function/test-network-function/networking"
@@ -62,6 +72,8 @@ const (
 	// dateTimeFormatDirective is the directive used to format date/time according to ISO 8601.
 	dateTimeFormatDirective = "2006-01-02T15:04:05+00:00"
 	extraInfoKey            = "testsExtraInfo"
+	// sessionReconnectionsKey is the claim configuration holding the reconnection counts of the shared sessions.
+	sessionReconnectionsKey = "sessionReconnections"
 )
 
 var (
@@ -78,8 +90,39 @@ var (
 	// gitDisplayRelease is a string used to hold the text to display
 	// the version on screen and in the claim file
 	gitDisplayRelease string
+	// timeBudget provides the contexts of the tests, bounded by the time budget of the run and of their suite.
+	timeBudget *budget.Budget
 )
 
+// The tests of a suite are skipped once its time budget is exhausted.
+var _ = ginkgo.BeforeEach(func() {
+	if timeBudget == nil {
+		return
+	}
+	if err := specContext().Err(); err != nil {
+		ginkgo.Skip(fmt.Sprintf("The time budget of the %s suite is exhausted: %v", specSuite(), err))
+	}
+})
+
+// specSuite returns the test suite of the running spec, which is its outermost container, or "" outside of a suite.
+func specSuite() string {
+	containers := ginkgo.CurrentSpecReport().ContainerHierarchyTexts
+	if len(containers) == 0 {
+		return ""
+	}
+	return containers[0]
+}
+
+// specContext returns the context of the tests of the running spec, which is the context of its suite, or of the run
+// outside of a suite.
+func specContext() context.Context {
+	suite := specSuite()
+	if suite == "" {
+		return timeBudget.Run()
+	}
+	return timeBudget.Suite(suite)
+}
+
 func init() {
 	claimPath = flag.String(claimPathFlagKey, defaultClaimPath,
 		"the path where the claimfile will be output")
@@ -135,9 +178,9 @@ func TestTest(t *testing.T) {
 	diagnosticMode := len(ginkgoConfig.FocusStrings) == 0
 
 	gomega.RegisterFailHandler(ginkgo.Fail)
-	common.SetLogFormat()
-	common.SetLogLevel()
-	if common.LogLevelTraceEnabled {
+	environment.SetLogFormat()
+	environment.SetLogLevel()
+	if environment.LogLevelTraceEnabled {
 		config.EnableExpectersVerboseMode()
 	}
 	// Display GinkGo Version
@@ -151,6 +194,20 @@ func TestTest(t *testing.T) {
 	}
 	log.Info("Version: ", gitDisplayRelease, " ( ", GitCommit, " )")
 
+	// Capture or replay the cluster interactions when requested.
+	if err := snapshot.StartFromEnvironment(); err != nil {
+		log.Fatalf("unable to start the snapshot: %s", err)
+	}
+	defer snapshot.Stop()
+	// Record or replay the transcript of the sessions when requested.
+	if err := interactive.StartTranscriptFromEnvironment(); err != nil {
+		log.Fatalf("unable to start the transcript: %s", err)
+	}
+	defer interactive.StopTranscript()
+	if err := snapshot.StoreConfigFile(config.GetConfigurationFilePath()); err != nil {
+		log.Fatalf("unable to store the configuration in the snapshot: %s", err)
+	}
+
 	// Initialize the claim with the start time, tnf version, etc.
 	claimRoot := createClaimRoot()
 	claimData := claimRoot.Claim
@@ -185,7 +242,17 @@ func TestTest(t *testing.T) {
 
 	// Run tests specs only if not in diagnostic mode, otherwise all TSs would run.
 	if !diagnosticMode {
+		// The running tests are cancelled on interrupt, or when the time budget is exhausted.
+		interruptContext, stopInterrupt := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		timeBudget = budget.New(interruptContext, config.GetTestEnvironment().Config.TimeBudget)
+		tnf.SetContextFunc(specContext)
+		tnf.SetReporter(ginkgoadapter.Reporter{})
 		ginkgo.RunSpecs(t, CnfCertificationTestSuiteName)
+		tnf.SetReporter(nil)
+		tnf.SetContextFunc(nil)
+		timeBudget.Stop()
+		stopInterrupt()
+		claimData.Configurations[sessionReconnectionsKey] = config.GetTestEnvironment().GetSessionReconnections()
 	}
 
 	endTime := time.Now()
@@ -205,7 +272,7 @@ func TestTest(t *testing.T) {
 
 	// Append results to claim file data.
 	claimData.RawResults = junitMap
-	claimData.Results = results.GetReconciledResults()
+	claimutil.SetResults(claimData, results.GetReconciledResults())
 
 	// Marshal the claim and output to file
 	payload := marshalClaimOutput(claimRoot)